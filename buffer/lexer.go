@@ -1,12 +1,16 @@
 package buffer
 
 import (
+	"errors"
 	"io"
 	"io/ioutil"
 )
 
 var nullBuffer = []byte{0}
 
+// errShortBuffer is the panic value of Peek and PeekErr when they need more data from the stream, see Retry.
+var errShortBuffer = errors.New("short buffer")
+
 // Lexer is a buffered reader that allows peeking forward and shifting, taking an io.Reader.
 // It keeps data in-memory until Free, taking a byte length, is called to move beyond the data.
 type Lexer struct {
@@ -16,6 +20,10 @@ type Lexer struct {
 	err   error
 
 	restore func()
+
+	stream  *StreamLexer // set when reading in chunks from a stream
+	offset  int          // offset of buf[0] in the stream
+	reading bool         // whether more data can be read from the stream, the buffer is not yet terminated by a NULL
 }
 
 // NewLexerBytes returns a new Lexer for a given io.Reader, and uses ioutil.ReadAll to read it into a byte slice.
@@ -71,6 +79,24 @@ func NewLexerBytes(b []byte) *Lexer {
 	return z
 }
 
+// NewLexerStream returns a new Lexer for a given io.Reader that reads the input in chunks using a StreamLexer, instead of reading it into memory at once.
+// Memory usage stays bounded as long as Free is called with the lengths returned by ShiftLen, which invalidates previously returned byte slices.
+// Peeking beyond the buffered data panics until the end of the stream has been reached, lexers must call Retry to recover and read in more data.
+// If the io.Reader implements Bytes, NewLexer is used instead.
+func NewLexerStream(r io.Reader) *Lexer {
+	if _, ok := r.(interface {
+		Bytes() []byte
+	}); ok {
+		return NewLexer(r)
+	}
+	z := NewStreamLexer(r)
+	return &Lexer{
+		buf:     z.buf,
+		stream:  z,
+		reading: true,
+	}
+}
+
 // Restore restores the replaced byte past the end of the buffer by NULL.
 func (z *Lexer) Restore() {
 	if z.restore != nil {
@@ -89,6 +115,12 @@ func (z *Lexer) PeekErr(pos int) error {
 	if z.err != nil {
 		return z.err
 	} else if z.pos+pos >= len(z.buf)-1 {
+		if z.reading {
+			if z.pos+pos < len(z.buf) {
+				return nil
+			}
+			panic(errShortBuffer)
+		}
 		return io.EOF
 	}
 	return nil
@@ -96,9 +128,13 @@ func (z *Lexer) PeekErr(pos int) error {
 
 // Peek returns the ith byte relative to the end position.
 // Peek returns 0 when an error has occurred, Err returns the error.
+// When reading from a stream, peeking beyond the buffered data panics with a short buffer error, see Retry.
 func (z *Lexer) Peek(pos int) byte {
-	pos += z.pos
-	return z.buf[pos]
+	i := z.pos + pos
+	if len(z.buf) <= i && z.reading {
+		panic(errShortBuffer)
+	}
+	return z.buf[i]
 }
 
 // PeekRune returns the rune and rune length of the ith byte relative to the end position.
@@ -147,12 +183,60 @@ func (z *Lexer) Shift() []byte {
 	return b
 }
 
-// Offset returns the character position in the buffer.
+// Offset returns the character position in the buffer, or in the stream when reading from a stream.
 func (z *Lexer) Offset() int {
-	return z.pos
+	return z.offset + z.pos
 }
 
-// Bytes returns the underlying buffer.
+// Bytes returns the underlying buffer. When reading from a stream, this only holds the data since the last call to Free.
 func (z *Lexer) Bytes() []byte {
+	if z.reading {
+		return z.buf[:len(z.buf):len(z.buf)]
+	}
 	return z.buf[: len(z.buf)-1 : len(z.buf)-1]
 }
+
+// Stream returns the StreamLexer when reading from a stream, or nil otherwise.
+func (z *Lexer) Stream() *StreamLexer {
+	return z.stream
+}
+
+// Free frees up bytes of length n from previously shifted tokens when reading from a stream, see StreamLexer.Free.
+func (z *Lexer) Free(n int) {
+	if z.stream != nil {
+		z.stream.Free(n)
+	}
+}
+
+// ShiftLen returns the number of bytes moved since the last call to ShiftLen when reading from a stream, see StreamLexer.ShiftLen. It returns zero otherwise.
+func (z *Lexer) ShiftLen() int {
+	if z.stream == nil {
+		return 0
+	}
+	z.stream.start = z.start
+	return z.stream.ShiftLen()
+}
+
+// Retry recovers from reading beyond the buffered data when reading from a stream, where err is the recovered panic value.
+// It rewinds to offset, which is the start of the current token in the stream, and reads in more data so that the token can be lexed again. It reads only as much as the reader returns and doesn't wait for more, so that tokens can be returned as soon as they arrive, such as from a pipe or socket.
+// It returns false when err is not caused by a short buffer or when the end of the stream has already been reached, in which case the caller should panic again.
+func (z *Lexer) Retry(err interface{}, offset int) bool {
+	if !z.reading || err != errShortBuffer {
+		return false
+	}
+
+	// read in at least one more byte, the buffer capacity grows with the token so that each read may return more
+	s := z.stream
+	s.start = offset - z.offset
+	s.pos = s.start
+	s.read(len(s.buf))
+	z.buf, z.start, z.pos, z.offset = s.buf, s.start, s.pos, s.offset
+	if s.err != nil {
+		if s.err != io.EOF {
+			z.err = s.err
+		}
+		z.buf = append(z.buf, 0)
+		z.reading = false
+	}
+	return true
+}
@@ -3,6 +3,7 @@ package buffer
 import (
 	"bytes"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/tdewolff/test"
@@ -107,3 +108,56 @@ func TestLexerRestore(t *testing.T) {
 	z.Restore()
 	test.Bytes(t, b, []byte{'a', 'b', 'c', 'd'}, "terminating NULL has been restored")
 }
+
+func TestLexerStream(t *testing.T) {
+	s := strings.Repeat("Lorem ipsum dolor sit amet. ", 1000)
+	z := NewLexerStream(test.NewPlainReader(bytes.NewBufferString(s)))
+	test.That(t, z.Stream() != nil, "must read from a stream")
+	test.T(t, z.ShiftLen(), 0)
+
+	var next func() []byte
+	next = func() (word []byte) {
+		z.Free(z.ShiftLen())
+		offset := z.Offset()
+		defer func() {
+			if err := recover(); err != nil {
+				test.That(t, z.Retry(err, offset), "must read more data")
+				word = next()
+			}
+		}()
+		for z.Peek(0) == ' ' {
+			z.Move(1)
+		}
+		z.Skip()
+		for c := z.Peek(0); c != ' ' && (c != 0 || z.Err() == nil); c = z.Peek(0) {
+			z.Move(1)
+		}
+		return z.Shift()
+	}
+
+	words := []string{}
+	for word := next(); len(word) != 0; word = next() {
+		words = append(words, string(word))
+	}
+	test.T(t, words, strings.Fields(s))
+	test.T(t, z.Offset(), len(s))
+	test.T(t, z.Err(), io.EOF, "error must be EOF at the end")
+	test.T(t, z.PeekErr(1), io.EOF, "error must be EOF past the end")
+	test.That(t, len(z.Bytes()) < len(s), "buffer must not hold the whole input")
+	test.That(t, len(z.stream.pool.pool) < 4, "buffers must be reused after freeing, have", len(z.stream.pool.pool))
+	test.That(t, !z.Retry(errShortBuffer, z.Offset()), "must not retry at the end of the stream")
+
+	z = NewLexerStream(test.NewPlainReader(bytes.NewBufferString(s)))
+	test.That(t, !z.Retry("error", 0), "must not retry for other panics")
+	recovered := func(f func()) (err interface{}) {
+		defer func() {
+			err = recover()
+		}()
+		f()
+		return nil
+	}
+	other := []byte{1, 2}
+	test.That(t, !z.Retry(recovered(func() { _ = other[len(other)+z.Offset()] }), 0), "must not retry for runtime errors")
+	test.That(t, z.Retry(recovered(func() { z.Peek(len(z.buf)) }), 0), "must retry for peeking past the end")
+	test.That(t, NewLexerStream(bytes.NewBufferString(s)).Stream() == nil, "must not stream when bytes are available")
+}
@@ -80,6 +80,18 @@ type StreamLexer struct {
 	prevStart int
 
 	free int
+
+	offset    int  // offset of buf[0] in the stream
+	line      int  // line number of buf[0]
+	lineStart int  // offset of the start of that line in the stream
+	cr        bool // whether the byte before buf[0] is \r
+	mark      mark // last position returned by Position, so that increasing offsets are counted from there
+}
+
+// mark is the line and the start offset of that line of an offset in the stream, and whether the byte before the offset is \r.
+type mark struct {
+	offset, line, lineStart int
+	cr                      bool
 }
 
 // NewStreamLexer returns a new StreamLexer for a given io.Reader with a 4kB estimated buffer size.
@@ -96,13 +108,15 @@ func NewStreamLexerSize(r io.Reader, size int) *StreamLexer {
 		Bytes() []byte
 	}); ok {
 		return &StreamLexer{
-			err: io.EOF,
-			buf: buffer.Bytes(),
+			err:  io.EOF,
+			buf:  buffer.Bytes(),
+			line: 1,
 		}
 	}
 	return &StreamLexer{
-		r:   r,
-		buf: make([]byte, 0, size),
+		r:    r,
+		buf:  make([]byte, 0, size),
+		line: 1,
 	}
 }
 
@@ -121,6 +135,17 @@ func (z *StreamLexer) read(pos int) byte {
 	if 2*p > c { // if the token is larger than half the buffer, increase buffer size
 		c = 2*c + p
 	}
+	// keep track of lines for the bytes that are dropped from the buffer
+	lines, last := countLines(z.buf[:z.start], z.cr)
+	z.line += lines
+	if last != -1 {
+		z.lineStart = z.offset + last
+	}
+	if 0 < z.start {
+		z.cr = z.buf[z.start-1] == '\r'
+	}
+	z.offset += z.start
+
 	d := len(z.buf) - z.start
 	buf := z.pool.swap(z.buf[:z.start], c)
 	copy(buf[:d], z.buf[z.start:]) // copy the left-overs (unfinished token) from the old buffer
@@ -133,6 +158,7 @@ func (z *StreamLexer) read(pos int) byte {
 	}
 	pos -= z.start
 	z.pos -= z.start
+	z.prevStart -= z.start
 	z.start, z.buf = 0, buf[:d]
 	if pos >= d {
 		return 0
@@ -196,7 +222,7 @@ func (z *StreamLexer) Rewind(pos int) {
 
 // Lexeme returns the bytes of the current selection.
 func (z *StreamLexer) Lexeme() []byte {
-	return z.buf[z.start:z.pos]
+	return z.buf[z.start:z.pos:z.pos]
 }
 
 // Skip collapses the position to the end of the selection.
@@ -210,7 +236,7 @@ func (z *StreamLexer) Shift() []byte {
 	if z.pos > len(z.buf) { // make sure we peeked at least as much as we shift
 		z.read(z.pos - 1)
 	}
-	b := z.buf[z.start:z.pos]
+	b := z.buf[z.start:z.pos:z.pos]
 	z.start = z.pos
 	return b
 }
@@ -221,3 +247,72 @@ func (z *StreamLexer) ShiftLen() int {
 	z.prevStart = z.start
 	return n
 }
+
+// Offset returns the character position in the stream.
+func (z *StreamLexer) Offset() int {
+	return z.offset + z.pos
+}
+
+// Position returns the line and column number of the given offset in the stream, both starting at one, with columns counted in bytes.
+// It also returns the bytes of that line as far as they are still buffered, and the index of offset into them. Offsets before the buffered data are moved forward to the start of the buffer.
+func (z *StreamLexer) Position(offset int) (line, col int, context []byte, i int) {
+	i = offset - z.offset
+	if i < 0 {
+		i = 0
+	} else if len(z.buf) < i {
+		i = len(z.buf)
+	}
+
+	from, m := 0, mark{z.offset, z.line, z.lineStart, z.cr}
+	if z.offset < z.mark.offset && z.mark.offset <= z.offset+i {
+		from, m = z.mark.offset-z.offset, z.mark
+		for 0 < from && z.buf[from-1] == 0xE2 || 1 < from && z.buf[from-2] == 0xE2 && z.buf[from-1] == 0x80 {
+			from-- // the mark was within a line separator that was not counted
+		}
+	}
+	n, last := countLines(z.buf[from:i], m.cr)
+	m.offset, m.line = z.offset+i, m.line+n
+	if last != -1 {
+		m.lineStart = z.offset + from + last
+	}
+	if from < i {
+		m.cr = z.buf[i-1] == '\r'
+	}
+	z.mark = m
+
+	line = m.line
+	start := m.lineStart - z.offset
+	col = i - start + 1
+	if start < 0 {
+		start = 0
+	}
+	end := i
+	for end < len(z.buf) && z.buf[end] != '\n' && z.buf[end] != '\r' {
+		end++
+	}
+	return line, col, z.buf[start:end:end], i - start
+}
+
+// countLines returns the number of line breaks in b and the index of the start of the last line, or -1 if b contains no line breaks.
+// Like parse.Position, it recognizes \n, \r, \r\n, U+2028, and U+2029 as line breaks. The cr argument is whether b follows a \r.
+func countLines(b []byte, cr bool) (n, last int) {
+	last = -1
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == '\n' {
+			if !cr {
+				n++
+			}
+			last = i + 1
+		} else if c == '\r' {
+			n++
+			last = i + 1
+		} else if c == 0xE2 && i+2 < len(b) && b[i+1] == 0x80 && (b[i+2] == 0xA8 || b[i+2] == 0xA9) {
+			n++
+			i += 2
+			last = i + 1
+		}
+		cr = c == '\r'
+	}
+	return
+}
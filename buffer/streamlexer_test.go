@@ -3,6 +3,7 @@ package buffer
 import (
 	"bytes"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/tdewolff/test"
@@ -90,6 +91,22 @@ func TestStreamLexerShift(t *testing.T) {
 	test.That(t, z.ShiftLen() == len("Lorem "), "shifted length must equal last shift")
 }
 
+func TestStreamLexerFree(t *testing.T) {
+	s := strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 1000)
+	z := NewStreamLexerSize(test.NewPlainReader(bytes.NewBufferString(s)), 16)
+	n := 0
+	for z.Peek(0) != 0 {
+		z.Move(1)
+		if z.Peek(0) == ' ' {
+			n += len(z.Shift())
+			test.T(t, z.ShiftLen(), n, "shifted length must equal bytes shifted since the last free")
+			z.Free(n)
+			n = 0
+		}
+	}
+	test.That(t, len(z.pool.pool) < 4, "buffers must be reused after freeing, have", len(z.pool.pool))
+}
+
 func TestStreamLexerSmall(t *testing.T) {
 	s := `abcdefghijklm`
 	z := NewStreamLexerSize(test.NewPlainReader(bytes.NewBufferString(s)), 4)
@@ -146,3 +163,44 @@ func TestStreamLexerEmptyReader(t *testing.T) {
 	test.T(t, z.Err(), io.EOF, "error must be EOF")
 	test.That(t, z.Peek(0) == 0, "second peek must also yield error")
 }
+
+func TestStreamLexerPosition(t *testing.T) {
+	s := "ab\ncd\r\nef\rgh\u2028ij"
+	z := NewStreamLexerSize(test.NewPlainReader(bytes.NewBufferString(s)), 2)
+	for i := 0; i < len(s); i++ {
+		z.Move(1)
+		z.Peek(0)
+		z.Skip()
+		z.Free(z.ShiftLen())
+		test.T(t, z.Offset(), i+1, "offset")
+	}
+
+	line, col, context, i := z.Position(len(s) - 1)
+	test.T(t, line, 5)
+	test.T(t, col, 2)
+	test.String(t, string(context), "j") // the start of the line is no longer buffered
+	test.T(t, i, 0)
+
+	z = NewStreamLexerSize(test.NewPlainReader(bytes.NewBufferString(s)), 2)
+	z.Move(8)
+	z.Peek(0)
+	z.Skip()
+	z.Move(3)
+	z.Peek(0)
+	line, col, context, i = z.Position(8)
+	test.T(t, line, 3)
+	test.T(t, col, 2)
+	test.String(t, string(context), "ef")
+	test.T(t, i, 1)
+
+	// increasing offsets continue counting from the previous position
+	z = NewStreamLexer(bytes.NewBufferString(s))
+	for offset := 0; offset <= len(s); offset++ {
+		line, col, context, i := z.Position(offset)
+		line2, col2, context2, i2 := NewStreamLexer(bytes.NewBufferString(s)).Position(offset)
+		test.T(t, line, line2, "line")
+		test.T(t, col, col2, "column")
+		test.String(t, string(context), string(context2))
+		test.T(t, i, i2)
+	}
+}
@@ -10,12 +10,16 @@ type Hash uint32
 
 // Unique hash definitions to be used instead of strings
 const (
-	Document  Hash = 0x8    // document
-	Font_Face Hash = 0x809  // font-face
-	Keyframes Hash = 0x1109 // keyframes
-	Media     Hash = 0x2105 // media
-	Page      Hash = 0x2604 // page
-	Supports  Hash = 0x1908 // supports
+	Container      Hash = 0xe09  // container
+	Document       Hash = 0x2908 // document
+	Font_Face      Hash = 0x1709 // font-face
+	Keyframes      Hash = 0x2009 // keyframes
+	Layer          Hash = 0x3905 // layer
+	Media          Hash = 0x3e05 // media
+	Page           Hash = 0x4804 // page
+	Scope          Hash = 0x4305 // scope
+	Starting_Style Hash = 0xe    // starting-style
+	Supports       Hash = 0x3108 // supports
 )
 
 // String returns the hash' name.
@@ -61,15 +65,19 @@ NEXT:
 	return 0
 }
 
-const _Hash_hash0 = 0x9acb0442
-const _Hash_maxLen = 9
-const _Hash_text = "documentfont-facekeyframesupportsmediapage"
+const _Hash_hash0 = 0xf0c5341e
+const _Hash_maxLen = 14
+const _Hash_text = "starting-stylecontainerfont-facekeyframesdocumentsupportslayermediascopepage"
 
-var _Hash_table = [1 << 3]Hash{
-	0x1: 0x2604, // page
-	0x2: 0x2105, // media
-	0x3: 0x809,  // font-face
-	0x5: 0x1109, // keyframes
-	0x6: 0x1908, // supports
-	0x7: 0x8,    // document
+var _Hash_table = [1 << 4]Hash{
+	0x3: 0x2908, // document
+	0x5: 0x4804, // page
+	0x6: 0x3905, // layer
+	0x9: 0xe,    // starting-style
+	0xa: 0x4305, // scope
+	0xb: 0x2009, // keyframes
+	0xc: 0x3108, // supports
+	0xd: 0xe09,  // container
+	0xe: 0x3e05, // media
+	0xf: 0x1709, // font-face
 }
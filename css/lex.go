@@ -138,13 +138,25 @@ func (tt TokenType) String() string {
 
 // Lexer is the state for the lexer.
 type Lexer struct {
-	r *buffer.Lexer
+	r      *buffer.Lexer
+	stream bool // reading from a stream, see nextStream
+	free   bool // free the previous token when calling Next
 }
 
 // NewLexer returns a new Lexer for a given io.Reader.
 func NewLexer(r io.Reader) *Lexer {
 	return &Lexer{
-		buffer.NewLexer(r),
+		r: buffer.NewLexer(r),
+	}
+}
+
+// NewStreamLexer returns a new Lexer that reads the stylesheet from r in chunks, so that memory usage does not grow with the size of the stylesheet.
+// The data of a token is freed by the next call to Next, copy it in order to keep it.
+func NewStreamLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		r:      buffer.NewLexerStream(r),
+		stream: true,
+		free:   true,
 	}
 }
 
@@ -165,6 +177,9 @@ func (l *Lexer) Offset() int {
 
 // Next returns the next Token. It returns ErrorToken when an error was encountered. Using Err() one can retrieve the error message.
 func (l *Lexer) Next() (TokenType, []byte) {
+	if l.stream {
+		return l.nextStream()
+	}
 	switch l.r.Peek(0) {
 	case ' ', '\t', '\n', '\r', '\f':
 		l.r.Move(1)
@@ -254,6 +269,93 @@ func (l *Lexer) Next() (TokenType, []byte) {
 	return DelimToken, l.r.Shift()
 }
 
+// nextStream calls Next when reading from a stream. The CSS lexer keeps no state between tokens, so when Next runs out of buffered data it is enough to read in more data and lex the token again from its start.
+func (l *Lexer) nextStream() (tt TokenType, data []byte) {
+	if l.free {
+		l.r.Free(l.r.ShiftLen())
+	}
+	offset := l.r.Offset()
+	defer func() {
+		if err := recover(); err != nil {
+			if !l.r.Retry(err, offset) {
+				panic(err)
+			}
+			tt, data = l.nextStream()
+		}
+	}()
+
+	l.stream = false
+	tt, data = l.Next()
+	l.stream = true
+	return
+}
+
+// peekRule returns true if the input that follows is the prelude of a rule, that is when a { comes before a ; or } outside of parentheses and brackets. The position is not changed. It is used to distinguish nested rules from declarations.
+func (l *Lexer) peekRule() (rule bool) {
+	if l.stream {
+		offset := l.r.Offset()
+		defer func() {
+			if err := recover(); err != nil {
+				if !l.r.Retry(err, offset) {
+					panic(err)
+				}
+				rule = l.peekRule()
+			}
+		}()
+	}
+
+	level := 0
+	for i := 0; ; i++ {
+		switch c := l.r.Peek(i); c {
+		case 0:
+			if l.r.PeekErr(i) != nil {
+				return false
+			}
+		case '\\':
+			i++
+		case '"', '\'':
+			for i++; ; i++ {
+				if d := l.r.Peek(i); d == c || d == '\n' || d == '\r' || d == '\f' {
+					break
+				} else if d == '\\' {
+					i++
+				} else if d == 0 && l.r.PeekErr(i) != nil {
+					return false
+				}
+			}
+		case '/':
+			if l.r.Peek(i+1) == '*' {
+				for i += 2; l.r.Peek(i) != '*' || l.r.Peek(i+1) != '/'; i++ {
+					if l.r.Peek(i) == 0 && l.r.PeekErr(i) != nil {
+						return false
+					}
+				}
+				i++
+			}
+		case '(', '[':
+			level++
+		case ')', ']':
+			if 0 < level {
+				level--
+			}
+		case '{':
+			if level == 0 {
+				return true
+			}
+			level++
+		case '}':
+			if level == 0 {
+				return false
+			}
+			level--
+		case ';':
+			if level == 0 {
+				return false
+			}
+		}
+	}
+}
+
 ////////////////////////////////////////////////////////////////
 
 /*
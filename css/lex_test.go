@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"testing/iotest"
 
+	"github.com/tdewolff/parse/v2/internal/testio"
 	"github.com/tdewolff/test"
 )
 
@@ -151,6 +153,39 @@ func TestOffset(t *testing.T) {
 	test.T(t, l.Offset(), 26) // }
 }
 
+func TestStreamLexer(t *testing.T) {
+	css := `@import url("x.css"); .a > b:hover, #c[d="e"]{color: #fff !important; margin: -1.5em 0 calc(100% - 2px); content: "\"q\""} /* c */ @media (min-width: 10px) { a { b: c } } <!-- --> U+0-7F`
+	l := NewLexer(bytes.NewBufferString(css))
+	z := NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString(css)))
+	for {
+		tt, data := l.Next()
+		ttStream, dataStream := z.Next()
+		test.T(t, ttStream, tt, "token types must match")
+		test.String(t, string(dataStream), string(data), "token data must match")
+		test.T(t, z.Offset(), l.Offset(), "offsets must match")
+		if tt == ErrorToken {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+}
+
+func TestStreamLexerMemory(t *testing.T) {
+	item := []byte(".a > b:hover { color: #fff; margin: -1.5em 0 } /* c */\n")
+	r := testio.NewRepeatReader(item, 500000)
+
+	l := NewStreamLexer(r)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for tt, _ := l.Next(); tt != ErrorToken; tt, _ = l.Next() {
+			n++
+		}
+	})
+	test.T(t, l.Err(), io.EOF)
+	test.T(t, n, 28*500000)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*500000, "bytes of input")
+}
+
 ////////////////////////////////////////////////////////////////
 
 func ExampleNewLexer() {
@@ -0,0 +1,332 @@
+package css
+
+import (
+	"bytes"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/strconv"
+)
+
+// MediaEnvironment is the device against which media queries are evaluated.
+type MediaEnvironment struct {
+	Type          string  // media type such as screen or print, screen if empty
+	Width, Height float64 // viewport size in CSS pixels
+	Resolution    float64 // in dppx, 1 if zero
+	ColorScheme   string  // light or dark, light if empty
+	ReducedMotion bool
+	FontSize      float64 // initial font size in CSS pixels for em, rem, ex and ch units, 16 if zero
+	Monochrome    bool    // monochrome device with one bit per pixel instead of a color device with eight bits per color component
+	Hover         string  // hover or none, hover if empty or none for print
+	Pointer       string  // fine, coarse or none, fine if empty or none for print
+}
+
+// Match returns true if any of the media queries matches the environment. An empty list matches all environments.
+func (l MediaQueryList) Match(env MediaEnvironment) bool {
+	if len(l) == 0 {
+		return true
+	}
+	for _, q := range l {
+		if q.Match(env) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns true if the media query matches the environment. Unknown media features and general enclosed conditions evaluate to unknown, and a query that evaluates to unknown does not match.
+func (q MediaQuery) Match(env MediaEnvironment) bool {
+	result := trueResult
+	if len(q.Type) != 0 && !bytes.Equal(q.Type, []byte("all")) {
+		envType := env.Type
+		if envType == "" {
+			envType = "screen"
+		}
+		if !parse.EqualFold(q.Type, []byte(envType)) {
+			result = falseResult
+		}
+	}
+	if result == trueResult && q.Condition != nil {
+		result = evalCondition(q.Condition, env)
+	}
+	if q.Not {
+		result = result.not()
+	}
+	return result == trueResult
+}
+
+////////////////////////////////////////////////////////////////
+
+// mediaResult is the three-valued logic result of a media condition.
+type mediaResult int
+
+const (
+	falseResult mediaResult = iota
+	unknownResult
+	trueResult
+)
+
+func (r mediaResult) not() mediaResult {
+	return trueResult - r
+}
+
+func boolResult(b bool) mediaResult {
+	if b {
+		return trueResult
+	}
+	return falseResult
+}
+
+func evalCondition(cond Condition, env MediaEnvironment) mediaResult {
+	switch c := cond.(type) {
+	case *NotCondition:
+		return evalCondition(c.Condition, env).not()
+	case *AndCondition:
+		result := trueResult
+		for _, cond := range c.Conditions {
+			if r := evalCondition(cond, env); r < result {
+				result = r
+			}
+		}
+		return result
+	case *OrCondition:
+		result := falseResult
+		for _, cond := range c.Conditions {
+			if r := evalCondition(cond, env); result < r {
+				result = r
+			}
+		}
+		return result
+	case *MediaFeature:
+		return evalMediaFeature(c, env)
+	}
+	return unknownResult
+}
+
+func evalMediaFeature(f *MediaFeature, env MediaEnvironment) mediaResult {
+	name := f.Name
+	cmp := EqualComparison
+	if bytes.HasPrefix(name, []byte("min-")) {
+		name, cmp = name[4:], GreaterEqualComparison
+	} else if bytes.HasPrefix(name, []byte("max-")) {
+		name, cmp = name[4:], LessEqualComparison
+	}
+	if cmp != EqualComparison && f.Value == nil {
+		return unknownResult // min- and max- prefixes require a value
+	}
+
+	switch string(name) {
+	case "width", "height", "aspect-ratio", "resolution", "color", "color-index", "monochrome":
+		var actual float64
+		switch string(name) {
+		case "width":
+			actual = env.Width
+		case "height":
+			actual = env.Height
+		case "aspect-ratio":
+			if env.Height == 0 {
+				return unknownResult
+			}
+			actual = env.Width / env.Height
+		case "resolution":
+			actual = env.Resolution
+			if actual == 0 {
+				actual = 1.0
+			}
+		case "color":
+			if !env.Monochrome {
+				actual = 8.0
+			}
+		case "monochrome":
+			if env.Monochrome {
+				actual = 1.0
+			}
+		}
+		if f.Value == nil && len(f.Ranges) == 0 {
+			return boolResult(actual != 0)
+		} else if f.Value != nil {
+			return compareMediaValue(name, actual, cmp, f.Value, env)
+		}
+		result := trueResult
+		for _, r := range f.Ranges {
+			if res := compareMediaValue(name, actual, r.Comparison, r.Value, env); res < result {
+				result = res
+			}
+		}
+		return result
+	case "grid":
+		if f.Value == nil && len(f.Ranges) == 0 {
+			return falseResult // only bitmap devices are supported
+		} else if cmp != EqualComparison || len(f.Ranges) != 0 {
+			return unknownResult
+		} else if i, ok := mediaInteger(f.Value); ok {
+			return boolResult(i == 0)
+		}
+		return unknownResult
+	case "orientation", "prefers-color-scheme", "prefers-reduced-motion", "hover", "any-hover", "pointer", "any-pointer", "scan":
+		isPrint := parse.EqualFold([]byte(env.Type), []byte("print"))
+		var actual string
+		switch string(name) {
+		case "orientation":
+			actual = "landscape"
+			if env.Width <= env.Height {
+				actual = "portrait"
+			}
+		case "prefers-color-scheme":
+			actual = env.ColorScheme
+			if actual == "" {
+				actual = "light"
+			}
+		case "prefers-reduced-motion":
+			actual = "no-preference"
+			if env.ReducedMotion {
+				actual = "reduce"
+			}
+		case "hover", "any-hover":
+			actual = env.Hover
+			if actual == "" {
+				actual = "hover"
+				if isPrint {
+					actual = "none"
+				}
+			}
+		case "pointer", "any-pointer":
+			actual = env.Pointer
+			if actual == "" {
+				actual = "fine"
+				if isPrint {
+					actual = "none"
+				}
+			}
+		case "scan":
+			actual = "progressive"
+		}
+		if f.Value == nil && len(f.Ranges) == 0 {
+			return boolResult(actual != "no-preference" && actual != "none")
+		} else if cmp != EqualComparison || len(f.Ranges) != 0 || len(f.Value) != 1 || f.Value[0].TokenType != IdentToken {
+			return unknownResult // discrete features have no range context
+		}
+		return boolResult(parse.EqualFold(f.Value[0].Data, []byte(actual)))
+	}
+	return unknownResult
+}
+
+// compareMediaValue compares the actual value of a range feature against a value, which must be of the type of the feature.
+func compareMediaValue(name []byte, actual float64, cmp Comparison, value []Token, env MediaEnvironment) mediaResult {
+	var f float64
+	var ok bool
+	switch string(name) {
+	case "width", "height":
+		f, ok = mediaLength(value, env)
+	case "aspect-ratio":
+		f, ok = mediaRatio(value)
+	case "resolution":
+		f, ok = mediaResolution(value)
+	case "color", "color-index", "monochrome":
+		f, ok = mediaInteger(value)
+	}
+	if !ok {
+		return unknownResult
+	}
+	switch cmp {
+	case EqualComparison:
+		return boolResult(actual == f)
+	case LessComparison:
+		return boolResult(actual < f)
+	case LessEqualComparison:
+		return boolResult(actual <= f)
+	case GreaterComparison:
+		return boolResult(actual > f)
+	case GreaterEqualComparison:
+		return boolResult(actual >= f)
+	}
+	return unknownResult
+}
+
+// splitDimension returns the number and the lowercase unit of a number or dimension token.
+func splitDimension(value []Token) (float64, string, bool) {
+	if len(value) != 1 || value[0].TokenType != NumberToken && value[0].TokenType != DimensionToken {
+		return 0, "", false
+	}
+	f, n := strconv.ParseFloat(value[0].Data)
+	if n == 0 {
+		return 0, "", false
+	}
+	return f, string(parse.ToLower(parse.Copy(value[0].Data[n:]))), true
+}
+
+func mediaLength(value []Token, env MediaEnvironment) (float64, bool) {
+	f, unit, ok := splitDimension(value)
+	if !ok {
+		return 0, false
+	}
+	fontSize := env.FontSize
+	if fontSize == 0 {
+		fontSize = 16.0
+	}
+	switch unit {
+	case "":
+		return f, f == 0 // only zero may omit the unit
+	case "px":
+		return f, true
+	case "cm":
+		return f * 96.0 / 2.54, true
+	case "mm":
+		return f * 96.0 / 25.4, true
+	case "q":
+		return f * 96.0 / 101.6, true
+	case "in":
+		return f * 96.0, true
+	case "pc":
+		return f * 16.0, true
+	case "pt":
+		return f * 96.0 / 72.0, true
+	case "em", "rem":
+		return f * fontSize, true
+	case "ex", "ch":
+		return f * fontSize / 2.0, true
+	case "vw":
+		return f * env.Width / 100.0, true
+	case "vh":
+		return f * env.Height / 100.0, true
+	}
+	return 0, false
+}
+
+func mediaInteger(value []Token) (float64, bool) {
+	f, unit, ok := splitDimension(value)
+	if !ok || unit != "" || f != float64(int64(f)) || bytes.IndexAny(value[0].Data, ".eE") != -1 {
+		return 0, false
+	}
+	return f, true
+}
+
+func mediaRatio(value []Token) (float64, bool) {
+	if len(value) == 3 {
+		num, _, ok := splitDimension(value[:1])
+		den, _, ok2 := splitDimension(value[2:])
+		if !ok || !ok2 || value[0].TokenType != NumberToken || value[2].TokenType != NumberToken || den == 0 {
+			return 0, false
+		}
+		return num / den, true
+	} else if len(value) == 1 && value[0].TokenType == NumberToken {
+		f, _, ok := splitDimension(value)
+		return f, ok
+	}
+	return 0, false
+}
+
+func mediaResolution(value []Token) (float64, bool) {
+	f, unit, ok := splitDimension(value)
+	if !ok {
+		return 0, false
+	}
+	switch unit {
+	case "dppx", "x":
+		return f, true
+	case "dpi":
+		return f / 96.0, true
+	case "dpcm":
+		return f * 2.54 / 96.0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,123 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestMediaQueryListMatch(t *testing.T) {
+	phone := MediaEnvironment{Width: 375, Height: 812, Resolution: 3, ColorScheme: "dark", ReducedMotion: true, Hover: "none", Pointer: "coarse"}
+	desktop := MediaEnvironment{Width: 1920, Height: 1080}
+	printer := MediaEnvironment{Type: "print", Width: 794, Height: 1123, Resolution: 3.125, Monochrome: true}
+
+	var matchTests = []struct {
+		media                   string
+		phone, desktop, printer bool
+	}{
+		{"", true, true, true},
+		{"all", true, true, true},
+		{"screen", true, true, false},
+		{"PRINT", false, false, true},
+		{"not print", true, true, false},
+		{"only screen", true, true, false},
+		{"print, screen", true, true, true},
+		{"tv", false, false, false},
+		{"not tv", true, true, true},
+		{"(min-width: 400px)", false, true, true},
+		{"(max-width: 400px)", true, false, false},
+		{"(width: 375px)", true, false, false},
+		{"(width > 375px)", false, true, true},
+		{"(width >= 375px)", true, true, true},
+		{"(700px < width < 1000px)", false, false, true},
+		{"(1000px > width >= 375px)", true, false, true},
+		{"screen and (min-width: 25em)", false, true, false},
+		{"(min-width: 20rem)", true, true, true},
+		{"(min-width: 10in)", false, true, false},
+		{"(max-width: 0)", false, false, false},
+		{"(width)", true, true, true},
+		{"(min-height: 900px)", false, true, true},
+		{"(orientation: portrait)", true, false, true},
+		{"(orientation: landscape)", false, true, false},
+		{"(orientation)", true, true, true},
+		{"(aspect-ratio > 1)", false, true, false},
+		{"(min-aspect-ratio: 16/9)", false, true, false},
+		{"(aspect-ratio: 16/9)", false, true, false},
+		{"(min-resolution: 2dppx)", true, false, true},
+		{"(resolution >= 192dpi)", true, false, true},
+		{"(max-resolution: 1x)", false, true, false},
+		{"(prefers-color-scheme: dark)", true, false, false},
+		{"(prefers-color-scheme: light)", false, true, true},
+		{"(prefers-reduced-motion: reduce)", true, false, false},
+		{"(prefers-reduced-motion: no-preference)", false, true, true},
+		{"(prefers-reduced-motion)", true, false, false},
+		{"(min-width: 400px) and (prefers-color-scheme: dark)", false, false, false},
+		{"(max-width: 400px) or (prefers-color-scheme: dark)", true, false, false},
+		{"not (max-width: 400px)", false, true, true},
+		{"not screen and (max-width: 400px)", false, true, true},
+		{"screen and (not (orientation: portrait))", false, true, false},
+		{"(color)", true, true, false},
+		{"(min-color: 8)", true, true, false},
+		{"(color: 4)", false, false, false},
+		{"(color-index)", false, false, false},
+		{"(monochrome)", false, false, true},
+		{"(monochrome: 0)", true, true, false},
+		{"not all and (monochrome)", true, true, false},
+		{"(grid)", false, false, false},
+		{"(grid: 0)", true, true, true},
+		{"(scan: progressive)", true, true, true},
+		{"(hover)", false, true, false},
+		{"(hover: hover)", false, true, false},
+		{"(any-hover: none)", true, false, true},
+		{"(pointer: coarse)", true, false, false},
+		{"(any-pointer: fine)", false, true, false},
+		{"(pointer)", true, true, false},
+
+		// unknown features and values
+		{"(x-foo: bar)", false, false, false},
+		{"not (x-foo: bar)", false, false, false},
+		{"(x-foo: bar) or (min-width: 400px)", false, true, true},
+		{"(x-foo: bar) and (min-width: 400px)", false, false, false},
+		{"not ((x-foo: bar) and (min-width: 400px))", true, false, false},
+		{"(color: 8px)", false, false, false},
+		{"(color: 8.5)", false, false, false},
+		{"(min-grid: 0)", false, false, false},
+		{"(width > 10%)", false, false, false},
+		{"(width > 10)", false, false, false},
+		{"(min-orientation: portrait)", false, false, false},
+		{"(foo bar)", false, false, false},
+		{"screen and", false, false, false},
+	}
+	for _, tt := range matchTests {
+		t.Run(tt.media, func(t *testing.T) {
+			list, _ := ParseMediaQueryList(prelude("@media " + tt.media))
+			test.T(t, list.Match(phone), tt.phone, "phone")
+			test.T(t, list.Match(desktop), tt.desktop, "desktop")
+			test.T(t, list.Match(printer), tt.printer, "printer")
+		})
+	}
+}
+
+func TestMediaQueryMatchDefaults(t *testing.T) {
+	env := MediaEnvironment{Width: 800, Height: 600, FontSize: 20}
+	for _, media := range []string{"screen", "(resolution: 1dppx)", "(prefers-color-scheme: light)", "(width: 40em)", "(width: 80ch)", "(height = 100vh)", "(color)", "(hover: hover)", "(pointer: fine)"} {
+		list, _ := ParseMediaQueryList(prelude("@media " + media))
+		test.That(t, list.Match(env), media)
+	}
+}
+
+func ExampleMediaQueryList_Match() {
+	sheet, _ := ParseStylesheet(bytes.NewBufferString("a { color: black } @media (max-width: 600px) { a { color: red } } @media print { a { color: gray } }"))
+	phone := MediaEnvironment{Width: 375, Height: 812}
+	for _, rule := range sheet.Rules {
+		if atRule, ok := rule.(*AtRule); ok && string(atRule.Name) == "@media" {
+			list, _ := ParseMediaQueryList(atRule.Prelude)
+			fmt.Println(list, list.Match(phone))
+		}
+	}
+	// Output:
+	// (max-width:600px) true
+	// print false
+}
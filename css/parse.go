@@ -7,7 +7,6 @@ import (
 	"strconv"
 
 	"github.com/tdewolff/parse/v2"
-	"github.com/tdewolff/parse/v2/buffer"
 )
 
 var wsBytes = []byte(" ")
@@ -79,12 +78,14 @@ func (t Token) String() string {
 // Parser is the state for the parser.
 type Parser struct {
 	l      *Lexer
+	free   bool // free the previous grammar when calling Next
 	state  []State
 	err    string
 	errPos int
 
-	buf   []Token
-	level int
+	buf     []Token
+	level   int
+	nesting int // number of enclosing style rules
 
 	tt          TokenType
 	data        []byte
@@ -92,11 +93,28 @@ type Parser struct {
 	prevWS      bool
 	prevEnd     bool
 	prevComment bool
+
+	offset       int       // start offset of the current grammar
+	comments     []Comment // comments skipped before the current grammar
+	keepComments bool      // keep the comments within selectors and declaration values as CommentToken in Values, see ParseStylesheet
 }
 
 // NewParser returns a new CSS parser from an io.Reader. isInline specifies whether this is an inline style attribute.
 func NewParser(r io.Reader, isInline bool) *Parser {
-	l := NewLexer(r)
+	return newParser(NewLexer(r), isInline)
+}
+
+// NewStreamParser returns a new CSS parser that reads the stylesheet from r in chunks. isInline specifies whether this is an inline style attribute.
+// The tokens of a grammar are kept until the next call to Next, so that the data returned by Next and Values must be copied in order to keep it longer.
+func NewStreamParser(r io.Reader, isInline bool) *Parser {
+	l := NewStreamLexer(r)
+	l.free = false // the parser frees the tokens of the previous grammar instead
+	p := newParser(l, isInline)
+	p.free = true
+	return p
+}
+
+func newParser(l *Lexer, isInline bool) *Parser {
 	p := &Parser{
 		l:     l,
 		state: make([]State, 0, 4),
@@ -118,8 +136,7 @@ func (p *Parser) HasParseError() bool {
 // Err returns the error encountered during parsing, this is often io.EOF but also other errors can be returned.
 func (p *Parser) Err() error {
 	if p.err != "" {
-		r := buffer.NewReader(p.l.r.Bytes())
-		return parse.NewError(r, p.errPos, p.err)
+		return parse.NewErrorLexerOffset(p.l.r, p.errPos, p.err)
 	}
 	return p.l.Err()
 }
@@ -136,13 +153,19 @@ func (p *Parser) Offset() int {
 
 // Next returns the next Grammar. It returns ErrorGrammar when an error was encountered. Using Err() one can retrieve the error message.
 func (p *Parser) Next() (GrammarType, TokenType, []byte) {
+	if p.free {
+		p.l.r.Free(p.l.r.ShiftLen())
+	}
 	p.err = ""
+	p.comments = p.comments[:0]
 
 	if p.prevEnd {
 		p.tt, p.data = RightBraceToken, endBytes
+		p.offset = p.l.Offset() - 1
 		p.prevEnd = false
 	} else {
 		p.tt, p.data = p.popToken(true)
+		p.offset = p.l.Offset() - len(p.data)
 	}
 	gt := p.state[len(p.state)-1](p)
 	return gt, p.tt, p.data
@@ -162,8 +185,11 @@ func (p *Parser) popToken(allowComment bool) (TokenType, []byte) {
 			p.prevWS = true
 		} else {
 			p.prevComment = true
-			if allowComment && len(p.state) == 1 {
-				break
+			if allowComment {
+				if len(p.state) == 1 {
+					break
+				}
+				p.comments = append(p.comments, Comment{p.l.Offset() - len(data), data})
 			}
 		}
 		tt, data = p.l.Next()
@@ -171,6 +197,21 @@ func (p *Parser) popToken(allowComment bool) (TokenType, []byte) {
 	return tt, data
 }
 
+// popValueToken pops the next token of a selector or declaration value, which may be a comment if they are kept.
+func (p *Parser) popValueToken() (TokenType, []byte) {
+	if !p.keepComments {
+		return p.popToken(false)
+	}
+	p.prevWS = false
+	p.prevComment = false
+	tt, data := p.l.Next()
+	for !p.keepWS && tt == WhitespaceToken {
+		p.prevWS = true
+		tt, data = p.l.Next()
+	}
+	return tt, data
+}
+
 func (p *Parser) initBuf() {
 	p.buf = p.buf[:0]
 }
@@ -254,7 +295,9 @@ func (p *Parser) parseAtRule() GrammarType {
 		if tt == LeftBraceToken && p.level == 0 {
 			if atRule == Font_Face || atRule == Page {
 				p.state = append(p.state, (*Parser).parseAtRuleDeclarationList)
-			} else if atRule == Document || atRule == Keyframes || atRule == Media || atRule == Supports {
+			} else if 0 < p.nesting && (atRule == Container || atRule == Layer || atRule == Media || atRule == Scope || atRule == Starting_Style || atRule == Supports) {
+				p.state = append(p.state, (*Parser).parseAtRuleStyleBlock)
+			} else if atRule == Container || atRule == Document || atRule == Keyframes || atRule == Layer || atRule == Media || atRule == Scope || atRule == Starting_Style || atRule == Supports {
 				p.state = append(p.state, (*Parser).parseAtRuleRuleList)
 			} else {
 				p.state = append(p.state, (*Parser).parseAtRuleUnknown)
@@ -310,6 +353,18 @@ func (p *Parser) parseAtRuleDeclarationList() GrammarType {
 	return p.parseDeclarationList()
 }
 
+// parseAtRuleStyleBlock parses the block of a nested group rule inside a style rule, which contains declarations and nested rules like the style rule itself.
+func (p *Parser) parseAtRuleStyleBlock() GrammarType {
+	for p.tt == SemicolonToken {
+		p.tt, p.data = p.popToken(false)
+	}
+	if p.tt == RightBraceToken || p.tt == ErrorToken {
+		p.state = p.state[:len(p.state)-1]
+		return EndAtRuleGrammar
+	}
+	return p.parseStyleBlock()
+}
+
 func (p *Parser) parseAtRuleUnknown() GrammarType {
 	p.keepWS = true
 	if p.tt == RightBraceToken && p.level == 0 || p.tt == ErrorToken {
@@ -339,10 +394,17 @@ func (p *Parser) parseQualifiedRule() GrammarType {
 			p.data = emptyBytes
 			first = false
 		} else {
-			tt, data = p.popToken(false)
+			tt, data = p.popValueToken()
 		}
-		if tt == LeftBraceToken && p.level == 0 {
+		if tt == CommentToken {
+			if p.prevWS && !skipWS && !inAttrSel {
+				p.pushBuf(WhitespaceToken, wsBytes)
+			}
+			p.pushBuf(tt, data)
+			continue
+		} else if tt == LeftBraceToken && p.level == 0 {
 			p.state = append(p.state, (*Parser).parseQualifiedRuleDeclarationList)
+			p.nesting++
 			return BeginRulesetGrammar
 		} else if tt == ErrorToken {
 			p.err, p.errPos = "CSS parse error: unexpected ending in qualified rule", p.l.r.Offset()
@@ -353,7 +415,7 @@ func (p *Parser) parseQualifiedRule() GrammarType {
 			p.level--
 		}
 		if len(data) == 1 && (data[0] == ',' || data[0] == '>' || data[0] == '+' || data[0] == '~') {
-			if data[0] == ',' {
+			if data[0] == ',' && p.level == 0 {
 				return QualifiedRuleGrammar
 			}
 			skipWS = true
@@ -377,8 +439,17 @@ func (p *Parser) parseQualifiedRuleDeclarationList() GrammarType {
 	}
 	if p.tt == RightBraceToken || p.tt == ErrorToken {
 		p.state = p.state[:len(p.state)-1]
+		p.nesting--
 		return EndRulesetGrammar
 	}
+	return p.parseStyleBlock()
+}
+
+// parseStyleBlock parses a declaration, a nested at-rule, or a nested style rule following CSS Nesting, which is a style rule when a { comes before the end of the declaration.
+func (p *Parser) parseStyleBlock() GrammarType {
+	if p.tt != AtKeywordToken && p.tt != CustomPropertyNameToken && p.tt != LeftBraceToken && p.tt != RightBraceToken && p.tt != ErrorToken && p.l.peekRule() {
+		return p.parseQualifiedRule()
+	}
 	return p.parseDeclarationList()
 }
 
@@ -398,8 +469,14 @@ func (p *Parser) parseDeclaration() GrammarType {
 
 	skipWS := true
 	for {
-		tt, data := p.popToken(false)
-		if (tt == SemicolonToken || tt == RightBraceToken) && p.level == 0 || tt == ErrorToken {
+		tt, data := p.popValueToken()
+		if tt == CommentToken {
+			if p.prevWS && !skipWS {
+				p.pushBuf(WhitespaceToken, wsBytes)
+			}
+			p.pushBuf(tt, data)
+			continue
+		} else if (tt == SemicolonToken || tt == RightBraceToken) && p.level == 0 || tt == ErrorToken {
 			p.prevEnd = (tt == RightBraceToken)
 			return DeclarationGrammar
 		} else if tt == LeftParenthesisToken || tt == LeftBraceToken || tt == LeftBracketToken || tt == FunctionToken {
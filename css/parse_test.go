@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/internal/testio"
 	"github.com/tdewolff/test"
 )
 
@@ -58,6 +60,7 @@ func TestParse(t *testing.T) {
 		{false, "@import;@import;", "@import;@import;"},
 		{false, ".a .b#c, .d<.e { x:y; }", ".a .b#c,.d<.e{x:y;}"},
 		{false, ".a[b~=c]d { x:y; }", ".a[b~=c]d{x:y;}"},
+		{false, ":is(a, b) c, d { x:y; }", ":is(a,b) c,d{x:y;}"},
 		// {false, "{x:y;}", "{x:y;}"},
 		{false, "a{}", "a{}"},
 		{false, "a,.b/*comment*/ {x:y;}", "a,.b{x:y;}"},
@@ -110,6 +113,52 @@ func TestParse(t *testing.T) {
 
 		// go-fuzz
 		{false, "@-webkit-", "@-webkit-;"},
+
+		// nesting, examples from https://www.w3.org/TR/css-nesting-1/
+		{false, ".foo { color: blue; & > .bar { color: red; } }", ".foo{color:blue;&>.bar{color:red;}}"},
+		{false, ".foo { color: blue; > .bar { color: red; } }", ".foo{color:blue;>.bar{color:red;}}"},
+		{false, ".foo { color: blue; &.bar { color: red; } }", ".foo{color:blue;&.bar{color:red;}}"},
+		{false, ".foo, .bar { color: blue; & + .baz, &.qux { color: red; } }", ".foo,.bar{color:blue;&+.baz,&.qux{color:red;}}"},
+		{false, ".foo { color: blue; & .bar & .baz & .qux { color: red; } }", ".foo{color:blue;& .bar & .baz & .qux{color:red;}}"},
+		{false, ".foo { color: blue; & { padding: 2ch; } }", ".foo{color:blue;&{padding:2ch;}}"},
+		{false, ".foo { color: blue; && { padding: 2ch; } }", ".foo{color:blue;&&{padding:2ch;}}"},
+		{false, ".error, #404 { &:hover > .baz { color: red; } }", ".error,#404{&:hover>.baz{color:red;}}"},
+		{false, ".ancestor .el { .other-ancestor & { color: red; } }", ".ancestor .el{.other-ancestor &{color:red;}}"},
+		{false, ".foo { color: red; :not(&) { color: blue; } }", ".foo{color:red;:not(&){color:blue;}}"},
+		{false, ".foo { color: blue; .bar { color: red; } }", ".foo{color:blue;.bar{color:red;}}"},
+		{false, "figure { margin: 0; > figcaption { background: hsl(0 0% 0% / 50%); > p { font-size: .9rem; } } }", "figure{margin:0;>figcaption{background:hsl(0 0% 0%/50%);>p{font-size:.9rem;}}}"},
+		{false, "article { color: green; & { color: blue; } color: red; }", "article{color:green;&{color:blue;}color:red;}"},
+
+		// type selectors and pseudo-classes that look like declarations
+		{false, "div { color: red; input { margin: 1em; } }", "div{color:red;input{margin:1em;}}"},
+		{false, "a { color: red; b:hover { x: y } c: d; }", "a{color:red;b:hover{x:y;}c:d;}"},
+		{false, "a { :hover { x: y } }", "a{:hover{x:y;}}"},
+		{false, "a { b:not(.c;d) { x: y } }", "a{b:not(.c;d){x:y;}}"},
+		{false, "a { b[title=\"{\"] { x: y } }", "a{b[title=\"{\"]{x:y;}}"},
+		{false, "a { b: url(x{y) }", "a{b:url(x{y);}"},
+		{false, "a { *color: red; * { x: y } }", "a{*color:red;*{x:y;}}"},
+		{false, "a { --x: { b: c }; d { e: f } }", "a{--x: { b: c };d{e:f;}}"},
+
+		// nested at-rules
+		{false, ".foo { display: grid; @media (orientation: landscape) { grid-auto-flow: column; } }", ".foo{display:grid;@media(orientation:landscape){grid-auto-flow:column;}}"},
+		{false, ".foo { @media (orientation: landscape) { grid-auto-flow: column; @media (min-width > 1024px) { max-inline-size: 1024px; } } }", ".foo{@media(orientation:landscape){grid-auto-flow:column;@media(min-width > 1024px){max-inline-size:1024px;}}}"},
+		{false, ".foo { @media print { & .bar { color: red } color: blue } }", ".foo{@media print{& .bar{color:red;}color:blue;}}"},
+		{false, "html { @layer base { block-size: 100%; @layer support { & body { min-block-size: 100%; } } } }", "html{@layer base{block-size:100%;@layer support{& body{min-block-size:100%;}}}}"},
+		{false, ".card { inline-size: 40ch; @container (inline-size > 40em) { aspect-ratio: 16/9; } }", ".card{inline-size:40ch;@container(inline-size > 40em){aspect-ratio:16/9;}}"},
+		{false, ".parent { @scope (& > .scope) to (& .limit) { .content { color: red; } } }", ".parent{@scope(& > .scope) to (& .limit){.content{color:red;}}}"},
+		{false, "a { @starting-style { opacity: 0; } @supports (display: grid) { b { c: d } } }", "a{@starting-style{opacity:0;}@supports(display:grid){b{c:d;}}}"},
+		{false, "a { @unknown x { y } }", "a{@unknown x{y }}"},
+
+		// group rules at the top level contain rules
+		{false, "@layer base { a { b: c } }", "@layer base{a{b:c;}}"},
+		{false, "@container sidebar (min-width: 400px) { a { b: c } }", "@container sidebar (min-width:400px){a{b:c;}}"},
+		{false, "@media print { a { b: c; d { e: f } } }", "@media print{a{b:c;d{e:f;}}}"},
+
+		// errors
+		{false, "a { b c; d { e: f } }", "a{ERROR(b c;)d{e:f;}}"},
+		{false, "a { b { c: d }", "a{b{c:d;}"},
+		{false, "a { b {", "a{b{"},
+		{false, "a { b", "a{ERROR(b)"},
 	}
 	for _, tt := range parseTests {
 		t.Run(tt.css, func(t *testing.T) {
@@ -214,6 +263,58 @@ func TestParseOffset(t *testing.T) {
 	test.T(t, p.Offset(), 26) // }
 }
 
+func TestStreamParser(t *testing.T) {
+	css := `@charset "utf-8"; @import url("x.css"); .a > b:hover, #c[d="e"]{color: #fff !important; margin: -1.5em 0 calc(100% - 2px); --x: { a: b }} @media (min-width: 10px) { a { b: c } } @font-face { src: url(x) } .n { a: b; & .c { d: e } f:hover { g: "}" } @media print { h: i } }`
+	p := NewParser(bytes.NewBufferString(css), false)
+	z := NewStreamParser(iotest.OneByteReader(bytes.NewBufferString(css)), false)
+	for {
+		gt, tt, data := p.Next()
+		gtStream, ttStream, dataStream := z.Next()
+		test.T(t, gtStream, gt, "grammar types must match")
+		test.T(t, ttStream, tt, "token types must match")
+		test.String(t, string(dataStream), string(data), "grammar data must match")
+		test.T(t, len(z.Values()), len(p.Values()), "number of values must match")
+		for i, val := range p.Values() {
+			if i < len(z.Values()) {
+				test.String(t, string(z.Values()[i].Data), string(val.Data), "values must match")
+			}
+		}
+		test.T(t, z.Offset(), p.Offset(), "offsets must match")
+		if gt == ErrorGrammar {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+
+	z = NewStreamParser(iotest.OneByteReader(bytes.NewBufferString("a { b: c }\nd { e; }")), false)
+	for gt, _, _ := z.Next(); gt != ErrorGrammar; gt, _, _ = z.Next() {
+	}
+	if perr, ok := z.Err().(*parse.Error); ok {
+		test.String(t, perr.Message, "CSS parse error: expected colon in declaration")
+		line, col, _ := perr.Position()
+		test.T(t, line, 2)
+		test.T(t, col, 6)
+	} else {
+		test.Fail(t, "bad error:", z.Err())
+	}
+}
+
+func TestStreamParserMemory(t *testing.T) {
+	item := []byte(".a > b:hover, #c { color: #fff; margin: -1.5em 0 } @media print { a { b: c } }\n")
+	r := testio.NewRepeatReader(item, 300000)
+
+	p := NewStreamParser(r, false)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for gt, _, _ := p.Next(); gt != ErrorGrammar; gt, _, _ = p.Next() {
+			n++
+		}
+	})
+	test.T(t, p.Err(), io.EOF)
+	test.T(t, n, 10*300000)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*300000, "bytes of input")
+}
+
 ////////////////////////////////////////////////////////////////
 
 type Obj struct{}
@@ -0,0 +1,833 @@
+package css
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// Comparison is the comparison operator of a range media feature.
+type Comparison uint32
+
+// Comparison values.
+const (
+	EqualComparison        Comparison = iota // =
+	LessComparison                           // <
+	LessEqualComparison                      // <=
+	GreaterComparison                        // >
+	GreaterEqualComparison                   // >=
+)
+
+// String returns the string representation of a Comparison.
+func (c Comparison) String() string {
+	switch c {
+	case EqualComparison:
+		return "="
+	case LessComparison:
+		return "<"
+	case LessEqualComparison:
+		return "<="
+	case GreaterComparison:
+		return ">"
+	case GreaterEqualComparison:
+		return ">="
+	}
+	return "Invalid(" + strconv.Itoa(int(c)) + ")"
+}
+
+// flip returns the comparison with its operands swapped, ie. a < b becomes b > a.
+func (c Comparison) flip() Comparison {
+	switch c {
+	case LessComparison:
+		return GreaterComparison
+	case LessEqualComparison:
+		return GreaterEqualComparison
+	case GreaterComparison:
+		return LessComparison
+	case GreaterEqualComparison:
+		return LessEqualComparison
+	}
+	return c
+}
+
+// Condition is a condition of a media query, @supports, or @container rule, ie. *NotCondition, *AndCondition, *OrCondition, *MediaFeature, *SupportsDeclaration, *FunctionCondition, or *GeneralEnclosed.
+type Condition interface {
+	String() string
+}
+
+// NotCondition negates a condition.
+type NotCondition struct {
+	Condition Condition
+}
+
+func (c *NotCondition) String() string {
+	return "not " + conditionString(c.Condition)
+}
+
+// AndCondition is true if all of its conditions are true.
+type AndCondition struct {
+	Conditions []Condition
+}
+
+func (c *AndCondition) String() string {
+	return joinConditions(c.Conditions, " and ")
+}
+
+// OrCondition is true if any of its conditions is true.
+type OrCondition struct {
+	Conditions []Condition
+}
+
+func (c *OrCondition) String() string {
+	return joinConditions(c.Conditions, " or ")
+}
+
+// MediaFeature is a media feature such as (color), (min-width: 400px) or (400px <= width <= 700px), or a size feature of a container query. Values are a single number, dimension or identifier token, or a ratio of the tokens number, / and number.
+type MediaFeature struct {
+	Name   []byte  // lowercase, including a min- or max- prefix
+	Value  []Token // value of a plain feature, nil for boolean and range features
+	Ranges []MediaRange
+}
+
+// MediaRange is a comparison of a range media feature, where the feature is on the left-hand side, that is 400px < width is stored as width > 400px.
+type MediaRange struct {
+	Comparison Comparison
+	Value      []Token
+}
+
+func (f *MediaFeature) String() string {
+	if f.Value != nil {
+		return "(" + string(f.Name) + ":" + tokensString(f.Value) + ")"
+	} else if len(f.Ranges) == 2 {
+		return "(" + tokensString(f.Ranges[0].Value) + f.Ranges[0].Comparison.flip().String() + string(f.Name) + f.Ranges[1].Comparison.String() + tokensString(f.Ranges[1].Value) + ")"
+	} else if len(f.Ranges) == 1 {
+		return "(" + string(f.Name) + f.Ranges[0].Comparison.String() + tokensString(f.Ranges[0].Value) + ")"
+	}
+	return "(" + string(f.Name) + ")"
+}
+
+// SupportsDeclaration is a declaration of a @supports condition, such as (display: grid).
+type SupportsDeclaration struct {
+	Property []byte // lowercase for regular properties
+	Values   []Token
+}
+
+func (c *SupportsDeclaration) String() string {
+	return "(" + string(c.Property) + ":" + tokensString(c.Values) + ")"
+}
+
+// FunctionCondition is a function of a @supports or @container condition, such as selector(), font-tech(), font-format(), style(), or scroll-state(). Its arguments are kept verbatim.
+type FunctionCondition struct {
+	Name []byte // lowercase, without the parenthesis
+	Args []Token
+}
+
+func (c *FunctionCondition) String() string {
+	return string(c.Name) + "(" + tokensString(c.Args) + ")"
+}
+
+// GeneralEnclosed is a parenthesized block or a function that is not a known condition, which evaluates to false. It is allowed for future extensions of the syntax.
+type GeneralEnclosed struct {
+	Tokens []Token // including the parentheses
+}
+
+func (c *GeneralEnclosed) String() string {
+	return tokensString(c.Tokens)
+}
+
+// MediaQuery is a query of a media query list, such as screen and (min-width: 400px).
+type MediaQuery struct {
+	Not, Only bool
+	Type      []byte    // lowercase, empty when the query has no media type
+	Condition Condition // nil if absent
+}
+
+func (q MediaQuery) String() string {
+	s := ""
+	if q.Not {
+		s = "not "
+	} else if q.Only {
+		s = "only "
+	}
+	if len(q.Type) != 0 {
+		s += string(q.Type)
+		if q.Condition != nil {
+			s += " and "
+		}
+	}
+	if q.Condition != nil {
+		if _, ok := q.Condition.(*OrCondition); ok && len(q.Type) != 0 {
+			s += "(" + q.Condition.String() + ")"
+		} else {
+			s += q.Condition.String()
+		}
+	}
+	return s
+}
+
+// MediaQueryList is the list of media queries of a @media or @import rule, which matches if any of its queries matches. An empty list matches all media.
+type MediaQueryList []MediaQuery
+
+func (l MediaQueryList) String() string {
+	s := ""
+	for i, q := range l {
+		if i != 0 {
+			s += ","
+		}
+		s += q.String()
+	}
+	return s
+}
+
+// ContainerQuery is a query of a @container rule, with an optional container name and condition.
+type ContainerQuery struct {
+	Name      []byte
+	Condition Condition // nil if absent
+}
+
+func (q ContainerQuery) String() string {
+	s := string(q.Name)
+	if q.Condition != nil {
+		if s != "" {
+			s += " "
+		}
+		s += q.Condition.String()
+	}
+	return s
+}
+
+// Import is the prelude of an @import rule.
+type Import struct {
+	URL       []byte // without url() and quotes, escapes are not resolved
+	Layer     bool
+	LayerName []byte    // empty for an anonymous layer
+	Supports  Condition // nil if absent
+	Media     MediaQueryList
+}
+
+func (i *Import) String() string {
+	s := "url(\"" + string(i.URL) + "\")"
+	if i.Layer {
+		if len(i.LayerName) == 0 {
+			s += " layer"
+		} else {
+			s += " layer(" + string(i.LayerName) + ")"
+		}
+	}
+	if i.Supports != nil {
+		if decl, ok := i.Supports.(*SupportsDeclaration); ok {
+			s += " supports" + decl.String()
+		} else {
+			s += " supports(" + i.Supports.String() + ")"
+		}
+	}
+	if len(i.Media) != 0 {
+		s += " " + i.Media.String()
+	}
+	return s
+}
+
+func conditionString(c Condition) string {
+	switch c.(type) {
+	case *NotCondition, *AndCondition, *OrCondition:
+		return "(" + c.String() + ")"
+	}
+	return c.String()
+}
+
+func joinConditions(conds []Condition, sep string) string {
+	s := ""
+	for i, c := range conds {
+		if i != 0 {
+			s += sep
+		}
+		s += conditionString(c)
+	}
+	return s
+}
+
+func tokensString(tokens []Token) string {
+	s := ""
+	for _, t := range tokens {
+		s += string(t.Data)
+	}
+	return s
+}
+
+////////////////////////////////////////////////////////////////
+
+// ParseMediaQueryList parses the prelude of a @media rule, such as returned by Parser.Values or in AtRule.Prelude. Following the CSS error recovery rules, invalid media queries are replaced by not all, in which case the first parse error is returned together with the list.
+func ParseMediaQueryList(tokens []Token) (MediaQueryList, error) {
+	p := &preludeParser{tokens: tokens}
+	list := p.parseMediaQueryList(len(tokens))
+	return list, p.err
+}
+
+// ParseSupportsCondition parses the prelude of a @supports rule.
+func ParseSupportsCondition(tokens []Token) (Condition, error) {
+	p := &preludeParser{tokens: tokens}
+	cond := p.parseCondition(supportsCondition, len(tokens), true)
+	p.expectEnd(len(tokens))
+	if p.err != nil {
+		return nil, p.err
+	}
+	return cond, nil
+}
+
+// ParseContainerQueryList parses the prelude of a @container rule, which is a comma-separated list of container queries.
+func ParseContainerQueryList(tokens []Token) ([]ContainerQuery, error) {
+	p := &preludeParser{tokens: tokens}
+	list := []ContainerQuery{}
+	for p.err == nil {
+		end := p.findComma(len(tokens))
+		q := ContainerQuery{}
+		if t := p.peek(end); t.TokenType == IdentToken && !isReservedName(t.Data) {
+			q.Name = parse.Copy(t.Data)
+			p.next(end)
+		}
+		if p.peek(end).TokenType != ErrorToken || q.Name == nil {
+			q.Condition = p.parseCondition(containerCondition, end, true)
+		}
+		p.expectEnd(end)
+		list = append(list, q)
+		if end == len(tokens) {
+			break
+		}
+		p.pos = end + 1
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return list, nil
+}
+
+// ParseImport parses the prelude of an @import rule, which has a URL or string followed by an optional layer, supports() condition, and media query list.
+func ParseImport(tokens []Token) (*Import, error) {
+	p := &preludeParser{tokens: tokens}
+	end := len(tokens)
+	imp := &Import{}
+	switch t := p.next(end); t.TokenType {
+	case URLToken:
+		url := t.Data[bytes.IndexByte(t.Data, '(')+1:]
+		if 0 < len(url) && url[len(url)-1] == ')' {
+			url = url[:len(url)-1]
+		}
+		imp.URL = parse.Copy(unquote(parse.TrimWhitespace(url)))
+	case StringToken:
+		imp.URL = parse.Copy(unquote(t.Data))
+	default:
+		if t.TokenType != ErrorToken {
+			p.pos--
+		}
+		p.fail("expected URL or string instead of %s", p.describe(end))
+		return nil, p.err
+	}
+
+	if t := p.peek(end); t.TokenType == IdentToken && parse.EqualFold(t.Data, []byte("layer")) {
+		p.next(end)
+		imp.Layer = true
+	} else if t.TokenType == FunctionToken && parse.EqualFold(t.Data, []byte("layer(")) {
+		p.next(end)
+		close := p.findClose(end)
+		names := p.parseLayerName(close)
+		p.expectEnd(close)
+		p.pos = close + 1
+		imp.Layer = true
+		imp.LayerName = names
+	}
+	if t := p.peek(end); p.err == nil && t.TokenType == FunctionToken && parse.EqualFold(t.Data, []byte("supports(")) {
+		p.next(end)
+		close := p.findClose(end)
+		start := p.pos
+		imp.Supports = p.parseCondition(supportsCondition, close, true)
+		p.expectEnd(close)
+		if p.err != nil {
+			// a declaration without parentheses
+			p.err, p.pos = nil, start
+			if decl := p.parseSupportsDeclaration(close); decl != nil {
+				imp.Supports = decl
+			} else {
+				p.pos = start
+				p.fail("invalid supports() condition")
+			}
+		}
+		p.pos = close + 1
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	imp.Media = p.parseMediaQueryList(end)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return imp, nil
+}
+
+// ParseLayerNames parses the prelude of a @layer rule, which is a comma-separated list of layer names such as framework.base. Whitespace is removed from the names, and the list is empty for an anonymous layer.
+func ParseLayerNames(tokens []Token) ([][]byte, error) {
+	p := &preludeParser{tokens: tokens}
+	names := [][]byte{}
+	if p.peek(len(tokens)).TokenType == ErrorToken {
+		return names, nil
+	}
+	for p.err == nil {
+		end := p.findComma(len(tokens))
+		names = append(names, p.parseLayerName(end))
+		p.expectEnd(end)
+		if end == len(tokens) {
+			break
+		}
+		p.pos = end + 1
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return names, nil
+}
+
+////////////////////////////////////////////////////////////////
+
+type conditionKind int
+
+const (
+	mediaCondition conditionKind = iota
+	supportsCondition
+	containerCondition
+)
+
+// preludeParser parses at-rule preludes. Most methods take the index of the token where parsing stops, so that parenthesized blocks and comma-separated items are parsed in place.
+type preludeParser struct {
+	tokens []Token
+	pos    int
+	err    error
+}
+
+// fail sets the error at the current token if no error has been set before, the input is reconstructed from the tokens.
+func (p *preludeParser) fail(message string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	p.skipWhitespace(len(p.tokens))
+	input := []byte{}
+	offset := 0
+	for i, t := range p.tokens {
+		if i == p.pos {
+			offset = len(input)
+		}
+		input = append(input, t.Data...)
+	}
+	if len(p.tokens) <= p.pos {
+		offset = len(input)
+	}
+	p.err = parse.NewError(bytes.NewBuffer(input), offset, "CSS at-rule parse error: "+message, a...)
+}
+
+func (p *preludeParser) describe(end int) string {
+	t := p.peek(end)
+	if t.TokenType == ErrorToken {
+		if end < len(p.tokens) {
+			return "'" + string(p.tokens[end].Data) + "'"
+		}
+		return "end of prelude"
+	}
+	return "'" + string(t.Data) + "'"
+}
+
+func (p *preludeParser) skipWhitespace(end int) {
+	for p.pos < end && (p.tokens[p.pos].TokenType == WhitespaceToken || p.tokens[p.pos].TokenType == CommentToken) {
+		p.pos++
+	}
+}
+
+// peek returns the next token that is not whitespace or a comment before end, or an ErrorToken.
+func (p *preludeParser) peek(end int) Token {
+	p.skipWhitespace(end)
+	if end <= p.pos {
+		return Token{ErrorToken, nil}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *preludeParser) next(end int) Token {
+	t := p.peek(end)
+	if t.TokenType != ErrorToken {
+		p.pos++
+	}
+	return t
+}
+
+func (p *preludeParser) expectEnd(end int) {
+	if p.err == nil && p.peek(end).TokenType != ErrorToken {
+		p.fail("unexpected %s", p.describe(end))
+	}
+}
+
+func (p *preludeParser) peekIdent(end int, ident string) bool {
+	t := p.peek(end)
+	return t.TokenType == IdentToken && parse.EqualFold(t.Data, []byte(ident))
+}
+
+// findClose returns the index of the parenthesis that closes the block or function before the current position, or end if it is not closed.
+func (p *preludeParser) findClose(end int) int {
+	level := 0
+	for i := p.pos; i < end; i++ {
+		switch p.tokens[i].TokenType {
+		case LeftParenthesisToken, LeftBracketToken, LeftBraceToken, FunctionToken:
+			level++
+		case RightParenthesisToken, RightBracketToken, RightBraceToken:
+			if level == 0 {
+				return i
+			}
+			level--
+		}
+	}
+	return end
+}
+
+// findComma returns the index of the next comma outside of blocks and functions, or end if there is none.
+func (p *preludeParser) findComma(end int) int {
+	level := 0
+	for i := p.pos; i < end; i++ {
+		switch p.tokens[i].TokenType {
+		case LeftParenthesisToken, LeftBracketToken, LeftBraceToken, FunctionToken:
+			level++
+		case RightParenthesisToken, RightBracketToken, RightBraceToken:
+			if 0 < level {
+				level--
+			}
+		case CommaToken:
+			if level == 0 {
+				return i
+			}
+		}
+	}
+	return end
+}
+
+func isReservedName(name []byte) bool {
+	return parse.EqualFold(name, []byte("not")) || parse.EqualFold(name, []byte("and")) || parse.EqualFold(name, []byte("or")) || parse.EqualFold(name, []byte("only")) || parse.EqualFold(name, []byte("layer")) || parse.EqualFold(name, []byte("none"))
+}
+
+// isCSSWideKeyword returns true for the keywords that are valid for every property, which are reserved in layer names.
+func isCSSWideKeyword(name []byte) bool {
+	return parse.EqualFold(name, []byte("initial")) || parse.EqualFold(name, []byte("inherit")) || parse.EqualFold(name, []byte("unset")) || parse.EqualFold(name, []byte("revert")) || parse.EqualFold(name, []byte("revert-layer"))
+}
+
+func unquote(b []byte) []byte {
+	if 0 < len(b) && (b[0] == '"' || b[0] == '\'') {
+		quote := b[0]
+		b = b[1:]
+		if 0 < len(b) && b[len(b)-1] == quote {
+			b = b[:len(b)-1]
+		}
+	}
+	return b
+}
+
+func copyTrimmed(tokens []Token) []Token {
+	for 0 < len(tokens) && (tokens[0].TokenType == WhitespaceToken || tokens[0].TokenType == CommentToken) {
+		tokens = tokens[1:]
+	}
+	for 0 < len(tokens) && (tokens[len(tokens)-1].TokenType == WhitespaceToken || tokens[len(tokens)-1].TokenType == CommentToken) {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return copyTokens(tokens)
+}
+
+////////////////////////////////////////////////////////////////
+
+func (p *preludeParser) parseMediaQueryList(end int) MediaQueryList {
+	list := MediaQueryList{}
+	if p.peek(end).TokenType == ErrorToken {
+		return list
+	}
+	var err error
+	for {
+		comma := p.findComma(end)
+		q := p.parseMediaQuery(comma)
+		if p.err != nil {
+			if err == nil {
+				err = p.err
+			}
+			p.err = nil
+			q = MediaQuery{Not: true, Type: []byte("all")}
+		}
+		list = append(list, q)
+		if comma == end {
+			break
+		}
+		p.pos = comma + 1
+	}
+	p.err = err
+	return list
+}
+
+func (p *preludeParser) parseMediaQuery(end int) MediaQuery {
+	q := MediaQuery{}
+	t := p.peek(end)
+	if t.TokenType == IdentToken && !parse.EqualFold(t.Data, []byte("not")) || p.peekIdent(end, "not") && p.tokenAfterWhitespace(end).TokenType == IdentToken {
+		if p.peekIdent(end, "not") {
+			q.Not = true
+			p.next(end)
+		} else if p.peekIdent(end, "only") {
+			q.Only = true
+			p.next(end)
+		}
+		t = p.next(end)
+		if t.TokenType != IdentToken || isReservedName(t.Data) {
+			if t.TokenType != ErrorToken {
+				p.pos--
+			}
+			p.fail("expected media type instead of %s", p.describe(end))
+			return q
+		}
+		q.Type = parse.ToLower(parse.Copy(t.Data))
+		if p.peek(end).TokenType == ErrorToken {
+			return q
+		} else if !p.peekIdent(end, "and") {
+			p.fail("expected 'and' instead of %s", p.describe(end))
+			return q
+		}
+		p.next(end)
+		q.Condition = p.parseCondition(mediaCondition, end, false)
+	} else {
+		q.Condition = p.parseCondition(mediaCondition, end, true)
+	}
+	p.expectEnd(end)
+	return q
+}
+
+// tokenAfterWhitespace returns the token after the next token, skipping whitespace and comments.
+func (p *preludeParser) tokenAfterWhitespace(end int) Token {
+	pos := p.pos
+	p.next(end)
+	t := p.peek(end)
+	p.pos = pos
+	return t
+}
+
+// parseCondition parses a condition of and or or combined conditions, where or is only allowed if allowOr is set.
+func (p *preludeParser) parseCondition(kind conditionKind, end int, allowOr bool) Condition {
+	if p.peekIdent(end, "not") {
+		p.next(end)
+		return &NotCondition{p.parseInParens(kind, end)}
+	}
+
+	cond := p.parseInParens(kind, end)
+	var conds []Condition
+	var op []byte
+	for p.err == nil {
+		t := p.peek(end)
+		if t.TokenType != IdentToken || !parse.EqualFold(t.Data, []byte("and")) && !parse.EqualFold(t.Data, []byte("or")) {
+			break
+		} else if op == nil {
+			if !allowOr && parse.EqualFold(t.Data, []byte("or")) {
+				p.fail("unexpected 'or' after media type")
+				break
+			}
+			op = t.Data
+			conds = []Condition{cond}
+		} else if !parse.EqualFold(t.Data, op) {
+			p.fail("cannot mix 'and' and 'or' without parentheses")
+			break
+		}
+		p.next(end)
+		conds = append(conds, p.parseInParens(kind, end))
+	}
+	if op == nil {
+		return cond
+	} else if parse.EqualFold(op, []byte("and")) {
+		return &AndCondition{conds}
+	}
+	return &OrCondition{conds}
+}
+
+// parseInParens parses a parenthesized condition, a feature, a function, or a general enclosed block.
+func (p *preludeParser) parseInParens(kind conditionKind, end int) Condition {
+	t := p.next(end)
+	start := p.pos - 1
+	if t.TokenType == FunctionToken {
+		close := p.findClose(end)
+		if close == end {
+			p.pos = end
+			p.fail("expected ')' instead of %s", p.describe(end))
+			return nil
+		}
+		p.pos = close + 1
+		name := parse.ToLower(parse.Copy(t.Data[:len(t.Data)-1]))
+		if kind == supportsCondition && (bytes.Equal(name, []byte("selector")) || bytes.Equal(name, []byte("font-tech")) || bytes.Equal(name, []byte("font-format"))) ||
+			kind == containerCondition && (bytes.Equal(name, []byte("style")) || bytes.Equal(name, []byte("scroll-state"))) {
+			return &FunctionCondition{name, copyTrimmed(p.tokens[start+1 : close])}
+		}
+		return &GeneralEnclosed{copyTokens(p.tokens[start : close+1])}
+	} else if t.TokenType != LeftParenthesisToken {
+		if t.TokenType != ErrorToken {
+			p.pos--
+		}
+		p.fail("expected '(' instead of %s", p.describe(end))
+		return nil
+	}
+
+	close := p.findClose(end)
+	if close == end {
+		p.pos = end
+		p.fail("expected ')' instead of %s", p.describe(end))
+		return nil
+	}
+
+	// try a nested condition, a feature or declaration, and finally accept any tokens
+	var cond Condition
+	if inner := p.peek(close); inner.TokenType == LeftParenthesisToken || inner.TokenType == FunctionToken || inner.TokenType == IdentToken && parse.EqualFold(inner.Data, []byte("not")) {
+		cond = p.parseCondition(kind, close, true)
+	} else if kind == supportsCondition {
+		cond = p.parseSupportsDeclaration(close)
+	} else {
+		cond = p.parseMediaFeature(close)
+	}
+	p.expectEnd(close)
+	if p.err != nil || cond == nil {
+		p.err = nil
+		cond = &GeneralEnclosed{copyTokens(p.tokens[start : close+1])}
+	}
+	p.pos = close + 1
+	return cond
+}
+
+// parseMediaFeature parses the contents of a media feature in plain, boolean or range syntax.
+func (p *preludeParser) parseMediaFeature(end int) Condition {
+	if t := p.peek(end); t.TokenType == IdentToken {
+		pos := p.pos
+		p.next(end)
+		if next := p.peek(end); next.TokenType == ColonToken {
+			p.next(end)
+			value := p.parseMediaValue(end)
+			if value == nil {
+				return nil
+			}
+			return &MediaFeature{Name: parse.ToLower(parse.Copy(t.Data)), Value: value}
+		} else if next.TokenType == ErrorToken {
+			return &MediaFeature{Name: parse.ToLower(parse.Copy(t.Data))}
+		} else if cmp, ok := p.parseComparison(end); ok {
+			value := p.parseMediaValue(end)
+			if value == nil {
+				return nil
+			}
+			return &MediaFeature{Name: parse.ToLower(parse.Copy(t.Data)), Ranges: []MediaRange{{cmp, value}}}
+		}
+		p.pos = pos
+	}
+
+	// value comparison name [comparison value]
+	value := p.parseMediaValue(end)
+	if value == nil {
+		return nil
+	}
+	cmp, ok := p.parseComparison(end)
+	if !ok {
+		return nil
+	}
+	name := p.next(end)
+	if name.TokenType != IdentToken {
+		return nil
+	}
+	feature := &MediaFeature{Name: parse.ToLower(parse.Copy(name.Data)), Ranges: []MediaRange{{cmp.flip(), value}}}
+	if p.peek(end).TokenType == ErrorToken {
+		return feature
+	}
+	cmp2, ok := p.parseComparison(end)
+	if !ok || (cmp == LessComparison || cmp == LessEqualComparison) != (cmp2 == LessComparison || cmp2 == LessEqualComparison) || cmp == EqualComparison || cmp2 == EqualComparison {
+		return nil
+	}
+	value = p.parseMediaValue(end)
+	if value == nil {
+		return nil
+	}
+	feature.Ranges = append(feature.Ranges, MediaRange{cmp2, value})
+	return feature
+}
+
+// parseMediaValue parses a number, dimension, identifier, or ratio.
+func (p *preludeParser) parseMediaValue(end int) []Token {
+	t := p.next(end)
+	if t.TokenType == NumberToken {
+		pos := p.pos
+		if slash := p.peek(end); slash.TokenType == DelimToken && slash.Data[0] == '/' {
+			p.next(end)
+			if denom := p.next(end); denom.TokenType == NumberToken {
+				return []Token{{NumberToken, parse.Copy(t.Data)}, {DelimToken, parse.Copy(slash.Data)}, {NumberToken, parse.Copy(denom.Data)}}
+			}
+			return nil
+		}
+		p.pos = pos
+	} else if t.TokenType != DimensionToken && t.TokenType != IdentToken && t.TokenType != PercentageToken {
+		return nil
+	}
+	return []Token{{t.TokenType, parse.Copy(t.Data)}}
+}
+
+// parseComparison parses =, <, <=, >, or >=, where the = must directly follow < or >.
+func (p *preludeParser) parseComparison(end int) (Comparison, bool) {
+	t := p.peek(end)
+	if t.TokenType != DelimToken || t.Data[0] != '=' && t.Data[0] != '<' && t.Data[0] != '>' {
+		return 0, false
+	}
+	p.next(end)
+	if t.Data[0] == '=' {
+		return EqualComparison, true
+	}
+	orEqual := false
+	if p.pos < end && p.tokens[p.pos].TokenType == DelimToken && p.tokens[p.pos].Data[0] == '=' {
+		p.pos++
+		orEqual = true
+	}
+	if t.Data[0] == '<' {
+		if orEqual {
+			return LessEqualComparison, true
+		}
+		return LessComparison, true
+	} else if orEqual {
+		return GreaterEqualComparison, true
+	}
+	return GreaterComparison, true
+}
+
+// parseSupportsDeclaration parses a declaration of a @supports condition.
+func (p *preludeParser) parseSupportsDeclaration(end int) Condition {
+	t := p.next(end)
+	if t.TokenType != IdentToken && t.TokenType != CustomPropertyNameToken || p.next(end).TokenType != ColonToken {
+		return nil
+	}
+	property := parse.Copy(t.Data)
+	if t.TokenType == IdentToken {
+		property = parse.ToLower(property)
+	}
+	values := copyTrimmed(p.tokens[p.pos:end])
+	if len(values) == 0 && t.TokenType == IdentToken {
+		return nil
+	}
+	p.pos = end
+	return &SupportsDeclaration{property, values}
+}
+
+// parseLayerName parses a layer name of identifiers separated by dots.
+func (p *preludeParser) parseLayerName(end int) []byte {
+	name := []byte{}
+	for {
+		t := p.next(end)
+		if t.TokenType != IdentToken || isCSSWideKeyword(t.Data) {
+			if t.TokenType != ErrorToken {
+				p.pos--
+			}
+			p.fail("expected layer name instead of %s", p.describe(end))
+			return nil
+		}
+		name = append(name, t.Data...)
+		if p.pos < end && p.tokens[p.pos].TokenType == DelimToken && p.tokens[p.pos].Data[0] == '.' {
+			p.pos++
+			name = append(name, '.')
+			continue
+		}
+		return name
+	}
+}
@@ -0,0 +1,295 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+// prelude returns the prelude tokens of an at-rule as returned by the parser.
+func prelude(s string) []Token {
+	p := NewParser(bytes.NewBufferString(s+"{}"), false)
+	for {
+		gt, _, _ := p.Next()
+		if gt == ErrorGrammar {
+			return nil
+		} else if gt == AtRuleGrammar || gt == BeginAtRuleGrammar {
+			return copyTokens(p.Values())
+		}
+	}
+}
+
+func TestParseMediaQueryList(t *testing.T) {
+	var mediaTests = []struct {
+		media    string
+		expected string
+	}{
+		{"", ""},
+		{"screen", "screen"},
+		{"SCREEN, Print", "screen,print"},
+		{"not print", "not print"},
+		{"only screen and (color)", "only screen and (color)"},
+		{"screen and (min-width: 400px)", "screen and (min-width:400px)"},
+		{"screen and (min-width:400px) and (max-width:700px)", "screen and (min-width:400px) and (max-width:700px)"},
+		{"(width >= 400px)", "(width>=400px)"},
+		{"(400px < width)", "(width>400px)"},
+		{"(400px <= width <= 700px)", "(400px<=width<=700px)"},
+		{"(700px > width > 400px)", "(700px>width>400px)"},
+		{"(width = 400px)", "(width=400px)"},
+		{"(aspect-ratio: 16 / 9)", "(aspect-ratio:16/9)"},
+		{"(min-resolution: 2dppx)", "(min-resolution:2dppx)"},
+		{"(prefers-color-scheme: dark)", "(prefers-color-scheme:dark)"},
+		{"not (color)", "not (color)"},
+		{"not ((color) and (hover))", "not ((color) and (hover))"},
+		{"(color) or (hover)", "(color) or (hover)"},
+		{"screen and ((color) or (hover))", "screen and ((color) or (hover))"},
+		{"(color) and ((hover) or (pointer: fine))", "(color) and ((hover) or (pointer:fine))"},
+		{"(foo bar baz)", "(foo bar baz)"},
+		{"unknown(x) or (color)", "unknown(x) or (color)"},
+		{"(width < 400px = 2)", "(width < 400px = 2)"},
+
+		// invalid queries become not all
+		{"screen and", "not all"},
+		{"screen, (color) and (hover) or (pointer)", "screen,not all"},
+		{"screen and (color) or (hover)", "not all"},
+		{"only (color)", "not all"},
+		{"and", "not all"},
+		{"print, 5px", "print,not all"},
+	}
+	for _, tt := range mediaTests {
+		t.Run(tt.media, func(t *testing.T) {
+			list, _ := ParseMediaQueryList(prelude("@media " + tt.media))
+			test.String(t, list.String(), tt.expected)
+		})
+	}
+}
+
+func TestParseMediaQueryListTree(t *testing.T) {
+	list, err := ParseMediaQueryList(prelude("@media not screen and (400px <= width < 700px), (aspect-ratio > 16/9)"))
+	test.Error(t, err)
+	test.T(t, len(list), 2)
+	test.That(t, list[0].Not)
+	test.String(t, string(list[0].Type), "screen")
+
+	feature := list[0].Condition.(*MediaFeature)
+	test.String(t, string(feature.Name), "width")
+	test.T(t, feature.Value, []Token(nil))
+	test.T(t, feature.Ranges, []MediaRange{
+		{GreaterEqualComparison, []Token{{DimensionToken, []byte("400px")}}},
+		{LessComparison, []Token{{DimensionToken, []byte("700px")}}},
+	})
+
+	feature = list[1].Condition.(*MediaFeature)
+	test.String(t, string(feature.Name), "aspect-ratio")
+	test.T(t, feature.Ranges[0].Comparison, GreaterComparison)
+	test.String(t, tokensString(feature.Ranges[0].Value), "16/9")
+}
+
+func TestParseMediaQueryListError(t *testing.T) {
+	var errorTests = []struct {
+		media string
+		err   string
+		col   int
+	}{
+		{"screen and", "expected '(' instead of end of prelude", 12},
+		{"screen (color)", "expected 'and' instead of '('", 9},
+		{"(color) and (hover) or (pointer)", "cannot mix 'and' and 'or' without parentheses", 21},
+		{"screen and (color) or (hover)", "unexpected 'or' after media type", 21},
+		{"only (color)", "expected media type instead of '('", 7},
+		{"only", "expected media type instead of end of prelude", 6},
+		{"(color) and", "expected '(' instead of end of prelude", 12},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.media, func(t *testing.T) {
+			list, err := ParseMediaQueryList(prelude("@media " + tt.media))
+			test.String(t, list.String(), "not all")
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "CSS at-rule parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestParseSupportsCondition(t *testing.T) {
+	var supportsTests = []struct {
+		supports string
+		expected string
+	}{
+		{"(display: grid)", "(display:grid)"},
+		{"(DISPLAY: grid)", "(display:grid)"},
+		{"(--x: 1 2)", "(--x:1 2)"},
+		{"not (display: grid)", "not (display:grid)"},
+		{"(display: grid) and (not (display: inline-grid))", "(display:grid) and (not (display:inline-grid))"},
+		{"(display: flex) or (display: -webkit-box) or (display: box)", "(display:flex) or (display:-webkit-box) or (display:box)"},
+		{"selector(a > b)", "selector(a > b)"},
+		{"font-tech(color-COLRv1) and font-format(woff2)", "font-tech(color-COLRv1) and font-format(woff2)"},
+		{"((display: grid))", "(display:grid)"},
+		{"(foo)", "(foo)"},
+		{"future(x)", "future(x)"},
+	}
+	for _, tt := range supportsTests {
+		t.Run(tt.supports, func(t *testing.T) {
+			cond, err := ParseSupportsCondition(prelude("@supports " + tt.supports))
+			test.Error(t, err)
+			test.String(t, cond.String(), tt.expected)
+		})
+	}
+
+	cond, _ := ParseSupportsCondition(prelude("@supports selector(:has(a))"))
+	fn := cond.(*FunctionCondition)
+	test.String(t, string(fn.Name), "selector")
+	test.String(t, tokensString(fn.Args), ":has(a)")
+
+	cond, _ = ParseSupportsCondition(prelude("@supports (foo)"))
+	_, ok := cond.(*GeneralEnclosed)
+	test.That(t, ok)
+
+	_, err := ParseSupportsCondition(prelude("@supports (a: b) and (c: d) or (e: f)"))
+	test.That(t, err != nil)
+	_, err = ParseSupportsCondition(prelude("@supports display: grid"))
+	test.That(t, err != nil)
+}
+
+func TestParseContainerQueryList(t *testing.T) {
+	var containerTests = []struct {
+		container string
+		expected  string
+	}{
+		{"(min-width: 400px)", "(min-width:400px)"},
+		{"sidebar (width > 400px)", "sidebar (width>400px)"},
+		{"card", "card"},
+		{"not (width < 400px)", "not (width<400px)"},
+		{"card (inline-size > 30em) and style(--responsive: true)", "card (inline-size>30em) and style(--responsive:true)"},
+		{"scroll-state(stuck: top)", "scroll-state(stuck:top)"},
+		{"a (width > 1px), b (orientation: portrait)", "a (width>1px),b (orientation:portrait)"},
+	}
+	for _, tt := range containerTests {
+		t.Run(tt.container, func(t *testing.T) {
+			list, err := ParseContainerQueryList(prelude("@container " + tt.container))
+			test.Error(t, err)
+			s := ""
+			for i, q := range list {
+				if i != 0 {
+					s += ","
+				}
+				s += q.String()
+			}
+			test.String(t, s, tt.expected)
+		})
+	}
+
+	list, _ := ParseContainerQueryList(prelude("@container sidebar (width > 400px)"))
+	test.String(t, string(list[0].Name), "sidebar")
+	test.String(t, string(list[0].Condition.(*MediaFeature).Name), "width")
+
+	_, err := ParseContainerQueryList(prelude("@container none (width > 400px)"))
+	test.That(t, err != nil)
+	_, err = ParseContainerQueryList(prelude("@container a b"))
+	test.That(t, err != nil)
+}
+
+func TestParseImport(t *testing.T) {
+	var importTests = []struct {
+		imp      string
+		expected string
+	}{
+		{"'a.css'", "url(\"a.css\")"},
+		{"url(a.css)", "url(\"a.css\")"},
+		{"url( \"a.css\" )", "url(\"a.css\")"},
+		{"\"a.css\" layer", "url(\"a.css\") layer"},
+		{"\"a.css\" layer(base.reset)", "url(\"a.css\") layer(base.reset)"},
+		{"\"a.css\" supports(display: grid)", "url(\"a.css\") supports(display:grid)"},
+		{"\"a.css\" supports(not (display: grid))", "url(\"a.css\") supports(not (display:grid))"},
+		{"\"a.css\" screen and (min-width: 400px)", "url(\"a.css\") screen and (min-width:400px)"},
+		{"\"a.css\" layer(x) supports((display: grid) and (gap: 1em)) print, screen", "url(\"a.css\") layer(x) supports((display:grid) and (gap:1em)) print,screen"},
+	}
+	for _, tt := range importTests {
+		t.Run(tt.imp, func(t *testing.T) {
+			imp, err := ParseImport(prelude("@import " + tt.imp))
+			test.Error(t, err)
+			test.String(t, imp.String(), tt.expected)
+		})
+	}
+
+	imp, _ := ParseImport(prelude("@import url(a.css) layer(x) supports(display: grid) print"))
+	test.String(t, string(imp.URL), "a.css")
+	test.That(t, imp.Layer)
+	test.String(t, string(imp.LayerName), "x")
+	test.String(t, string(imp.Supports.(*SupportsDeclaration).Property), "display")
+	test.String(t, string(imp.Media[0].Type), "print")
+
+	_, err := ParseImport(prelude("@import layer"))
+	test.That(t, err != nil)
+	_, err = ParseImport(prelude("@import 'a.css' layer(1)"))
+	test.That(t, err != nil)
+	_, err = ParseImport(prelude("@import 'a.css' layer(inherit)"))
+	test.That(t, err != nil)
+	_, err = ParseImport(prelude("@import 'a.css' screen and"))
+	test.That(t, err != nil)
+
+	// empty prelude
+	for _, tokens := range [][]Token{nil, prelude("@import;"), prelude("@import ;")} {
+		_, err = ParseImport(tokens)
+		if perr, ok := err.(*parse.Error); ok {
+			test.String(t, perr.Message, "CSS at-rule parse error: expected URL or string instead of end of prelude")
+		} else {
+			test.Fail(t, "bad error:", err)
+		}
+	}
+}
+
+func TestParseLayerNames(t *testing.T) {
+	var layerTests = []struct {
+		layer    string
+		expected []string
+	}{
+		{"", []string{}},
+		{"base", []string{"base"}},
+		{"reset, base , theme.dark", []string{"reset", "base", "theme.dark"}},
+		{"not, and.or, only, layer, none", []string{"not", "and.or", "only", "layer", "none"}},
+	}
+	for _, tt := range layerTests {
+		t.Run(tt.layer, func(t *testing.T) {
+			names, err := ParseLayerNames(prelude("@layer " + tt.layer))
+			test.Error(t, err)
+			s := []string{}
+			for _, name := range names {
+				s = append(s, string(name))
+			}
+			test.T(t, s, tt.expected)
+		})
+	}
+
+	_, err := ParseLayerNames(prelude("@layer a b"))
+	test.That(t, err != nil)
+	_, err = ParseLayerNames(prelude("@layer a.5"))
+	test.That(t, err != nil)
+	_, err = ParseLayerNames(prelude("@layer a,"))
+	test.That(t, err != nil)
+	for _, keyword := range []string{"initial", "INHERIT", "unset", "revert", "revert-layer"} {
+		_, err = ParseLayerNames(prelude("@layer " + keyword))
+		test.That(t, err != nil, keyword)
+		_, err = ParseLayerNames(prelude("@layer a." + keyword))
+		test.That(t, err != nil, "a."+keyword)
+	}
+}
+
+func ExampleParseMediaQueryList() {
+	sheet, _ := ParseStylesheet(bytes.NewBufferString("@media screen and (400px <= width <= 700px) { a { color: red } }"))
+	atRule := sheet.Rules[0].(*AtRule)
+	list, _ := ParseMediaQueryList(atRule.Prelude)
+	feature := list[0].Condition.(*MediaFeature)
+	for _, r := range feature.Ranges {
+		fmt.Println(string(feature.Name), r.Comparison, tokensString(r.Value))
+	}
+	// Output:
+	// width >= 400px
+	// width <= 700px
+}
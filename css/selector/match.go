@@ -0,0 +1,246 @@
+package selector
+
+import (
+	"bytes"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// Element is an element in a document tree that selectors can be matched against. The navigation methods only return elements and must return a nil interface when there is no such element.
+type Element interface {
+	Tag() []byte                    // local name of the element
+	Attr(key string) ([]byte, bool) // attribute value, the key is lowercase
+	Parent() Element
+	FirstChild() Element
+	PrevSibling() Element
+	NextSibling() Element
+}
+
+// EmptyElement can be implemented by an Element to report whether it has no children at all, including text. Otherwise, :empty matches elements without child elements.
+type EmptyElement interface {
+	Empty() bool
+}
+
+// Match returns true if any of the complex selectors in the list matches the element.
+func (l List) Match(e Element) bool {
+	for _, c := range l {
+		if c.Match(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns true if the complex selector matches the element. Selectors with pseudo-elements never match.
+func (c Complex) Match(e Element) bool {
+	return 0 < len(c) && matchComplex(c, len(c)-1, e, nil)
+}
+
+// Select returns the descendants of root that match any of the selectors in the list in document order, like querySelectorAll.
+func (l List) Select(root Element) []Element {
+	elems := []Element{}
+	for child := root.FirstChild(); child != nil; child = child.NextSibling() {
+		walk(child, func(e Element) {
+			if l.Match(e) {
+				elems = append(elems, e)
+			}
+		})
+	}
+	return elems
+}
+
+func walk(e Element, f func(Element)) {
+	f(e)
+	for child := e.FirstChild(); child != nil; child = child.NextSibling() {
+		walk(child, f)
+	}
+}
+
+// matchComplex matches the compounds c[:i+1] with c[i] matching e. For relative selectors, anchor is the element of :has() and the first compound is matched relative to it.
+func matchComplex(c Complex, i int, e Element, anchor Element) bool {
+	if !c[i].Match(e) {
+		return false
+	}
+	comb := c[i].Combinator
+	var next func(Element) bool
+	if i == 0 {
+		if anchor == nil {
+			return true
+		}
+		next = func(x Element) bool { return x == anchor }
+	} else {
+		next = func(x Element) bool { return matchComplex(c, i-1, x, anchor) }
+	}
+
+	switch comb {
+	case Descendant:
+		for p := e.Parent(); p != nil; p = p.Parent() {
+			if next(p) {
+				return true
+			}
+		}
+	case Child:
+		if p := e.Parent(); p != nil {
+			return next(p)
+		}
+	case NextSibling:
+		if s := e.PrevSibling(); s != nil {
+			return next(s)
+		}
+	case SubsequentSibling:
+		for s := e.PrevSibling(); s != nil; s = s.PrevSibling() {
+			if next(s) {
+				return true
+			}
+		}
+	case NoCombinator:
+		return i == 0
+	}
+	return false
+}
+
+// Match returns true if the element matches all simple selectors of the compound, ignoring its combinator.
+func (c Compound) Match(e Element) bool {
+	if c.Tag != nil && !(len(c.Tag) == 1 && c.Tag[0] == '*') && !bytes.EqualFold(c.Tag, e.Tag()) {
+		return false
+	}
+	for _, s := range c.Simples {
+		if !s.Match(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if the element matches the simple selector. Pseudo-elements, and pseudo-classes that depend on user interaction or are unknown, never match.
+func (s Simple) Match(e Element) bool {
+	switch s.Type {
+	case IDSelector:
+		id, ok := e.Attr("id")
+		return ok && bytes.Equal(id, s.Name)
+	case ClassSelector:
+		class, ok := e.Attr("class")
+		return ok && includes(class, s.Name)
+	case AttributeSelector:
+		val, ok := e.Attr(string(s.Name))
+		return ok && s.matchAttr(val)
+	case PseudoClassSelector:
+		return s.matchPseudoClass(e)
+	}
+	return false
+}
+
+func (s Simple) matchAttr(val []byte) bool {
+	ref := s.Value
+	if s.Insensitive {
+		val = parse.ToLower(parse.Copy(val))
+		ref = parse.ToLower(parse.Copy(ref))
+	}
+	switch s.Matcher {
+	case ExistsMatcher:
+		return true
+	case EqualMatcher:
+		return bytes.Equal(val, ref)
+	case IncludeMatcher:
+		return includes(val, ref)
+	case DashMatcher:
+		return bytes.Equal(val, ref) || bytes.HasPrefix(val, ref) && len(ref) < len(val) && val[len(ref)] == '-'
+	case PrefixMatcher:
+		return 0 < len(ref) && bytes.HasPrefix(val, ref)
+	case SuffixMatcher:
+		return 0 < len(ref) && bytes.HasSuffix(val, ref)
+	case SubstringMatcher:
+		return 0 < len(ref) && bytes.Contains(val, ref)
+	}
+	return false
+}
+
+// includes returns true if the whitespace-separated list contains the item.
+func includes(list, item []byte) bool {
+	if len(item) == 0 {
+		return false
+	}
+	for _, field := range bytes.Fields(list) {
+		if bytes.Equal(field, item) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Simple) matchPseudoClass(e Element) bool {
+	switch string(s.Name) {
+	case "is", "where", "matches", "any":
+		return s.Selectors.Match(e)
+	case "not":
+		return !s.Selectors.Match(e)
+	case "has":
+		for _, c := range s.Selectors {
+			if matchRelative(c, e) {
+				return true
+			}
+		}
+		return false
+	case "root":
+		return e.Parent() == nil
+	case "empty":
+		if empty, ok := e.(EmptyElement); ok {
+			return empty.Empty()
+		}
+		return e.FirstChild() == nil
+	case "first-child":
+		return e.PrevSibling() == nil
+	case "last-child":
+		return e.NextSibling() == nil
+	case "only-child":
+		return e.PrevSibling() == nil && e.NextSibling() == nil
+	case "first-of-type":
+		return index(e, Element.PrevSibling, true, nil) == 1
+	case "last-of-type":
+		return index(e, Element.NextSibling, true, nil) == 1
+	case "only-of-type":
+		return index(e, Element.PrevSibling, true, nil) == 1 && index(e, Element.NextSibling, true, nil) == 1
+	case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+		if s.Selectors != nil && !s.Selectors.Match(e) {
+			return false
+		}
+		dir := Element.PrevSibling
+		if s.Name[4] == 'l' {
+			dir = Element.NextSibling
+		}
+		ofType := bytes.HasSuffix(s.Name, []byte("of-type"))
+		return s.Nth.Matches(index(e, dir, ofType, s.Selectors))
+	}
+	return false
+}
+
+// index returns the 1-based position of the element among its siblings in the given direction, counting only siblings of the same type or that match the selector list.
+func index(e Element, dir func(Element) Element, ofType bool, of List) int {
+	i := 1
+	for s := dir(e); s != nil; s = dir(s) {
+		if ofType && !bytes.EqualFold(s.Tag(), e.Tag()) || of != nil && !of.Match(s) {
+			continue
+		}
+		i++
+	}
+	return i
+}
+
+// matchRelative returns true if an element relative to the anchor matches the relative selector of :has().
+func matchRelative(c Complex, anchor Element) bool {
+	found := false
+	visit := func(e Element) {
+		if !found && matchComplex(c, len(c)-1, e, anchor) {
+			found = true
+		}
+	}
+	for child := anchor.FirstChild(); child != nil && !found; child = child.NextSibling() {
+		walk(child, visit)
+	}
+	if c[0].Combinator == NextSibling || c[0].Combinator == SubsequentSibling {
+		for s := anchor.NextSibling(); s != nil && !found; s = s.NextSibling() {
+			walk(s, visit)
+		}
+	}
+	return found
+}
@@ -0,0 +1,152 @@
+package selector
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/parse/v2/html"
+	"github.com/tdewolff/test"
+)
+
+// node implements Element for the HTML document tree.
+type node struct {
+	*html.Node
+}
+
+func element(n *html.Node) Element {
+	for n != nil && n.Type != html.ElementNode {
+		n = n.NextSibling
+	}
+	if n == nil {
+		return nil
+	}
+	return node{n}
+}
+
+func (n node) Tag() []byte {
+	return n.Data
+}
+
+func (n node) Empty() bool {
+	return n.Node.FirstChild == nil
+}
+
+func (n node) Parent() Element {
+	if n.Node.Parent == nil || n.Node.Parent.Type != html.ElementNode {
+		return nil
+	}
+	return node{n.Node.Parent}
+}
+
+func (n node) FirstChild() Element {
+	return element(n.Node.FirstChild)
+}
+
+func (n node) NextSibling() Element {
+	return element(n.Node.NextSibling)
+}
+
+func (n node) PrevSibling() Element {
+	for p := n.Node.PrevSibling; p != nil; p = p.PrevSibling {
+		if p.Type == html.ElementNode {
+			return node{p}
+		}
+	}
+	return nil
+}
+
+const document = `<ul id=list class="a b">
+<li id=1 class=a>one</li>
+<li id=2 lang=en-US data-x="Foo Bar">two</li>
+<li id=3 class="a c"><p id=4>three</p></li>
+<li id=5><span id=6></span><p id=7></p><span id=8></span></li>
+</ul>
+<p id=9 class=b></p>`
+
+func TestMatch(t *testing.T) {
+	doc, err := html.Parse(bytes.NewBufferString(document))
+	test.Error(t, err)
+	root := element(doc.FirstChild)
+
+	var matchTests = []struct {
+		sel      string
+		expected string // ids of the selected elements
+	}{
+		{"li", "1 2 3 5"},
+		{"*", "list 1 2 3 4 5 6 7 8 9"},
+		{"LI", "1 2 3 5"},
+		{"#list", "list"},
+		{".a", "list 1 3"},
+		{".a.c", "3"},
+		{"ul .a", "1 3"},
+		{"ul > p", ""},
+		{"li > p", "4 7"},
+		{"ul p", "4 7"},
+		{"li + li", "2 3 5"},
+		{"#1 ~ li", "2 3 5"},
+		{"span + p", "7"},
+		{"p ~ span", "8"},
+		{"[lang]", "2"},
+		{"[lang|=en]", "2"},
+		{"[lang|=e]", ""},
+		{"[data-x~=Bar]", "2"},
+		{"[data-x=\"foo bar\"]", ""},
+		{"[data-x=\"foo bar\" i]", "2"},
+		{"[data-x=\"\\46oo B\\61r\"]", "2"},
+		{"[class^=a]", "list 1 3"},
+		{"[class$=c]", "3"},
+		{"[class*=' ']", "list 3"},
+		{"[class*='']", ""},
+		{":root", "html"},
+		{"li:empty", ""},
+		{"span:empty", "6 8"},
+		{"li:first-child", "1"},
+		{"li:last-child", "5"},
+		{"p:only-child", "4"},
+		{"span:first-of-type", "6"},
+		{"span:last-of-type", "8"},
+		{"p:only-of-type", "4 7 9"},
+		{"li:nth-child(odd)", "1 3"},
+		{"li:nth-child(2n)", "2 5"},
+		{"li:nth-child(-n+2)", "1 2"},
+		{"li:nth-last-child(1)", "5"},
+		{"li:nth-child(2 of .a)", "3"},
+		{"li:nth-last-child(1 of :not(#5))", "3"},
+		{"span:nth-of-type(2)", "8"},
+		{"span:nth-last-of-type(2)", "6"},
+		{":is(#1, #9)", "1 9"},
+		{":where(ul) > :not(.a)", "2 5"},
+		{"li:has(p)", "3 5"},
+		{"li:has(> span + p)", "5"},
+		{"li:has(+ li > p)", "2 3"},
+		{"li:has(~ li #8)", "1 2 3"},
+		{"span:has(~ p)", "6"},
+		{":has(#4) > p", "4 9"},
+		{"li::before", ""},
+		{"a:hover", ""},
+		{"ul, p.b", "list 9"},
+	}
+	for _, tt := range matchTests {
+		t.Run(tt.sel, func(t *testing.T) {
+			list, err := ParseString(tt.sel)
+			test.Error(t, err)
+			ids := []string{}
+			for _, e := range list.Select(node{doc}) {
+				if id, ok := e.Attr("id"); ok {
+					ids = append(ids, string(id))
+				} else {
+					ids = append(ids, string(e.Tag()))
+				}
+			}
+			if tt.sel == "*" {
+				ids = ids[3:] // html, head, body
+			}
+			test.String(t, strings.Join(ids, " "), tt.expected)
+		})
+	}
+
+	list, err := ParseString("html")
+	test.Error(t, err)
+	test.That(t, list.Match(root))
+}
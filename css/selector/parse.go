@@ -0,0 +1,544 @@
+package selector
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/css"
+)
+
+type parser struct {
+	tokens []css.Token
+	pos    int
+	err    error
+}
+
+// Parse parses a selector list from the tokens of a qualified rule's prelude, such as those returned by css.Parser.Values. All byte slices are copied.
+func Parse(tokens []css.Token) (List, error) {
+	p := &parser{tokens: tokens}
+	list := p.parseList(false)
+	if p.err == nil {
+		p.skipWhitespace()
+		if p.pos < len(p.tokens) {
+			p.fail("unexpected %s", p.describe())
+		}
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return list, nil
+}
+
+// ParseString parses a selector list from a string, such as the argument of querySelectorAll.
+func ParseString(s string) (List, error) {
+	l := css.NewLexer(bytes.NewBufferString(s))
+	tokens := []css.Token{}
+	for {
+		tt, data := l.Next()
+		if tt == css.ErrorToken {
+			if l.Err() != io.EOF {
+				return nil, l.Err()
+			}
+			break
+		}
+		tokens = append(tokens, css.Token{TokenType: tt, Data: data})
+	}
+	return Parse(tokens)
+}
+
+// fail sets the error at the current token if no error has been set before, the input is reconstructed from the tokens.
+func (p *parser) fail(message string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	p.skipComments()
+	input := []byte{}
+	offset := 0
+	for i, t := range p.tokens {
+		if i == p.pos {
+			offset = len(input)
+		}
+		input = append(input, t.Data...)
+	}
+	if len(p.tokens) <= p.pos {
+		offset = len(input)
+	}
+	p.err = parse.NewError(bytes.NewBuffer(input), offset, "CSS selector parse error: "+message, a...)
+}
+
+func (p *parser) describe() string {
+	t := p.peek(0)
+	if t.TokenType == css.ErrorToken {
+		return "end of selector"
+	}
+	return "'" + string(t.Data) + "'"
+}
+
+func (p *parser) peek(i int) css.Token {
+	i += p.pos
+	for i < len(p.tokens) {
+		if p.tokens[i].TokenType != css.CommentToken {
+			return p.tokens[i]
+		}
+		i++
+	}
+	return css.Token{TokenType: css.ErrorToken}
+}
+
+func (p *parser) next() css.Token {
+	for p.pos < len(p.tokens) && p.tokens[p.pos].TokenType == css.CommentToken {
+		p.pos++
+	}
+	if len(p.tokens) <= p.pos {
+		return css.Token{TokenType: css.ErrorToken}
+	}
+	p.pos++
+	return p.tokens[p.pos-1]
+}
+
+func (p *parser) skipComments() {
+	for p.pos < len(p.tokens) && p.tokens[p.pos].TokenType == css.CommentToken {
+		p.pos++
+	}
+}
+
+// skipWhitespace skips whitespace and comments, and returns true if any whitespace was skipped.
+func (p *parser) skipWhitespace() bool {
+	ws := false
+	for p.pos < len(p.tokens) && (p.tokens[p.pos].TokenType == css.WhitespaceToken || p.tokens[p.pos].TokenType == css.CommentToken) {
+		ws = ws || p.tokens[p.pos].TokenType == css.WhitespaceToken
+		p.pos++
+	}
+	return ws
+}
+
+func isDelim(t css.Token, c byte) bool {
+	return t.TokenType == css.DelimToken && len(t.Data) == 1 && t.Data[0] == c
+}
+
+func combinator(t css.Token) Combinator {
+	if t.TokenType == css.DelimToken && len(t.Data) == 1 && (t.Data[0] == '>' || t.Data[0] == '+' || t.Data[0] == '~') {
+		return Combinator(t.Data[0])
+	}
+	return NoCombinator
+}
+
+func (p *parser) parseList(relative bool) List {
+	list := List{}
+	for {
+		p.skipWhitespace()
+		c := p.parseComplex(relative)
+		if p.err != nil {
+			return nil
+		}
+		list = append(list, c)
+		p.skipWhitespace()
+		if p.peek(0).TokenType != css.CommaToken {
+			return list
+		}
+		p.next()
+	}
+}
+
+func (p *parser) parseComplex(relative bool) Complex {
+	first := NoCombinator
+	if relative {
+		first = Descendant
+		if c := combinator(p.peek(0)); c != NoCombinator {
+			first = c
+			p.next()
+			p.skipWhitespace()
+		}
+	}
+	compound := p.parseCompound()
+	if p.err != nil {
+		return nil
+	}
+	compound.Combinator = first
+	complex := Complex{compound}
+	for {
+		pos := p.pos
+		ws := p.skipWhitespace()
+		c := combinator(p.peek(0))
+		if c == NoCombinator && (!ws || !p.startsCompound()) {
+			p.pos = pos
+			return complex
+		} else if compound.hasPseudoElement() {
+			p.fail("unexpected %s after pseudo-element", p.describe())
+			return nil
+		}
+		if c != NoCombinator {
+			p.next()
+			p.skipWhitespace()
+		} else {
+			c = Descendant
+		}
+		compound = p.parseCompound()
+		if p.err != nil {
+			return nil
+		}
+		compound.Combinator = c
+		complex = append(complex, compound)
+	}
+}
+
+func (p *parser) startsCompound() bool {
+	t := p.peek(0)
+	switch t.TokenType {
+	case css.IdentToken, css.HashToken, css.LeftBracketToken, css.ColonToken:
+		return true
+	case css.DelimToken:
+		return isDelim(t, '*') || isDelim(t, '.')
+	}
+	return false
+}
+
+func (p *parser) parseCompound() Compound {
+	compound := Compound{}
+	p.skipComments()
+	if t := p.peek(0); t.TokenType == css.IdentToken || isDelim(t, '*') {
+		p.next()
+		compound.Tag = parse.Copy(t.Data)
+		if isDelim(p.peek(0), '|') {
+			p.fail("namespace prefixes are not supported")
+			return compound
+		}
+	}
+	pseudoElement := false // only user action pseudo-classes may follow a pseudo-element
+	for {
+		p.skipComments()
+		t := p.peek(0)
+		if pseudoElement && (t.TokenType == css.HashToken || isDelim(t, '.') || t.TokenType == css.LeftBracketToken || t.TokenType == css.ColonToken && !isUserAction(p.peek(1))) {
+			p.fail("unexpected %s after pseudo-element", p.describe())
+			return compound
+		}
+		switch t.TokenType {
+		case css.HashToken:
+			p.next()
+			compound.Simples = append(compound.Simples, Simple{Type: IDSelector, Name: parse.Copy(t.Data[1:])})
+		case css.DelimToken:
+			if !isDelim(t, '.') {
+				return p.finishCompound(compound)
+			}
+			p.next()
+			name := p.peek(0)
+			if name.TokenType != css.IdentToken {
+				p.fail("expected class name instead of %s", p.describe())
+				return compound
+			}
+			p.next()
+			compound.Simples = append(compound.Simples, Simple{Type: ClassSelector, Name: parse.Copy(name.Data)})
+		case css.LeftBracketToken:
+			p.next()
+			compound.Simples = append(compound.Simples, p.parseAttribute())
+		case css.ColonToken:
+			p.next()
+			simple := p.parsePseudo()
+			compound.Simples = append(compound.Simples, simple)
+			pseudoElement = pseudoElement || simple.Type == PseudoElementSelector
+		default:
+			return p.finishCompound(compound)
+		}
+		if p.err != nil {
+			return compound
+		}
+	}
+}
+
+// isUserAction returns true for the name of a user action pseudo-class, which are the only pseudo-classes allowed after a pseudo-element.
+func isUserAction(t css.Token) bool {
+	if t.TokenType != css.IdentToken {
+		return false
+	}
+	switch string(parse.ToLower(parse.Copy(t.Data))) {
+	case "hover", "active", "focus", "focus-visible", "focus-within":
+		return true
+	}
+	return false
+}
+
+func (p *parser) finishCompound(compound Compound) Compound {
+	if compound.Tag == nil && len(compound.Simples) == 0 {
+		p.fail("unexpected %s", p.describe())
+	}
+	return compound
+}
+
+func (p *parser) parseAttribute() Simple {
+	simple := Simple{Type: AttributeSelector}
+	p.skipWhitespace()
+	name := p.peek(0)
+	if name.TokenType != css.IdentToken {
+		p.fail("expected attribute name instead of %s", p.describe())
+		return simple
+	}
+	p.next()
+	simple.Name = parse.ToLower(parse.Copy(name.Data))
+	p.skipWhitespace()
+
+	t := p.peek(0)
+	switch {
+	case t.TokenType == css.RightBracketToken:
+		p.next()
+		return simple
+	case isDelim(t, '='):
+		simple.Matcher = EqualMatcher
+	case t.TokenType == css.IncludeMatchToken:
+		simple.Matcher = IncludeMatcher
+	case t.TokenType == css.DashMatchToken:
+		simple.Matcher = DashMatcher
+	case t.TokenType == css.PrefixMatchToken:
+		simple.Matcher = PrefixMatcher
+	case t.TokenType == css.SuffixMatchToken:
+		simple.Matcher = SuffixMatcher
+	case t.TokenType == css.SubstringMatchToken:
+		simple.Matcher = SubstringMatcher
+	default:
+		p.fail("expected attribute matcher instead of %s", p.describe())
+		return simple
+	}
+	p.next()
+	p.skipWhitespace()
+
+	val := p.peek(0)
+	if val.TokenType == css.StringToken {
+		simple.Value = unescapeString(val.Data)
+	} else if val.TokenType == css.IdentToken {
+		simple.Value = unescape(val.Data)
+	} else {
+		p.fail("expected attribute value instead of %s", p.describe())
+		return simple
+	}
+	p.next()
+	p.skipWhitespace()
+
+	if t := p.peek(0); t.TokenType == css.IdentToken && len(t.Data) == 1 && (t.Data[0]|0x20 == 'i' || t.Data[0]|0x20 == 's') {
+		p.next()
+		simple.Insensitive = t.Data[0]|0x20 == 'i'
+		p.skipWhitespace()
+	}
+	if p.peek(0).TokenType != css.RightBracketToken {
+		p.fail("expected ']' instead of %s", p.describe())
+		return simple
+	}
+	p.next()
+	return simple
+}
+
+func (p *parser) parsePseudo() Simple {
+	simple := Simple{Type: PseudoClassSelector}
+	if p.peek(0).TokenType == css.ColonToken {
+		p.next()
+		simple.Type = PseudoElementSelector
+	}
+	t := p.peek(0)
+	if t.TokenType != css.IdentToken && t.TokenType != css.FunctionToken {
+		p.fail("expected pseudo-class name instead of %s", p.describe())
+		return simple
+	}
+	p.next()
+	if t.TokenType == css.IdentToken {
+		simple.Name = parse.ToLower(parse.Copy(t.Data))
+		switch string(simple.Name) {
+		case "before", "after", "first-line", "first-letter":
+			simple.Type = PseudoElementSelector
+		}
+		return simple
+	}
+	simple.Name = parse.ToLower(parse.Copy(t.Data[:len(t.Data)-1]))
+	simple.Function = true
+
+	if simple.Type == PseudoClassSelector {
+		switch string(simple.Name) {
+		case "is", "where", "not", "matches", "any", "has":
+			simple.Selectors = p.parseList(string(simple.Name) == "has")
+			p.skipWhitespace()
+			p.closeParenthesis()
+			return simple
+		case "nth-child", "nth-last-child", "nth-of-type", "nth-last-of-type":
+			simple.Nth = p.parseNth()
+			if p.err != nil {
+				return simple
+			}
+			p.skipWhitespace()
+			if t := p.peek(0); (string(simple.Name) == "nth-child" || string(simple.Name) == "nth-last-child") && t.TokenType == css.IdentToken && parse.EqualFold(t.Data, []byte("of")) {
+				p.next()
+				simple.Selectors = p.parseList(false)
+				p.skipWhitespace()
+			}
+			p.closeParenthesis()
+			return simple
+		}
+	}
+
+	// keep the arguments of other functions verbatim
+	level := 0
+	args := []byte{}
+	for {
+		t := p.next()
+		switch t.TokenType {
+		case css.ErrorToken:
+			p.fail("unexpected end of selector in arguments of :%s()", simple.Name)
+			return simple
+		case css.FunctionToken, css.LeftParenthesisToken:
+			level++
+		case css.RightParenthesisToken:
+			if level == 0 {
+				simple.Args = parse.TrimWhitespace(args)
+				return simple
+			}
+			level--
+		}
+		args = append(args, t.Data...)
+	}
+}
+
+func (p *parser) closeParenthesis() {
+	if p.err != nil {
+		return
+	} else if p.peek(0).TokenType != css.RightParenthesisToken {
+		p.fail("expected ')' instead of %s", p.describe())
+		return
+	}
+	p.next()
+}
+
+// parseNth parses the An+B microsyntax from the tokens up to the closing parenthesis or the "of" keyword.
+func (p *parser) parseNth() *Nth {
+	p.skipWhitespace()
+	start := p.pos
+	b := []byte{}
+	for {
+		t := p.peek(0)
+		if t.TokenType == css.RightParenthesisToken || t.TokenType == css.ErrorToken || t.TokenType == css.IdentToken && parse.EqualFold(t.Data, []byte("of")) {
+			break
+		}
+		p.next()
+		if t.TokenType != css.WhitespaceToken {
+			b = append(b, t.Data...)
+		} else if p.peek(0).TokenType != css.RightParenthesisToken {
+			b = append(b, ' ') // only allowed around the sign of B
+		}
+	}
+	nth, ok := parseAnB(parse.ToLower(bytes.TrimRight(b, " ")))
+	if !ok {
+		p.pos = start
+		p.fail("bad An+B expression")
+		return nil
+	}
+	return &nth
+}
+
+func parseAnB(b []byte) (Nth, bool) {
+	if string(b) == "odd" {
+		return Nth{2, 1}, true
+	} else if string(b) == "even" {
+		return Nth{2, 0}, true
+	}
+	i := bytes.IndexByte(b, 'n')
+	if i == -1 {
+		n, err := strconv.Atoi(string(b))
+		return Nth{0, n}, err == nil
+	}
+
+	nth := Nth{}
+	switch a := string(b[:i]); a {
+	case "", "+":
+		nth.A = 1
+	case "-":
+		nth.A = -1
+	default:
+		n, err := strconv.Atoi(a)
+		if err != nil {
+			return nth, false
+		}
+		nth.A = n
+	}
+	if rest := bytes.TrimLeft(b[i+1:], " "); 0 < len(rest) {
+		digits := bytes.TrimLeft(rest[1:], " ")
+		if rest[0] != '+' && rest[0] != '-' || len(digits) == 0 || digits[0] < '0' || '9' < digits[0] {
+			return nth, false
+		}
+		n, err := strconv.Atoi(string(digits))
+		if err != nil {
+			return nth, false
+		}
+		if rest[0] == '-' {
+			n = -n
+		}
+		nth.B = n
+	}
+	return nth, true
+}
+
+// unescape returns a copy of an identifier or of the contents of a string with its escapes resolved.
+func unescape(b []byte) []byte {
+	if bytes.IndexByte(b, '\\') == -1 {
+		return parse.Copy(b)
+	}
+	dst := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' {
+			dst = append(dst, b[i])
+			continue
+		}
+		i++
+		if i == len(b) {
+			dst = appendRune(dst, utf8.RuneError)
+			break
+		}
+
+		n := 0
+		for n < 6 && i+n < len(b) && isHex(b[i+n]) {
+			n++
+		}
+		if n == 0 {
+			dst = append(dst, b[i])
+			continue
+		}
+		r, _ := strconv.ParseUint(string(b[i:i+n]), 16, 32)
+		if r == 0 || 0x10FFFF < r || 0xD800 <= r && r <= 0xDFFF {
+			r = utf8.RuneError
+		}
+		dst = appendRune(dst, rune(r))
+		i += n
+		if i < len(b) && b[i] == '\r' && i+1 < len(b) && b[i+1] == '\n' {
+			i++
+		} else if i == len(b) || !parse.IsWhitespace(b[i]) {
+			i-- // no whitespace to skip after the escape
+		}
+	}
+	return dst
+}
+
+// unescapeString removes the quotes and escaped newlines of a string token, and returns a copy with its escapes resolved.
+func unescapeString(b []byte) []byte {
+	quote := b[0]
+	b = b[1:]
+	if n := len(b); 0 < n && b[n-1] == quote {
+		escaped := false // the quote is escaped when preceded by an odd number of backslashes, which happens for unterminated strings at EOF
+		for i := n - 2; 0 <= i && b[i] == '\\'; i-- {
+			escaped = !escaped
+		}
+		if !escaped {
+			b = b[:n-1]
+		}
+	}
+	for _, newline := range []string{"\\\r\n", "\\\n", "\\\r", "\\\f"} {
+		b = bytes.Replace(b, []byte(newline), nil, -1)
+	}
+	return unescape(b)
+}
+
+func appendRune(b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(b, buf[:n]...)
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,179 @@
+package selector
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/css"
+	"github.com/tdewolff/test"
+)
+
+func TestParse(t *testing.T) {
+	var selectorTests = []struct {
+		sel      string
+		expected string
+	}{
+		{"a", "a"},
+		{"*", "*"},
+		{"  a  ,  b  ", "a,b"},
+		{"a b > c + d ~ e", "a b>c+d~e"},
+		{"a>b", "a>b"},
+		{"a /*x*/ b", "a b"},
+		{"#id.class1.class2", "#id.class1.class2"},
+		{"div#id", "div#id"},
+		{"[href]", "[href]"},
+		{"[HREF = 'x']", "[href=\"x\"]"},
+		{"[a~=b][a|=b][a^=b][a$=b][a*=b]", "[a~=\"b\"][a|=\"b\"][a^=\"b\"][a$=\"b\"][a*=\"b\"]"},
+		{"[a=\"b'\" i]", "[a=\"b'\" i]"},
+		{"[a='b\"' s]", "[a='b\"']"},
+		{"[x=\"a'b\\\"\"]", "[x=\"a'b\\\"\"]"},
+		{"[a=\"b\\\\c\"]", "[a=\"b\\\\c\"]"},
+		{"[a=\\31 x]", "[a=\"1x\"]"},
+		{"[a='\\41 b\\\nc\\27']", "[a=\"Abc'\"]"},
+		{"[a='\\9']", "[a=\"\\9 \"]"},
+		{"a:hover", "a:hover"},
+		{"a:HOVER::Before", "a:hover::before"},
+		{"a:before", "a::before"},
+		{"a::before:hover:FOCUS-within", "a::before:hover:focus-within"},
+		{":is(a, b c)", ":is(a,b c)"},
+		{":not(.a):where(#b)", ":not(.a):where(#b)"},
+		{":has(> img, + p, ~ q, r)", ":has(>img,+p,~q,r)"},
+		{":nth-child(2n+1)", ":nth-child(2n+1)"},
+		{":nth-child( odd )", ":nth-child(2n+1)"},
+		{":nth-child(even)", ":nth-child(2n)"},
+		{":nth-child(-n + 3)", ":nth-child(-n+3)"},
+		{":nth-child(n)", ":nth-child(n)"},
+		{":nth-last-child(5)", ":nth-last-child(5)"},
+		{":nth-child(2n-1 of .a, b)", ":nth-child(2n-1 of .a,b)"},
+		{":nth-of-type(+3n - 2)", ":nth-of-type(3n-2)"},
+		{":nth-child(2n+ 1)", ":nth-child(2n+1)"},
+		{":nth-child( -n- 6 )", ":nth-child(-n-6)"},
+		{":nth-child( 3 of a)", ":nth-child(3 of a)"},
+		{":lang(en, fr)", ":lang(en, fr)"},
+		{"::slotted(span)", "::slotted(span)"},
+	}
+	for _, tt := range selectorTests {
+		t.Run(tt.sel, func(t *testing.T) {
+			list, err := ParseString(tt.sel)
+			test.Error(t, err)
+			test.String(t, list.String(), tt.expected)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var errorTests = []struct {
+		sel string
+		err string
+		col int
+	}{
+		{"", "unexpected end of selector", 1},
+		{"a,", "unexpected end of selector", 3},
+		{"a >", "unexpected end of selector", 4},
+		{"a {", "unexpected '{'", 3},
+		{".5", "unexpected '.5'", 1},
+		{"a/*x*/b", "unexpected 'b'", 7},
+		{". a", "expected class name instead of ' '", 2},
+		{"[=a]", "expected attribute name instead of '='", 2},
+		{"[a b]", "expected attribute matcher instead of 'b'", 4},
+		{"[a=5]", "expected attribute value instead of '5'", 4},
+		{"[a=b c]", "expected ']' instead of 'c'", 6},
+		{":5", "expected pseudo-class name instead of '5'", 2},
+		{":is(a", "expected ')' instead of end of selector", 6},
+		{":nth-child(x)", "bad An+B expression", 12},
+		{":nth-child(2n+-1)", "bad An+B expression", 12},
+		{":nth-child(- n+3)", "bad An+B expression", 12},
+		{":nth-child(+ 2n)", "bad An+B expression", 12},
+		{":nth-child(2 n)", "bad An+B expression", 12},
+		{":nth-child(+ 2)", "bad An+B expression", 12},
+		{"::before.x", "unexpected '.' after pseudo-element", 9},
+		{"a::after#b", "unexpected '#b' after pseudo-element", 9},
+		{"::before:hover[a]", "unexpected '[' after pseudo-element", 15},
+		{"::before a", "unexpected 'a' after pseudo-element", 10},
+		{"a::after > b", "unexpected '>' after pseudo-element", 10},
+		{"::before:first-child", "unexpected ':' after pseudo-element", 9},
+		{"::before::after", "unexpected ':' after pseudo-element", 9},
+		{":lang(en", "unexpected end of selector in arguments of :lang()", 9},
+		{"ns|a", "namespace prefixes are not supported", 3},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.sel, func(t *testing.T) {
+			_, err := ParseString(tt.sel)
+			test.That(t, err != nil, "expected error")
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "CSS selector parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	p := css.NewParser(bytes.NewBufferString("ul > li:not(.a, .b) , #c { x: y }"), false)
+	gt, _, _ := p.Next()
+	test.T(t, gt, css.QualifiedRuleGrammar)
+	list, err := Parse(p.Values())
+	test.Error(t, err)
+	test.String(t, list.String(), "ul>li:not(.a,.b)")
+	gt, _, _ = p.Next()
+	test.T(t, gt, css.BeginRulesetGrammar)
+	list, err = Parse(p.Values())
+	test.Error(t, err)
+	test.String(t, list.String(), "#c")
+}
+
+func TestSpecificity(t *testing.T) {
+	var specificityTests = []struct {
+		sel      string
+		expected Specificity
+	}{
+		{"*", Specificity{0, 0, 0}},
+		{"li", Specificity{0, 0, 1}},
+		{"ul li", Specificity{0, 0, 2}},
+		{"ul ol+li", Specificity{0, 0, 3}},
+		{"h1 + *[rel=up]", Specificity{0, 1, 1}},
+		{"ul ol li.red", Specificity{0, 1, 3}},
+		{"li.red.level", Specificity{0, 2, 1}},
+		{"#x34y", Specificity{1, 0, 0}},
+		{"#s12:not(foo)", Specificity{1, 0, 1}},
+		{".foo :is(.bar, #baz)", Specificity{1, 1, 0}},
+		{":where(#a, .b) c", Specificity{0, 0, 1}},
+		{":has(> a#b)", Specificity{1, 0, 1}},
+		{"a::before:hover", Specificity{0, 1, 2}},
+		{":nth-child(2n of .a, #b)", Specificity{1, 1, 0}},
+		{"a, #b, .c", Specificity{1, 0, 0}},
+	}
+	for _, tt := range specificityTests {
+		t.Run(tt.sel, func(t *testing.T) {
+			list, err := ParseString(tt.sel)
+			test.Error(t, err)
+			test.T(t, list.Specificity(), tt.expected)
+		})
+	}
+
+	test.That(t, Specificity{0, 1, 0}.Less(Specificity{1, 0, 0}))
+	test.That(t, Specificity{0, 1, 5}.Less(Specificity{0, 2, 0}))
+	test.That(t, !Specificity{0, 1, 0}.Less(Specificity{0, 1, 0}))
+	test.String(t, Specificity{1, 2, 3}.String(), "(1,2,3)")
+}
+
+func ExampleParseString() {
+	list, err := ParseString("#nav li > a:not(.external)")
+	if err != nil {
+		panic(err)
+	}
+	for _, compound := range list[0] {
+		fmt.Println(compound.Combinator, compound)
+	}
+	fmt.Println(list.Specificity())
+	// Output:
+	// None #nav
+	// Descendant li
+	// Child a:not(.external)
+	// (1,1,2)
+}
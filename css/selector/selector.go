@@ -0,0 +1,334 @@
+// Package selector parses CSS selectors following the specification at https://www.w3.org/TR/selectors-4/, computes their specificity, and matches them against elements.
+package selector
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Combinator is the combinator between two compound selectors.
+type Combinator byte
+
+// Combinator values.
+const (
+	NoCombinator      Combinator = 0
+	Descendant        Combinator = ' '
+	Child             Combinator = '>'
+	NextSibling       Combinator = '+'
+	SubsequentSibling Combinator = '~'
+)
+
+// String returns the string representation of a Combinator.
+func (c Combinator) String() string {
+	switch c {
+	case NoCombinator:
+		return "None"
+	case Descendant:
+		return "Descendant"
+	case Child:
+		return "Child"
+	case NextSibling:
+		return "NextSibling"
+	case SubsequentSibling:
+		return "SubsequentSibling"
+	}
+	return "Invalid(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Matcher is the operator of an attribute selector.
+type Matcher uint32
+
+// Matcher values.
+const (
+	ExistsMatcher    Matcher = iota // [attr]
+	EqualMatcher                    // [attr=val]
+	IncludeMatcher                  // [attr~=val]
+	DashMatcher                     // [attr|=val]
+	PrefixMatcher                   // [attr^=val]
+	SuffixMatcher                   // [attr$=val]
+	SubstringMatcher                // [attr*=val]
+)
+
+// String returns the string representation of a Matcher.
+func (m Matcher) String() string {
+	switch m {
+	case ExistsMatcher:
+		return ""
+	case EqualMatcher:
+		return "="
+	case IncludeMatcher:
+		return "~="
+	case DashMatcher:
+		return "|="
+	case PrefixMatcher:
+		return "^="
+	case SuffixMatcher:
+		return "$="
+	case SubstringMatcher:
+		return "*="
+	}
+	return "Invalid(" + strconv.Itoa(int(m)) + ")"
+}
+
+// SimpleType determines the type of a simple selector that is not a type selector.
+type SimpleType uint32
+
+// SimpleType values.
+const (
+	IDSelector SimpleType = iota
+	ClassSelector
+	AttributeSelector
+	PseudoClassSelector
+	PseudoElementSelector
+)
+
+// String returns the string representation of a SimpleType.
+func (st SimpleType) String() string {
+	switch st {
+	case IDSelector:
+		return "ID"
+	case ClassSelector:
+		return "Class"
+	case AttributeSelector:
+		return "Attribute"
+	case PseudoClassSelector:
+		return "PseudoClass"
+	case PseudoElementSelector:
+		return "PseudoElement"
+	}
+	return "Invalid(" + strconv.Itoa(int(st)) + ")"
+}
+
+// Nth is the An+B argument of the :nth-*() pseudo-classes.
+type Nth struct {
+	A, B int
+}
+
+// Matches returns true if the 1-based index equals An+B for some non-negative integer n.
+func (nth Nth) Matches(index int) bool {
+	if nth.A == 0 {
+		return index == nth.B
+	}
+	d := index - nth.B
+	return d%nth.A == 0 && 0 <= d/nth.A
+}
+
+func (nth Nth) String() string {
+	if nth.A == 0 {
+		return strconv.Itoa(nth.B)
+	}
+	s := strconv.Itoa(nth.A) + "n"
+	if nth.A == 1 {
+		s = "n"
+	} else if nth.A == -1 {
+		s = "-n"
+	}
+	if 0 < nth.B {
+		s += "+" + strconv.Itoa(nth.B)
+	} else if nth.B < 0 {
+		s += strconv.Itoa(nth.B)
+	}
+	return s
+}
+
+// Simple is an ID, class, attribute, pseudo-class, or pseudo-element selector.
+type Simple struct {
+	Type        SimpleType
+	Name        []byte  // ID or class name, attribute name, or pseudo-class or pseudo-element name, the latter three lowercased
+	Matcher     Matcher // attribute selectors only
+	Value       []byte  // attribute value without quotes and with escapes resolved
+	Insensitive bool    // attribute value is compared case-insensitively by the i modifier
+	Selectors   List    // argument of :is(), :where(), :not(), and :has(), or the S of :nth-child(An+B of S)
+	Nth         *Nth    // argument of the :nth-*() pseudo-classes
+	Args        []byte  // verbatim argument of other functional pseudo-classes and pseudo-elements
+	Function    bool    // pseudo-class or pseudo-element is written as a function
+}
+
+func (s Simple) String() string {
+	switch s.Type {
+	case IDSelector:
+		return "#" + string(s.Name)
+	case ClassSelector:
+		return "." + string(s.Name)
+	case AttributeSelector:
+		str := "[" + string(s.Name)
+		if s.Matcher != ExistsMatcher {
+			str += s.Matcher.String() + quoteString(s.Value)
+			if s.Insensitive {
+				str += " i"
+			}
+		}
+		return str + "]"
+	}
+	str := ":" + string(s.Name)
+	if s.Type == PseudoElementSelector {
+		str = ":" + str
+	}
+	if s.Function {
+		str += "("
+		if s.Nth != nil {
+			str += s.Nth.String()
+			if s.Selectors != nil {
+				str += " of " + s.Selectors.String()
+			}
+		} else if s.Selectors != nil {
+			str += s.Selectors.String()
+		} else {
+			str += string(s.Args)
+		}
+		str += ")"
+	}
+	return str
+}
+
+// quoteString returns the value as a CSS string, using single quotes if that avoids escaping double quotes.
+func quoteString(b []byte) string {
+	quote := byte('"')
+	if bytes.IndexByte(b, '"') != -1 && bytes.IndexByte(b, '\'') == -1 {
+		quote = '\''
+	}
+	sb := strings.Builder{}
+	sb.WriteByte(quote)
+	for _, c := range b {
+		if c == quote || c == '\\' {
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		} else if c < 0x20 || c == 0x7F {
+			sb.WriteString("\\" + strconv.FormatInt(int64(c), 16) + " ")
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte(quote)
+	return sb.String()
+}
+
+// Compound is a compound selector, a sequence of simple selectors that all match the same element.
+type Compound struct {
+	Combinator Combinator // combinator between the previous compound and this one, for the first compound this is NoCombinator or the combinator of a relative selector
+	Tag        []byte     // type selector, * for the universal selector, or nil if absent
+	Simples    []Simple
+}
+
+func (c Compound) hasPseudoElement() bool {
+	for _, s := range c.Simples {
+		if s.Type == PseudoElementSelector {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Compound) String() string {
+	s := string(c.Tag)
+	for _, simple := range c.Simples {
+		s += simple.String()
+	}
+	return s
+}
+
+// Complex is a complex selector, a sequence of compound selectors separated by combinators.
+type Complex []Compound
+
+func (c Complex) String() string {
+	s := ""
+	for i, compound := range c {
+		if compound.Combinator == Descendant {
+			if i != 0 {
+				s += " "
+			}
+		} else if compound.Combinator != NoCombinator {
+			s += string(compound.Combinator)
+		}
+		s += compound.String()
+	}
+	return s
+}
+
+// List is a comma-separated list of complex selectors.
+type List []Complex
+
+func (l List) String() string {
+	s := ""
+	for i, c := range l {
+		if i != 0 {
+			s += ","
+		}
+		s += c.String()
+	}
+	return s
+}
+
+////////////////////////////////////////////////////////////////
+
+// Specificity is the specificity of a selector as the number of (ID, class, type) selectors.
+type Specificity [3]int
+
+// Less returns true if s has a lower specificity than t.
+func (s Specificity) Less(t Specificity) bool {
+	for i := 0; i < 3; i++ {
+		if s[i] != t[i] {
+			return s[i] < t[i]
+		}
+	}
+	return false
+}
+
+func (s Specificity) add(t Specificity) Specificity {
+	return Specificity{s[0] + t[0], s[1] + t[1], s[2] + t[2]}
+}
+
+func (s Specificity) String() string {
+	return "(" + strconv.Itoa(s[0]) + "," + strconv.Itoa(s[1]) + "," + strconv.Itoa(s[2]) + ")"
+}
+
+// Specificity returns the highest specificity of the complex selectors in the list.
+func (l List) Specificity() Specificity {
+	max := Specificity{}
+	for _, c := range l {
+		if s := c.Specificity(); max.Less(s) {
+			max = s
+		}
+	}
+	return max
+}
+
+// Specificity returns the specificity of the complex selector.
+func (c Complex) Specificity() Specificity {
+	spec := Specificity{}
+	for _, compound := range c {
+		spec = spec.add(compound.Specificity())
+	}
+	return spec
+}
+
+// Specificity returns the specificity of the compound selector.
+func (c Compound) Specificity() Specificity {
+	spec := Specificity{}
+	if c.Tag != nil && !(len(c.Tag) == 1 && c.Tag[0] == '*') {
+		spec[2]++
+	}
+	for _, s := range c.Simples {
+		switch s.Type {
+		case IDSelector:
+			spec[0]++
+		case ClassSelector, AttributeSelector:
+			spec[1]++
+		case PseudoElementSelector:
+			spec[2]++
+		case PseudoClassSelector:
+			switch string(s.Name) {
+			case "where":
+			case "is", "not", "has", "matches", "any":
+				spec = spec.add(s.Selectors.Specificity())
+			default:
+				spec[1]++
+				if s.Nth != nil {
+					spec = spec.add(s.Selectors.Specificity())
+				}
+			}
+		}
+	}
+	return spec
+}
@@ -0,0 +1,354 @@
+package css
+
+import (
+	"io"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// Node is a node in the stylesheet tree, ie. *Stylesheet, *AtRule, *Ruleset, *Declaration, or *Comment.
+type Node interface {
+	Offset() int
+	String() string
+}
+
+// Comment is a comment including the /* and */ delimiters.
+type Comment struct {
+	Start int
+	Data  []byte
+}
+
+// Offset returns the start offset of the comment in the input.
+func (n *Comment) Offset() int {
+	return n.Start
+}
+
+func (n *Comment) String() string {
+	return string(n.Data)
+}
+
+func (n *Comment) appendTo(b []byte) []byte {
+	return append(b, n.Data...)
+}
+
+// Declaration is a property declaration in a ruleset or at-rule block. For custom properties Values holds a single CustomPropertyValueToken with the verbatim value.
+type Declaration struct {
+	Start     int
+	Comments  []*Comment // preceding comments
+	Property  []byte     // lowercased for regular properties
+	Values    []Token    // without the trailing !important, including comments
+	Important bool
+	Custom    bool
+}
+
+// Offset returns the start offset of the declaration in the input.
+func (n *Declaration) Offset() int {
+	return n.Start
+}
+
+func (n *Declaration) String() string {
+	return string(n.appendTo(nil))
+}
+
+func (n *Declaration) appendTo(b []byte) []byte {
+	b = append(b, n.Property...)
+	b = append(b, ':')
+	b = appendTokens(b, n.Values)
+	if n.Important {
+		b = append(b, "!important"...)
+	}
+	return b
+}
+
+// Selector is a single selector of a ruleset's comma-separated selector list, including comments. Whitespace tokens denote descendant combinators.
+type Selector struct {
+	Start  int
+	Tokens []Token
+}
+
+func (sel Selector) String() string {
+	return string(appendTokens(nil, sel.Tokens))
+}
+
+// Ruleset is a qualified rule with its selectors and a block of declarations and nested rules.
+type Ruleset struct {
+	Start     int
+	Comments  []*Comment // preceding comments
+	Selectors []Selector
+	Rules     []Node
+}
+
+// Offset returns the start offset of the ruleset in the input.
+func (n *Ruleset) Offset() int {
+	return n.Start
+}
+
+func (n *Ruleset) String() string {
+	return string(n.appendTo(nil))
+}
+
+func (n *Ruleset) appendTo(b []byte) []byte {
+	for i, sel := range n.Selectors {
+		if i != 0 {
+			b = append(b, ',')
+		}
+		b = appendTokens(b, sel.Tokens)
+	}
+	b = append(b, '{')
+	b = appendBlock(b, n.Rules)
+	return append(b, '}')
+}
+
+// AtRule is an at-rule such as @import or @media. The block of known at-rules is parsed into Rules, for unknown at-rules its verbatim tokens are kept in Tokens.
+type AtRule struct {
+	Start    int
+	Comments []*Comment // preceding comments
+	Name     []byte     // lowercased and including the @
+	Prelude  []Token
+	Block    bool // whether the at-rule has a {} block
+	Rules    []Node
+	Tokens   []Token
+}
+
+// Offset returns the start offset of the at-rule in the input.
+func (n *AtRule) Offset() int {
+	return n.Start
+}
+
+func (n *AtRule) String() string {
+	return string(n.appendTo(nil))
+}
+
+func (n *AtRule) appendTo(b []byte) []byte {
+	b = append(b, n.Name...)
+	b = appendTokens(b, n.Prelude)
+	if !n.Block {
+		return append(b, ';')
+	}
+	b = append(b, '{')
+	b = appendBlock(b, n.Rules)
+	b = appendTokens(b, n.Tokens)
+	return append(b, '}')
+}
+
+// Stylesheet is the root of the stylesheet tree.
+type Stylesheet struct {
+	Rules []Node
+}
+
+// Offset returns zero, the start of the stylesheet.
+func (n *Stylesheet) Offset() int {
+	return 0
+}
+
+func (n *Stylesheet) String() string {
+	return string(appendBlock(nil, n.Rules))
+}
+
+// appendTokens appends the data of the tokens to b.
+func appendTokens(b []byte, tokens []Token) []byte {
+	for _, t := range tokens {
+		b = append(b, t.Data...)
+	}
+	return b
+}
+
+// appendBlock appends the serialized rules, including their attached comments, to b.
+func appendBlock(b []byte, rules []Node) []byte {
+	for _, rule := range rules {
+		var comments []*Comment
+		switch n := rule.(type) {
+		case *Declaration:
+			comments = n.Comments
+		case *Ruleset:
+			comments = n.Comments
+		case *AtRule:
+			comments = n.Comments
+		}
+		for _, comment := range comments {
+			b = comment.appendTo(b)
+		}
+		switch n := rule.(type) {
+		case *Comment:
+			b = n.appendTo(b)
+		case *Declaration:
+			b = append(n.appendTo(b), ';')
+		case *Ruleset:
+			b = n.appendTo(b)
+		case *AtRule:
+			b = n.appendTo(b)
+		default:
+			b = append(b, rule.String()...)
+		}
+	}
+	return b
+}
+
+// Walk traverses the tree rooted at n in depth-first order. It calls f for each node, and skips the children of a node when f returns false. Attached comments are not visited.
+func Walk(n Node, f func(Node) bool) {
+	if !f(n) {
+		return
+	}
+	var rules []Node
+	switch n := n.(type) {
+	case *Stylesheet:
+		rules = n.Rules
+	case *AtRule:
+		rules = n.Rules
+	case *Ruleset:
+		rules = n.Rules
+	}
+	for _, rule := range rules {
+		Walk(rule, f)
+	}
+}
+
+////////////////////////////////////////////////////////////////
+
+// ParseStylesheet parses a complete stylesheet into a tree. All byte slices in the tree are copies and remain valid after parsing. Invalid declarations and rules are dropped following the CSS error recovery rules, in which case the first parse error is returned together with the tree.
+func ParseStylesheet(r io.Reader) (*Stylesheet, error) {
+	p := NewParser(r, false)
+	p.keepComments = true
+	sheet := &Stylesheet{}
+	stack := []Node{sheet}
+	var ruleset *Ruleset    // ruleset whose selectors are being parsed
+	var comments []*Comment // comments to attach to the next node
+	var err error
+
+	add := func(node Node) {
+		switch parent := stack[len(stack)-1].(type) {
+		case *Stylesheet:
+			parent.Rules = append(parent.Rules, node)
+		case *AtRule:
+			parent.Rules = append(parent.Rules, node)
+		case *Ruleset:
+			parent.Rules = append(parent.Rules, node)
+		}
+	}
+	flush := func() {
+		for _, comment := range comments {
+			add(comment)
+		}
+		comments = comments[:0]
+	}
+
+	for {
+		gt, _, data := p.Next()
+		for _, comment := range p.comments {
+			comments = append(comments, &Comment{comment.Start, parse.Copy(comment.Data)})
+		}
+		switch gt {
+		case ErrorGrammar:
+			if !p.HasParseError() {
+				if p.Err() != io.EOF {
+					return nil, p.Err()
+				}
+				for 1 < len(stack) {
+					flush()
+					stack = stack[:len(stack)-1]
+				}
+				flush()
+				return sheet, err
+			} else if err == nil {
+				err = p.Err()
+			}
+			ruleset = nil
+		case CommentGrammar:
+			comments = append(comments, &Comment{p.offset, parse.Copy(data)})
+		case AtRuleGrammar, BeginAtRuleGrammar:
+			atRule := &AtRule{
+				Start:    p.offset,
+				Comments: takeComments(&comments),
+				Name:     parse.Copy(data),
+				Prelude:  copyTokens(p.Values()),
+				Block:    gt == BeginAtRuleGrammar,
+			}
+			add(atRule)
+			if atRule.Block {
+				stack = append(stack, atRule)
+			}
+		case EndAtRuleGrammar, EndRulesetGrammar:
+			flush()
+			if 1 < len(stack) {
+				stack = stack[:len(stack)-1]
+			}
+		case QualifiedRuleGrammar, BeginRulesetGrammar:
+			var tokens []Token
+			if ruleset == nil {
+				ruleset = &Ruleset{
+					Start:    p.offset,
+					Comments: takeComments(&comments),
+				}
+			} else {
+				// comments after the comma of the previous selector
+				for _, comment := range takeComments(&comments) {
+					tokens = append(tokens, Token{CommentToken, comment.Data})
+				}
+			}
+			tokens = append(tokens, copyTokens(p.Values())...)
+			ruleset.Selectors = append(ruleset.Selectors, Selector{p.offset, tokens})
+			if gt == BeginRulesetGrammar {
+				add(ruleset)
+				stack = append(stack, ruleset)
+				ruleset = nil
+			}
+		case DeclarationGrammar, CustomPropertyGrammar:
+			decl := &Declaration{
+				Start:    p.offset,
+				Comments: takeComments(&comments),
+				Property: parse.Copy(data),
+				Values:   copyTokens(p.Values()),
+				Custom:   gt == CustomPropertyGrammar,
+			}
+			if !decl.Custom {
+				decl.Values, decl.Important = splitImportant(decl.Values)
+			}
+			add(decl)
+		case TokenGrammar:
+			if atRule, ok := stack[len(stack)-1].(*AtRule); ok {
+				for _, comment := range takeComments(&comments) {
+					atRule.Tokens = append(atRule.Tokens, Token{CommentToken, comment.Data})
+				}
+				atRule.Tokens = append(atRule.Tokens, Token{p.tt, parse.Copy(data)})
+			}
+		}
+	}
+}
+
+func takeComments(comments *[]*Comment) []*Comment {
+	if len(*comments) == 0 {
+		return nil
+	}
+	taken := make([]*Comment, len(*comments))
+	copy(taken, *comments)
+	*comments = (*comments)[:0]
+	return taken
+}
+
+func copyTokens(tokens []Token) []Token {
+	if len(tokens) == 0 {
+		return nil
+	}
+	c := make([]Token, len(tokens))
+	for i, t := range tokens {
+		c[i] = Token{t.TokenType, parse.Copy(t.Data)}
+	}
+	return c
+}
+
+// splitImportant strips a trailing !important from the declaration values, keeping the comments that follow it.
+func splitImportant(values []Token) ([]Token, bool) {
+	n := len(values)
+	for 0 < n && (values[n-1].TokenType == CommentToken || values[n-1].TokenType == WhitespaceToken) {
+		n--
+	}
+	if 2 <= n && values[n-2].TokenType == DelimToken && values[n-2].Data[0] == '!' && values[n-1].TokenType == IdentToken && parse.EqualFold(values[n-1].Data, []byte("important")) {
+		tail := values[n:]
+		values = values[:n-2]
+		for 0 < len(values) && values[len(values)-1].TokenType == WhitespaceToken {
+			values = values[:len(values)-1]
+		}
+		return append(values, tail...), true
+	}
+	return values, false
+}
@@ -0,0 +1,129 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func TestParseStylesheet(t *testing.T) {
+	var stylesheetTests = []struct {
+		css      string
+		expected string
+	}{
+		{"", ""},
+		{"a { color: red; border: 0; } b { padding: 0; }", "a{color:red;border:0;}b{padding:0;}"},
+		{"a, .b > c { x: y }", "a,.b>c{x:y;}"},
+		{"a { color: red ! important; }", "a{color:red!important;}"},
+		{"@import 'x.css';@charset 'utf-8'", "@import 'x.css';@charset 'utf-8';"},
+		{"@media print, screen { a { x: y } @media (min-width: 1px) { b { x: y } } }", "@media print,screen{a{x:y;}@media(min-width:1px){b{x:y;}}}"},
+		{"@font-face { font-family: x; }", "@font-face{font-family:x;}"},
+		{"@unknown abc { {} lala }", "@unknown abc{{} lala }"},
+		{"a { --custom:  (0;)  ; }", "a{--custom:  (0;)  ;}"},
+		{"table { @unknown }", "table{@unknown;}"},
+		{"/*a*/ a { /*b*/ x: y; /*c*/ } /*d*/", "/*a*/a{/*b*/x:y;/*c*/}/*d*/"},
+		{"@unknown { a /*b*/ c }", "@unknown{a /*b*/ c }"},
+		{"/* c1 */ a /* c2 */ { /* c3 */ x: y /* c4 */ }", "/* c1 */a /* c2 */{/* c3 */x:y /* c4 */;}"},
+		{"a /*b*/ > /*c*/ d, /*e*/ f { x: /*g*/ 1px/**/2px !important /*h*/ }", "a /*b*/>/*c*/d,/*e*/f{x:/*g*/1px/**/2px /*h*/!important;}"},
+		{"<!-- a { x: y } -->", "a{x:y;}"},
+		{"a { x: y", "a{x:y;}"},
+		{"@media { a { /*b*/", "@media{a{/*b*/}}"},
+		{".a { color: red; &:hover { color: blue } b c { x: y } @media print { color: green } }", ".a{color:red;&:hover{color:blue;}b c{x:y;}@media print{color:green;}}"},
+	}
+	for _, tt := range stylesheetTests {
+		t.Run(tt.css, func(t *testing.T) {
+			sheet, err := ParseStylesheet(bytes.NewBufferString(tt.css))
+			test.Error(t, err)
+			test.String(t, sheet.String(), tt.expected)
+
+			// round-trip
+			sheet, err = ParseStylesheet(bytes.NewBufferString(sheet.String()))
+			test.Error(t, err)
+			test.String(t, sheet.String(), tt.expected)
+		})
+	}
+}
+
+func TestParseStylesheetError(t *testing.T) {
+	sheet, err := ParseStylesheet(bytes.NewBufferString(".foo { baddecl; color: red } .bar { x: y }"))
+	test.String(t, sheet.String(), ".foo{color:red;}.bar{x:y;}")
+	if perr, ok := err.(*parse.Error); ok {
+		_, col, _ := perr.Position()
+		test.T(t, col, 15)
+	} else {
+		test.Fail(t, "bad error:", err)
+	}
+}
+
+func TestStylesheetTree(t *testing.T) {
+	sheet, err := ParseStylesheet(bytes.NewBufferString("/*x*/\na, b c { color: red !important; --v: 1 }\n@media screen { d { x: y } }"))
+	test.Error(t, err)
+	test.T(t, len(sheet.Rules), 2)
+
+	ruleset := sheet.Rules[0].(*Ruleset)
+	test.T(t, ruleset.Offset(), 6)
+	test.T(t, len(ruleset.Comments), 1)
+	test.String(t, ruleset.Comments[0].String(), "/*x*/")
+	test.T(t, len(ruleset.Selectors), 2)
+	test.String(t, ruleset.Selectors[1].String(), "b c")
+	test.T(t, ruleset.Selectors[1].Start, 9)
+	test.T(t, len(ruleset.Rules), 2)
+
+	decl := ruleset.Rules[0].(*Declaration)
+	test.T(t, decl.Offset(), 15)
+	test.String(t, string(decl.Property), "color")
+	test.T(t, decl.Values, []Token{{IdentToken, []byte("red")}})
+	test.That(t, decl.Important)
+
+	custom := ruleset.Rules[1].(*Declaration)
+	test.That(t, custom.Custom)
+	test.String(t, string(custom.Values[0].Data), " 1 ")
+
+	atRule := sheet.Rules[1].(*AtRule)
+	test.T(t, atRule.Offset(), 47)
+	test.String(t, string(atRule.Name), "@media")
+	test.That(t, atRule.Block)
+
+	// edit and re-serialize
+	decl.Values = []Token{{IdentToken, []byte("blue")}}
+	decl.Important = false
+	ruleset.Rules = ruleset.Rules[:1]
+	atRule.Rules = append(atRule.Rules, &Declaration{Property: []byte("z"), Values: []Token{{NumberToken, []byte("0")}}})
+	test.String(t, sheet.String(), "/*x*/a,b c{color:blue;}@media screen{d{x:y;}z:0;}")
+
+	// walk
+	n := 0
+	Walk(sheet, func(node Node) bool {
+		if _, ok := node.(*Declaration); ok {
+			n++
+		}
+		return true
+	})
+	test.T(t, n, 3)
+
+	n = 0
+	Walk(sheet, func(node Node) bool {
+		n++
+		_, ok := node.(*AtRule)
+		return !ok
+	})
+	test.T(t, n, 4) // stylesheet, ruleset, declaration, at-rule
+}
+
+func ExampleParseStylesheet() {
+	sheet, err := ParseStylesheet(bytes.NewBufferString("a { color: red; } @media print { b { color: black; } }"))
+	if err != nil {
+		panic(err)
+	}
+	Walk(sheet, func(n Node) bool {
+		if decl, ok := n.(*Declaration); ok && string(decl.Property) == "color" {
+			decl.Values = []Token{{IdentToken, []byte("green")}}
+		}
+		return true
+	})
+	fmt.Println(sheet)
+	// Output: a{color:green;}@media print{b{color:green;}}
+}
@@ -0,0 +1,162 @@
+package values
+
+import (
+	"math"
+	"strings"
+)
+
+// Calc is a math function, ie. calc(), min(), max(), or clamp(). Its arguments are expressions of Number, Percentage, Dimension, the Keyword constants e, pi, infinity, -infinity and NaN, nested *Calc, *Function for var() and similar functions, and *Operation.
+type Calc struct {
+	Name string // lowercase
+	Args []Value
+	Type Type // type of the result, where percentages resolve against the other type if mixed
+}
+
+func (v *Calc) String() string {
+	args := make([]string, len(v.Args))
+	for i, arg := range v.Args {
+		args[i] = arg.String()
+	}
+	return v.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Eval evaluates the math function to a number in the canonical unit of its type, ie. px, deg, s, hz, or dppx. Percentages and relative or unknown dimensions are passed to resolve, which returns their value in the canonical unit. Resolve may be nil if these do not occur. It returns false if a value cannot be resolved or if the expression contains var() or a similar function.
+func (v *Calc) Eval(resolve func(Value) (float64, bool)) (float64, bool) {
+	return eval(v, resolve)
+}
+
+// Operation is a binary operation of a math function, where Op is one of + - * /.
+type Operation struct {
+	Op          byte
+	Left, Right Value
+}
+
+func (v *Operation) String() string {
+	left, right := v.Left.String(), v.Right.String()
+	if precedence(v.Left) < precedence(v) {
+		left = "(" + left + ")"
+	}
+	if precedence(v.Right) < precedence(v) || precedence(v.Right) == precedence(v) && (v.Op == '-' || v.Op == '/') {
+		right = "(" + right + ")"
+	}
+	return left + " " + string(v.Op) + " " + right
+}
+
+func precedence(v Value) int {
+	if op, ok := v.(*Operation); ok {
+		if op.Op == '+' || op.Op == '-' {
+			return 1
+		}
+		return 2
+	}
+	return 3
+}
+
+func eval(v Value, resolve func(Value) (float64, bool)) (float64, bool) {
+	switch v := v.(type) {
+	case Number:
+		return float64(v), true
+	case Dimension:
+		if f, ok := v.Canonical(); ok {
+			return f, true
+		} else if resolve != nil {
+			return resolve(v)
+		}
+	case Percentage:
+		if resolve != nil {
+			return resolve(v)
+		}
+	case Keyword:
+		if f, ok := calcConstant(string(v)); ok {
+			return f, true
+		}
+	case *Operation:
+		left, ok := eval(v.Left, resolve)
+		if !ok {
+			return 0, false
+		}
+		right, ok := eval(v.Right, resolve)
+		if !ok {
+			return 0, false
+		}
+		switch v.Op {
+		case '+':
+			return left + right, true
+		case '-':
+			return left - right, true
+		case '*':
+			return left * right, true
+		case '/':
+			return left / right, true
+		}
+	case *Calc:
+		args := make([]float64, len(v.Args))
+		for i, arg := range v.Args {
+			f, ok := eval(arg, resolve)
+			if !ok {
+				return 0, false
+			}
+			args[i] = f
+		}
+		switch v.Name {
+		case "calc":
+			return args[0], true
+		case "min", "max":
+			f := args[0]
+			for _, arg := range args[1:] {
+				if v.Name == "min" {
+					f = math.Min(f, arg)
+				} else {
+					f = math.Max(f, arg)
+				}
+			}
+			return f, true
+		case "clamp":
+			return math.Max(args[0], math.Min(args[1], args[2])), true
+		}
+	}
+	return 0, false
+}
+
+// calcConstant returns the value of the constants of math functions, which are case-insensitive.
+func calcConstant(name string) (float64, bool) {
+	switch strings.ToLower(name) {
+	case "e":
+		return math.E, true
+	case "pi":
+		return math.Pi, true
+	case "infinity":
+		return math.Inf(1), true
+	case "-infinity":
+		return math.Inf(-1), true
+	case "nan":
+		return math.NaN(), true
+	}
+	return 0, false
+}
+
+// addTypes returns the type of the sum of two types, which must be equal except that a percentage may be added to a dimension. UnknownType is compatible with all types.
+func addTypes(a, b Type) (Type, bool) {
+	if a == b || b == UnknownType {
+		return a, true
+	} else if a == UnknownType {
+		return b, true
+	} else if a == PercentageType && b != NumberType {
+		return b, true
+	} else if b == PercentageType && a != NumberType {
+		return a, true
+	}
+	return 0, false
+}
+
+// multiplyTypes returns the type of the product of two types, of which at least one must be a number.
+func multiplyTypes(a, b Type) (Type, bool) {
+	if a == NumberType {
+		return b, true
+	} else if b == NumberType || b == UnknownType {
+		return a, true
+	} else if a == UnknownType {
+		return b, true
+	}
+	return 0, false
+}
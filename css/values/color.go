@@ -0,0 +1,544 @@
+package values
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/parse/v2/css"
+)
+
+// ColorSpace is the color space of a color, which determines the meaning of its channels.
+type ColorSpace uint32
+
+// ColorSpace values.
+const (
+	SRGB         ColorSpace = iota // red, green, and blue in [0,1]
+	HSL                            // hue in degrees, saturation and lightness in [0,1]
+	HWB                            // hue in degrees, whiteness and blackness in [0,1]
+	Lab                            // CIE lightness in [0,100], a and b in about [-125,125]
+	LCH                            // CIE lightness in [0,100], chroma in about [0,150], and hue in degrees
+	OKLab                          // Oklab lightness in [0,1], a and b in about [-0.4,0.4]
+	OKLCH                          // Oklab lightness in [0,1], chroma in about [0,0.4], and hue in degrees
+	SRGBLinear                     // linear-light red, green, and blue in [0,1]
+	DisplayP3                      // red, green, and blue of the Display P3 gamut in [0,1]
+	XYZ                            // CIE XYZ relative to the D65 white point, with Y in [0,1]
+	XYZD50                         // CIE XYZ relative to the D50 white point, with Y in [0,1]
+	CurrentColor                   // the currentcolor keyword, whose value is the color property of the element, it has no channels and is not converted by To
+)
+
+// String returns the string representation of a ColorSpace.
+func (space ColorSpace) String() string {
+	switch space {
+	case SRGB:
+		return "sRGB"
+	case HSL:
+		return "HSL"
+	case HWB:
+		return "HWB"
+	case Lab:
+		return "Lab"
+	case LCH:
+		return "LCH"
+	case OKLab:
+		return "OKLab"
+	case OKLCH:
+		return "OKLCH"
+	case SRGBLinear:
+		return "sRGB-linear"
+	case DisplayP3:
+		return "Display-P3"
+	case XYZ:
+		return "XYZ"
+	case XYZD50:
+		return "XYZ-D50"
+	case CurrentColor:
+		return "CurrentColor"
+	}
+	return "Invalid(" + strconv.Itoa(int(space)) + ")"
+}
+
+// Color is a color with three channels in its color space and an alpha channel in [0,1]. Channels specified as none are zero. Colors converted from wider color spaces may have sRGB channels outside of [0,1].
+type Color struct {
+	Space    ColorSpace
+	Channels [3]float64
+	Alpha    float64
+}
+
+// RGB returns an opaque sRGB color from 8-bit channels.
+func RGB(r, g, b uint8) Color {
+	return Color{SRGB, [3]float64{float64(r) / 255.0, float64(g) / 255.0, float64(b) / 255.0}, 1.0}
+}
+
+// NamedColor returns the color for a named color such as red or transparent, case-insensitively. It returns false for other keywords, including currentcolor and system colors.
+func NamedColor(name string) (Color, bool) {
+	name = strings.ToLower(name)
+	if name == "transparent" {
+		return Color{SRGB, [3]float64{}, 0.0}, true
+	} else if rgb, ok := namedColors[name]; ok {
+		return RGB(uint8(rgb>>16), uint8(rgb>>8), uint8(rgb)), true
+	}
+	return Color{}, false
+}
+
+// To converts the color to the given color space. Conversions between sRGB, HSL, and HWB are direct, other conversions go through CIE XYZ. The currentcolor keyword is returned unchanged.
+func (c Color) To(space ColorSpace) Color {
+	if c.Space == space || c.Space == CurrentColor || space == CurrentColor {
+		return c
+	} else if isRGBSpace(c.Space) && isRGBSpace(space) {
+		return rgbTo(c.toRGB(), space, c.Alpha)
+	}
+
+	var xyz [3]float64 // D65
+	switch c.Space {
+	case SRGB, HSL, HWB:
+		rgb := c.toRGB()
+		xyz = mulMatrix(linearSRGBToXYZ, [3]float64{linearize(rgb[0]), linearize(rgb[1]), linearize(rgb[2])})
+	case Lab, LCH:
+		lab := c.Channels
+		if c.Space == LCH {
+			lab = fromPolar(lab)
+		}
+		xyz = mulMatrix(d50ToD65, labToXYZ(lab))
+	case OKLab, OKLCH:
+		lab := c.Channels
+		if c.Space == OKLCH {
+			lab = fromPolar(lab)
+		}
+		lms := mulMatrix(okLabToLMS, lab)
+		xyz = mulMatrix(lmsToXYZ, [3]float64{lms[0] * lms[0] * lms[0], lms[1] * lms[1] * lms[1], lms[2] * lms[2] * lms[2]})
+	case SRGBLinear:
+		xyz = mulMatrix(linearSRGBToXYZ, c.Channels)
+	case DisplayP3:
+		ch := c.Channels
+		xyz = mulMatrix(linearP3ToXYZ, [3]float64{linearize(ch[0]), linearize(ch[1]), linearize(ch[2])})
+	case XYZ:
+		xyz = c.Channels
+	case XYZD50:
+		xyz = mulMatrix(d50ToD65, c.Channels)
+	}
+
+	var ch [3]float64
+	switch space {
+	case SRGB, HSL, HWB:
+		lin := mulMatrix(xyzToLinearSRGB, xyz)
+		return rgbTo([3]float64{delinearize(lin[0]), delinearize(lin[1]), delinearize(lin[2])}, space, c.Alpha)
+	case Lab, LCH:
+		ch = xyzToLab(mulMatrix(d65ToD50, xyz))
+		if space == LCH {
+			ch = toPolar(ch)
+		}
+	case OKLab, OKLCH:
+		lms := mulMatrix(xyzToLMS, xyz)
+		ch = mulMatrix(lmsToOKLab, [3]float64{math.Cbrt(lms[0]), math.Cbrt(lms[1]), math.Cbrt(lms[2])})
+		if space == OKLCH {
+			ch = toPolar(ch)
+		}
+	case SRGBLinear:
+		ch = mulMatrix(xyzToLinearSRGB, xyz)
+	case DisplayP3:
+		lin := mulMatrix(xyzToLinearP3, xyz)
+		ch = [3]float64{delinearize(lin[0]), delinearize(lin[1]), delinearize(lin[2])}
+	case XYZ:
+		ch = xyz
+	case XYZD50:
+		ch = mulMatrix(d65ToD50, xyz)
+	}
+	return Color{space, ch, c.Alpha}
+}
+
+// RGBA returns the alpha-premultiplied red, green, blue and alpha values in [0,0xffff] of the color converted to sRGB and clipped to its gamut, which implements the color.Color interface of the image/color package. The currentcolor keyword is black.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	rgb := c.To(SRGB).Channels
+	alpha := clip(c.Alpha)
+	r = uint32(math.Round(clip(rgb[0]) * alpha * 0xffff))
+	g = uint32(math.Round(clip(rgb[1]) * alpha * 0xffff))
+	b = uint32(math.Round(clip(rgb[2]) * alpha * 0xffff))
+	a = uint32(math.Round(alpha * 0xffff))
+	return
+}
+
+// String returns the color using the function of its color space, such as rgb(255 0 0), oklch(0.5 0.1 120 / 0.5), or color(display-p3 1 0 0).
+func (c Color) String() string {
+	ch := c.Channels
+	var s string
+	switch c.Space {
+	case CurrentColor:
+		return "currentcolor"
+	case SRGB:
+		s = "rgb(" + formatChannel(ch[0]*255.0) + " " + formatChannel(ch[1]*255.0) + " " + formatChannel(ch[2]*255.0)
+	case HSL, HWB:
+		s = strings.ToLower(c.Space.String()) + "(" + formatChannel(ch[0]) + " " + formatChannel(ch[1]*100.0) + "% " + formatChannel(ch[2]*100.0) + "%"
+	case Lab, LCH, OKLab, OKLCH:
+		s = strings.ToLower(c.Space.String()) + "(" + formatChannel(ch[0]) + " " + formatChannel(ch[1]) + " " + formatChannel(ch[2])
+	case SRGBLinear, DisplayP3, XYZ, XYZD50:
+		s = "color(" + strings.ToLower(c.Space.String()) + " " + formatChannel(ch[0]) + " " + formatChannel(ch[1]) + " " + formatChannel(ch[2])
+	default:
+		return "Invalid(" + strconv.Itoa(int(c.Space)) + ")"
+	}
+	if c.Alpha != 1.0 {
+		s += " / " + formatChannel(c.Alpha)
+	}
+	return s + ")"
+}
+
+// formatChannel formats a channel value with at most six decimals, which hides rounding errors of conversions.
+func formatChannel(f float64) string {
+	f = math.Round(f*1e6) / 1e6
+	if f == 0.0 {
+		f = 0.0 // no negative zero
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+////////////////////////////////////////////////////////////////
+
+var (
+	linearSRGBToXYZ = [3][3]float64{
+		{0.41239079926595934, 0.357584339383878, 0.1804807884018343},
+		{0.21263900587151027, 0.715168678767756, 0.07219231536073371},
+		{0.01933081871559182, 0.11919477979462598, 0.9505321522496607},
+	}
+	xyzToLinearSRGB = [3][3]float64{
+		{3.2409699419045226, -1.537383177570094, -0.4986107602930034},
+		{-0.9692436362808796, 1.8759675015077202, 0.04155505740717559},
+		{0.05563007969699366, -0.20397695888897652, 1.0569715142428786},
+	}
+
+	linearP3ToXYZ = [3][3]float64{
+		{0.48657094864821626, 0.26566769316909294, 0.1982172852343625},
+		{0.22897456406974884, 0.6917385218365062, 0.079286914093745},
+		{0.0, 0.045113381858902575, 1.0439443689009757},
+	}
+	xyzToLinearP3 = [3][3]float64{
+		{2.4934969119414245, -0.9313836179191236, -0.40271078445071684},
+		{-0.829488969561575, 1.7626640603183468, 0.02362468584194359},
+		{0.035845830243784335, -0.07617238926804171, 0.9568845240076873},
+	}
+
+	// Bradford chromatic adaptation between the D65 and D50 white points
+	d65ToD50 = [3][3]float64{
+		{1.0479297925449969, 0.022946870601609652, -0.05019226628920524},
+		{0.02962780877005599, 0.9904344267538799, -0.017073799063418826},
+		{-0.009243040646204504, 0.015055191490298152, 0.7518742814281371},
+	}
+	d50ToD65 = [3][3]float64{
+		{0.955473421488075, -0.02309845494876471, 0.06325924320057072},
+		{-0.0283697093338637, 1.0099953980813041, 0.021041441191917323},
+		{0.012314014864481998, -0.020507649298898964, 1.330365926242124},
+	}
+	d50White = [3]float64{0.3457 / 0.3585, 1.0, (1.0 - 0.3457 - 0.3585) / 0.3585}
+
+	xyzToLMS = [3][3]float64{
+		{0.8190224379967030, 0.3619062600528904, -0.1288737815209879},
+		{0.0329836539323885, 0.9292868615863434, 0.0361446663506424},
+		{0.0481771893596242, 0.2642395317527308, 0.6335478284694309},
+	}
+	lmsToXYZ = [3][3]float64{
+		{1.2268798758459243, -0.5578149944602171, 0.2813910456659647},
+		{-0.0405757452148008, 1.1122868032803170, -0.0717110580655164},
+		{-0.0763729366746601, -0.4214933324022432, 1.5869240198367816},
+	}
+	lmsToOKLab = [3][3]float64{
+		{0.2104542683093140, 0.7936177747023054, -0.0040720430116193},
+		{1.9779985324311684, -2.4285922420485799, 0.4505937096174110},
+		{0.0259040424655478, 0.7827717124575296, -0.8086757549230774},
+	}
+	okLabToLMS = [3][3]float64{
+		{1.0, 0.3963377773761749, 0.2158037573099136},
+		{1.0, -0.1055613458156586, -0.0638541728258133},
+		{1.0, -0.0894841775298119, -1.2914855480194092},
+	}
+)
+
+const (
+	labEpsilon = 216.0 / 24389.0
+	labKappa   = 24389.0 / 27.0
+)
+
+func isRGBSpace(space ColorSpace) bool {
+	return space == SRGB || space == HSL || space == HWB
+}
+
+// toRGB returns the sRGB channels of a color in the SRGB, HSL or HWB color space.
+func (c Color) toRGB() [3]float64 {
+	ch := c.Channels
+	switch c.Space {
+	case HSL:
+		r, g, b := css.HSL2RGB(normalizeHue(ch[0])/360.0, ch[1], ch[2])
+		return [3]float64{r, g, b}
+	case HWB:
+		w, b := ch[1], ch[2]
+		if 1.0 <= w+b {
+			gray := w / (w + b)
+			return [3]float64{gray, gray, gray}
+		}
+		r, g, b2 := css.HSL2RGB(normalizeHue(ch[0])/360.0, 1.0, 0.5)
+		return [3]float64{r*(1.0-w-b) + w, g*(1.0-w-b) + w, b2*(1.0-w-b) + w}
+	}
+	return ch
+}
+
+// rgbTo converts sRGB channels to a color in the SRGB, HSL or HWB color space.
+func rgbTo(rgb [3]float64, space ColorSpace, alpha float64) Color {
+	if space == SRGB {
+		return Color{SRGB, rgb, alpha}
+	}
+
+	r, g, b := rgb[0], rgb[1], rgb[2]
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	hue := 0.0
+	if d := max - min; d != 0.0 {
+		switch max {
+		case r:
+			hue = (g - b) / d
+		case g:
+			hue = (b-r)/d + 2.0
+		default:
+			hue = (r-g)/d + 4.0
+		}
+		hue = normalizeHue(hue * 60.0)
+	}
+	if space == HWB {
+		return Color{HWB, [3]float64{hue, min, 1.0 - max}, alpha}
+	}
+
+	l := (max + min) / 2.0
+	s := 0.0
+	if l != 0.0 && l != 1.0 {
+		s = (max - l) / math.Min(l, 1.0-l)
+	}
+	return Color{HSL, [3]float64{hue, s, l}, alpha}
+}
+
+func linearize(c float64) float64 {
+	if abs := math.Abs(c); 0.04045 < abs {
+		return math.Copysign(math.Pow((abs+0.055)/1.055, 2.4), c)
+	}
+	return c / 12.92
+}
+
+func delinearize(c float64) float64 {
+	if abs := math.Abs(c); 0.0031308 < abs {
+		return math.Copysign(1.055*math.Pow(abs, 1.0/2.4)-0.055, c)
+	}
+	return c * 12.92
+}
+
+func labToXYZ(lab [3]float64) [3]float64 {
+	f1 := (lab[0] + 16.0) / 116.0
+	f0 := lab[1]/500.0 + f1
+	f2 := f1 - lab[2]/200.0
+
+	x := f0 * f0 * f0
+	if x <= labEpsilon {
+		x = (116.0*f0 - 16.0) / labKappa
+	}
+	y := f1 * f1 * f1
+	if lab[0] <= labKappa*labEpsilon {
+		y = lab[0] / labKappa
+	}
+	z := f2 * f2 * f2
+	if z <= labEpsilon {
+		z = (116.0*f2 - 16.0) / labKappa
+	}
+	return [3]float64{x * d50White[0], y * d50White[1], z * d50White[2]}
+}
+
+func xyzToLab(xyz [3]float64) [3]float64 {
+	var f [3]float64
+	for i := range xyz {
+		if v := xyz[i] / d50White[i]; labEpsilon < v {
+			f[i] = math.Cbrt(v)
+		} else {
+			f[i] = (labKappa*v + 16.0) / 116.0
+		}
+	}
+	return [3]float64{116.0*f[1] - 16.0, 500.0 * (f[0] - f[1]), 200.0 * (f[1] - f[2])}
+}
+
+// toPolar converts lightness, a and b to lightness, chroma and hue.
+func toPolar(lab [3]float64) [3]float64 {
+	return [3]float64{lab[0], math.Hypot(lab[1], lab[2]), normalizeHue(math.Atan2(lab[2], lab[1]) * 180.0 / math.Pi)}
+}
+
+// fromPolar converts lightness, chroma and hue to lightness, a and b.
+func fromPolar(lch [3]float64) [3]float64 {
+	sin, cos := math.Sincos(lch[2] * math.Pi / 180.0)
+	return [3]float64{lch[0], lch[1] * cos, lch[1] * sin}
+}
+
+func mulMatrix(m [3][3]float64, v [3]float64) [3]float64 {
+	return [3]float64{
+		m[0][0]*v[0] + m[0][1]*v[1] + m[0][2]*v[2],
+		m[1][0]*v[0] + m[1][1]*v[1] + m[1][2]*v[2],
+		m[2][0]*v[0] + m[2][1]*v[1] + m[2][2]*v[2],
+	}
+}
+
+// normalizeHue returns the hue in degrees in [0,360).
+func normalizeHue(h float64) float64 {
+	h = math.Mod(h, 360.0)
+	if h < 0.0 {
+		h += 360.0
+	}
+	return h
+}
+
+func clip(f float64) float64 {
+	return math.Max(0.0, math.Min(1.0, f))
+}
+
+////////////////////////////////////////////////////////////////
+
+var namedColors = map[string]uint32{
+	"aliceblue":            0xf0f8ff,
+	"antiquewhite":         0xfaebd7,
+	"aqua":                 0x00ffff,
+	"aquamarine":           0x7fffd4,
+	"azure":                0xf0ffff,
+	"beige":                0xf5f5dc,
+	"bisque":               0xffe4c4,
+	"black":                0x000000,
+	"blanchedalmond":       0xffebcd,
+	"blue":                 0x0000ff,
+	"blueviolet":           0x8a2be2,
+	"brown":                0xa52a2a,
+	"burlywood":            0xdeb887,
+	"cadetblue":            0x5f9ea0,
+	"chartreuse":           0x7fff00,
+	"chocolate":            0xd2691e,
+	"coral":                0xff7f50,
+	"cornflowerblue":       0x6495ed,
+	"cornsilk":             0xfff8dc,
+	"crimson":              0xdc143c,
+	"cyan":                 0x00ffff,
+	"darkblue":             0x00008b,
+	"darkcyan":             0x008b8b,
+	"darkgoldenrod":        0xb8860b,
+	"darkgray":             0xa9a9a9,
+	"darkgreen":            0x006400,
+	"darkgrey":             0xa9a9a9,
+	"darkkhaki":            0xbdb76b,
+	"darkmagenta":          0x8b008b,
+	"darkolivegreen":       0x556b2f,
+	"darkorange":           0xff8c00,
+	"darkorchid":           0x9932cc,
+	"darkred":              0x8b0000,
+	"darksalmon":           0xe9967a,
+	"darkseagreen":         0x8fbc8f,
+	"darkslateblue":        0x483d8b,
+	"darkslategray":        0x2f4f4f,
+	"darkslategrey":        0x2f4f4f,
+	"darkturquoise":        0x00ced1,
+	"darkviolet":           0x9400d3,
+	"deeppink":             0xff1493,
+	"deepskyblue":          0x00bfff,
+	"dimgray":              0x696969,
+	"dimgrey":              0x696969,
+	"dodgerblue":           0x1e90ff,
+	"firebrick":            0xb22222,
+	"floralwhite":          0xfffaf0,
+	"forestgreen":          0x228b22,
+	"fuchsia":              0xff00ff,
+	"gainsboro":            0xdcdcdc,
+	"ghostwhite":           0xf8f8ff,
+	"gold":                 0xffd700,
+	"goldenrod":            0xdaa520,
+	"gray":                 0x808080,
+	"green":                0x008000,
+	"greenyellow":          0xadff2f,
+	"grey":                 0x808080,
+	"honeydew":             0xf0fff0,
+	"hotpink":              0xff69b4,
+	"indianred":            0xcd5c5c,
+	"indigo":               0x4b0082,
+	"ivory":                0xfffff0,
+	"khaki":                0xf0e68c,
+	"lavender":             0xe6e6fa,
+	"lavenderblush":        0xfff0f5,
+	"lawngreen":            0x7cfc00,
+	"lemonchiffon":         0xfffacd,
+	"lightblue":            0xadd8e6,
+	"lightcoral":           0xf08080,
+	"lightcyan":            0xe0ffff,
+	"lightgoldenrodyellow": 0xfafad2,
+	"lightgray":            0xd3d3d3,
+	"lightgreen":           0x90ee90,
+	"lightgrey":            0xd3d3d3,
+	"lightpink":            0xffb6c1,
+	"lightsalmon":          0xffa07a,
+	"lightseagreen":        0x20b2aa,
+	"lightskyblue":         0x87cefa,
+	"lightslategray":       0x778899,
+	"lightslategrey":       0x778899,
+	"lightsteelblue":       0xb0c4de,
+	"lightyellow":          0xffffe0,
+	"lime":                 0x00ff00,
+	"limegreen":            0x32cd32,
+	"linen":                0xfaf0e6,
+	"magenta":              0xff00ff,
+	"maroon":               0x800000,
+	"mediumaquamarine":     0x66cdaa,
+	"mediumblue":           0x0000cd,
+	"mediumorchid":         0xba55d3,
+	"mediumpurple":         0x9370db,
+	"mediumseagreen":       0x3cb371,
+	"mediumslateblue":      0x7b68ee,
+	"mediumspringgreen":    0x00fa9a,
+	"mediumturquoise":      0x48d1cc,
+	"mediumvioletred":      0xc71585,
+	"midnightblue":         0x191970,
+	"mintcream":            0xf5fffa,
+	"mistyrose":            0xffe4e1,
+	"moccasin":             0xffe4b5,
+	"navajowhite":          0xffdead,
+	"navy":                 0x000080,
+	"oldlace":              0xfdf5e6,
+	"olive":                0x808000,
+	"olivedrab":            0x6b8e23,
+	"orange":               0xffa500,
+	"orangered":            0xff4500,
+	"orchid":               0xda70d6,
+	"palegoldenrod":        0xeee8aa,
+	"palegreen":            0x98fb98,
+	"paleturquoise":        0xafeeee,
+	"palevioletred":        0xdb7093,
+	"papayawhip":           0xffefd5,
+	"peachpuff":            0xffdab9,
+	"peru":                 0xcd853f,
+	"pink":                 0xffc0cb,
+	"plum":                 0xdda0dd,
+	"powderblue":           0xb0e0e6,
+	"purple":               0x800080,
+	"rebeccapurple":        0x663399,
+	"red":                  0xff0000,
+	"rosybrown":            0xbc8f8f,
+	"royalblue":            0x4169e1,
+	"saddlebrown":          0x8b4513,
+	"salmon":               0xfa8072,
+	"sandybrown":           0xf4a460,
+	"seagreen":             0x2e8b57,
+	"seashell":             0xfff5ee,
+	"sienna":               0xa0522d,
+	"silver":               0xc0c0c0,
+	"skyblue":              0x87ceeb,
+	"slateblue":            0x6a5acd,
+	"slategray":            0x708090,
+	"slategrey":            0x708090,
+	"snow":                 0xfffafa,
+	"springgreen":          0x00ff7f,
+	"steelblue":            0x4682b4,
+	"tan":                  0xd2b48c,
+	"teal":                 0x008080,
+	"thistle":              0xd8bfd8,
+	"tomato":               0xff6347,
+	"turquoise":            0x40e0d0,
+	"violet":               0xee82ee,
+	"wheat":                0xf5deb3,
+	"white":                0xffffff,
+	"whitesmoke":           0xf5f5f5,
+	"yellow":               0xffff00,
+	"yellowgreen":          0x9acd32,
+}
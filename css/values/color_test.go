@@ -0,0 +1,141 @@
+package values
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestColorConvert(t *testing.T) {
+	var convertTests = []struct {
+		c         Color
+		space     ColorSpace
+		expected  [3]float64
+		tolerance float64
+	}{
+		{RGB(255, 0, 0), HSL, [3]float64{0, 1, 0.5}, 1e-9},
+		{RGB(0, 255, 0), HWB, [3]float64{120, 0, 0}, 1e-9},
+		{RGB(0x66, 0x33, 0x99), HSL, [3]float64{270, 0.5, 0.4}, 1e-9},
+		{Color{HSL, [3]float64{-120, 1, 0.5}, 1}, SRGB, [3]float64{0, 0, 1}, 1e-9},
+		{Color{HWB, [3]float64{0, 0.6, 0.6}, 1}, SRGB, [3]float64{0.5, 0.5, 0.5}, 1e-9},
+		{Color{HWB, [3]float64{240, 0.2, 0.4}, 1}, HSL, [3]float64{240, 0.5, 0.4}, 1e-9},
+		{RGB(255, 255, 255), Lab, [3]float64{100, 0, 0}, 1e-4},
+		{RGB(255, 0, 0), Lab, [3]float64{54.29, 80.8, 69.89}, 0.05},
+		{RGB(255, 0, 0), LCH, [3]float64{54.29, 106.84, 40.85}, 0.05},
+		{RGB(255, 255, 255), OKLab, [3]float64{1, 0, 0}, 1e-4},
+		{RGB(255, 0, 0), OKLab, [3]float64{0.62796, 0.22486, 0.12585}, 1e-4},
+		{RGB(255, 0, 0), OKLCH, [3]float64{0.62796, 0.25768, 29.2339}, 1e-3},
+		{Color{Lab, [3]float64{100, 0, 0}, 1}, OKLab, [3]float64{1, 0, 0}, 1e-4},
+		{Color{OKLCH, [3]float64{0.62796, 0.25768, 29.2339}, 1}, SRGB, [3]float64{1, 0, 0}, 1e-4},
+		{Color{LCH, [3]float64{50, 10, 90}, 1}, Lab, [3]float64{50, 0, 10}, 1e-9},
+		{Color{OKLab, [3]float64{0.5, -0.1, 0}, 1}, OKLCH, [3]float64{0.5, 0.1, 180}, 1e-9},
+		{RGB(255, 0, 0), DisplayP3, [3]float64{0.9175, 0.2003, 0.1386}, 1e-4},
+		{Color{DisplayP3, [3]float64{1, 0, 0}, 1}, SRGB, [3]float64{1.0931, -0.2267, -0.1501}, 1e-4},
+		{Color{SRGB, [3]float64{0.5, 0.5, 0.5}, 1}, SRGBLinear, [3]float64{0.21404, 0.21404, 0.21404}, 1e-5},
+		{RGB(255, 255, 255), XYZ, [3]float64{0.95046, 1, 1.08906}, 1e-4},
+		{RGB(255, 255, 255), XYZD50, [3]float64{0.9643, 1, 0.8251}, 1e-4},
+		{Color{XYZ, [3]float64{0.95046, 1, 1.08906}, 1}, SRGB, [3]float64{1, 1, 1}, 1e-4},
+		{Color{CurrentColor, [3]float64{}, 1}, CurrentColor, [3]float64{}, 0},
+	}
+	for _, tt := range convertTests {
+		t.Run(tt.c.String()+" to "+tt.space.String(), func(t *testing.T) {
+			c := tt.c.To(tt.space)
+			test.T(t, c.Space, tt.space)
+			for i := range tt.expected {
+				test.That(t, math.Abs(c.Channels[i]-tt.expected[i]) <= tt.tolerance, c, "!=", tt.expected)
+			}
+			test.T(t, c.Alpha, tt.c.Alpha)
+		})
+	}
+
+	// round trips
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 1000; i++ {
+		c := Color{SRGB, [3]float64{r.Float64(), r.Float64(), r.Float64()}, r.Float64()}
+		for space := SRGB; space <= XYZD50; space++ {
+			c2 := c.To(space).To(SRGB)
+			for j := range c.Channels {
+				test.That(t, math.Abs(c.Channels[j]-c2.Channels[j]) < 1e-9, c, "to", space, "and back gives", c2)
+			}
+			test.T(t, c2.Alpha, c.Alpha)
+		}
+	}
+}
+
+func TestColorRGBA(t *testing.T) {
+	var _ color.Color = Color{}
+
+	r, g, b, a := RGB(255, 128, 0).RGBA()
+	test.T(t, [4]uint32{r, g, b, a}, [4]uint32{0xffff, 0x8080, 0, 0xffff})
+	r, g, b, a = Color{SRGB, [3]float64{1, 0, 0}, 0.5}.RGBA()
+	test.T(t, [4]uint32{r, g, b, a}, [4]uint32{0x8000, 0, 0, 0x8000})
+
+	// out of the sRGB gamut
+	r, g, b, a = Color{SRGB, [3]float64{2, -1, 0.5}, 1}.RGBA()
+	test.T(t, [4]uint32{r, g, b, a}, [4]uint32{0xffff, 0, 0x8000, 0xffff})
+	c := color.NRGBAModel.Convert(Color{SRGB, [3]float64{2, -1, 0.5}, 1.5}).(color.NRGBA)
+	test.T(t, c, color.NRGBA{255, 0, 128, 255})
+
+	// currentcolor is not converted
+	current := Color{CurrentColor, [3]float64{}, 1}
+	test.T(t, current.To(SRGB), current)
+	test.T(t, RGB(255, 0, 0).To(CurrentColor), RGB(255, 0, 0))
+	r, g, b, a = current.RGBA()
+	test.T(t, [4]uint32{r, g, b, a}, [4]uint32{0, 0, 0, 0xffff})
+}
+
+func TestColorString(t *testing.T) {
+	var stringTests = []struct {
+		c        Color
+		expected string
+	}{
+		{RGB(255, 0, 0), "rgb(255 0 0)"},
+		{Color{SRGB, [3]float64{0.5, -0.000000001, 1}, 0.25}, "rgb(127.5 0 255 / 0.25)"},
+		{Color{HSL, [3]float64{120, 1, 0.5}, 1}, "hsl(120 100% 50%)"},
+		{Color{HWB, [3]float64{120, 0.1, 0.2}, 0}, "hwb(120 10% 20% / 0)"},
+		{Color{Lab, [3]float64{50, 40, -20}, 1}, "lab(50 40 -20)"},
+		{Color{LCH, [3]float64{50, 40, 20}, 1}, "lch(50 40 20)"},
+		{Color{OKLab, [3]float64{0.5, 0.1, -0.1}, 1}, "oklab(0.5 0.1 -0.1)"},
+		{Color{OKLCH, [3]float64{0.5, 0.1, 300}, 0.5}, "oklch(0.5 0.1 300 / 0.5)"},
+		{Color{SRGBLinear, [3]float64{0.5, 0, 1.5}, 1}, "color(srgb-linear 0.5 0 1.5)"},
+		{Color{DisplayP3, [3]float64{1, 0, 0}, 0.5}, "color(display-p3 1 0 0 / 0.5)"},
+		{Color{XYZ, [3]float64{0.25, 0.5, 0.75}, 1}, "color(xyz 0.25 0.5 0.75)"},
+		{Color{XYZD50, [3]float64{0, 1, 0}, 1}, "color(xyz-d50 0 1 0)"},
+		{Color{CurrentColor, [3]float64{}, 1}, "currentcolor"},
+		{Color{ColorSpace(100), [3]float64{}, 1}, "Invalid(100)"},
+	}
+	for _, tt := range stringTests {
+		t.Run(tt.expected, func(t *testing.T) {
+			test.String(t, tt.c.String(), tt.expected)
+		})
+	}
+	test.String(t, OKLCH.String(), "OKLCH")
+	test.String(t, ColorSpace(100).String(), "Invalid(100)")
+}
+
+func TestNamedColor(t *testing.T) {
+	test.T(t, len(namedColors), 148)
+	c, ok := NamedColor("CornflowerBlue")
+	test.That(t, ok)
+	test.T(t, c, RGB(0x64, 0x95, 0xed))
+	_, ok = NamedColor("currentcolor")
+	test.That(t, !ok)
+}
+
+////////////////////////////////////////////////////////////////
+
+func ExampleColor_To() {
+	c, err := ParseColorString("#663399")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(c.To(HSL))
+	fmt.Println(c.To(OKLCH))
+	// Output:
+	// hsl(270 50% 40%)
+	// oklch(0.440272 0.160296 303.372988)
+}
@@ -0,0 +1,678 @@
+package values
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/css"
+)
+
+type parser struct {
+	tokens []css.Token
+	pos    int
+	err    error
+}
+
+// Parse parses the component values of a declaration, such as those returned by css.Parser.Values or in css.Declaration.Values. Whitespace and comments are skipped, commas and slashes are returned as Delim. The value of a custom property is tokenized and parsed as well.
+func Parse(tokens []css.Token) ([]Value, error) {
+	if len(tokens) == 1 && tokens[0].TokenType == css.CustomPropertyValueToken {
+		return ParseString(string(tokens[0].Data))
+	}
+	p := &parser{tokens: tokens}
+	vals := p.parseValues(css.ErrorToken)
+	if p.err != nil {
+		return nil, p.err
+	}
+	return vals, nil
+}
+
+// ParseString parses the component values of a declaration from a string, such as the value of a style attribute's property.
+func ParseString(s string) ([]Value, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(tokens)
+}
+
+// ParseColor parses a single color, which may be a named color, currentcolor, a hex color, or a color function. Colors that cannot be computed, such as colors with var(), are not accepted.
+func ParseColor(tokens []css.Token) (Color, error) {
+	p := &parser{tokens: tokens}
+	if p.peek().TokenType == css.IdentToken {
+		name := unescape(p.next().Data)
+		if c, ok := NamedColor(name); ok {
+			p.expectEnd()
+			return c, p.err
+		} else if strings.EqualFold(name, "currentcolor") {
+			p.expectEnd()
+			return Color{Space: CurrentColor, Alpha: 1.0}, p.err
+		}
+		p.pos--
+	}
+	val := p.parseValue()
+	c, ok := val.(Color)
+	if p.err == nil && !ok {
+		p.pos = 0
+		p.fail("expected color")
+	}
+	p.expectEnd()
+	return c, p.err
+}
+
+// ParseColorString parses a single color from a string.
+func ParseColorString(s string) (Color, error) {
+	tokens, err := tokenize(s)
+	if err != nil {
+		return Color{}, err
+	}
+	return ParseColor(tokens)
+}
+
+func tokenize(s string) ([]css.Token, error) {
+	l := css.NewLexer(bytes.NewBufferString(s))
+	tokens := []css.Token{}
+	for {
+		tt, data := l.Next()
+		if tt == css.ErrorToken {
+			if l.Err() != io.EOF {
+				return nil, l.Err()
+			}
+			break
+		}
+		tokens = append(tokens, css.Token{TokenType: tt, Data: data})
+	}
+	return tokens, nil
+}
+
+// fail sets the error at the current token if no error has been set before, the input is reconstructed from the tokens.
+func (p *parser) fail(message string, a ...interface{}) {
+	if p.err != nil {
+		return
+	}
+	p.skipWhitespace()
+	input := []byte{}
+	offset := 0
+	for i, t := range p.tokens {
+		if i == p.pos {
+			offset = len(input)
+		}
+		input = append(input, t.Data...)
+	}
+	if len(p.tokens) <= p.pos {
+		offset = len(input)
+	}
+	p.err = parse.NewError(bytes.NewBuffer(input), offset, "CSS value parse error: "+message, a...)
+}
+
+func (p *parser) describe() string {
+	t := p.peek()
+	if t.TokenType == css.ErrorToken {
+		return "end of value"
+	}
+	return "'" + string(t.Data) + "'"
+}
+
+func (p *parser) skipWhitespace() {
+	for p.pos < len(p.tokens) && (p.tokens[p.pos].TokenType == css.WhitespaceToken || p.tokens[p.pos].TokenType == css.CommentToken) {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() css.Token {
+	p.skipWhitespace()
+	if len(p.tokens) <= p.pos {
+		return css.Token{TokenType: css.ErrorToken}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() css.Token {
+	t := p.peek()
+	if t.TokenType != css.ErrorToken {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expectEnd() {
+	if p.err == nil && p.peek().TokenType != css.ErrorToken {
+		p.fail("unexpected %s", p.describe())
+	}
+}
+
+// close consumes the closing parenthesis of a function or block.
+func (p *parser) close(end css.TokenType) {
+	if p.peek().TokenType != end {
+		if end == css.RightParenthesisToken {
+			p.fail("expected ')' instead of %s", p.describe())
+		} else {
+			p.fail("expected ']' instead of %s", p.describe())
+		}
+		return
+	}
+	p.next()
+}
+
+func isDelim(t css.Token, c byte) bool {
+	return t.TokenType == css.DelimToken && len(t.Data) == 1 && t.Data[0] == c
+}
+
+////////////////////////////////////////////////////////////////
+
+// parseValues parses component values until the end token, which is not consumed.
+func (p *parser) parseValues(end css.TokenType) []Value {
+	vals := []Value{}
+	for p.err == nil {
+		if tt := p.peek().TokenType; tt == end || tt == css.ErrorToken {
+			break
+		}
+		vals = append(vals, p.parseValue())
+	}
+	return vals
+}
+
+func (p *parser) parseValue() Value {
+	t := p.next()
+	switch t.TokenType {
+	case css.NumberToken:
+		return Number(parseNumber(t.Data))
+	case css.PercentageToken:
+		return Percentage(parseNumber(t.Data[:len(t.Data)-1]))
+	case css.DimensionToken:
+		n := parse.Number(t.Data)
+		return Dimension{parseNumber(t.Data[:n]), strings.ToLower(unescape(t.Data[n:]))}
+	case css.IdentToken, css.CustomPropertyNameToken:
+		return Keyword(unescape(t.Data))
+	case css.StringToken:
+		return String(unescapeString(t.Data))
+	case css.URLToken:
+		return URL(unescapeURL(t.Data))
+	case css.HashToken:
+		if c, ok := parseHex(t.Data[1:]); ok {
+			return c
+		}
+		p.pos--
+		p.fail("invalid hex color %s", p.describe())
+	case css.CommaToken:
+		return Delim(',')
+	case css.DelimToken:
+		if isDelim(t, '/') {
+			return Delim('/')
+		}
+	case css.LeftBracketToken:
+		vals := p.parseValues(css.RightBracketToken)
+		p.close(css.RightBracketToken)
+		return Brackets(vals)
+	case css.FunctionToken:
+		name := strings.ToLower(unescape(t.Data[:len(t.Data)-1]))
+		switch name {
+		case "calc", "min", "max", "clamp":
+			return p.parseCalc(name)
+		case "src":
+			if s := p.next(); s.TokenType == css.StringToken {
+				p.close(css.RightParenthesisToken)
+				return URL(unescapeString(s.Data))
+			}
+			p.pos--
+			p.fail("expected string instead of %s", p.describe())
+			return nil
+		}
+		start := p.pos
+		args := p.parseValues(css.RightParenthesisToken)
+		p.close(css.RightParenthesisToken)
+		if _, ok := colorFunctions[name]; ok || name == "color" {
+			if c, ok := p.parseColor(name, args, start); ok {
+				return c
+			}
+		}
+		return &Function{name, args}
+	}
+	if p.err == nil {
+		if t.TokenType != css.ErrorToken {
+			p.pos--
+		}
+		p.fail("unexpected %s", p.describe())
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////
+
+// parseCalc parses the arguments of a math function after its name.
+func (p *parser) parseCalc(name string) Value {
+	calc := &Calc{Name: name}
+	for {
+		arg, typ := p.parseSum()
+		if p.err != nil {
+			return nil
+		}
+		if len(calc.Args) == 0 {
+			calc.Type = typ
+		} else if t, ok := addTypes(calc.Type, typ); ok {
+			calc.Type = t
+		} else {
+			p.fail("incompatible types %s and %s in %s()", calc.Type, typ, name)
+			return nil
+		}
+		calc.Args = append(calc.Args, arg)
+
+		if name == "calc" || p.peek().TokenType != css.CommaToken {
+			break
+		}
+		p.next()
+	}
+	if name == "clamp" && len(calc.Args) != 3 {
+		p.fail("expected three arguments in clamp()")
+		return nil
+	}
+	p.close(css.RightParenthesisToken)
+	return calc
+}
+
+func (p *parser) parseSum() (Value, Type) {
+	left, typ := p.parseProduct()
+	for p.err == nil {
+		t := p.peek()
+		if !isDelim(t, '+') && !isDelim(t, '-') {
+			break
+		}
+		pos := p.pos
+		p.next()
+		right, rightTyp := p.parseProduct()
+		if p.err != nil {
+			break
+		}
+		sumTyp, ok := addTypes(typ, rightTyp)
+		if !ok {
+			p.pos = pos
+			p.fail("cannot add %s and %s", typ, rightTyp)
+			break
+		}
+		left, typ = &Operation{t.Data[0], left, right}, sumTyp
+	}
+	return left, typ
+}
+
+func (p *parser) parseProduct() (Value, Type) {
+	left, typ := p.parseOperand()
+	for p.err == nil {
+		t := p.peek()
+		if !isDelim(t, '*') && !isDelim(t, '/') {
+			break
+		}
+		pos := p.pos
+		p.next()
+		right, rightTyp := p.parseOperand()
+		if p.err != nil {
+			break
+		}
+		productTyp, ok := typ, rightTyp == NumberType || rightTyp == UnknownType
+		if t.Data[0] == '*' {
+			productTyp, ok = multiplyTypes(typ, rightTyp)
+		}
+		if !ok {
+			p.pos = pos
+			if t.Data[0] == '*' {
+				p.fail("cannot multiply %s and %s", typ, rightTyp)
+			} else {
+				p.fail("cannot divide %s by %s", typ, rightTyp)
+			}
+			break
+		}
+		left, typ = &Operation{t.Data[0], left, right}, productTyp
+	}
+	return left, typ
+}
+
+func (p *parser) parseOperand() (Value, Type) {
+	t := p.peek()
+	switch t.TokenType {
+	case css.LeftParenthesisToken:
+		p.next()
+		val, typ := p.parseSum()
+		p.close(css.RightParenthesisToken)
+		return val, typ
+	case css.IdentToken:
+		if _, ok := calcConstant(unescape(t.Data)); ok {
+			return p.parseValue(), NumberType
+		}
+	case css.NumberToken, css.PercentageToken, css.DimensionToken, css.FunctionToken:
+		start := p.pos
+		switch val := p.parseValue().(type) {
+		case Number:
+			return val, NumberType
+		case Percentage:
+			return val, PercentageType
+		case Dimension:
+			if typ := val.Type(); typ != UnknownType {
+				return val, typ
+			}
+			p.pos = start
+			p.fail("unknown unit in %s", p.describe())
+		case *Calc:
+			return val, val.Type
+		case *Function:
+			return val, UnknownType
+		case nil:
+		default:
+			p.pos = start
+			p.fail("unexpected %s in math function", p.describe())
+		}
+		return nil, 0
+	}
+	p.fail("unexpected %s in math function", p.describe())
+	return nil, 0
+}
+
+////////////////////////////////////////////////////////////////
+
+type channel struct {
+	number, percentage float64 // scale of numbers and percentages, zero if not allowed
+	min, max           float64
+	hue                bool
+}
+
+var (
+	rgbChannel       = channel{1.0 / 255.0, 0.01, 0.0, 1.0, false}
+	fractionChannel  = channel{0.01, 0.01, 0.0, math.Inf(1), false}
+	hueChannel       = channel{1.0, 0.0, math.Inf(-1), math.Inf(1), true}
+	labLightness     = channel{1.0, 1.0, 0.0, 100.0, false}
+	okLabLightness   = channel{1.0, 0.01, 0.0, 1.0, false}
+	alphaChannel     = channel{1.0, 0.01, 0.0, 1.0, false}
+	unboundedChannel = func(percentage float64) channel {
+		return channel{1.0, percentage, math.Inf(-1), math.Inf(1), false}
+	}
+	chromaChannel = func(percentage float64) channel {
+		return channel{1.0, percentage, 0.0, math.Inf(1), false}
+	}
+)
+
+type colorFunction struct {
+	space    ColorSpace
+	channels [3]channel
+	legacy   bool // allows comma-separated arguments
+}
+
+var colorFunctions = map[string]colorFunction{
+	"rgb":   {SRGB, [3]channel{rgbChannel, rgbChannel, rgbChannel}, true},
+	"rgba":  {SRGB, [3]channel{rgbChannel, rgbChannel, rgbChannel}, true},
+	"hsl":   {HSL, [3]channel{hueChannel, fractionChannel, fractionChannel}, true},
+	"hsla":  {HSL, [3]channel{hueChannel, fractionChannel, fractionChannel}, true},
+	"hwb":   {HWB, [3]channel{hueChannel, fractionChannel, fractionChannel}, false},
+	"lab":   {Lab, [3]channel{labLightness, unboundedChannel(1.25), unboundedChannel(1.25)}, false},
+	"lch":   {LCH, [3]channel{labLightness, chromaChannel(1.5), hueChannel}, false},
+	"oklab": {OKLab, [3]channel{okLabLightness, unboundedChannel(0.004), unboundedChannel(0.004)}, false},
+	"oklch": {OKLCH, [3]channel{okLabLightness, chromaChannel(0.004), hueChannel}, false},
+}
+
+// colorSpaces are the predefined color spaces of the color() function.
+var colorSpaces = map[string]ColorSpace{
+	"srgb":        SRGB,
+	"srgb-linear": SRGBLinear,
+	"display-p3":  DisplayP3,
+	"xyz":         XYZ,
+	"xyz-d65":     XYZ,
+	"xyz-d50":     XYZD50,
+}
+
+// parseColor converts the arguments of a color function, starting at token start, into a color. It returns false if the arguments contain var() or similar functions, or use the relative color syntax, in which case the color cannot be computed.
+func (p *parser) parseColor(name string, args []Value, start int) (Color, bool) {
+	for _, arg := range args {
+		if _, ok := arg.(*Function); ok {
+			return Color{}, false
+		} else if keyword, ok := arg.(Keyword); ok && strings.EqualFold(string(keyword), "from") {
+			return Color{}, false
+		}
+	}
+
+	f := colorFunctions[name]
+	end := p.pos
+	p.pos = start
+	if name == "color" {
+		var keyword Keyword
+		if 0 < len(args) {
+			keyword, _ = args[0].(Keyword)
+		}
+		if keyword == "" {
+			p.fail("expected color space in color()")
+			return Color{}, false
+		} else if strings.HasPrefix(string(keyword), "--") {
+			p.pos = end
+			return Color{}, false // custom color space of @color-profile
+		}
+		space, ok := colorSpaces[strings.ToLower(string(keyword))]
+		if !ok {
+			p.fail("unknown color space %s in color()", keyword)
+			return Color{}, false
+		}
+		f = colorFunction{space, [3]channel{unboundedChannel(0.01), unboundedChannel(0.01), unboundedChannel(0.01)}, false}
+		args = args[1:]
+	}
+	var channels []Value
+	var alpha Value
+	if 1 < len(args) && args[1] == Delim(',') {
+		if !f.legacy {
+			p.fail("unexpected comma in %s()", name)
+			return Color{}, false
+		}
+		for i, arg := range args {
+			if i%2 == 1 {
+				if arg != Delim(',') {
+					p.fail("expected comma between arguments of %s()", name)
+					return Color{}, false
+				}
+			} else if keyword, ok := arg.(Keyword); ok && strings.EqualFold(string(keyword), "none") {
+				p.fail("unexpected none in %s() with commas", name)
+				return Color{}, false
+			} else {
+				channels = append(channels, arg)
+			}
+		}
+		if len(args)%2 == 0 {
+			channels = append(channels, nil) // trailing comma
+		}
+	} else {
+		for i, arg := range args {
+			if arg == Delim('/') && i == len(args)-2 {
+				alpha = args[i+1]
+				break
+			}
+			channels = append(channels, arg)
+		}
+	}
+	if len(channels) == 4 && alpha == nil && 1 < len(args) && args[1] == Delim(',') {
+		channels, alpha = channels[:3], channels[3]
+	}
+	if len(channels) != 3 {
+		p.fail("expected three channels in %s()", name)
+		return Color{}, false
+	}
+
+	c := Color{Space: f.space, Alpha: 1.0}
+	for i, ch := range channels {
+		v, ok := colorChannel(ch, f.channels[i])
+		if !ok {
+			p.fail("invalid channel %d in %s()", i+1, name)
+			return Color{}, false
+		}
+		c.Channels[i] = v
+	}
+	if alpha != nil {
+		v, ok := colorChannel(alpha, alphaChannel)
+		if !ok {
+			p.fail("invalid alpha in %s()", name)
+			return Color{}, false
+		}
+		c.Alpha = v
+	}
+	p.pos = end
+	return c, true
+}
+
+// colorChannel returns the value of a color channel, which may be a number, percentage, angle for hues, none, or a math function.
+func colorChannel(v Value, ch channel) (float64, bool) {
+	if calc, ok := v.(*Calc); ok {
+		f, ok := calc.Eval(func(v Value) (float64, bool) {
+			if p, ok := v.(Percentage); ok {
+				return float64(p), true
+			}
+			return 0, false
+		})
+		if !ok {
+			return 0, false
+		}
+		switch calc.Type {
+		case NumberType:
+			v = Number(f)
+		case PercentageType:
+			v = Percentage(f)
+		case AngleType:
+			v = Dimension{f, "deg"}
+		default:
+			return 0, false
+		}
+	}
+
+	var f float64
+	switch v := v.(type) {
+	case Number:
+		f = float64(v) * ch.number
+	case Percentage:
+		if ch.percentage == 0.0 {
+			return 0, false
+		}
+		f = float64(v) * ch.percentage
+	case Dimension:
+		if !ch.hue || v.Type() != AngleType {
+			return 0, false
+		}
+		f, _ = v.Canonical()
+	case Keyword:
+		if !strings.EqualFold(string(v), "none") {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	return math.Max(ch.min, math.Min(ch.max, f)), true
+}
+
+// parseHex parses the digits of a hex color with 3, 4, 6, or 8 digits.
+func parseHex(b []byte) (Color, bool) {
+	if len(b) != 3 && len(b) != 4 && len(b) != 6 && len(b) != 8 {
+		return Color{}, false
+	}
+	var digits [8]uint8
+	for i, c := range b {
+		if '0' <= c && c <= '9' {
+			digits[i] = c - '0'
+		} else if 'a' <= c && c <= 'f' {
+			digits[i] = c - 'a' + 10
+		} else if 'A' <= c && c <= 'F' {
+			digits[i] = c - 'A' + 10
+		} else {
+			return Color{}, false
+		}
+	}
+
+	var rgba [4]uint8
+	rgba[3] = 255
+	for i := 0; i < len(b); i++ {
+		if len(b) <= 4 {
+			rgba[i] = digits[i] * 17
+		} else if i%2 == 0 {
+			rgba[i/2] = digits[i]*16 + digits[i+1]
+		}
+	}
+	c := RGB(rgba[0], rgba[1], rgba[2])
+	c.Alpha = float64(rgba[3]) / 255.0
+	return c, true
+}
+
+////////////////////////////////////////////////////////////////
+
+func parseNumber(b []byte) float64 {
+	f, _ := strconv.ParseFloat(string(b), 64) // the lexer guarantees a valid number, large numbers become infinite
+	return f
+}
+
+// unescape resolves the escapes of an identifier.
+func unescape(b []byte) string {
+	if bytes.IndexByte(b, '\\') == -1 {
+		return string(b)
+	}
+	sb := strings.Builder{}
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' {
+			sb.WriteByte(b[i])
+			continue
+		}
+		i++
+		if i == len(b) {
+			sb.WriteRune(utf8.RuneError)
+			break
+		}
+
+		n := 0
+		for n < 6 && i+n < len(b) && isHex(b[i+n]) {
+			n++
+		}
+		if n == 0 {
+			sb.WriteByte(b[i])
+			continue
+		}
+		r, _ := strconv.ParseUint(string(b[i:i+n]), 16, 32)
+		if r == 0 || 0x10FFFF < r || 0xD800 <= r && r <= 0xDFFF {
+			r = utf8.RuneError
+		}
+		sb.WriteRune(rune(r))
+		i += n
+		if i < len(b) && b[i] == '\r' && i+1 < len(b) && b[i+1] == '\n' {
+			i++
+		} else if i == len(b) || !parse.IsWhitespace(b[i]) {
+			i-- // no whitespace to skip after the escape
+		}
+	}
+	return sb.String()
+}
+
+// unescapeString removes the quotes and escaped newlines of a string, and resolves its escapes.
+func unescapeString(b []byte) string {
+	quote := b[0]
+	b = b[1:]
+	if n := len(b); 0 < n && b[n-1] == quote {
+		escaped := false // the quote is escaped when preceded by an odd number of backslashes, which happens for unterminated strings at EOF
+		for i := n - 2; 0 <= i && b[i] == '\\'; i-- {
+			escaped = !escaped
+		}
+		if !escaped {
+			b = b[:n-1]
+		}
+	}
+	for _, newline := range []string{"\\\r\n", "\\\n", "\\\r", "\\\f"} {
+		b = bytes.Replace(b, []byte(newline), nil, -1)
+	}
+	return unescape(b)
+}
+
+// unescapeURL returns the URL of an url() token.
+func unescapeURL(b []byte) string {
+	b = b[bytes.IndexByte(b, '(')+1:]
+	if 0 < len(b) && b[len(b)-1] == ')' {
+		b = b[:len(b)-1]
+	}
+	b = parse.TrimWhitespace(b)
+	if 0 < len(b) && (b[0] == '"' || b[0] == '\'') {
+		return unescapeString(b)
+	}
+	return unescape(b)
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
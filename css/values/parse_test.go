@@ -0,0 +1,333 @@
+package values
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/css"
+	"github.com/tdewolff/test"
+)
+
+func TestParse(t *testing.T) {
+	var valueTests = []struct {
+		value    string
+		expected string
+	}{
+		{"", ""},
+		{"1px solid red", "1px solid red"},
+		{"  1px /* comment */ 2PX  ", "1px 2px"},
+		{"50% 1.5E2 -.5em +3 1e400", "50% 150 -0.5em 3 infinity"},
+		{`italic 12px/1.5 "Helvetica Neue", sans-serif`, `italic 12px / 1.5 "Helvetica Neue", sans-serif`},
+		{`'a\'b' "c\
+d" "\41 x" "unterminated`, `"a'b" "cd" "Ax" "unterminated"`},
+		{`\31 0px a\.b`, `\31 0px a\.b`},
+		{"url(a.png) url( 'b c.png' ) url(d\\)e) src(\"f.png\")", `url("a.png") url("b c.png") url("d)e") url("f.png")`},
+		{"[full-start] minmax(1em, 1fr) [main-start]", "[full-start] minmax(1em, 1fr) [main-start]"},
+		{"var(--a, 1px) ENV(safe-area-inset-top)", "var(--a, 1px) env(safe-area-inset-top)"},
+
+		// colors
+		{"#f00 #FF000080 #abcd #a1b2c3", "rgb(255 0 0) rgb(255 0 0 / 0.501961) rgb(170 187 204 / 0.866667) rgb(161 178 195)"},
+		{"rgb(255, 0, 0) rgba(255,0,0,.5) RGB(255 0 0)", "rgb(255 0 0) rgb(255 0 0 / 0.5) rgb(255 0 0)"},
+		{"rgb(100% 50% 0% / 25%) rgb(300 -5 none) rgb(0 0 0 / 2)", "rgb(255 127.5 0 / 0.25) rgb(255 0 0) rgb(0 0 0)"},
+		{"hsl(120deg 100% 50%) hsla(0.5turn, 50%, 50%, 0.5) hsl(120 100 50)", "hsl(120 100% 50%) hsl(180 50% 50% / 0.5) hsl(120 100% 50%)"},
+		{"hwb(90 10% 20%) hwb(90 10% 20% / none)", "hwb(90 10% 20%) hwb(90 10% 20% / 0)"},
+		{"lab(50% 40 -20) lab(120 100% -100%)", "lab(50 40 -20) lab(100 125 -125)"},
+		{"lch(52.2% 72.2 50) lch(50 -10 1rad)", "lch(52.2 72.2 50) lch(50 0 57.29578)"},
+		{"oklab(0.5 0.1 -0.1) oklch(60% 50% 120 / 50%)", "oklab(0.5 0.1 -0.1) oklch(0.6 0.2 120 / 0.5)"},
+		{"rgb(calc(255 / 2) calc(10% * 2) 0)", "rgb(127.5 51 0)"},
+		{"rgb(var(--r) 0 0) rgb(from red r g b)", "rgb(var(--r) 0 0) rgb(from red r g b)"},
+
+		// math functions
+		{"calc(100% - 2 * 10px)", "calc(100% - 2 * 10px)"},
+		{"calc((1px + 2px) * 3) calc(1px - (2px - 3px)) calc(1 / (2 / 3))", "calc((1px + 2px) * 3) calc(1px - (2px - 3px)) calc(1 / (2 / 3))"},
+		{"min(10px, 5vw) MAX(1em, 2rem, 3px) clamp(1rem, 2.5vw, 2rem)", "min(10px, 5vw) max(1em, 2rem, 3px) clamp(1rem, 2.5vw, 2rem)"},
+		{"calc(var(--x) * 2) calc(pi * 1rad) calc(min(1px, 2%) + 1em)", "calc(var(--x) * 2) calc(pi * 1rad) calc(min(1px, 2%) + 1em)"},
+	}
+	for _, tt := range valueTests {
+		t.Run(tt.value, func(t *testing.T) {
+			vals, err := ParseString(tt.value)
+			test.Error(t, err)
+			test.String(t, joinValues(vals), tt.expected)
+		})
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	vals, err := ParseString(`1 2% 3px 4foo a "b" url(c) , / #d00 rgb(var(--x) 0 0) calc(1px) [e]`)
+	test.Error(t, err)
+	test.T(t, vals, []Value{
+		Number(1),
+		Percentage(2),
+		Dimension{3, "px"},
+		Dimension{4, "foo"},
+		Keyword("a"),
+		String("b"),
+		URL("c"),
+		Delim(','),
+		Delim('/'),
+		RGB(0xdd, 0, 0),
+		&Function{"rgb", []Value{&Function{"var", []Value{Keyword("--x")}}, Number(0), Number(0)}},
+		&Calc{"calc", []Value{Dimension{1, "px"}}, LengthType},
+		Brackets{Keyword("e")},
+	})
+
+	var typeTests = []struct {
+		value    string
+		expected Type
+	}{
+		{"calc(1 + 2)", NumberType},
+		{"calc(50% * 2)", PercentageType},
+		{"calc(100% - 10px)", LengthType},
+		{"calc(10px - 100%)", LengthType},
+		{"calc(2 * 10deg / 4)", AngleType},
+		{"calc(1s + 10ms)", TimeType},
+		{"max(1hz, 1khz)", FrequencyType},
+		{"calc(2x)", ResolutionType},
+		{"calc(1fr * 2)", FlexType},
+		{"calc(var(--a))", UnknownType},
+		{"calc(var(--a) + 1px)", LengthType},
+	}
+	for _, tt := range typeTests {
+		t.Run(tt.value, func(t *testing.T) {
+			vals, err := ParseString(tt.value)
+			test.Error(t, err)
+			test.T(t, vals[0].(*Calc).Type, tt.expected)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var errorTests = []struct {
+		value string
+		err   string
+		col   int
+	}{
+		{"a; b", "unexpected ';'", 2},
+		{"a ! b", "unexpected '!'", 3},
+		{"a(b", "expected ')' instead of end of value", 4},
+		{"[a", "expected ']' instead of end of value", 3},
+		{"#ggg", "invalid hex color '#ggg'", 1},
+		{"#12345", "invalid hex color '#12345'", 1},
+		{"src(a)", "expected string instead of 'a'", 5},
+		{"rgb(1 2)", "expected three channels in rgb()", 5},
+		{"rgb(1, 2, 3, 4, 5)", "expected three channels in rgb()", 5},
+		{"rgb(1 2 3 / 4 5)", "expected three channels in rgb()", 5},
+		{"rgb(1, 2 3)", "expected comma between arguments of rgb()", 5},
+		{"hwb(1, 2%, 3%)", "unexpected comma in hwb()", 5},
+		{"rgb(1, 2, none)", "unexpected none in rgb() with commas", 5},
+		{"hsl(10% 20% 30%)", "invalid channel 1 in hsl()", 5},
+		{"rgb(1 2 1deg)", "invalid channel 3 in rgb()", 5},
+		{"rgb(1 2 3 / a)", "invalid alpha in rgb()", 5},
+		{"calc(1px + 2s)", "cannot add Length and Time", 10},
+		{"calc(1px * 2px)", "cannot multiply Length and Length", 10},
+		{"calc(1 / 1px)", "cannot divide Number by Length", 8},
+		{"max(1px, 1)", "incompatible types Length and Number in max()", 11},
+		{"calc(1foo)", "unknown unit in '1foo'", 6},
+		{"calc(1px, 2px)", "expected ')' instead of ','", 9},
+		{"calc(a)", "unexpected 'a' in math function", 6},
+		{"calc(#fff)", "unexpected '#fff' in math function", 6},
+		{"calc(1 +)", "unexpected ')' in math function", 9},
+		{"min()", "unexpected ')' in math function", 5},
+		{"clamp(1px, 2px)", "expected three arguments in clamp()", 15},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := ParseString(tt.value)
+			test.That(t, err != nil, "expected error")
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "CSS value parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestParseDeclaration(t *testing.T) {
+	stylesheet, err := css.ParseStylesheet(bytes.NewBufferString("a { margin: 0 calc(1em + 2px) ; --x: { a: b } ; --y: 10px , red }"))
+	test.Error(t, err)
+	decls := stylesheet.Rules[0].(*css.Ruleset).Rules
+
+	vals, err := Parse(decls[0].(*css.Declaration).Values)
+	test.Error(t, err)
+	test.String(t, joinValues(vals), "0 calc(1em + 2px)")
+
+	_, err = Parse(decls[1].(*css.Declaration).Values)
+	test.That(t, err != nil, "expected error")
+
+	vals, err = Parse(decls[2].(*css.Declaration).Values)
+	test.Error(t, err)
+	test.String(t, joinValues(vals), "10px, red")
+}
+
+func TestParseColor(t *testing.T) {
+	var colorTests = []struct {
+		color    string
+		expected Color
+	}{
+		{"red", RGB(255, 0, 0)},
+		{" RebeccaPurple ", RGB(0x66, 0x33, 0x99)},
+		{"transparent", Color{SRGB, [3]float64{0, 0, 0}, 0}},
+		{"#0f08", Color{SRGB, [3]float64{0, 1, 0}, 0.5333333333333333}},
+		{"hsl(0 0% 100%)", Color{HSL, [3]float64{0, 0, 1}, 1}},
+		{"CurrentColor", Color{CurrentColor, [3]float64{}, 1}},
+		{"color(srgb 1 50% 0)", Color{SRGB, [3]float64{1, 0.5, 0}, 1}},
+		{"color(SRGB-linear 0.5 none 1.5 / 50%)", Color{SRGBLinear, [3]float64{0.5, 0, 1.5}, 0.5}},
+		{"color(display-p3 1 0 0)", Color{DisplayP3, [3]float64{1, 0, 0}, 1}},
+		{"color(xyz 0.25 0.5 0.75)", Color{XYZ, [3]float64{0.25, 0.5, 0.75}, 1}},
+		{"color(xyz-d65 0 1 0)", Color{XYZ, [3]float64{0, 1, 0}, 1}},
+		{"color(xyz-d50 0 1 0)", Color{XYZD50, [3]float64{0, 1, 0}, 1}},
+	}
+	for _, tt := range colorTests {
+		t.Run(tt.color, func(t *testing.T) {
+			c, err := ParseColorString(tt.color)
+			test.Error(t, err)
+			test.T(t, c, tt.expected)
+		})
+	}
+
+	var errorTests = []struct {
+		color string
+		err   string
+		col   int
+	}{
+		{"", "unexpected end of value", 1},
+		{"currentcolor red", "unexpected 'red'", 14},
+		{"color(--profile 1 0 0)", "expected color", 1},
+		{"color(1 0 0)", "expected color space in color()", 7},
+		{"color(rec2100 1 0 0)", "unknown color space rec2100 in color()", 7},
+		{"color(srgb 1 0)", "expected three channels in color()", 7},
+		{"color(srgb 1, 0, 0)", "unexpected comma in color()", 7},
+		{"rgb(var(--x) 0 0)", "expected color", 1},
+		{"1px", "expected color", 1},
+		{"red blue", "unexpected 'blue'", 5},
+		{"#fff 1", "unexpected '1'", 6},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.color, func(t *testing.T) {
+			_, err := ParseColorString(tt.color)
+			test.That(t, err != nil, "expected error")
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "CSS value parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestCalcEval(t *testing.T) {
+	var evalTests = []struct {
+		value    string
+		expected float64
+	}{
+		{"calc(1px + 2px * 3)", 7},
+		{"calc((1px + 2px) * 3)", 9},
+		{"calc(1in - 6pt / 2)", 92},
+		{"calc(1px - (2px - 3px))", 2},
+		{"calc(100% - 2em)", 68},
+		{"min(10px, 1em, 50%)", 10},
+		{"max(10px, 1em, 50%)", 50},
+		{"clamp(1px, 5px, 3px)", 3},
+		{"clamp(4px, 5px, 3px)", 4},
+		{"calc(1turn / 4 + 0.5rad * 0)", 90},
+		{"calc(2 * pi)", 2 * math.Pi},
+		{"calc(1s + 500ms)", 1.5},
+		{"calc(1 / 0)", math.Inf(1)},
+		{"calc(-infinity)", math.Inf(-1)},
+	}
+	resolve := func(v Value) (float64, bool) {
+		switch v := v.(type) {
+		case Percentage:
+			return float64(v) / 100.0 * 100.0, true // of 100px
+		case Dimension:
+			if v.Unit == "em" {
+				return v.Value * 16.0, true
+			}
+		}
+		return 0, false
+	}
+	for _, tt := range evalTests {
+		t.Run(tt.value, func(t *testing.T) {
+			vals, err := ParseString(tt.value)
+			test.Error(t, err)
+			f, ok := vals[0].(*Calc).Eval(resolve)
+			test.That(t, ok, "must evaluate")
+			test.Float(t, f, tt.expected)
+		})
+	}
+
+	vals, _ := ParseString("calc(1px + 1vw) calc(var(--x) + 1px) calc(1px + 10%) calc(NaN)")
+	_, ok := vals[0].(*Calc).Eval(resolve)
+	test.That(t, !ok, "vw must not resolve")
+	_, ok = vals[1].(*Calc).Eval(resolve)
+	test.That(t, !ok, "var() must not resolve")
+	_, ok = vals[2].(*Calc).Eval(nil)
+	test.That(t, !ok, "percentage must not resolve")
+	f, ok := vals[3].(*Calc).Eval(nil)
+	test.That(t, ok && math.IsNaN(f), "must be NaN")
+}
+
+func TestDimension(t *testing.T) {
+	var conversionTests = []struct {
+		d        Dimension
+		unit     string
+		expected float64
+	}{
+		{Dimension{1, "in"}, "px", 96},
+		{Dimension{1, "in"}, "CM", 2.54},
+		{Dimension{72, "pt"}, "pc", 6},
+		{Dimension{40, "q"}, "mm", 10},
+		{Dimension{0.5, "turn"}, "grad", 200},
+		{Dimension{180, "deg"}, "rad", math.Pi},
+		{Dimension{1500, "ms"}, "s", 1.5},
+		{Dimension{1, "khz"}, "hz", 1000},
+		{Dimension{96, "dpi"}, "x", 1},
+		{Dimension{2, "dppx"}, "dpcm", 2 * 96 / 2.54},
+	}
+	for _, tt := range conversionTests {
+		t.Run(tt.d.String()+" to "+tt.unit, func(t *testing.T) {
+			d, ok := tt.d.To(tt.unit)
+			test.That(t, ok, "must convert")
+			test.Float(t, d.Value, tt.expected)
+		})
+	}
+
+	_, ok := Dimension{1, "em"}.To("px")
+	test.That(t, !ok, "relative unit")
+	_, ok = Dimension{1, "px"}.To("deg")
+	test.That(t, !ok, "different types")
+	_, ok = Dimension{1, "foo"}.Canonical()
+	test.That(t, !ok, "unknown unit")
+	test.T(t, Dimension{1, "vmin"}.Type(), LengthType)
+	test.T(t, Dimension{1, "fr"}.Type(), FlexType)
+	test.T(t, Dimension{1, "foo"}.Type(), UnknownType)
+
+	test.String(t, NumberType.String(), "Number")
+	test.String(t, Type(100).String(), "Invalid(100)")
+}
+
+////////////////////////////////////////////////////////////////
+
+func ExampleParseString() {
+	vals, err := ParseString("1px solid rgb(255 0 0 / 50%), calc(100% - 2em)")
+	if err != nil {
+		panic(err)
+	}
+	for _, val := range vals {
+		fmt.Printf("%T %v\n", val, val)
+	}
+	// Output:
+	// values.Dimension 1px
+	// values.Keyword solid
+	// values.Color rgb(255 0 0 / 0.5)
+	// values.Delim ,
+	// *values.Calc calc(100% - 2em)
+}
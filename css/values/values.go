@@ -0,0 +1,262 @@
+// Package values parses CSS component values, such as the values of declarations, into typed values following the specifications at https://www.w3.org/TR/css-values-4/ and https://www.w3.org/TR/css-color-4/.
+package values
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Value is a parsed component value, ie. Number, Percentage, Dimension, Keyword, String, URL, Delim, Color, *Calc, *Function, or Brackets.
+type Value interface {
+	String() string
+}
+
+// Type is the type of a numeric value.
+type Type uint32
+
+// Type values.
+const (
+	NumberType Type = iota
+	PercentageType
+	LengthType
+	AngleType
+	TimeType
+	FrequencyType
+	ResolutionType
+	FlexType
+	UnknownType // dimension with an unknown unit, or a math function that contains var()
+)
+
+// String returns the string representation of a Type.
+func (t Type) String() string {
+	switch t {
+	case NumberType:
+		return "Number"
+	case PercentageType:
+		return "Percentage"
+	case LengthType:
+		return "Length"
+	case AngleType:
+		return "Angle"
+	case TimeType:
+		return "Time"
+	case FrequencyType:
+		return "Frequency"
+	case ResolutionType:
+		return "Resolution"
+	case FlexType:
+		return "Flex"
+	case UnknownType:
+		return "Unknown"
+	}
+	return "Invalid(" + strconv.Itoa(int(t)) + ")"
+}
+
+type unit struct {
+	typ    Type
+	factor float64 // to the canonical unit, zero for relative units
+}
+
+// units maps units to their type and conversion factor to the canonical units px, deg, s, hz, and dppx.
+var units = map[string]unit{
+	// absolute lengths
+	"px": {LengthType, 1.0},
+	"cm": {LengthType, 96.0 / 2.54},
+	"mm": {LengthType, 96.0 / 25.4},
+	"q":  {LengthType, 96.0 / 101.6},
+	"in": {LengthType, 96.0},
+	"pc": {LengthType, 16.0},
+	"pt": {LengthType, 96.0 / 72.0},
+
+	// font-relative lengths
+	"em": {LengthType, 0}, "rem": {LengthType, 0},
+	"ex": {LengthType, 0}, "rex": {LengthType, 0},
+	"cap": {LengthType, 0}, "rcap": {LengthType, 0},
+	"ch": {LengthType, 0}, "rch": {LengthType, 0},
+	"ic": {LengthType, 0}, "ric": {LengthType, 0},
+	"lh": {LengthType, 0}, "rlh": {LengthType, 0},
+
+	// viewport and container lengths
+	"vw": {LengthType, 0}, "svw": {LengthType, 0}, "lvw": {LengthType, 0}, "dvw": {LengthType, 0},
+	"vh": {LengthType, 0}, "svh": {LengthType, 0}, "lvh": {LengthType, 0}, "dvh": {LengthType, 0},
+	"vi": {LengthType, 0}, "svi": {LengthType, 0}, "lvi": {LengthType, 0}, "dvi": {LengthType, 0},
+	"vb": {LengthType, 0}, "svb": {LengthType, 0}, "lvb": {LengthType, 0}, "dvb": {LengthType, 0},
+	"vmin": {LengthType, 0}, "svmin": {LengthType, 0}, "lvmin": {LengthType, 0}, "dvmin": {LengthType, 0},
+	"vmax": {LengthType, 0}, "svmax": {LengthType, 0}, "lvmax": {LengthType, 0}, "dvmax": {LengthType, 0},
+	"cqw": {LengthType, 0}, "cqh": {LengthType, 0}, "cqi": {LengthType, 0}, "cqb": {LengthType, 0},
+	"cqmin": {LengthType, 0}, "cqmax": {LengthType, 0},
+
+	"deg":  {AngleType, 1.0},
+	"grad": {AngleType, 0.9},
+	"rad":  {AngleType, 180.0 / math.Pi},
+	"turn": {AngleType, 360.0},
+
+	"s":  {TimeType, 1.0},
+	"ms": {TimeType, 0.001},
+
+	"hz":  {FrequencyType, 1.0},
+	"khz": {FrequencyType, 1000.0},
+
+	"dppx": {ResolutionType, 1.0},
+	"x":    {ResolutionType, 1.0},
+	"dpi":  {ResolutionType, 1.0 / 96.0},
+	"dpcm": {ResolutionType, 2.54 / 96.0},
+
+	"fr": {FlexType, 0},
+}
+
+// Number is a <number>.
+type Number float64
+
+func (v Number) String() string {
+	return formatNumber(float64(v))
+}
+
+// Percentage is a <percentage>, where 50% has the value 50.
+type Percentage float64
+
+func (v Percentage) String() string {
+	return formatNumber(float64(v)) + "%"
+}
+
+// Dimension is a number with a unit, such as a <length>, <angle>, <time>, <frequency>, <resolution>, or <flex>.
+type Dimension struct {
+	Value float64
+	Unit  string // lowercase
+}
+
+func (v Dimension) String() string {
+	return formatNumber(v.Value) + v.Unit
+}
+
+// Type returns the type of the dimension, or UnknownType if the unit is not known.
+func (v Dimension) Type() Type {
+	if u, ok := units[v.Unit]; ok {
+		return u.typ
+	}
+	return UnknownType
+}
+
+// Canonical returns the value converted to the canonical unit of its type, ie. px, deg, s, hz, or dppx. It returns false for relative units such as em or vw, and for flex and unknown units.
+func (v Dimension) Canonical() (float64, bool) {
+	if u, ok := units[v.Unit]; ok && u.factor != 0 {
+		return v.Value * u.factor, true
+	}
+	return 0, false
+}
+
+// To converts the dimension to the given unit, which must be of the same type. It returns false if either unit is relative or unknown.
+func (v Dimension) To(unit string) (Dimension, bool) {
+	unit = strings.ToLower(unit)
+	from, ok := units[v.Unit]
+	to, ok2 := units[unit]
+	if !ok || !ok2 || from.typ != to.typ || from.factor == 0 || to.factor == 0 {
+		return Dimension{}, false
+	}
+	return Dimension{v.Value * from.factor / to.factor, unit}, true
+}
+
+// Keyword is an identifier with its escapes resolved, such as auto or a custom identifier. CSS-wide keywords and named colors are also returned as keywords, see NamedColor to convert the latter.
+type Keyword string
+
+func (v Keyword) String() string {
+	return escapeIdent(string(v))
+}
+
+// String is a <string> without its quotes and with its escapes resolved.
+type String string
+
+func (v String) String() string {
+	return quoteString(string(v))
+}
+
+// URL is the <url> of url() or src() with its escapes resolved.
+type URL string
+
+func (v URL) String() string {
+	return "url(" + quoteString(string(v)) + ")"
+}
+
+// Delim is a comma or slash between component values.
+type Delim byte
+
+func (v Delim) String() string {
+	return string(v)
+}
+
+// Function is a function other than a color or math function, such as var(), env(), or attr(). Its arguments are parsed as component values.
+type Function struct {
+	Name string // lowercase
+	Args []Value
+}
+
+func (v *Function) String() string {
+	return v.Name + "(" + joinValues(v.Args) + ")"
+}
+
+// Brackets is a block in square brackets, such as the line names of grid-template-columns.
+type Brackets []Value
+
+func (v Brackets) String() string {
+	return "[" + joinValues(v) + "]"
+}
+
+////////////////////////////////////////////////////////////////
+
+func formatNumber(f float64) string {
+	if math.IsInf(f, 1) {
+		return "infinity"
+	} else if math.IsInf(f, -1) {
+		return "-infinity"
+	} else if math.IsNaN(f) {
+		return "NaN"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// joinValues joins values by spaces, except before commas.
+func joinValues(vals []Value) string {
+	sb := strings.Builder{}
+	for i, val := range vals {
+		if delim, ok := val.(Delim); i != 0 && (!ok || delim != ',') {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(val.String())
+	}
+	return sb.String()
+}
+
+func escapeIdent(s string) string {
+	sb := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '-' || c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || 0x80 <= c || '0' <= c && c <= '9' && 0 < i && (1 < i || s[0] != '-') {
+			sb.WriteByte(c)
+		} else if c < 0x20 || c == 0x7F || '0' <= c && c <= '9' {
+			sb.WriteString("\\" + strconv.FormatInt(int64(c), 16) + " ")
+		} else {
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func quoteString(s string) string {
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '"' || c == '\\' {
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		} else if c < 0x20 || c == 0x7F {
+			sb.WriteString("\\" + strconv.FormatInt(int64(c), 16) + " ")
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
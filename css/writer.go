@@ -0,0 +1,368 @@
+package css
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// Format determines the output format of the writer.
+type Format uint32
+
+// Format values.
+const (
+	CompactFormat Format = iota // without optional whitespace and semicolons
+	IndentFormat                // one declaration or rule per line, indented by nesting level
+)
+
+// String returns the string representation of a Format.
+func (format Format) String() string {
+	switch format {
+	case CompactFormat:
+		return "Compact"
+	case IndentFormat:
+		return "Indent"
+	}
+	return "Invalid(" + strconv.Itoa(int(format)) + ")"
+}
+
+// ErrIncomplete is returned by Close when a ruleset or at-rule block has not been closed.
+var ErrIncomplete = errors.New("css: unclosed block")
+
+const writerBufferSize = 4096
+
+// Writer writes the grammars returned by Parser as CSS to an io.Writer. It inserts the braces, colons and semicolons, normalizes the whitespace between the tokens of selectors, at-rule preludes and declaration values, and inserts whitespace where needed to keep tokens from merging following https://www.w3.org/TR/css-syntax-3/#serialization. Plus and minus operators of math functions such as calc() are always surrounded by whitespace.
+// Output is buffered, and Close must be called after the last grammar to flush the output.
+type Writer struct {
+	w         io.Writer
+	format    Format
+	indent    string
+	buf       []byte
+	blocks    []GrammarType // BeginRulesetGrammar or BeginAtRuleGrammar of the open blocks
+	selector  bool          // in a selector list after QualifiedRuleGrammar
+	semicolon GrammarType   // grammar that needs a semicolon unless followed by the end of a block, ErrorGrammar if none
+	last      GrammarType
+	started   bool
+	math      []bool // whether the open functions and parentheses are in a math function
+	err       error
+}
+
+// NewWriter returns a new Writer for a given io.Writer, which writes in CompactFormat.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:      w,
+		indent: "\t",
+	}
+}
+
+// SetFormat sets the output format. It must be called before the first call to Write.
+func (w *Writer) SetFormat(format Format) {
+	w.format = format
+}
+
+// SetIndent sets the string used for each level of indentation in IndentFormat, which is a tab by default.
+func (w *Writer) SetIndent(indent string) {
+	w.indent = indent
+}
+
+// Write writes a grammar as returned by Parser.Next together with the tokens of Parser.Values. The data is the at-keyword of at-rules, the property name of declarations and custom properties, the comment of CommentGrammar, and the token of TokenGrammar, which is written verbatim. The values are the prelude of at-rules, the selector of rulesets, and the values of declarations. ErrorGrammar is ignored, so that invalid declarations are dropped.
+// It returns an error if the grammar is not expected, after which the writer cannot be used anymore.
+func (w *Writer) Write(gt GrammarType, data []byte, values []Token) error {
+	if w.err != nil {
+		return w.err
+	} else if gt == ErrorGrammar {
+		return nil
+	} else if w.selector && gt != QualifiedRuleGrammar && gt != BeginRulesetGrammar {
+		return w.fail("css: expected selector but got %s", gt)
+	}
+
+	switch gt {
+	case EndRulesetGrammar, EndAtRuleGrammar:
+		begin := BeginRulesetGrammar
+		if gt == EndAtRuleGrammar {
+			begin = BeginAtRuleGrammar
+		}
+		if len(w.blocks) == 0 || w.blocks[len(w.blocks)-1] != begin {
+			return w.fail("css: unexpected %s", gt)
+		}
+		w.blocks = w.blocks[:len(w.blocks)-1]
+		w.semicolon = ErrorGrammar
+		if w.last != begin && w.last != TokenGrammar {
+			w.newline()
+		}
+		w.buf = append(w.buf, '}')
+	case TokenGrammar:
+		w.writeSemicolon()
+		if len(w.blocks) == 0 {
+			w.newline()
+		}
+		w.buf = append(w.buf, data...)
+	case CommentGrammar:
+		w.writeSemicolon()
+		w.newline()
+		w.buf = append(w.buf, data...)
+	case AtRuleGrammar, BeginAtRuleGrammar:
+		w.writeSemicolon()
+		w.separate(gt)
+		w.buf = append(w.buf, data...)
+		w.writeValues(values, Token{AtKeywordToken, data}, preludeContext)
+		if gt == AtRuleGrammar {
+			w.endStatement(gt)
+		} else {
+			w.begin(gt)
+		}
+	case QualifiedRuleGrammar, BeginRulesetGrammar:
+		if !w.selector {
+			w.writeSemicolon()
+			w.separate(gt)
+		}
+		w.writeValues(values, Token{}, selectorContext)
+		if gt == QualifiedRuleGrammar {
+			w.buf = append(w.buf, ',')
+			if w.format == IndentFormat {
+				w.newline()
+			}
+			w.selector = true
+		} else {
+			w.begin(gt)
+			w.selector = false
+		}
+	case DeclarationGrammar, CustomPropertyGrammar:
+		w.writeSemicolon()
+		w.newline()
+		w.buf = append(w.buf, data...)
+		w.buf = append(w.buf, ':')
+		if gt == CustomPropertyGrammar {
+			if w.format == IndentFormat {
+				w.buf = append(w.buf, ' ')
+			}
+			for _, t := range values {
+				w.buf = append(w.buf, parse.TrimWhitespace(t.Data)...)
+			}
+		} else {
+			if w.format == IndentFormat && len(values) != 0 {
+				w.buf = append(w.buf, ' ')
+			}
+			w.writeValues(values, Token{}, valueContext)
+		}
+		w.endStatement(gt)
+	default:
+		return w.fail("css: unexpected %s", gt)
+	}
+	w.last = gt
+	return w.flush()
+}
+
+// Close flushes the output. It returns an error if a ruleset or at-rule block has not been closed.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	} else if len(w.blocks) != 0 || w.selector {
+		w.err = ErrIncomplete
+		return w.err
+	}
+	if w.semicolon == AtRuleGrammar {
+		w.buf = append(w.buf, ';')
+	}
+	w.semicolon = ErrorGrammar
+	if _, err := w.w.Write(w.buf); err != nil {
+		w.err = err
+		return err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *Writer) fail(message string, a ...interface{}) error {
+	w.err = fmt.Errorf(message, a...)
+	return w.err
+}
+
+// flush writes the buffer when it is full.
+func (w *Writer) flush() error {
+	if writerBufferSize <= len(w.buf) {
+		if _, err := w.w.Write(w.buf); err != nil {
+			w.err = err
+			return err
+		}
+		w.buf = w.buf[:0]
+	}
+	return nil
+}
+
+// newline starts a new line at the current indentation level in IndentFormat, except at the start of the output.
+func (w *Writer) newline() {
+	if w.format == IndentFormat && w.started {
+		w.buf = append(w.buf, '\n')
+		for i := 0; i < len(w.blocks); i++ {
+			w.buf = append(w.buf, w.indent...)
+		}
+	}
+	w.started = true
+}
+
+// separate starts a new line for a rule, and leaves an empty line before top-level blocks in IndentFormat.
+func (w *Writer) separate(gt GrammarType) {
+	if w.format == IndentFormat && w.started && len(w.blocks) == 0 && (gt == BeginAtRuleGrammar || gt == QualifiedRuleGrammar || gt == BeginRulesetGrammar) {
+		w.buf = append(w.buf, '\n')
+	}
+	w.newline()
+}
+
+func (w *Writer) begin(gt GrammarType) {
+	if w.format == IndentFormat {
+		w.buf = append(w.buf, ' ')
+	}
+	w.buf = append(w.buf, '{')
+	w.blocks = append(w.blocks, gt)
+}
+
+// endStatement ends a declaration or at-rule, where the semicolon is omitted before the end of a block in CompactFormat.
+func (w *Writer) endStatement(gt GrammarType) {
+	if w.format == IndentFormat {
+		w.buf = append(w.buf, ';')
+	} else {
+		w.semicolon = gt
+	}
+}
+
+func (w *Writer) writeSemicolon() {
+	if w.semicolon != ErrorGrammar {
+		w.buf = append(w.buf, ';')
+		w.semicolon = ErrorGrammar
+	}
+}
+
+type valuesContext int
+
+const (
+	selectorContext valuesContext = iota
+	preludeContext
+	valueContext
+)
+
+// writeValues writes the tokens of a selector, at-rule prelude or declaration value, where prev is the token before the values. Whitespace is only kept where it is significant or, in IndentFormat, where it helps readability.
+func (w *Writer) writeValues(values []Token, prev Token, context valuesContext) {
+	w.math = w.math[:0]
+	space := false  // whitespace before the token in the input
+	forced := false // whitespace required after a math operator
+	for _, t := range values {
+		if t.TokenType == WhitespaceToken {
+			space = true
+			continue
+		}
+
+		math := 0 < len(w.math) && w.math[len(w.math)-1]
+		operator := math && isDelim(t, "+-")
+		if math && (isDelim(prev, "*/") || isDelim(t, "*/")) {
+			space = false
+		}
+		if prev.TokenType != ErrorToken && (forced || operator || needsWhitespace(prev, t) || space && significantWhitespace(prev, t, context) || w.format == IndentFormat && readableWhitespace(prev, t, context)) {
+			w.buf = append(w.buf, ' ')
+		}
+		w.buf = append(w.buf, t.Data...)
+
+		switch t.TokenType {
+		case FunctionToken:
+			w.math = append(w.math, isMathFunction(t.Data))
+		case LeftParenthesisToken:
+			w.math = append(w.math, 0 < len(w.math) && w.math[len(w.math)-1])
+		case LeftBracketToken:
+			w.math = append(w.math, false)
+		case RightParenthesisToken, RightBracketToken:
+			if 0 < len(w.math) {
+				w.math = w.math[:len(w.math)-1]
+			}
+		}
+		prev = t
+		space = false
+		forced = operator
+	}
+}
+
+// isMathFunction returns true for the math functions of https://www.w3.org/TR/css-values-4/#math, including vendor-prefixed calc().
+func isMathFunction(data []byte) bool {
+	name := string(parse.ToLower(parse.Copy(data[:len(data)-1])))
+	switch name {
+	case "calc", "-webkit-calc", "-moz-calc", "min", "max", "clamp", "round", "mod", "rem", "sin", "cos", "tan", "asin", "acos", "atan", "atan2", "pow", "sqrt", "hypot", "log", "exp", "abs", "sign":
+		return true
+	}
+	return false
+}
+
+// significantWhitespace returns false for whitespace that can be removed between two tokens, such as around commas and inside parentheses, around combinators of selectors, and around slashes and before !important in declaration values.
+func significantWhitespace(a, b Token, context valuesContext) bool {
+	switch a.TokenType {
+	case CommaToken, LeftParenthesisToken, LeftBracketToken, FunctionToken:
+		return false
+	}
+	switch b.TokenType {
+	case CommaToken, RightParenthesisToken, RightBracketToken:
+		return false
+	}
+	if context == selectorContext {
+		return !isDelim(a, ">+~") && !isDelim(b, ">+~")
+	} else if context == valueContext {
+		return !isDelim(a, "/") && !isDelim(b, "/!")
+	}
+	return true
+}
+
+// readableWhitespace returns true where IndentFormat adds whitespace, such as after commas and around combinators of selectors.
+func readableWhitespace(a, b Token, context valuesContext) bool {
+	if a.TokenType == AtKeywordToken || a.TokenType == CommaToken {
+		return true
+	} else if context == selectorContext {
+		return isDelim(a, ">+~") || isDelim(b, ">+~")
+	}
+	return context == valueContext && isDelim(b, "!")
+}
+
+// needsWhitespace returns true if the two tokens would be lexed differently without whitespace in between, following the table of https://www.w3.org/TR/css-syntax-3/#serialization.
+func needsWhitespace(a, b Token) bool {
+	identLike := b.TokenType == IdentToken || b.TokenType == FunctionToken || b.TokenType == URLToken || b.TokenType == BadURLToken || isDelim(b, "-")
+	numeric := b.TokenType == NumberToken || b.TokenType == PercentageToken || b.TokenType == DimensionToken
+	switch a.TokenType {
+	case IdentToken:
+		return identLike || numeric || b.TokenType == CDCToken || b.TokenType == LeftParenthesisToken
+	case AtKeywordToken, HashToken, DimensionToken:
+		return identLike || numeric || b.TokenType == CDCToken
+	case NumberToken:
+		return identLike || numeric || isDelim(b, "%")
+	case DelimToken:
+		switch a.Data[0] {
+		case '#', '-':
+			return identLike || numeric
+		case '@':
+			return identLike
+		case '.', '+':
+			return numeric
+		case '/':
+			return isDelim(b, "*") || b.TokenType == SubstringMatchToken
+		case '~', '^', '$', '*':
+			return isDelim(b, "=")
+		case '|':
+			return isDelim(b, "=|") || b.TokenType == ColumnToken || b.TokenType == DashMatchToken
+		case '<':
+			return isDelim(b, "!")
+		case '\\':
+			return true
+		}
+	}
+	return false
+}
+
+func isDelim(t Token, delims string) bool {
+	if t.TokenType != DelimToken || len(t.Data) != 1 {
+		return false
+	}
+	for i := 0; i < len(delims); i++ {
+		if t.Data[0] == delims[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package css
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+// rewrite writes the grammars parsed from s with a Writer in the given format.
+func rewrite(s string, isInline bool, format Format) (string, error) {
+	p := NewParser(bytes.NewBufferString(s), isInline)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(format)
+	for {
+		gt, _, data := p.Next()
+		if gt == ErrorGrammar && p.Err() == io.EOF {
+			break
+		} else if err := w.Write(gt, data, p.Values()); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func TestWriter(t *testing.T) {
+	var writerTests = []struct {
+		inline   bool
+		css      string
+		expected string
+	}{
+		{true, "color: red;", "color:red"},
+		{true, " color : red ; border : 1px solid black ; ", "color:red;border:1px solid black"},
+		{true, "color: red !important;", "color:red!important"},
+		{true, "font: 12px / 1.5 a , b;", "font:12px/1.5 a,b"},
+		{true, "width: calc( 100% - 2 * 1em );", "width:calc(100% - 2*1em)"},
+		{true, "width: calc(100%/3 - (1em + 2px));", "width:calc(100%/3 - (1em + 2px))"},
+		{true, "--x:  1 2 ;", "--x:1 2"},
+		{true, "*zoom: 1;", "*zoom:1"},
+		{false, "", ""},
+		{false, "a { color: red; border: 0; } b { padding: 0; }", "a{color:red;border:0}b{padding:0}"},
+		{false, "a , .b > c ~ d + e  f { x: y }", "a,.b>c~d+e f{x:y}"},
+		{false, "a :hover, a:hover, [ href ] {}", "a :hover,a:hover,[href]{}"},
+		{false, "@import 'x.css' screen;@charset 'utf-8'", "@import 'x.css' screen;@charset 'utf-8';"},
+		{false, "@media print , screen and ( min-width : 400px ) { a { x: y } }", "@media print,screen and (min-width:400px){a{x:y}}"},
+		{false, "@media (color) { a { x: y } }", "@media(color){a{x:y}}"},
+		{false, "@font-face { font-family: x; src: url(a.woff); }", "@font-face{font-family:x;src:url(a.woff)}"},
+		{false, "@unknown abc { {} lala }", "@unknown abc{{} lala }"},
+		{false, "/* a */ a { /* b */ x: y; }", "/* a */a{x:y}"},
+		{false, "a { x: y; & b { z: w } v: u }", "a{x:y;& b{z:w}v:u}"},
+		{false, ".a { @media print { color: red } }", ".a{@media print{color:red}}"},
+		{false, "a { x: y; } @import 'x';", "a{x:y}@import 'x';"},
+		{false, "a { baddecl; x: y }", "a{x:y}"},
+	}
+	for _, tt := range writerTests {
+		t.Run(tt.css, func(t *testing.T) {
+			s, err := rewrite(tt.css, tt.inline, CompactFormat)
+			test.Error(t, err)
+			test.String(t, s, tt.expected)
+
+			// idempotent
+			s2, err := rewrite(s, tt.inline, CompactFormat)
+			test.Error(t, err)
+			test.String(t, s2, s)
+		})
+	}
+}
+
+func TestWriterIndent(t *testing.T) {
+	css := "/*x*/@import 'a.css' print;a,b>c{color:red;margin:0 auto!important;&:hover{color:blue}}@media screen and (min-width:400px){d{font:12px/1.5 a,b}e{}}@font-face{font-family:x}"
+	expected := `/*x*/
+@import 'a.css' print;
+
+a,
+b > c {
+	color: red;
+	margin: 0 auto !important;
+	&:hover {
+		color: blue;
+	}
+}
+
+@media screen and (min-width:400px) {
+	d {
+		font: 12px/1.5 a, b;
+	}
+	e {}
+}
+
+@font-face {
+	font-family: x;
+}`
+	s, err := rewrite(css, false, IndentFormat)
+	test.Error(t, err)
+	test.String(t, s, expected)
+
+	s, err = rewrite(s, false, CompactFormat)
+	test.Error(t, err)
+	test.String(t, s, "/*x*/@import 'a.css' print;a,b>c{color:red;margin:0 auto!important;&:hover{color:blue}}@media screen and (min-width:400px){d{font:12px/1.5 a,b}e{}}@font-face{font-family:x}")
+}
+
+func TestWriterSetIndent(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(IndentFormat)
+	w.SetIndent("  ")
+	test.Error(t, w.Write(BeginRulesetGrammar, nil, []Token{{IdentToken, []byte("a")}}))
+	test.Error(t, w.Write(DeclarationGrammar, []byte("x"), []Token{{IdentToken, []byte("y")}}))
+	test.Error(t, w.Write(EndRulesetGrammar, nil, nil))
+	test.Error(t, w.Close())
+	test.String(t, buf.String(), "a {\n  x: y;\n}")
+}
+
+func TestWriterWhitespace(t *testing.T) {
+	ident := func(s string) Token { return Token{IdentToken, []byte(s)} }
+	delim := func(c byte) Token { return Token{DelimToken, []byte{c}} }
+	number := func(s string) Token { return Token{NumberToken, []byte(s)} }
+	dimension := func(s string) Token { return Token{DimensionToken, []byte(s)} }
+
+	var whitespaceTests = []struct {
+		values   []Token
+		expected string
+	}{
+		{[]Token{ident("a"), ident("b")}, "a b"},
+		{[]Token{ident("a"), number("1")}, "a 1"},
+		{[]Token{ident("a"), delim('-')}, "a -"},
+		{[]Token{ident("a"), {LeftParenthesisToken, []byte("(")}, {RightParenthesisToken, []byte(")")}}, "a ()"},
+		{[]Token{ident("a"), {StringToken, []byte("'b'")}}, "a'b'"},
+		{[]Token{number("1"), dimension("2px")}, "1 2px"},
+		{[]Token{number("1"), delim('%')}, "1 %"},
+		{[]Token{number("1"), delim('.'), number("5")}, "1. 5"},
+		{[]Token{dimension("1px"), ident("a")}, "1px a"},
+		{[]Token{{HashToken, []byte("#a")}, ident("b")}, "#a b"},
+		{[]Token{delim('#'), ident("a")}, "# a"},
+		{[]Token{delim('-'), number("1")}, "- 1"},
+		{[]Token{delim('@'), ident("a")}, "@ a"},
+		{[]Token{delim('+'), number("1")}, "+ 1"},
+		{[]Token{delim('/'), delim('*')}, "/ *"},
+		{[]Token{delim('|'), delim('=')}, "| ="},
+		{[]Token{delim('*'), delim('|'), ident("a")}, "*|a"},
+		{[]Token{{CommaToken, []byte(",")}, ident("a"), {WhitespaceToken, []byte(" ")}, {CommaToken, []byte(",")}}, ",a,"},
+
+		// math operators
+		{[]Token{{FunctionToken, []byte("calc(")}, dimension("1px"), delim('+'), dimension("2px"), {RightParenthesisToken, []byte(")")}}, "calc(1px + 2px)"},
+		{[]Token{{FunctionToken, []byte("CALC(")}, number("1"), delim('-'), {LeftParenthesisToken, []byte("(")}, number("2"), delim('*'), number("3"), {RightParenthesisToken, []byte(")")}, {RightParenthesisToken, []byte(")")}}, "CALC(1 - (2*3))"},
+		{[]Token{{FunctionToken, []byte("max(")}, number("1"), delim('-'), {FunctionToken, []byte("var(")}, ident("--a"), {RightParenthesisToken, []byte(")")}, {RightParenthesisToken, []byte(")")}}, "max(1 - var(--a))"},
+		{[]Token{{FunctionToken, []byte("f(")}, ident("a"), delim('+'), ident("b"), {RightParenthesisToken, []byte(")")}}, "f(a+b)"},
+	}
+	for _, tt := range whitespaceTests {
+		t.Run(tt.expected, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := NewWriter(buf)
+			test.Error(t, w.Write(DeclarationGrammar, []byte("x"), tt.values))
+			test.Error(t, w.Close())
+			test.String(t, buf.String(), "x:"+tt.expected)
+
+			// tokens must not merge
+			p := NewParser(bytes.NewBufferString(buf.String()), true)
+			p.Next()
+			n := 0
+			for _, v := range p.Values() {
+				if v.TokenType != WhitespaceToken {
+					n++
+				}
+			}
+			test.T(t, n, len(tt.values)-strings.Count(tt.expected, ",a,")) // the whitespace token in the input is removed
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	var errorTests = []struct {
+		grammars []GrammarType
+		err      string
+	}{
+		{[]GrammarType{EndRulesetGrammar}, "css: unexpected EndRuleset"},
+		{[]GrammarType{BeginRulesetGrammar, EndAtRuleGrammar}, "css: unexpected EndAtRule"},
+		{[]GrammarType{QualifiedRuleGrammar, DeclarationGrammar}, "css: expected selector but got Declaration"},
+		{[]GrammarType{BeginAtRuleGrammar}, "css: unclosed block"},
+		{[]GrammarType{QualifiedRuleGrammar}, "css: unclosed block"},
+		{[]GrammarType{GrammarType(100)}, "css: unexpected Invalid(100)"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.err, func(t *testing.T) {
+			w := NewWriter(&bytes.Buffer{})
+			var err error
+			for _, gt := range tt.grammars {
+				if err = w.Write(gt, []byte("a"), nil); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = w.Close()
+			}
+			test.That(t, err != nil)
+			test.String(t, err.Error(), tt.err)
+
+			// sticky error
+			test.T(t, w.Write(DeclarationGrammar, []byte("a"), nil), err)
+			test.T(t, w.Close(), err)
+		})
+	}
+}
+
+func TestWriterFlush(t *testing.T) {
+	css := strings.Repeat(".a{color:red}", 1000)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	p := NewParser(bytes.NewBufferString(css), false)
+	for {
+		gt, _, data := p.Next()
+		if gt == ErrorGrammar {
+			break
+		}
+		test.Error(t, w.Write(gt, data, p.Values()))
+	}
+	test.That(t, 0 < buf.Len() && buf.Len() < len(css), "buffer must flush before Close")
+	test.Error(t, w.Close())
+	test.String(t, buf.String(), css)
+}
+
+func ExampleWriter() {
+	p := NewParser(bytes.NewBufferString("a{color:red;width:calc(100% - 2em)}@media print{a{color:black}}"), false)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(IndentFormat)
+	w.SetIndent("  ")
+	for {
+		gt, _, data := p.Next()
+		if gt == ErrorGrammar {
+			break
+		} else if err := w.Write(gt, data, p.Values()); err != nil {
+			panic(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	fmt.Println(buf.String())
+	// Output:
+	// a {
+	//   color: red;
+	//   width: calc(100% - 2em);
+	// }
+	//
+	// @media print {
+	//   a {
+	//     color: black;
+	//   }
+	// }
+}
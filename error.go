@@ -31,9 +31,27 @@ func NewError(r io.Reader, offset int, message string, a ...interface{}) *Error
 
 // NewErrorLexer creates a new error from an active Lexer.
 func NewErrorLexer(l *buffer.Lexer, message string, a ...interface{}) *Error {
-	r := buffer.NewReader(l.Bytes())
-	offset := l.Offset()
-	return NewError(r, offset, message, a...)
+	return NewErrorLexerOffset(l, l.Offset(), message, a...)
+}
+
+// NewErrorLexerOffset creates a new error at offset from an active Lexer.
+// When the Lexer reads from a stream, the context is limited to the data that is still buffered, that is the data since the last call to Free.
+func NewErrorLexerOffset(l *buffer.Lexer, offset int, message string, a ...interface{}) *Error {
+	z := l.Stream()
+	if z == nil {
+		return NewError(buffer.NewReader(l.Bytes()), offset, message, a...)
+	}
+
+	line, col, context, i := z.Position(offset)
+	if 0 < len(a) {
+		message = fmt.Sprintf(message, a...)
+	}
+	return &Error{
+		Message: message,
+		Line:    line,
+		Column:  col,
+		Context: positionContext(buffer.NewLexerBytes(append([]byte{}, context...)), line, i+1),
+	}
 }
 
 // Positions returns the line, column, and context of the error.
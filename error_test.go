@@ -32,6 +32,29 @@ func TestErrorLexer(t *testing.T) {
 	test.T(t, err.Error(), "message on line 1 and column 4\n    1: buffer\n          ^", "error")
 }
 
+func TestErrorLexerStream(t *testing.T) {
+	l := buffer.NewLexerStream(test.NewPlainReader(bytes.NewBufferString("line\nbuffer\nline")))
+	func() {
+		defer func() {
+			test.That(t, l.Retry(recover(), 0), "must read in the stream")
+		}()
+		l.Peek(0)
+	}()
+	l.Move(5)
+	l.Skip()
+	l.Free(l.ShiftLen())
+	l.Move(3)
+	err := NewErrorLexer(l, "message %d", 5)
+
+	line, column, context := err.Position()
+	test.T(t, line, 2, "line")
+	test.T(t, column, 4, "column")
+	test.T(t, "\n"+context, "\n    2: buffer\n          ^", "context")
+
+	err = NewErrorLexerOffset(l, 5, "message")
+	test.T(t, err.Error(), "message on line 2 and column 1\n    2: buffer\n       ^", "error")
+}
+
 func TestErrorMessages(t *testing.T) {
 	err := NewError(bytes.NewBufferString("buffer"), 3, "message %d", 5)
 	test.T(t, err.Error(), "message 5 on line 1 and column 4\n    1: buffer\n          ^", "error")
@@ -10,15 +10,114 @@ type Hash uint32
 
 // Unique hash definitions to be used instead of strings
 const (
-	Iframe    Hash = 0x6    // iframe
-	Math      Hash = 0x604  // math
-	Plaintext Hash = 0x1e09 // plaintext
-	Script    Hash = 0xa06  // script
-	Style     Hash = 0x1405 // style
-	Svg       Hash = 0x1903 // svg
-	Textarea  Hash = 0x2308 // textarea
-	Title     Hash = 0xf05  // title
-	Xmp       Hash = 0x1c03 // xmp
+	A          Hash = 0x1001  // a
+	Address    Hash = 0x9407  // address
+	Applet     Hash = 0x8806  // applet
+	Area       Hash = 0xad04  // area
+	Article    Hash = 0xf507  // article
+	Aside      Hash = 0xb005  // aside
+	B          Hash = 0x2401  // b
+	Base       Hash = 0x7c04  // base
+	Basefont   Hash = 0x7c08  // basefont
+	Bgsound    Hash = 0x7007  // bgsound
+	Big        Hash = 0xfc03  // big
+	Blockquote Hash = 0xd20a  // blockquote
+	Body       Hash = 0x6404  // body
+	Br         Hash = 0x4802  // br
+	Button     Hash = 0x6806  // button
+	Caption    Hash = 0x4007  // caption
+	Center     Hash = 0x9e06  // center
+	Code       Hash = 0x3904  // code
+	Col        Hash = 0x5203  // col
+	Colgroup   Hash = 0x5208  // colgroup
+	Dd         Hash = 0x9502  // dd
+	Details    Hash = 0xb307  // details
+	Dialog     Hash = 0x8e06  // dialog
+	Dir        Hash = 0x7603  // dir
+	Div        Hash = 0xff03  // div
+	Dl         Hash = 0x4a02  // dl
+	Dt         Hash = 0x2602  // dt
+	Em         Hash = 0x2202  // em
+	Embed      Hash = 0x2205  // embed
+	Fieldset   Hash = 0x10208 // fieldset
+	Figcaption Hash = 0x3d0a  // figcaption
+	Figure     Hash = 0x10a06 // figure
+	Font       Hash = 0x8004  // font
+	Footer     Hash = 0xcc06  // footer
+	Form       Hash = 0x2c04  // form
+	Frame      Hash = 0xc405  // frame
+	Frameset   Hash = 0xc408  // frameset
+	H1         Hash = 0x11002 // h1
+	H2         Hash = 0x11202 // h2
+	H3         Hash = 0x11402 // h3
+	H4         Hash = 0x11602 // h4
+	H5         Hash = 0x11802 // h5
+	H6         Hash = 0x11a02 // h6
+	Head       Hash = 0x3204  // head
+	Header     Hash = 0x3206  // header
+	Hgroup     Hash = 0x1306  // hgroup
+	Hr         Hash = 0x11c02 // hr
+	Html       Hash = 0x11e04 // html
+	I          Hash = 0x401   // i
+	Iframe     Hash = 0xe206  // iframe
+	Image      Hash = 0x12205 // image
+	Img        Hash = 0x12703 // img
+	Input      Hash = 0x5f05  // input
+	Keygen     Hash = 0xbd06  // keygen
+	Li         Hash = 0x4b02  // li
+	Link       Hash = 0xea04  // link
+	Listing    Hash = 0x4b07  // listing
+	Main       Hash = 0x5d04  // main
+	Marquee    Hash = 0x12a07 // marquee
+	Math       Hash = 0x2f04  // math
+	Menu       Hash = 0xe604  // menu
+	Meta       Hash = 0xee04  // meta
+	Nav        Hash = 0x6d03  // nav
+	Nobr       Hash = 0x4604  // nobr
+	Noembed    Hash = 0x2007  // noembed
+	Noframes   Hash = 0xc208  // noframes
+	Noscript   Hash = 0x608   // noscript
+	Object     Hash = 0x13106 // object
+	Ol         Hash = 0x5302  // ol
+	Optgroup   Hash = 0x13708 // optgroup
+	Option     Hash = 0x1b06  // option
+	P          Hash = 0xc01   // p
+	Param      Hash = 0x5905  // param
+	Plaintext  Hash = 0xa409  // plaintext
+	Pre        Hash = 0x1803  // pre
+	Rb         Hash = 0xd102  // rb
+	Rp         Hash = 0xa302  // rp
+	Rt         Hash = 0x3702  // rt
+	Rtc        Hash = 0x3703  // rtc
+	Ruby       Hash = 0x7804  // ruby
+	S          Hash = 0x1     // s
+	Script     Hash = 0x806   // script
+	Search     Hash = 0xe06   // search
+	Section    Hash = 0x7     // section
+	Select     Hash = 0x13f06 // select
+	Small      Hash = 0x14505 // small
+	Source     Hash = 0x9a06  // source
+	Strike     Hash = 0xb906  // strike
+	Strong     Hash = 0x14a06 // strong
+	Style      Hash = 0x15005 // style
+	Summary    Hash = 0x15507 // summary
+	Svg        Hash = 0x15c03 // svg
+	Table      Hash = 0xf005  // table
+	Tbody      Hash = 0x6305  // tbody
+	Td         Hash = 0x8d02  // td
+	Template   Hash = 0xda08  // template
+	Textarea   Hash = 0xa908  // textarea
+	Tfoot      Hash = 0xcb05  // tfoot
+	Th         Hash = 0x3102  // th
+	Thead      Hash = 0x3105  // thead
+	Title      Hash = 0x8305  // title
+	Tr         Hash = 0x2702  // tr
+	Track      Hash = 0x2705  // track
+	Tt         Hash = 0x6a02  // tt
+	U          Hash = 0x1701  // u
+	Ul         Hash = 0xe902  // ul
+	Wbr        Hash = 0x15f03 // wbr
+	Xmp        Hash = 0x16203 // xmp
 )
 
 // String returns the hash' name.
@@ -65,17 +164,121 @@ NEXT:
 }
 
 const _Hash_hash0 = 0x9acb0442
-const _Hash_maxLen = 9
-const _Hash_text = "iframemathscriptitlestylesvgxmplaintextarea"
+const _Hash_maxLen = 10
+const _Hash_text = "sectionoscriptsearchgroupreoptionoembedtrackformatheadertcod" +
+	"efigcaptionobrdlistingcolgrouparamainputbodybuttonavbgsoundi" +
+	"rubybasefontitleappletdialogaddressourcenterplaintextareasid" +
+	"etailstrikeygenoframesetfooterblockquotemplateiframenulinkme" +
+	"tablearticlebigdivfieldsetfigureh1h2h3h4h5h6hrhtmlimageimgma" +
+	"rqueeobjectoptgroupselectsmallstrongstylesummarysvgwbrxmp"
 
-var _Hash_table = [1 << 4]Hash{
-	0x0: 0x2308, // textarea
-	0x2: 0x6,    // iframe
-	0x4: 0xf05,  // title
-	0x5: 0x1e09, // plaintext
-	0x7: 0x1405, // style
-	0x8: 0x604,  // math
-	0x9: 0xa06,  // script
-	0xa: 0x1903, // svg
-	0xb: 0x1c03, // xmp
+var _Hash_table = [1 << 8]Hash{
+	0x0:  0xa908,  // textarea
+	0x4:  0x11c02, // hr
+	0x6:  0xd20a,  // blockquote
+	0xa:  0x6806,  // button
+	0xb:  0x5208,  // colgroup
+	0xe:  0x15507, // summary
+	0xf:  0xee04,  // meta
+	0x19: 0x1001,  // a
+	0x1a: 0x4a02,  // dl
+	0x1d: 0x3703,  // rtc
+	0x1e: 0x11602, // h4
+	0x1f: 0x7c04,  // base
+	0x20: 0x16203, // xmp
+	0x22: 0xcb05,  // tfoot
+	0x23: 0xc208,  // noframes
+	0x24: 0xda08,  // template
+	0x2a: 0xe06,   // search
+	0x31: 0x2705,  // track
+	0x33: 0xc405,  // frame
+	0x35: 0xff03,  // div
+	0x38: 0xf507,  // article
+	0x3a: 0xea04,  // link
+	0x3e: 0x10208, // fieldset
+	0x41: 0x4604,  // nobr
+	0x42: 0xe206,  // iframe
+	0x44: 0x11202, // h2
+	0x46: 0x12a07, // marquee
+	0x47: 0x15005, // style
+	0x49: 0x12703, // img
+	0x4b: 0x7603,  // dir
+	0x4f: 0x2007,  // noembed
+	0x50: 0x2702,  // tr
+	0x51: 0x14505, // small
+	0x52: 0x2602,  // dt
+	0x55: 0xcc06,  // footer
+	0x56: 0x4802,  // br
+	0x57: 0x806,   // script
+	0x59: 0x12205, // image
+	0x5a: 0x13708, // optgroup
+	0x5b: 0x3206,  // header
+	0x60: 0x2401,  // b
+	0x61: 0x5302,  // ol
+	0x65: 0xa409,  // plaintext
+	0x66: 0x6305,  // tbody
+	0x68: 0x5203,  // col
+	0x6b: 0x4007,  // caption
+	0x6c: 0x3105,  // thead
+	0x6e: 0x8305,  // title
+	0x78: 0x15c03, // svg
+	0x7a: 0x7804,  // ruby
+	0x7f: 0x9a06,  // source
+	0x82: 0x9502,  // dd
+	0x86: 0x9407,  // address
+	0x88: 0xb906,  // strike
+	0x8a: 0x3d0a,  // figcaption
+	0x8b: 0x5d04,  // main
+	0x8f: 0x2205,  // embed
+	0x91: 0xc408,  // frameset
+	0x92: 0x4b07,  // listing
+	0x93: 0x5905,  // param
+	0x94: 0x11802, // h5
+	0x95: 0x1701,  // u
+	0x97: 0x1,     // s
+	0x98: 0x2202,  // em
+	0x99: 0x13106, // object
+	0x9e: 0x10a06, // figure
+	0xa0: 0xa302,  // rp
+	0xa2: 0x8e06,  // dialog
+	0xa4: 0xfc03,  // big
+	0xa6: 0x6404,  // body
+	0xa7: 0xad04,  // area
+	0xac: 0x3204,  // head
+	0xb0: 0xb307,  // details
+	0xb1: 0x401,   // i
+	0xb3: 0x8d02,  // td
+	0xb5: 0xe902,  // ul
+	0xb6: 0xc01,   // p
+	0xb9: 0x4b02,  // li
+	0xbd: 0x7,     // section
+	0xbf: 0x11e04, // html
+	0xc2: 0x6a02,  // tt
+	0xc4: 0x1306,  // hgroup
+	0xc7: 0x9e06,  // center
+	0xcb: 0x1803,  // pre
+	0xd3: 0x8004,  // font
+	0xd4: 0x7007,  // bgsound
+	0xd5: 0x14a06, // strong
+	0xd7: 0x11402, // h3
+	0xd8: 0x2f04,  // math
+	0xde: 0x3102,  // th
+	0xdf: 0x15f03, // wbr
+	0xe3: 0x7c08,  // basefont
+	0xe5: 0xe604,  // menu
+	0xe6: 0x5f05,  // input
+	0xe8: 0x8806,  // applet
+	0xe9: 0xbd06,  // keygen
+	0xeb: 0x6d03,  // nav
+	0xec: 0x3702,  // rt
+	0xef: 0x3904,  // code
+	0xf1: 0x13f06, // select
+	0xf2: 0xb005,  // aside
+	0xf3: 0xf005,  // table
+	0xf4: 0x2c04,  // form
+	0xf6: 0xd102,  // rb
+	0xf7: 0x1b06,  // option
+	0xf8: 0x11a02, // h6
+	0xfb: 0x608,   // noscript
+	0xfd: 0x11002, // h1
 }
@@ -60,11 +60,13 @@ func (tt TokenType) String() string {
 
 // Lexer is the state for the lexer.
 type Lexer struct {
-	r   *buffer.Lexer
-	err error
+	r      *buffer.Lexer
+	stream bool // reading from a stream, see nextStream
+	err    error
 
-	rawTag Hash
-	inTag  bool
+	rawTag  Hash
+	inTag   bool
+	foreign bool // lex the contents of <svg> and <math> as tags instead of returning a SvgToken or MathToken, see Parse
 
 	text    []byte
 	attrVal []byte
@@ -77,6 +79,15 @@ func NewLexer(r io.Reader) *Lexer {
 	}
 }
 
+// NewStreamLexer returns a new Lexer that reads the HTML document from r in chunks, so that memory usage does not grow with the size of the document.
+// The data returned by Next, Text, and AttrVal points into the current chunk and is only valid until the next call to Next.
+func NewStreamLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		r:      buffer.NewLexerStream(r),
+		stream: true,
+	}
+}
+
 // Err returns the error encountered during lexing, this is often io.EOF but also other errors can be returned.
 func (l *Lexer) Err() error {
 	if l.err != nil {
@@ -107,6 +118,9 @@ func (l *Lexer) AttrVal() []byte {
 
 // Next returns the next Token. It returns ErrorToken when an error was encountered. Using Err() one can retrieve the error message.
 func (l *Lexer) Next() (TokenType, []byte) {
+	if l.stream {
+		return l.nextStream()
+	}
 	l.text = nil
 	var c byte
 	if l.inTag {
@@ -181,6 +195,27 @@ func (l *Lexer) Next() (TokenType, []byte) {
 	}
 }
 
+// nextStream calls Next when reading from a stream. When Next runs out of buffered data, the lexer including its raw text tag and in-tag state is restored, more data is read in, and the token is lexed again.
+func (l *Lexer) nextStream() (tt TokenType, data []byte) {
+	l.r.Free(l.r.ShiftLen())
+	offset := l.r.Offset()
+	state := *l
+	defer func() {
+		if err := recover(); err != nil {
+			if !l.r.Retry(err, offset) {
+				panic(err)
+			}
+			*l = state
+			tt, data = l.nextStream()
+		}
+	}()
+
+	l.stream = false
+	tt, data = l.Next()
+	l.stream = true
+	return
+}
+
 ////////////////////////////////////////////////////////////////
 
 // The following functions follow the specifications at https://html.spec.whatwg.org/multipage/parsing.html
@@ -336,7 +371,7 @@ func (l *Lexer) shiftStartTag() (TokenType, []byte) {
 		l.r.Move(1)
 	}
 	l.text = parse.ToLower(l.r.Lexeme()[1:])
-	if h := ToHash(l.text); h == Textarea || h == Title || h == Style || h == Xmp || h == Iframe || h == Script || h == Plaintext || h == Svg || h == Math {
+	if h := ToHash(l.text); h == Textarea || h == Title || h == Style || h == Xmp || h == Iframe || h == Script || h == Plaintext || (h == Svg || h == Math) && !l.foreign {
 		if h == Svg || h == Math {
 			data := l.shiftXml(h)
 			if l.err != nil {
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/internal/testio"
 	"github.com/tdewolff/test"
 )
 
@@ -290,6 +292,52 @@ func TestOffset(t *testing.T) {
 	test.T(t, l.Offset(), 26) // </div>
 }
 
+func TestStreamLexer(t *testing.T) {
+	html := `<!DOCTYPE html><html><head><title>a < b</title><script>if (a</b) {}</script><style>p{}</style></head><body class="x" data-a='1' b=c d>text &amp; <!--c--><p>para<br/><svg><![CDATA[x]]></svg><textarea></div></textarea></body></html>`
+	l := NewLexer(bytes.NewBufferString(html))
+	z := NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString(html)))
+	for {
+		tt, data := l.Next()
+		ttStream, dataStream := z.Next()
+		test.T(t, ttStream, tt, "token types must match")
+		test.String(t, string(dataStream), string(data), "token data must match")
+		test.String(t, string(z.Text()), string(l.Text()), "texts must match")
+		test.String(t, string(z.AttrVal()), string(l.AttrVal()), "attribute values must match")
+		test.T(t, z.Offset(), l.Offset(), "offsets must match")
+		if tt == ErrorToken {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+
+	z = NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString("<p>\n<svg>a\x00</svg>")))
+	for tt, _ := z.Next(); tt != ErrorToken; tt, _ = z.Next() {
+	}
+	if perr, ok := z.Err().(*parse.Error); ok {
+		line, col, _ := perr.Position()
+		test.T(t, line, 2)
+		test.T(t, col, 7)
+	} else {
+		test.Fail(t, "bad error:", z.Err())
+	}
+}
+
+func TestStreamLexerMemory(t *testing.T) {
+	item := []byte(`<div class="a">text &amp; more<!--c--><br/></div>`)
+	r := io.MultiReader(bytes.NewBufferString("<html>"), testio.NewRepeatReader(item, 500000), bytes.NewBufferString("</html>"))
+
+	l := NewStreamLexer(r)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for tt, _ := l.Next(); tt != ErrorToken; tt, _ = l.Next() {
+			n++
+		}
+	})
+	test.T(t, l.Err(), io.EOF)
+	test.T(t, n, 8*500000+3)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*500000, "bytes of input")
+}
+
 ////////////////////////////////////////////////////////////////
 
 var J int
@@ -0,0 +1,2213 @@
+package html
+
+import (
+	"bytes"
+	stdhtml "html"
+	"io"
+	"strconv"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// NodeType determines the type of node, eg. an element or a text node.
+type NodeType uint32
+
+// NodeType values.
+const (
+	ErrorNode NodeType = iota
+	DocumentNode
+	DoctypeNode
+	ElementNode
+	TextNode
+	CommentNode
+)
+
+// String returns the string representation of a NodeType.
+func (nt NodeType) String() string {
+	switch nt {
+	case ErrorNode:
+		return "Error"
+	case DocumentNode:
+		return "Document"
+	case DoctypeNode:
+		return "Doctype"
+	case ElementNode:
+		return "Element"
+	case TextNode:
+		return "Text"
+	case CommentNode:
+		return "Comment"
+	}
+	return "Invalid(" + strconv.Itoa(int(nt)) + ")"
+}
+
+// Namespace determines the namespace of an element.
+type Namespace uint32
+
+// Namespace values.
+const (
+	HTMLNamespace Namespace = iota
+	SVGNamespace
+	MathMLNamespace
+)
+
+// String returns the string representation of a Namespace.
+func (ns Namespace) String() string {
+	switch ns {
+	case HTMLNamespace:
+		return "html"
+	case SVGNamespace:
+		return "svg"
+	case MathMLNamespace:
+		return "math"
+	}
+	return "Invalid(" + strconv.Itoa(int(ns)) + ")"
+}
+
+// Attr is an attribute of an element. Val is nil for attributes without a value.
+type Attr struct {
+	Key, Val []byte
+}
+
+// Node is a node in the document tree. Data holds the element name (lowercased for HTML elements), the text, the comment, or the doctype.
+type Node struct {
+	Type      NodeType
+	Namespace Namespace
+	Hash      Hash // hash of the element name, zero for unknown elements
+	Data      []byte
+	Attrs     []Attr
+
+	Parent, FirstChild, LastChild, PrevSibling, NextSibling *Node
+}
+
+// Attr returns the value of the attribute with the given key and whether it exists.
+func (n *Node) Attr(key string) ([]byte, bool) {
+	for _, attr := range n.Attrs {
+		if string(attr.Key) == key {
+			return attr.Val, true
+		}
+	}
+	return nil, false
+}
+
+// AppendChild adds c as the last child of n. It panics if c already has a parent.
+func (n *Node) AppendChild(c *Node) {
+	n.InsertBefore(c, nil)
+}
+
+// InsertBefore inserts c as a child of n, immediately before ref, or as the last child if ref is nil. It panics if c already has a parent.
+func (n *Node) InsertBefore(c, ref *Node) {
+	if c.Parent != nil || c.PrevSibling != nil || c.NextSibling != nil {
+		panic("html: InsertBefore called for an attached child Node")
+	}
+	var prev, next *Node
+	if ref != nil {
+		prev, next = ref.PrevSibling, ref
+	} else {
+		prev = n.LastChild
+	}
+	if prev != nil {
+		prev.NextSibling = c
+	} else {
+		n.FirstChild = c
+	}
+	if next != nil {
+		next.PrevSibling = c
+	} else {
+		n.LastChild = c
+	}
+	c.Parent, c.PrevSibling, c.NextSibling = n, prev, next
+}
+
+// RemoveChild removes the child c from n. It panics if c is not a child of n.
+func (n *Node) RemoveChild(c *Node) {
+	if c.Parent != n {
+		panic("html: RemoveChild called for a non-child Node")
+	}
+	if c.PrevSibling != nil {
+		c.PrevSibling.NextSibling = c.NextSibling
+	} else {
+		n.FirstChild = c.NextSibling
+	}
+	if c.NextSibling != nil {
+		c.NextSibling.PrevSibling = c.PrevSibling
+	} else {
+		n.LastChild = c.PrevSibling
+	}
+	c.Parent, c.PrevSibling, c.NextSibling = nil, nil, nil
+}
+
+// String returns the HTML serialization of the node and its descendants. Text and attribute values are escaped, except for the raw text of elements such as <script> and <style>.
+func (n *Node) String() string {
+	buf := &bytes.Buffer{}
+	n.render(buf)
+	return buf.String()
+}
+
+func (n *Node) render(buf *bytes.Buffer) {
+	switch n.Type {
+	case DoctypeNode:
+		buf.WriteString("<!doctype ")
+		buf.Write(n.Data)
+		buf.WriteByte('>')
+		return
+	case CommentNode:
+		buf.WriteString("<!--")
+		buf.Write(n.Data)
+		buf.WriteString("-->")
+		return
+	case TextNode:
+		if p := n.Parent; p != nil && p.isOneOf(Iframe, Noembed, Noframes, Noscript, Plaintext, Script, Style, Xmp) {
+			buf.Write(n.Data)
+		} else {
+			escape(buf, n.Data, false)
+		}
+		return
+	case ElementNode:
+		buf.WriteByte('<')
+		buf.Write(n.Data)
+		for _, attr := range n.Attrs {
+			buf.WriteByte(' ')
+			buf.Write(attr.Key)
+			if attr.Val != nil {
+				buf.WriteString("=\"")
+				escape(buf, attr.Val, true)
+				buf.WriteByte('"')
+			}
+		}
+		if n.Namespace != HTMLNamespace && n.FirstChild == nil {
+			buf.WriteString("/>")
+			return
+		}
+		buf.WriteByte('>')
+		if n.Namespace == HTMLNamespace && isVoid(n.Hash) {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		c.render(buf)
+	}
+	if n.Type == ElementNode {
+		buf.WriteString("</")
+		buf.Write(n.Data)
+		buf.WriteByte('>')
+	}
+}
+
+// escape writes b with &, non-breaking spaces, and either double quotes for attribute values or < and > for text escaped.
+func escape(buf *bytes.Buffer, b []byte, attr bool) {
+	start := 0
+	for i := 0; i < len(b); i++ {
+		var ref string
+		switch c := b[i]; {
+		case c == '&':
+			ref = "&amp;"
+		case c == '"' && attr:
+			ref = "&quot;"
+		case c == '<' && !attr:
+			ref = "&lt;"
+		case c == '>' && !attr:
+			ref = "&gt;"
+		case c == 0xC2 && i+1 < len(b) && b[i+1] == 0xA0:
+			ref = "&nbsp;"
+		default:
+			continue
+		}
+		buf.Write(b[start:i])
+		buf.WriteString(ref)
+		if ref == "&nbsp;" {
+			i++
+		}
+		start = i + 1
+	}
+	buf.Write(b[start:])
+}
+
+func (n *Node) is(h Hash) bool {
+	return n.Type == ElementNode && n.Namespace == HTMLNamespace && n.Hash == h
+}
+
+func (n *Node) isOneOf(hs ...Hash) bool {
+	if n.Type != ElementNode || n.Namespace != HTMLNamespace {
+		return false
+	}
+	for _, h := range hs {
+		if n.Hash == h {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *Node) clone() *Node {
+	return &Node{Type: n.Type, Namespace: n.Namespace, Hash: n.Hash, Data: n.Data, Attrs: append([]Attr{}, n.Attrs...)}
+}
+
+func isVoid(h Hash) bool {
+	switch h {
+	case Area, Base, Basefont, Bgsound, Br, Col, Embed, Frame, Hr, Img, Input, Keygen, Link, Meta, Param, Source, Track, Wbr:
+		return true
+	}
+	return false
+}
+
+// isSpecial returns true for elements in the special category of the specification.
+func isSpecial(n *Node) bool {
+	if n.Namespace == MathMLNamespace {
+		switch string(n.Data) {
+		case "mi", "mo", "mn", "ms", "mtext", "annotation-xml":
+			return true
+		}
+		return false
+	} else if n.Namespace == SVGNamespace {
+		switch string(n.Data) {
+		case "foreignObject", "desc", "title":
+			return true
+		}
+		return false
+	}
+	switch n.Hash {
+	case Address, Applet, Area, Article, Aside, Base, Basefont, Bgsound, Blockquote, Body, Br, Button, Caption, Center, Col, Colgroup, Dd, Details, Dir, Div, Dl, Dt, Embed, Fieldset, Figcaption, Figure, Footer, Form, Frame, Frameset, H1, H2, H3, H4, H5, H6, Head, Header, Hgroup, Hr, Html, Iframe, Img, Input, Keygen, Li, Link, Listing, Main, Marquee, Menu, Meta, Nav, Noembed, Noframes, Noscript, Object, Ol, P, Param, Plaintext, Pre, Script, Search, Section, Select, Source, Style, Summary, Table, Tbody, Td, Template, Textarea, Tfoot, Th, Thead, Title, Tr, Track, Ul, Wbr, Xmp:
+		return true
+	}
+	return false
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// splitWhitespace splits the leading whitespace from the text.
+func splitWhitespace(b []byte) ([]byte, []byte) {
+	i := 0
+	for i < len(b) && isWhitespace(b[i]) {
+		i++
+	}
+	return b[:i], b[i:]
+}
+
+////////////////////////////////////////////////////////////////
+
+type token struct {
+	tt          TokenType // StartTagToken, EndTagToken, TextToken, CommentToken, DoctypeToken, or ErrorToken
+	hash        Hash
+	data        []byte // tag name, text, comment, or doctype
+	attrs       []Attr
+	selfClosing bool
+	cdata       []byte // text of a CDATA section, which is only a CDATA section in foreign content and a comment otherwise
+}
+
+type insertionMode func(*treeBuilder, *token) bool
+
+type scope int
+
+const (
+	defaultScope scope = iota
+	listItemScope
+	buttonScope
+	tableScope
+	selectScope
+)
+
+type treeBuilder struct {
+	l   *Lexer
+	doc *Node
+
+	oe  []*Node // stack of open elements
+	afe []*Node // list of active formatting elements, nil is a marker
+
+	head, form      *Node
+	mode            insertionMode
+	originalMode    insertionMode
+	templateModes   []insertionMode
+	framesetOK      bool
+	fosterParenting bool
+	skipNewline     bool
+	tableText       []byte
+}
+
+// Parse parses an HTML5 document following the tree construction algorithm of the specification, including implied tags, foster parenting, and the adoption agency algorithm, and returns the document node.
+// Character references in text and attribute values are decoded, and scripting is considered disabled. The contents of <svg> and <math> elements are parsed following the rules for foreign content into the SVG and MathML namespaces, so that HTML elements such as <p> break out of them and HTML integration points such as <foreignObject> contain HTML. The error is only non-nil for read errors.
+func Parse(r io.Reader) (*Node, error) {
+	b := &treeBuilder{
+		l:          NewLexer(r),
+		doc:        &Node{Type: DocumentNode},
+		mode:       initialMode,
+		framesetOK: true,
+	}
+	b.l.foreign = true
+	for {
+		tok := b.next()
+		if b.skipNewline {
+			b.skipNewline = false
+			if tok.tt == TextToken {
+				if bytes.HasPrefix(tok.data, []byte("\r\n")) {
+					tok.data = tok.data[2:]
+				} else if 0 < len(tok.data) && (tok.data[0] == '\n' || tok.data[0] == '\r') {
+					tok.data = tok.data[1:]
+				}
+				if len(tok.data) == 0 {
+					continue
+				}
+			}
+		}
+		for !b.dispatch(tok) {
+		}
+		if tok.tt == ErrorToken {
+			break
+		}
+	}
+	if err := b.l.Err(); err != io.EOF {
+		return nil, err
+	}
+	return b.doc, nil
+}
+
+func (b *treeBuilder) next() *token {
+	rawTag := b.l.rawTag
+	tt, data := b.l.Next()
+	switch tt {
+	case StartTagToken:
+		tok := &token{tt: StartTagToken, data: parse.Copy(b.l.Text())}
+		tok.hash = ToHash(tok.data)
+		for {
+			tt, _ = b.l.Next()
+			if tt != AttributeToken {
+				tok.selfClosing = tt == StartTagVoidToken
+				break
+			}
+			key := b.l.Text()
+			duplicate := false
+			for _, attr := range tok.attrs {
+				if bytes.Equal(attr.Key, key) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				tok.attrs = append(tok.attrs, Attr{parse.Copy(key), unescape(unquote(b.l.AttrVal()))})
+			}
+		}
+		return tok
+	case EndTagToken:
+		tok := &token{tt: EndTagToken, data: parse.Copy(b.l.Text())}
+		tok.hash = ToHash(tok.data)
+		return tok
+	case TextToken:
+		if rawTag == 0 && bytes.HasPrefix(data, []byte("<![CDATA[")) {
+			text := append([]byte("[CDATA["), b.l.Text()...)
+			if bytes.HasSuffix(data, []byte("]]>")) {
+				text = append(text, "]]"...)
+			}
+			return &token{tt: CommentToken, data: text, cdata: parse.Copy(b.l.Text())}
+		} else if rawTag == 0 || rawTag == Title || rawTag == Textarea {
+			// RCDATA
+			return &token{tt: TextToken, data: unescape(parse.Copy(data))}
+		}
+		return &token{tt: TextToken, data: parse.Copy(data)}
+	case CommentToken:
+		return &token{tt: tt, data: parse.Copy(b.l.Text())}
+	case DoctypeToken:
+		_, text := splitWhitespace(b.l.Text())
+		return &token{tt: tt, data: parse.Copy(text)}
+	}
+	return &token{tt: ErrorToken}
+}
+
+func unquote(b []byte) []byte {
+	if b == nil {
+		return nil
+	} else if 0 < len(b) && (b[0] == '"' || b[0] == '\'') {
+		if 1 < len(b) && b[len(b)-1] == b[0] {
+			return parse.Copy(b[1 : len(b)-1])
+		}
+		return parse.Copy(b[1:])
+	}
+	return parse.Copy(b)
+}
+
+// unescape decodes the character references in b.
+func unescape(b []byte) []byte {
+	if bytes.IndexByte(b, '&') == -1 {
+		return b
+	}
+	return []byte(stdhtml.UnescapeString(string(b)))
+}
+
+////////////////////////////////////////////////////////////////
+
+func (b *treeBuilder) top() *Node {
+	if len(b.oe) == 0 {
+		return b.doc
+	}
+	return b.oe[len(b.oe)-1]
+}
+
+func (b *treeBuilder) pop() {
+	b.oe = b.oe[:len(b.oe)-1]
+}
+
+// popUntil pops elements from the stack of open elements until an HTML element with one of the given names has been popped.
+func (b *treeBuilder) popUntil(hs ...Hash) {
+	for i := len(b.oe) - 1; 0 <= i; i-- {
+		if b.oe[i].isOneOf(hs...) {
+			b.oe = b.oe[:i]
+			return
+		}
+	}
+}
+
+func (b *treeBuilder) removeOpen(n *Node) {
+	if i := indexOf(b.oe, n); i != -1 {
+		b.oe = append(b.oe[:i], b.oe[i+1:]...)
+	}
+}
+
+func indexOf(list []*Node, n *Node) int {
+	for i := len(list) - 1; 0 <= i; i-- {
+		if list[i] == n {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *treeBuilder) hasTemplate() bool {
+	for _, n := range b.oe {
+		if n.is(Template) {
+			return true
+		}
+	}
+	return false
+}
+
+func isScopeBoundary(n *Node, s scope) bool {
+	if n.Namespace != HTMLNamespace {
+		return s != tableScope && s != selectScope && isSpecial(n)
+	}
+	switch s {
+	case tableScope:
+		return n.Hash == Html || n.Hash == Table || n.Hash == Template
+	case selectScope:
+		return n.Hash != Optgroup && n.Hash != Option
+	}
+	switch n.Hash {
+	case Applet, Caption, Html, Table, Td, Th, Marquee, Object, Template:
+		return true
+	case Ol, Ul:
+		return s == listItemScope
+	case Button:
+		return s == buttonScope
+	}
+	return false
+}
+
+// inScope returns true if an HTML element with one of the given names is in the specific scope.
+func (b *treeBuilder) inScope(s scope, hs ...Hash) bool {
+	for i := len(b.oe) - 1; 0 <= i; i-- {
+		if b.oe[i].isOneOf(hs...) {
+			return true
+		} else if isScopeBoundary(b.oe[i], s) {
+			return false
+		}
+	}
+	return false
+}
+
+func (b *treeBuilder) nodeInScope(n *Node) bool {
+	for i := len(b.oe) - 1; 0 <= i; i-- {
+		if b.oe[i] == n {
+			return true
+		} else if isScopeBoundary(b.oe[i], defaultScope) {
+			return false
+		}
+	}
+	return false
+}
+
+// generateImpliedEndTags pops elements with implied end tags, except for the given element.
+func (b *treeBuilder) generateImpliedEndTags(except Hash) {
+	for 0 < len(b.oe) {
+		n := b.top()
+		if n.Hash == except || !n.isOneOf(Dd, Dt, Li, Optgroup, Option, P, Rb, Rp, Rt, Rtc) {
+			return
+		}
+		b.pop()
+	}
+}
+
+func (b *treeBuilder) closePInButtonScope() {
+	if b.inScope(buttonScope, P) {
+		b.generateImpliedEndTags(P)
+		b.popUntil(P)
+	}
+}
+
+func (b *treeBuilder) clearStackBackTo(hs ...Hash) {
+	for 0 < len(b.oe) && !b.top().isOneOf(hs...) {
+		b.pop()
+	}
+}
+
+////////////////////////////////////////////////////////////////
+
+func newElement(tok *token) *Node {
+	return &Node{Type: ElementNode, Hash: tok.hash, Data: tok.data, Attrs: tok.attrs}
+}
+
+// insertionLocation returns the appropriate place for inserting a node, taking into account foster parenting.
+func (b *treeBuilder) insertionLocation() (*Node, *Node) {
+	target := b.top()
+	if b.fosterParenting && target.isOneOf(Table, Tbody, Tfoot, Thead, Tr) {
+		for i := len(b.oe) - 1; 0 <= i; i-- {
+			if b.oe[i].is(Template) {
+				return b.oe[i], nil
+			} else if b.oe[i].is(Table) {
+				if b.oe[i].Parent != nil {
+					return b.oe[i].Parent, b.oe[i]
+				}
+				return b.oe[i-1], nil
+			}
+		}
+		return b.oe[0], nil
+	}
+	return target, nil
+}
+
+func (b *treeBuilder) insert(n *Node) {
+	parent, before := b.insertionLocation()
+	parent.InsertBefore(n, before)
+}
+
+// insertElement inserts an HTML element for the token and pushes it onto the stack of open elements.
+func (b *treeBuilder) insertElement(tok *token) *Node {
+	n := newElement(tok)
+	b.insert(n)
+	b.oe = append(b.oe, n)
+	return n
+}
+
+// insertForeignElement inserts an SVG or MathML element for the token, fixing the case of its name and attributes, and pushes it onto the stack of open elements unless it is self-closing.
+func (b *treeBuilder) insertForeignElement(tok *token, ns Namespace) {
+	n := newElement(tok)
+	n.Namespace = ns
+	caseTable := mathMLAttrNames
+	if ns == SVGNamespace {
+		caseTable = svgAttrNames
+		if name, ok := svgTagNames[string(n.Data)]; ok {
+			n.Data = []byte(name)
+		}
+	}
+	for i, attr := range n.Attrs {
+		if key, ok := caseTable[string(attr.Key)]; ok {
+			n.Attrs[i].Key = []byte(key)
+		}
+	}
+	b.insert(n)
+	if !tok.selfClosing {
+		b.oe = append(b.oe, n)
+	}
+	b.l.rawTag = 0 // only HTML elements such as <style> contain raw text
+}
+
+func (b *treeBuilder) insertHTMLElement(h Hash) *Node {
+	return b.insertElement(&token{tt: StartTagToken, hash: h, data: []byte(h.String())})
+}
+
+func (b *treeBuilder) insertText(text []byte) {
+	if len(text) == 0 {
+		return
+	}
+	parent, before := b.insertionLocation()
+	if parent == b.doc {
+		return
+	}
+	prev := parent.LastChild
+	if before != nil {
+		prev = before.PrevSibling
+	}
+	if prev != nil && prev.Type == TextNode {
+		prev.Data = append(prev.Data, text...)
+		return
+	}
+	parent.InsertBefore(&Node{Type: TextNode, Data: text}, before)
+}
+
+func (b *treeBuilder) insertComment(data []byte) {
+	parent, before := b.insertionLocation()
+	parent.InsertBefore(&Node{Type: CommentNode, Data: data}, before)
+}
+
+// parseRawText inserts an element whose contents are raw text, which the lexer returns as a single text token.
+func (b *treeBuilder) parseRawText(tok *token) {
+	b.insertElement(tok)
+	b.originalMode = b.mode
+	b.mode = textMode
+}
+
+////////////////////////////////////////////////////////////////
+
+func (b *treeBuilder) pushFormatting(n *Node) {
+	// Noah's Ark clause: at most three identical elements after the last marker
+	count, earliest := 0, -1
+	for i := len(b.afe) - 1; 0 <= i && b.afe[i] != nil; i-- {
+		if b.afe[i].Hash == n.Hash && bytes.Equal(b.afe[i].Data, n.Data) && sameAttrs(b.afe[i].Attrs, n.Attrs) {
+			count++
+			earliest = i
+		}
+	}
+	if 3 <= count {
+		b.afe = append(b.afe[:earliest], b.afe[earliest+1:]...)
+	}
+	b.afe = append(b.afe, n)
+}
+
+func sameAttrs(a, b []Attr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, attrA := range a {
+		found := false
+		for _, attrB := range b {
+			if bytes.Equal(attrA.Key, attrB.Key) && bytes.Equal(attrA.Val, attrB.Val) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *treeBuilder) reconstructFormatting() {
+	if len(b.afe) == 0 {
+		return
+	}
+	i := len(b.afe) - 1
+	if b.afe[i] == nil || indexOf(b.oe, b.afe[i]) != -1 {
+		return
+	}
+	for 0 < i {
+		i--
+		if b.afe[i] == nil || indexOf(b.oe, b.afe[i]) != -1 {
+			i++
+			break
+		}
+	}
+	for ; i < len(b.afe); i++ {
+		n := b.afe[i].clone()
+		b.insert(n)
+		b.oe = append(b.oe, n)
+		b.afe[i] = n
+	}
+}
+
+func (b *treeBuilder) clearFormattingToMarker() {
+	for 0 < len(b.afe) {
+		n := b.afe[len(b.afe)-1]
+		b.afe = b.afe[:len(b.afe)-1]
+		if n == nil {
+			return
+		}
+	}
+}
+
+// adoptionAgency runs the adoption agency algorithm for an end tag of a formatting element. It returns false if the end tag must be handled as any other end tag.
+func (b *treeBuilder) adoptionAgency(tok *token) bool {
+	if cur := b.top(); cur.is(tok.hash) && indexOf(b.afe, cur) == -1 {
+		b.pop()
+		return true
+	}
+	for outer := 0; outer < 8; outer++ {
+		fi := -1
+		for i := len(b.afe) - 1; 0 <= i && b.afe[i] != nil; i-- {
+			if b.afe[i].is(tok.hash) {
+				fi = i
+				break
+			}
+		}
+		if fi == -1 {
+			return false
+		}
+		formatting := b.afe[fi]
+		oi := indexOf(b.oe, formatting)
+		if oi == -1 {
+			b.afe = append(b.afe[:fi], b.afe[fi+1:]...)
+			return true
+		} else if !b.nodeInScope(formatting) {
+			return true
+		}
+
+		var furthest *Node
+		fbi := -1
+		for i := oi + 1; i < len(b.oe); i++ {
+			if isSpecial(b.oe[i]) {
+				furthest, fbi = b.oe[i], i
+				break
+			}
+		}
+		if furthest == nil {
+			b.oe = b.oe[:oi]
+			b.afe = append(b.afe[:fi], b.afe[fi+1:]...)
+			return true
+		}
+
+		ancestor := b.oe[oi-1]
+		bookmark := fi
+		lastNode := furthest
+		ni := fbi
+		for inner := 1; ; inner++ {
+			ni--
+			node := b.oe[ni]
+			if node == formatting {
+				break
+			}
+			ai := indexOf(b.afe, node)
+			if 3 < inner && ai != -1 {
+				b.afe = append(b.afe[:ai], b.afe[ai+1:]...)
+				if ai < bookmark {
+					bookmark--
+				}
+				ai = -1
+			}
+			if ai == -1 {
+				b.oe = append(b.oe[:ni], b.oe[ni+1:]...)
+				continue
+			}
+			clone := node.clone()
+			b.afe[ai] = clone
+			b.oe[ni] = clone
+			if lastNode == furthest {
+				bookmark = ai + 1
+			}
+			if lastNode.Parent != nil {
+				lastNode.Parent.RemoveChild(lastNode)
+			}
+			clone.AppendChild(lastNode)
+			lastNode = clone
+		}
+
+		if lastNode.Parent != nil {
+			lastNode.Parent.RemoveChild(lastNode)
+		}
+		if ancestor.isOneOf(Table, Tbody, Tfoot, Thead, Tr) {
+			fosterParenting := b.fosterParenting
+			b.fosterParenting = true
+			oe := b.oe
+			b.oe = b.oe[:indexOf(b.oe, ancestor)+1]
+			b.insert(lastNode)
+			b.oe = oe
+			b.fosterParenting = fosterParenting
+		} else {
+			ancestor.AppendChild(lastNode)
+		}
+
+		clone := formatting.clone()
+		for c := furthest.FirstChild; c != nil; c = furthest.FirstChild {
+			furthest.RemoveChild(c)
+			clone.AppendChild(c)
+		}
+		furthest.AppendChild(clone)
+
+		if fi = indexOf(b.afe, formatting); fi != -1 {
+			b.afe = append(b.afe[:fi], b.afe[fi+1:]...)
+			if fi < bookmark {
+				bookmark--
+			}
+		}
+		b.afe = append(b.afe[:bookmark], append([]*Node{clone}, b.afe[bookmark:]...)...)
+
+		b.removeOpen(formatting)
+		fbi = indexOf(b.oe, furthest)
+		b.oe = append(b.oe[:fbi+1], append([]*Node{clone}, b.oe[fbi+1:]...)...)
+	}
+	return true
+}
+
+// anyOtherEndTag handles an end tag in body according to the "any other end tag" rule.
+func (b *treeBuilder) anyOtherEndTag(tok *token) {
+	for i := len(b.oe) - 1; 0 <= i; i-- {
+		n := b.oe[i]
+		if n.Namespace == HTMLNamespace && bytes.Equal(n.Data, tok.data) {
+			b.generateImpliedEndTags(tok.hash)
+			b.oe = b.oe[:i]
+			return
+		} else if isSpecial(n) {
+			return
+		}
+	}
+}
+
+func (b *treeBuilder) resetInsertionMode() {
+	for i := len(b.oe) - 1; 0 <= i; i-- {
+		n := b.oe[i]
+		last := i == 0
+		if n.Namespace == HTMLNamespace {
+			switch n.Hash {
+			case Select:
+				for j := i - 1; 0 < j; j-- {
+					if b.oe[j].is(Template) {
+						break
+					} else if b.oe[j].is(Table) {
+						b.mode = inSelectInTableMode
+						return
+					}
+				}
+				b.mode = inSelectMode
+				return
+			case Td, Th:
+				if !last {
+					b.mode = inCellMode
+					return
+				}
+			case Tr:
+				b.mode = inRowMode
+				return
+			case Tbody, Thead, Tfoot:
+				b.mode = inTableBodyMode
+				return
+			case Caption:
+				b.mode = inCaptionMode
+				return
+			case Colgroup:
+				b.mode = inColumnGroupMode
+				return
+			case Table:
+				b.mode = inTableMode
+				return
+			case Template:
+				b.mode = b.templateModes[len(b.templateModes)-1]
+				return
+			case Head:
+				if !last {
+					b.mode = inHeadMode
+					return
+				}
+			case Body:
+				b.mode = inBodyMode
+				return
+			case Frameset:
+				b.mode = inFramesetMode
+				return
+			case Html:
+				if b.head == nil {
+					b.mode = beforeHeadMode
+				} else {
+					b.mode = afterHeadMode
+				}
+				return
+			}
+		}
+		if last {
+			b.mode = inBodyMode
+			return
+		}
+	}
+	b.mode = inBodyMode
+}
+
+// dispatch processes the token according to the current insertion mode, or according to the rules for foreign content when the current node is an SVG or MathML element. It returns false if the token must be reprocessed.
+func (b *treeBuilder) dispatch(tok *token) bool {
+	n := b.top()
+	foreign := 0 < len(b.oe) && n.Namespace != HTMLNamespace
+	if tok.cdata != nil {
+		if foreign {
+			tok.tt, tok.data = TextToken, tok.cdata
+		}
+		tok.cdata = nil
+	}
+	if !foreign || tok.tt == ErrorToken ||
+		isTextIntegrationPoint(n) && (tok.tt == TextToken || tok.tt == StartTagToken && string(tok.data) != "mglyph" && string(tok.data) != "malignmark") ||
+		n.Namespace == MathMLNamespace && string(n.Data) == "annotation-xml" && tok.tt == StartTagToken && tok.hash == Svg ||
+		isHTMLIntegrationPoint(n) && (tok.tt == TextToken || tok.tt == StartTagToken) {
+		return b.mode(b, tok)
+	}
+	return foreignContent(b, tok)
+}
+
+// isTextIntegrationPoint returns true for MathML elements whose text and elements are parsed as HTML.
+func isTextIntegrationPoint(n *Node) bool {
+	if n.Namespace == MathMLNamespace {
+		switch string(n.Data) {
+		case "mi", "mo", "mn", "ms", "mtext":
+			return true
+		}
+	}
+	return false
+}
+
+// isHTMLIntegrationPoint returns true for SVG and MathML elements whose contents are parsed as HTML.
+func isHTMLIntegrationPoint(n *Node) bool {
+	if n.Namespace == SVGNamespace {
+		switch string(n.Data) {
+		case "foreignObject", "desc", "title":
+			return true
+		}
+	} else if n.Namespace == MathMLNamespace && string(n.Data) == "annotation-xml" {
+		encoding, _ := n.Attr("encoding")
+		return parse.EqualFold(encoding, []byte("text/html")) || parse.EqualFold(encoding, []byte("application/xhtml+xml"))
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////
+
+// The following insertion modes follow the specifications at https://html.spec.whatwg.org/multipage/parsing.html#tree-construction
+
+func initialMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		_, tok.data = splitWhitespace(tok.data)
+		if len(tok.data) == 0 {
+			return true
+		}
+	case CommentToken:
+		b.doc.AppendChild(&Node{Type: CommentNode, Data: tok.data})
+		return true
+	case DoctypeToken:
+		b.doc.AppendChild(&Node{Type: DoctypeNode, Data: tok.data})
+		b.mode = beforeHTMLMode
+		return true
+	}
+	b.mode = beforeHTMLMode
+	return false
+}
+
+func beforeHTMLMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case DoctypeToken:
+		return true
+	case CommentToken:
+		b.doc.AppendChild(&Node{Type: CommentNode, Data: tok.data})
+		return true
+	case TextToken:
+		_, tok.data = splitWhitespace(tok.data)
+		if len(tok.data) == 0 {
+			return true
+		}
+	case StartTagToken:
+		if tok.hash == Html {
+			b.insertElement(tok)
+			b.mode = beforeHeadMode
+			return true
+		}
+	case EndTagToken:
+		if tok.hash != Head && tok.hash != Body && tok.hash != Html && tok.hash != Br {
+			return true
+		}
+	}
+	b.insertHTMLElement(Html)
+	b.mode = beforeHeadMode
+	return false
+}
+
+func beforeHeadMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		_, tok.data = splitWhitespace(tok.data)
+		if len(tok.data) == 0 {
+			return true
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+		return true
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		if tok.hash == Html {
+			return inBodyMode(b, tok)
+		} else if tok.hash == Head {
+			b.head = b.insertElement(tok)
+			b.mode = inHeadMode
+			return true
+		}
+	case EndTagToken:
+		if tok.hash != Head && tok.hash != Body && tok.hash != Html && tok.hash != Br {
+			return true
+		}
+	}
+	b.head = b.insertHTMLElement(Head)
+	b.mode = inHeadMode
+	return false
+}
+
+func inHeadMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		var ws []byte
+		ws, tok.data = splitWhitespace(tok.data)
+		b.insertText(ws)
+		if len(tok.data) == 0 {
+			return true
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+		return true
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Base, Basefont, Bgsound, Link, Meta:
+			b.insertElement(tok)
+			b.pop()
+			return true
+		case Title, Style, Script:
+			b.parseRawText(tok)
+			return true
+		case Noframes:
+			b.insertElement(tok)
+			return true
+		case Noscript:
+			b.insertElement(tok)
+			b.mode = inHeadNoscriptMode
+			return true
+		case Template:
+			b.insertElement(tok)
+			b.afe = append(b.afe, nil)
+			b.framesetOK = false
+			b.mode = inTemplateMode
+			b.templateModes = append(b.templateModes, inTemplateMode)
+			return true
+		case Head:
+			return true
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Head:
+			b.pop()
+			b.mode = afterHeadMode
+			return true
+		case Template:
+			if !b.hasTemplate() {
+				return true
+			}
+			b.generateImpliedEndTags(0)
+			b.popUntil(Template)
+			b.clearFormattingToMarker()
+			b.templateModes = b.templateModes[:len(b.templateModes)-1]
+			b.resetInsertionMode()
+			return true
+		case Body, Html, Br:
+		default:
+			return true
+		}
+	}
+	b.pop()
+	b.mode = afterHeadMode
+	return false
+}
+
+func inHeadNoscriptMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Basefont, Bgsound, Link, Meta, Noframes, Style:
+			return inHeadMode(b, tok)
+		case Head, Noscript:
+			return true
+		}
+	case EndTagToken:
+		if tok.hash == Noscript {
+			b.pop()
+			b.mode = inHeadMode
+			return true
+		} else if tok.hash != Br {
+			return true
+		}
+	case CommentToken:
+		return inHeadMode(b, tok)
+	case TextToken:
+		var ws []byte
+		ws, tok.data = splitWhitespace(tok.data)
+		b.insertText(ws)
+		if len(tok.data) == 0 {
+			return true
+		}
+	}
+	b.pop()
+	b.mode = inHeadMode
+	return false
+}
+
+func afterHeadMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		var ws []byte
+		ws, tok.data = splitWhitespace(tok.data)
+		b.insertText(ws)
+		if len(tok.data) == 0 {
+			return true
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+		return true
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Body:
+			b.insertElement(tok)
+			b.framesetOK = false
+			b.mode = inBodyMode
+			return true
+		case Frameset:
+			b.insertElement(tok)
+			b.mode = inFramesetMode
+			return true
+		case Base, Basefont, Bgsound, Link, Meta, Noframes, Script, Style, Template, Title:
+			b.oe = append(b.oe, b.head)
+			consumed := inHeadMode(b, tok)
+			b.removeOpen(b.head)
+			return consumed
+		case Head:
+			return true
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Template:
+			return inHeadMode(b, tok)
+		case Body, Html, Br:
+		default:
+			return true
+		}
+	}
+	b.insertHTMLElement(Body)
+	b.mode = inBodyMode
+	return false
+}
+
+func inBodyMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		b.reconstructFormatting()
+		b.insertText(tok.data)
+		if _, rest := splitWhitespace(tok.data); 0 < len(rest) {
+			b.framesetOK = false
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+	case DoctypeToken:
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			if !b.hasTemplate() {
+				addMissingAttrs(b.oe[0], tok.attrs)
+			}
+		case Base, Basefont, Bgsound, Link, Meta, Noframes, Script, Style, Template, Title:
+			return inHeadMode(b, tok)
+		case Body:
+			if 1 < len(b.oe) && b.oe[1].is(Body) && !b.hasTemplate() {
+				b.framesetOK = false
+				addMissingAttrs(b.oe[1], tok.attrs)
+			}
+		case Frameset:
+			if 1 < len(b.oe) && b.oe[1].is(Body) && b.framesetOK {
+				if body := b.oe[1]; body.Parent != nil {
+					body.Parent.RemoveChild(body)
+				}
+				b.oe = b.oe[:1]
+				b.insertElement(tok)
+				b.mode = inFramesetMode
+			}
+		case Address, Article, Aside, Blockquote, Center, Details, Dialog, Dir, Div, Dl, Fieldset, Figcaption, Figure, Footer, Header, Hgroup, Main, Menu, Nav, Ol, P, Search, Section, Summary, Ul:
+			b.closePInButtonScope()
+			b.insertElement(tok)
+		case H1, H2, H3, H4, H5, H6:
+			b.closePInButtonScope()
+			if b.top().isOneOf(H1, H2, H3, H4, H5, H6) {
+				b.pop()
+			}
+			b.insertElement(tok)
+		case Pre, Listing:
+			b.closePInButtonScope()
+			b.insertElement(tok)
+			b.skipNewline = true
+			b.framesetOK = false
+		case Form:
+			hasTemplate := b.hasTemplate()
+			if b.form == nil || hasTemplate {
+				b.closePInButtonScope()
+				n := b.insertElement(tok)
+				if !hasTemplate {
+					b.form = n
+				}
+			}
+		case Li, Dd, Dt:
+			b.framesetOK = false
+			for i := len(b.oe) - 1; 0 <= i; i-- {
+				n := b.oe[i]
+				if tok.hash == Li && n.is(Li) || tok.hash != Li && n.isOneOf(Dd, Dt) {
+					b.generateImpliedEndTags(n.Hash)
+					b.popUntil(n.Hash)
+					break
+				} else if isSpecial(n) && !n.isOneOf(Address, Div, P) {
+					break
+				}
+			}
+			b.closePInButtonScope()
+			b.insertElement(tok)
+		case Plaintext:
+			b.closePInButtonScope()
+			b.insertElement(tok)
+		case Button:
+			if b.inScope(defaultScope, Button) {
+				b.generateImpliedEndTags(0)
+				b.popUntil(Button)
+			}
+			b.reconstructFormatting()
+			b.insertElement(tok)
+			b.framesetOK = false
+		case A:
+			for i := len(b.afe) - 1; 0 <= i && b.afe[i] != nil; i-- {
+				if n := b.afe[i]; n.is(A) {
+					b.adoptionAgency(&token{tt: EndTagToken, hash: A, data: []byte("a")})
+					if i := indexOf(b.afe, n); i != -1 {
+						b.afe = append(b.afe[:i], b.afe[i+1:]...)
+					}
+					b.removeOpen(n)
+					break
+				}
+			}
+			b.reconstructFormatting()
+			b.pushFormatting(b.insertElement(tok))
+		case B, Big, Code, Em, Font, I, S, Small, Strike, Strong, Tt, U:
+			b.reconstructFormatting()
+			b.pushFormatting(b.insertElement(tok))
+		case Nobr:
+			b.reconstructFormatting()
+			if b.inScope(defaultScope, Nobr) {
+				b.adoptionAgency(&token{tt: EndTagToken, hash: Nobr, data: []byte("nobr")})
+				b.reconstructFormatting()
+			}
+			b.pushFormatting(b.insertElement(tok))
+		case Applet, Marquee, Object:
+			b.reconstructFormatting()
+			b.insertElement(tok)
+			b.afe = append(b.afe, nil)
+			b.framesetOK = false
+		case Table:
+			b.closePInButtonScope()
+			b.insertElement(tok)
+			b.framesetOK = false
+			b.mode = inTableMode
+		case Area, Br, Embed, Img, Keygen, Wbr, Input:
+			b.reconstructFormatting()
+			b.insertElement(tok)
+			b.pop()
+			if val, _ := getAttr(tok.attrs, "type"); tok.hash != Input || !parse.EqualFold(val, []byte("hidden")) {
+				b.framesetOK = false
+			}
+		case Param, Source, Track:
+			b.insertElement(tok)
+			b.pop()
+		case Hr:
+			b.closePInButtonScope()
+			b.insertElement(tok)
+			b.pop()
+			b.framesetOK = false
+		case Image:
+			tok.hash, tok.data = Img, []byte("img")
+			return false
+		case Textarea:
+			b.parseRawText(tok)
+			b.skipNewline = true
+			b.framesetOK = false
+		case Xmp:
+			b.closePInButtonScope()
+			b.reconstructFormatting()
+			b.framesetOK = false
+			b.parseRawText(tok)
+		case Iframe:
+			b.framesetOK = false
+			b.parseRawText(tok)
+		case Select:
+			b.reconstructFormatting()
+			b.insertElement(tok)
+			b.framesetOK = false
+			b.resetInsertionMode()
+		case Optgroup, Option:
+			if b.top().is(Option) {
+				b.pop()
+			}
+			b.reconstructFormatting()
+			b.insertElement(tok)
+		case Rb, Rtc:
+			if b.inScope(defaultScope, Ruby) {
+				b.generateImpliedEndTags(0)
+			}
+			b.insertElement(tok)
+		case Rp, Rt:
+			if b.inScope(defaultScope, Ruby) {
+				b.generateImpliedEndTags(Rtc)
+			}
+			b.insertElement(tok)
+		case Math:
+			b.reconstructFormatting()
+			b.insertForeignElement(tok, MathMLNamespace)
+		case Svg:
+			b.reconstructFormatting()
+			b.insertForeignElement(tok, SVGNamespace)
+		case Caption, Col, Colgroup, Frame, Head, Tbody, Td, Tfoot, Th, Thead, Tr:
+		default:
+			b.reconstructFormatting()
+			b.insertElement(tok)
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Template:
+			return inHeadMode(b, tok)
+		case Body, Html:
+			if b.inScope(defaultScope, Body) {
+				b.mode = afterBodyMode
+				return tok.hash == Body
+			}
+		case Address, Article, Aside, Blockquote, Button, Center, Details, Dialog, Dir, Div, Dl, Fieldset, Figcaption, Figure, Footer, Header, Hgroup, Listing, Main, Menu, Nav, Ol, Pre, Search, Section, Summary, Ul:
+			if b.inScope(defaultScope, tok.hash) {
+				b.generateImpliedEndTags(0)
+				b.popUntil(tok.hash)
+			}
+		case Form:
+			if b.hasTemplate() {
+				if b.inScope(defaultScope, Form) {
+					b.generateImpliedEndTags(0)
+					b.popUntil(Form)
+				}
+			} else {
+				n := b.form
+				b.form = nil
+				if n != nil && b.nodeInScope(n) {
+					b.generateImpliedEndTags(0)
+					b.removeOpen(n)
+				}
+			}
+		case P:
+			if !b.inScope(buttonScope, P) {
+				b.insertHTMLElement(P)
+			}
+			b.generateImpliedEndTags(P)
+			b.popUntil(P)
+		case Li:
+			if b.inScope(listItemScope, Li) {
+				b.generateImpliedEndTags(Li)
+				b.popUntil(Li)
+			}
+		case Dd, Dt:
+			if b.inScope(defaultScope, tok.hash) {
+				b.generateImpliedEndTags(tok.hash)
+				b.popUntil(tok.hash)
+			}
+		case H1, H2, H3, H4, H5, H6:
+			if b.inScope(defaultScope, H1, H2, H3, H4, H5, H6) {
+				b.generateImpliedEndTags(0)
+				b.popUntil(H1, H2, H3, H4, H5, H6)
+			}
+		case A, B, Big, Code, Em, Font, I, Nobr, S, Small, Strike, Strong, Tt, U:
+			if !b.adoptionAgency(tok) {
+				b.anyOtherEndTag(tok)
+			}
+		case Applet, Marquee, Object:
+			if b.inScope(defaultScope, tok.hash) {
+				b.generateImpliedEndTags(0)
+				b.popUntil(tok.hash)
+				b.clearFormattingToMarker()
+			}
+		case Br:
+			tok.tt, tok.attrs = StartTagToken, nil
+			return false
+		default:
+			b.anyOtherEndTag(tok)
+		}
+	case ErrorToken:
+		if 0 < len(b.templateModes) {
+			return inTemplateMode(b, tok)
+		}
+	}
+	return true
+}
+
+func getAttr(attrs []Attr, key string) ([]byte, bool) {
+	for _, attr := range attrs {
+		if string(attr.Key) == key {
+			return attr.Val, true
+		}
+	}
+	return nil, false
+}
+
+func addMissingAttrs(n *Node, attrs []Attr) {
+	for _, attr := range attrs {
+		if _, ok := n.Attr(string(attr.Key)); !ok {
+			n.Attrs = append(n.Attrs, attr)
+		}
+	}
+}
+
+func textMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		b.insertText(tok.data)
+		return true
+	case ErrorToken:
+		b.pop()
+		b.mode = b.originalMode
+		return false
+	case EndTagToken:
+		b.pop()
+		b.mode = b.originalMode
+		return true
+	}
+	b.pop()
+	b.mode = b.originalMode
+	return false
+}
+
+func inTableMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		if b.top().isOneOf(Table, Tbody, Template, Tfoot, Thead, Tr) {
+			b.tableText = b.tableText[:0]
+			b.originalMode = b.mode
+			b.mode = inTableTextMode
+			return false
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+		return true
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		switch tok.hash {
+		case Caption:
+			b.clearStackBackTo(Table, Template, Html)
+			b.afe = append(b.afe, nil)
+			b.insertElement(tok)
+			b.mode = inCaptionMode
+			return true
+		case Colgroup:
+			b.clearStackBackTo(Table, Template, Html)
+			b.insertElement(tok)
+			b.mode = inColumnGroupMode
+			return true
+		case Col:
+			b.clearStackBackTo(Table, Template, Html)
+			b.insertHTMLElement(Colgroup)
+			b.mode = inColumnGroupMode
+			return false
+		case Tbody, Tfoot, Thead:
+			b.clearStackBackTo(Table, Template, Html)
+			b.insertElement(tok)
+			b.mode = inTableBodyMode
+			return true
+		case Td, Th, Tr:
+			b.clearStackBackTo(Table, Template, Html)
+			b.insertHTMLElement(Tbody)
+			b.mode = inTableBodyMode
+			return false
+		case Table:
+			if !b.inScope(tableScope, Table) {
+				return true
+			}
+			b.popUntil(Table)
+			b.resetInsertionMode()
+			return false
+		case Style, Script, Template:
+			return inHeadMode(b, tok)
+		case Input:
+			if val, _ := getAttr(tok.attrs, "type"); parse.EqualFold(val, []byte("hidden")) {
+				b.insertElement(tok)
+				b.pop()
+				return true
+			}
+		case Form:
+			if b.form == nil && !b.hasTemplate() {
+				b.form = b.insertElement(tok)
+				b.pop()
+			}
+			return true
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Table:
+			if b.inScope(tableScope, Table) {
+				b.popUntil(Table)
+				b.resetInsertionMode()
+			}
+			return true
+		case Body, Caption, Col, Colgroup, Html, Tbody, Td, Tfoot, Th, Thead, Tr:
+			return true
+		case Template:
+			return inHeadMode(b, tok)
+		}
+	case ErrorToken:
+		return inBodyMode(b, tok)
+	}
+	b.fosterParenting = true
+	consumed := inBodyMode(b, tok)
+	b.fosterParenting = false
+	return consumed
+}
+
+func inTableTextMode(b *treeBuilder, tok *token) bool {
+	if tok.tt == TextToken {
+		b.tableText = append(b.tableText, tok.data...)
+		return true
+	}
+	text := append([]byte{}, b.tableText...)
+	if _, rest := splitWhitespace(text); 0 < len(rest) {
+		b.fosterParenting = true
+		b.reconstructFormatting()
+		b.insertText(text)
+		b.fosterParenting = false
+		b.framesetOK = false
+	} else {
+		b.insertText(text)
+	}
+	b.mode = b.originalMode
+	return false
+}
+
+func (b *treeBuilder) closeCaption() bool {
+	if !b.inScope(tableScope, Caption) {
+		return false
+	}
+	b.generateImpliedEndTags(0)
+	b.popUntil(Caption)
+	b.clearFormattingToMarker()
+	b.mode = inTableMode
+	return true
+}
+
+func inCaptionMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case StartTagToken:
+		switch tok.hash {
+		case Caption, Col, Colgroup, Tbody, Td, Tfoot, Th, Thead, Tr:
+			return !b.closeCaption()
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Caption:
+			b.closeCaption()
+			return true
+		case Table:
+			return !b.closeCaption()
+		case Body, Col, Colgroup, Html, Tbody, Td, Tfoot, Th, Thead, Tr:
+			return true
+		}
+	}
+	return inBodyMode(b, tok)
+}
+
+func inColumnGroupMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		var ws []byte
+		ws, tok.data = splitWhitespace(tok.data)
+		b.insertText(ws)
+		if len(tok.data) == 0 {
+			return true
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+		return true
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Col:
+			b.insertElement(tok)
+			b.pop()
+			return true
+		case Template:
+			return inHeadMode(b, tok)
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Colgroup:
+			if b.top().is(Colgroup) {
+				b.pop()
+				b.mode = inTableMode
+			}
+			return true
+		case Col:
+			return true
+		case Template:
+			return inHeadMode(b, tok)
+		}
+	case ErrorToken:
+		return inBodyMode(b, tok)
+	}
+	if !b.top().is(Colgroup) {
+		return true
+	}
+	b.pop()
+	b.mode = inTableMode
+	return false
+}
+
+func inTableBodyMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case StartTagToken:
+		switch tok.hash {
+		case Tr:
+			b.clearStackBackTo(Tbody, Tfoot, Thead, Template, Html)
+			b.insertElement(tok)
+			b.mode = inRowMode
+			return true
+		case Th, Td:
+			b.clearStackBackTo(Tbody, Tfoot, Thead, Template, Html)
+			b.insertHTMLElement(Tr)
+			b.mode = inRowMode
+			return false
+		case Caption, Col, Colgroup, Tbody, Tfoot, Thead:
+			if !b.inScope(tableScope, Tbody, Thead, Tfoot) {
+				return true
+			}
+			b.clearStackBackTo(Tbody, Tfoot, Thead, Template, Html)
+			b.pop()
+			b.mode = inTableMode
+			return false
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Tbody, Tfoot, Thead:
+			if b.inScope(tableScope, tok.hash) {
+				b.clearStackBackTo(Tbody, Tfoot, Thead, Template, Html)
+				b.pop()
+				b.mode = inTableMode
+			}
+			return true
+		case Table:
+			if !b.inScope(tableScope, Tbody, Thead, Tfoot) {
+				return true
+			}
+			b.clearStackBackTo(Tbody, Tfoot, Thead, Template, Html)
+			b.pop()
+			b.mode = inTableMode
+			return false
+		case Body, Caption, Col, Colgroup, Html, Td, Th, Tr:
+			return true
+		}
+	}
+	return inTableMode(b, tok)
+}
+
+func inRowMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case StartTagToken:
+		switch tok.hash {
+		case Th, Td:
+			b.clearStackBackTo(Tr, Template, Html)
+			b.insertElement(tok)
+			b.mode = inCellMode
+			b.afe = append(b.afe, nil)
+			return true
+		case Caption, Col, Colgroup, Tbody, Tfoot, Thead, Tr:
+			if !b.inScope(tableScope, Tr) {
+				return true
+			}
+			b.clearStackBackTo(Tr, Template, Html)
+			b.pop()
+			b.mode = inTableBodyMode
+			return false
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Tr:
+			if b.inScope(tableScope, Tr) {
+				b.clearStackBackTo(Tr, Template, Html)
+				b.pop()
+				b.mode = inTableBodyMode
+			}
+			return true
+		case Table, Tbody, Tfoot, Thead:
+			if tok.hash != Table && !b.inScope(tableScope, tok.hash) || !b.inScope(tableScope, Tr) {
+				return true
+			}
+			b.clearStackBackTo(Tr, Template, Html)
+			b.pop()
+			b.mode = inTableBodyMode
+			return false
+		case Body, Caption, Col, Colgroup, Html, Td, Th:
+			return true
+		}
+	}
+	return inTableMode(b, tok)
+}
+
+func (b *treeBuilder) closeCell() {
+	b.generateImpliedEndTags(0)
+	b.popUntil(Td, Th)
+	b.clearFormattingToMarker()
+	b.mode = inRowMode
+}
+
+func inCellMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case StartTagToken:
+		switch tok.hash {
+		case Caption, Col, Colgroup, Tbody, Td, Tfoot, Th, Thead, Tr:
+			if !b.inScope(tableScope, Td, Th) {
+				return true
+			}
+			b.closeCell()
+			return false
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Td, Th:
+			if b.inScope(tableScope, tok.hash) {
+				b.generateImpliedEndTags(0)
+				b.popUntil(tok.hash)
+				b.clearFormattingToMarker()
+				b.mode = inRowMode
+			}
+			return true
+		case Body, Caption, Col, Colgroup, Html:
+			return true
+		case Table, Tbody, Tfoot, Thead, Tr:
+			if !b.inScope(tableScope, tok.hash) {
+				return true
+			}
+			b.closeCell()
+			return false
+		}
+	}
+	return inBodyMode(b, tok)
+}
+
+func inSelectMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		b.insertText(tok.data)
+	case CommentToken:
+		b.insertComment(tok.data)
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Option:
+			if b.top().is(Option) {
+				b.pop()
+			}
+			b.insertElement(tok)
+		case Optgroup, Hr:
+			if b.top().is(Option) {
+				b.pop()
+			}
+			if b.top().is(Optgroup) {
+				b.pop()
+			}
+			b.insertElement(tok)
+			if tok.hash == Hr {
+				b.pop()
+			}
+		case Select, Input, Keygen, Textarea:
+			if !b.inScope(selectScope, Select) {
+				return true
+			}
+			b.popUntil(Select)
+			b.resetInsertionMode()
+			return tok.hash == Select
+		case Script, Template:
+			return inHeadMode(b, tok)
+		}
+	case EndTagToken:
+		switch tok.hash {
+		case Optgroup:
+			if b.top().is(Option) && 1 < len(b.oe) && b.oe[len(b.oe)-2].is(Optgroup) {
+				b.pop()
+			}
+			if b.top().is(Optgroup) {
+				b.pop()
+			}
+		case Option:
+			if b.top().is(Option) {
+				b.pop()
+			}
+		case Select:
+			if b.inScope(selectScope, Select) {
+				b.popUntil(Select)
+				b.resetInsertionMode()
+			}
+		case Template:
+			return inHeadMode(b, tok)
+		}
+	case ErrorToken:
+		return inBodyMode(b, tok)
+	}
+	return true
+}
+
+func inSelectInTableMode(b *treeBuilder, tok *token) bool {
+	if tok.tt == StartTagToken || tok.tt == EndTagToken {
+		switch tok.hash {
+		case Caption, Table, Tbody, Tfoot, Thead, Tr, Td, Th:
+			if tok.tt == EndTagToken && !b.inScope(tableScope, tok.hash) {
+				return true
+			}
+			b.popUntil(Select)
+			b.resetInsertionMode()
+			return false
+		}
+	}
+	return inSelectMode(b, tok)
+}
+
+func inTemplateMode(b *treeBuilder, tok *token) bool {
+	var mode insertionMode
+	switch tok.tt {
+	case TextToken, CommentToken, DoctypeToken:
+		return inBodyMode(b, tok)
+	case StartTagToken:
+		switch tok.hash {
+		case Base, Basefont, Bgsound, Link, Meta, Noframes, Script, Style, Template, Title:
+			return inHeadMode(b, tok)
+		case Caption, Colgroup, Tbody, Tfoot, Thead:
+			mode = inTableMode
+		case Col:
+			mode = inColumnGroupMode
+		case Tr:
+			mode = inTableBodyMode
+		case Td, Th:
+			mode = inRowMode
+		default:
+			mode = inBodyMode
+		}
+	case EndTagToken:
+		if tok.hash == Template {
+			return inHeadMode(b, tok)
+		}
+		return true
+	case ErrorToken:
+		if !b.hasTemplate() {
+			return true
+		}
+		b.popUntil(Template)
+		b.clearFormattingToMarker()
+		b.templateModes = b.templateModes[:len(b.templateModes)-1]
+		b.resetInsertionMode()
+		return false
+	}
+	b.templateModes[len(b.templateModes)-1] = mode
+	b.mode = mode
+	return false
+}
+
+func afterBodyMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		var ws []byte
+		ws, tok.data = splitWhitespace(tok.data)
+		if 0 < len(ws) {
+			inBodyMode(b, &token{tt: TextToken, data: ws})
+		}
+		if len(tok.data) == 0 {
+			return true
+		}
+	case CommentToken:
+		b.oe[0].AppendChild(&Node{Type: CommentNode, Data: tok.data})
+		return true
+	case DoctypeToken:
+		return true
+	case StartTagToken:
+		if tok.hash == Html {
+			return inBodyMode(b, tok)
+		}
+	case EndTagToken:
+		if tok.hash == Html {
+			b.mode = afterAfterBodyMode
+			return true
+		}
+	case ErrorToken:
+		return true
+	}
+	b.mode = inBodyMode
+	return false
+}
+
+// whitespaceOnly returns only the whitespace characters of the text.
+func whitespaceOnly(b []byte) []byte {
+	ws := []byte{}
+	for _, c := range b {
+		if isWhitespace(c) {
+			ws = append(ws, c)
+		}
+	}
+	return ws
+}
+
+func inFramesetMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		b.insertText(whitespaceOnly(tok.data))
+	case CommentToken:
+		b.insertComment(tok.data)
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Frameset:
+			b.insertElement(tok)
+		case Frame:
+			b.insertElement(tok)
+			b.pop()
+		case Noframes:
+			return inHeadMode(b, tok)
+		}
+	case EndTagToken:
+		if tok.hash == Frameset && !b.top().is(Html) {
+			b.pop()
+			if !b.top().is(Frameset) {
+				b.mode = afterFramesetMode
+			}
+		}
+	}
+	return true
+}
+
+func afterFramesetMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		b.insertText(whitespaceOnly(tok.data))
+	case CommentToken:
+		b.insertComment(tok.data)
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Noframes:
+			return inHeadMode(b, tok)
+		}
+	case EndTagToken:
+		if tok.hash == Html {
+			b.mode = afterAfterFramesetMode
+		}
+	}
+	return true
+}
+
+func afterAfterBodyMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case CommentToken:
+		b.doc.AppendChild(&Node{Type: CommentNode, Data: tok.data})
+		return true
+	case DoctypeToken:
+		return inBodyMode(b, tok)
+	case TextToken:
+		if _, rest := splitWhitespace(tok.data); len(rest) == 0 {
+			return inBodyMode(b, tok)
+		}
+	case StartTagToken:
+		if tok.hash == Html {
+			return inBodyMode(b, tok)
+		}
+	case ErrorToken:
+		return true
+	}
+	b.mode = inBodyMode
+	return false
+}
+
+func afterAfterFramesetMode(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case CommentToken:
+		b.doc.AppendChild(&Node{Type: CommentNode, Data: tok.data})
+	case DoctypeToken:
+		return inBodyMode(b, tok)
+	case TextToken:
+		if ws := whitespaceOnly(tok.data); 0 < len(ws) {
+			return inBodyMode(b, &token{tt: TextToken, data: ws})
+		}
+	case StartTagToken:
+		switch tok.hash {
+		case Html:
+			return inBodyMode(b, tok)
+		case Noframes:
+			return inHeadMode(b, tok)
+		}
+	}
+	return true
+}
+
+////////////////////////////////////////////////////////////////
+
+// The following follows the specification at https://html.spec.whatwg.org/multipage/parsing.html#parsing-main-inforeign
+
+func foreignContent(b *treeBuilder, tok *token) bool {
+	switch tok.tt {
+	case TextToken:
+		b.insertText(tok.data)
+		if _, rest := splitWhitespace(tok.data); 0 < len(rest) {
+			b.framesetOK = false
+		}
+	case CommentToken:
+		b.insertComment(tok.data)
+	case DoctypeToken:
+	case StartTagToken:
+		if isBreakout(tok) {
+			b.popForeign()
+			return false
+		}
+		b.insertForeignElement(tok, b.top().Namespace)
+	case EndTagToken:
+		if tok.hash == Br || tok.hash == P {
+			b.popForeign()
+			return false
+		}
+		for i := len(b.oe) - 1; 0 < i; i-- {
+			if n := b.oe[i]; n.Namespace == HTMLNamespace {
+				return b.mode(b, tok)
+			} else if parse.EqualFold(n.Data, tok.data) {
+				b.oe = b.oe[:i]
+				break
+			}
+		}
+	}
+	return true
+}
+
+// isBreakout returns true for start tags of HTML elements that close all open SVG and MathML elements.
+func isBreakout(tok *token) bool {
+	switch string(tok.data) {
+	case "b", "big", "blockquote", "body", "br", "center", "code", "dd", "div", "dl", "dt", "em", "embed", "h1", "h2", "h3", "h4", "h5", "h6", "head", "hr", "i", "img", "li", "listing", "menu", "meta", "nobr", "ol", "p", "pre", "ruby", "s", "small", "span", "strong", "strike", "sub", "sup", "table", "tt", "u", "ul", "var":
+		return true
+	case "font":
+		for _, key := range []string{"color", "face", "size"} {
+			if _, ok := getAttr(tok.attrs, key); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// popForeign pops SVG and MathML elements until the current node is an HTML element or an integration point.
+func (b *treeBuilder) popForeign() {
+	for 0 < len(b.oe) {
+		if n := b.top(); n.Namespace == HTMLNamespace || isTextIntegrationPoint(n) || isHTMLIntegrationPoint(n) {
+			return
+		}
+		b.pop()
+	}
+}
+
+// svgTagNames holds the SVG element names that are not lowercase.
+var svgTagNames = map[string]string{
+	"altglyph":            "altGlyph",
+	"altglyphdef":         "altGlyphDef",
+	"altglyphitem":        "altGlyphItem",
+	"animatecolor":        "animateColor",
+	"animatemotion":       "animateMotion",
+	"animatetransform":    "animateTransform",
+	"clippath":            "clipPath",
+	"feblend":             "feBlend",
+	"fecolormatrix":       "feColorMatrix",
+	"fecomponenttransfer": "feComponentTransfer",
+	"fecomposite":         "feComposite",
+	"feconvolvematrix":    "feConvolveMatrix",
+	"fediffuselighting":   "feDiffuseLighting",
+	"fedisplacementmap":   "feDisplacementMap",
+	"fedistantlight":      "feDistantLight",
+	"fedropshadow":        "feDropShadow",
+	"feflood":             "feFlood",
+	"fefunca":             "feFuncA",
+	"fefuncb":             "feFuncB",
+	"fefuncg":             "feFuncG",
+	"fefuncr":             "feFuncR",
+	"fegaussianblur":      "feGaussianBlur",
+	"feimage":             "feImage",
+	"femerge":             "feMerge",
+	"femergenode":         "feMergeNode",
+	"femorphology":        "feMorphology",
+	"feoffset":            "feOffset",
+	"fepointlight":        "fePointLight",
+	"fespecularlighting":  "feSpecularLighting",
+	"fespotlight":         "feSpotLight",
+	"fetile":              "feTile",
+	"feturbulence":        "feTurbulence",
+	"foreignobject":       "foreignObject",
+	"glyphref":            "glyphRef",
+	"lineargradient":      "linearGradient",
+	"radialgradient":      "radialGradient",
+	"textpath":            "textPath",
+}
+
+// svgAttrNames holds the SVG attribute names that are not lowercase.
+var svgAttrNames = map[string]string{
+	"attributename":       "attributeName",
+	"attributetype":       "attributeType",
+	"basefrequency":       "baseFrequency",
+	"baseprofile":         "baseProfile",
+	"calcmode":            "calcMode",
+	"clippathunits":       "clipPathUnits",
+	"diffuseconstant":     "diffuseConstant",
+	"edgemode":            "edgeMode",
+	"filterunits":         "filterUnits",
+	"glyphref":            "glyphRef",
+	"gradienttransform":   "gradientTransform",
+	"gradientunits":       "gradientUnits",
+	"kernelmatrix":        "kernelMatrix",
+	"kernelunitlength":    "kernelUnitLength",
+	"keypoints":           "keyPoints",
+	"keysplines":          "keySplines",
+	"keytimes":            "keyTimes",
+	"lengthadjust":        "lengthAdjust",
+	"limitingconeangle":   "limitingConeAngle",
+	"markerheight":        "markerHeight",
+	"markerunits":         "markerUnits",
+	"markerwidth":         "markerWidth",
+	"maskcontentunits":    "maskContentUnits",
+	"maskunits":           "maskUnits",
+	"numoctaves":          "numOctaves",
+	"pathlength":          "pathLength",
+	"patterncontentunits": "patternContentUnits",
+	"patterntransform":    "patternTransform",
+	"patternunits":        "patternUnits",
+	"pointsatx":           "pointsAtX",
+	"pointsaty":           "pointsAtY",
+	"pointsatz":           "pointsAtZ",
+	"preservealpha":       "preserveAlpha",
+	"preserveaspectratio": "preserveAspectRatio",
+	"primitiveunits":      "primitiveUnits",
+	"refx":                "refX",
+	"refy":                "refY",
+	"repeatcount":         "repeatCount",
+	"repeatdur":           "repeatDur",
+	"requiredextensions":  "requiredExtensions",
+	"requiredfeatures":    "requiredFeatures",
+	"specularconstant":    "specularConstant",
+	"specularexponent":    "specularExponent",
+	"spreadmethod":        "spreadMethod",
+	"startoffset":         "startOffset",
+	"stddeviation":        "stdDeviation",
+	"stitchtiles":         "stitchTiles",
+	"surfacescale":        "surfaceScale",
+	"systemlanguage":      "systemLanguage",
+	"tablevalues":         "tableValues",
+	"targetx":             "targetX",
+	"targety":             "targetY",
+	"textlength":          "textLength",
+	"viewbox":             "viewBox",
+	"viewtarget":          "viewTarget",
+	"xchannelselector":    "xChannelSelector",
+	"ychannelselector":    "yChannelSelector",
+	"zoomandpan":          "zoomAndPan",
+}
+
+// mathMLAttrNames holds the MathML attribute names that are not lowercase.
+var mathMLAttrNames = map[string]string{
+	"definitionurl": "definitionURL",
+}
@@ -0,0 +1,169 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+// dump writes the tree in the format of the html5lib tree construction tests.
+func dump(n *Node) string {
+	sb := &strings.Builder{}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		dumpNode(sb, c, 0)
+	}
+	return sb.String()
+}
+
+func dumpNode(sb *strings.Builder, n *Node, level int) {
+	indent := "| " + strings.Repeat("  ", level)
+	sb.WriteString(indent)
+	switch n.Type {
+	case DoctypeNode:
+		fmt.Fprintf(sb, "<!DOCTYPE %s>\n", n.Data)
+	case CommentNode:
+		fmt.Fprintf(sb, "<!-- %s -->\n", n.Data)
+	case TextNode:
+		fmt.Fprintf(sb, "\"%s\"\n", n.Data)
+	case ElementNode:
+		if n.Namespace != HTMLNamespace {
+			fmt.Fprintf(sb, "<%s %s>\n", n.Namespace, n.Data)
+		} else {
+			fmt.Fprintf(sb, "<%s>\n", n.Data)
+		}
+		for _, attr := range n.Attrs {
+			fmt.Fprintf(sb, "%s  %s=\"%s\"\n", indent, attr.Key, attr.Val)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		dumpNode(sb, c, level+1)
+	}
+}
+
+func TestParse(t *testing.T) {
+	var parseTests = []struct {
+		html     string
+		expected string
+	}{
+		{"", "| <html>\n|   <head>\n|   <body>\n"},
+		{"<!doctype html>x", "| <!DOCTYPE html>\n| <html>\n|   <head>\n|   <body>\n|     \"x\"\n"},
+		{"<!--a--><html><!--b--><head></head><!--c--><body></body></html><!--d-->", "| <!-- a -->\n| <html>\n|   <!-- b -->\n|   <head>\n|   <!-- c -->\n|   <body>\n| <!-- d -->\n"},
+		{"<title>a<b></title><p>x", "| <html>\n|   <head>\n|     <title>\n|       \"a<b>\"\n|   <body>\n|     <p>\n|       \"x\"\n"},
+		{"<meta charset=utf-8><link rel='x'>", "| <html>\n|   <head>\n|     <meta>\n|       charset=\"utf-8\"\n|     <link>\n|       rel=\"x\"\n|   <body>\n"},
+		{"<p id=a id=b class>x", "| <html>\n|   <head>\n|   <body>\n|     <p>\n|       id=\"a\"\n|       class=\"\"\n|       \"x\"\n"},
+
+		// implied end tags
+		{"<p>a<p>b<div>c</div>", "| <html>\n|   <head>\n|   <body>\n|     <p>\n|       \"a\"\n|     <p>\n|       \"b\"\n|     <div>\n|       \"c\"\n"},
+		{"<ul><li>a<li>b</ul>", "| <html>\n|   <head>\n|   <body>\n|     <ul>\n|       <li>\n|         \"a\"\n|       <li>\n|         \"b\"\n"},
+		{"<dl><dt>a<dd>b<dt>c</dl>", "| <html>\n|   <head>\n|   <body>\n|     <dl>\n|       <dt>\n|         \"a\"\n|       <dd>\n|         \"b\"\n|       <dt>\n|         \"c\"\n"},
+		{"<body></p>", "| <html>\n|   <head>\n|   <body>\n|     <p>\n"},
+		{"<h1>a<h2>b", "| <html>\n|   <head>\n|   <body>\n|     <h1>\n|       \"a\"\n|     <h2>\n|       \"b\"\n"},
+		{"<br></br>", "| <html>\n|   <head>\n|   <body>\n|     <br>\n|     <br>\n"},
+		{"<image>", "| <html>\n|   <head>\n|   <body>\n|     <img>\n"},
+		{"<pre>\nx</pre>", "| <html>\n|   <head>\n|   <body>\n|     <pre>\n|       \"x\"\n"},
+		{"<select><option>a<option>b</select>", "| <html>\n|   <head>\n|   <body>\n|     <select>\n|       <option>\n|         \"a\"\n|       <option>\n|         \"b\"\n"},
+
+		// adoption agency
+		{"<b>1<p>2</b>3</p>", "| <html>\n|   <head>\n|   <body>\n|     <b>\n|       \"1\"\n|     <p>\n|       <b>\n|         \"2\"\n|       \"3\"\n"},
+		{"<a><p>x</a>y", "| <html>\n|   <head>\n|   <body>\n|     <a>\n|     <p>\n|       <a>\n|         \"x\"\n|       \"y\"\n"},
+		{"<b><i>x</b>y</i>", "| <html>\n|   <head>\n|   <body>\n|     <b>\n|       <i>\n|         \"x\"\n|     <i>\n|       \"y\"\n"},
+		{"<a>1<a>2", "| <html>\n|   <head>\n|   <body>\n|     <a>\n|       \"1\"\n|     <a>\n|       \"2\"\n"},
+		{"<p><b><b><b><b>x</p>y", "| <html>\n|   <head>\n|   <body>\n|     <p>\n|       <b>\n|         <b>\n|           <b>\n|             <b>\n|               \"x\"\n|     <b>\n|       <b>\n|         <b>\n|           \"y\"\n"},
+
+		// tables and foster parenting
+		{"<table><tr><td>x</table>", "| <html>\n|   <head>\n|   <body>\n|     <table>\n|       <tbody>\n|         <tr>\n|           <td>\n|             \"x\"\n"},
+		{"<table>a<tr>b</table>", "| <html>\n|   <head>\n|   <body>\n|     \"ab\"\n|     <table>\n|       <tbody>\n|         <tr>\n"},
+		{"<table><div>x</div></table>", "| <html>\n|   <head>\n|   <body>\n|     <div>\n|       \"x\"\n|     <table>\n"},
+		{"<table> <caption>c</caption><col></table>", "| <html>\n|   <head>\n|   <body>\n|     <table>\n|       \" \"\n|       <caption>\n|         \"c\"\n|       <colgroup>\n|         <col>\n"},
+		{"<table><td>a<td>b<tr><th>c</table>", "| <html>\n|   <head>\n|   <body>\n|     <table>\n|       <tbody>\n|         <tr>\n|           <td>\n|             \"a\"\n|           <td>\n|             \"b\"\n|         <tr>\n|           <th>\n|             \"c\"\n"},
+		{"<table><input type=hidden><input></table>", "| <html>\n|   <head>\n|   <body>\n|     <input>\n|     <table>\n|       <input>\n|         type=\"hidden\"\n"},
+
+		// character references
+		{"<p title='a&amp;b'>&lt;&#x41;&nbsp<svg><text x='&quot;'>&amp;</text></svg>", "| <html>\n|   <head>\n|   <body>\n|     <p>\n|       title=\"a&b\"\n|       \"<A\u00a0\"\n|       <svg svg>\n|         <svg text>\n|           x=\"\"\"\n|           \"&\"\n"},
+		{"<title>&amp;</title><script>&amp;</script>", "| <html>\n|   <head>\n|     <title>\n|       \"&\"\n|     <script>\n|       \"&amp;\"\n|   <body>\n"},
+
+		// raw text, frameset, foreign content
+		{"<script>a</b>c</script>", "| <html>\n|   <head>\n|     <script>\n|       \"a</b>c\"\n|   <body>\n"},
+		{"<frameset><frame></frameset>", "| <html>\n|   <head>\n|   <frameset>\n|     <frame>\n"},
+		{"<svg viewBox='0 0 1 1'><path d=x /></svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       viewBox=\"0 0 1 1\"\n|       <svg path>\n|         d=\"x\"\n"},
+		{"<svg viewbox='0 0 1 1'><clippath/><FeBlend></feblend></svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       viewBox=\"0 0 1 1\"\n|       <svg clipPath>\n|       <svg feBlend>\n"},
+		{"<math definitionurl=x></math>", "| <html>\n|   <head>\n|   <body>\n|     <math math>\n|       definitionURL=\"x\"\n"},
+		{"<svg><p>x</svg>y", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|     <p>\n|       \"xy\"\n"},
+		{"<svg></p>x", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|     <p>\n|     \"x\"\n"},
+		{"<svg><font>a</font><font color=red>b</svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       <svg font>\n|         \"a\"\n|     <font>\n|       color=\"red\"\n|       \"b\"\n"},
+		{"<svg><style>a<g></g></style></svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       <svg style>\n|         \"a\"\n|         <svg g>\n"},
+		{"<svg><g></G>x</svg>y", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       <svg g>\n|       \"x\"\n|     \"y\"\n"},
+		{"<svg/>a<svg><svg></svg>b</svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|     \"a\"\n|     <svg svg>\n|       <svg svg>\n|       \"b\"\n"},
+		{"<svg><foreignObject><p>a</p></foreignObject>b</svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       <svg foreignObject>\n|         <p>\n|           \"a\"\n|       \"b\"\n"},
+		{"<svg><desc><b>a</b></desc><title><p>b</svg>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       <svg desc>\n|         <b>\n|           \"a\"\n|       <svg title>\n|         <p>\n|           \"b\"\n"},
+		{"<math><mi><b>x</b></mi><mglyph/></math>", "| <html>\n|   <head>\n|   <body>\n|     <math math>\n|       <math mi>\n|         <b>\n|           \"x\"\n|       <math mglyph>\n"},
+		{"<math><annotation-xml encoding='text/html'><div>x</div></annotation-xml><annotation-xml><div>y", "| <html>\n|   <head>\n|   <body>\n|     <math math>\n|       <math annotation-xml>\n|         encoding=\"text/html\"\n|         <div>\n|           \"x\"\n|       <math annotation-xml>\n|     <div>\n|       \"y\"\n"},
+		{"<li><svg><desc><li>x", "| <html>\n|   <head>\n|   <body>\n|     <li>\n|       <svg svg>\n|         <svg desc>\n|           <li>\n|             \"x\"\n"},
+		{"<p><svg><foreignObject><p>x", "| <html>\n|   <head>\n|   <body>\n|     <p>\n|       <svg svg>\n|         <svg foreignObject>\n|           <p>\n|             \"x\"\n"},
+		{"<svg><![CDATA[a<b]]></svg><![CDATA[c]]>", "| <html>\n|   <head>\n|   <body>\n|     <svg svg>\n|       \"a<b\"\n|     <!-- [CDATA[c]] -->\n"},
+		{"<template><td>x</template>", "| <html>\n|   <head>\n|     <template>\n|       <td>\n|         \"x\"\n|   <body>\n"},
+	}
+	for _, tt := range parseTests {
+		t.Run(tt.html, func(t *testing.T) {
+			doc, err := Parse(bytes.NewBufferString(tt.html))
+			test.Error(t, err)
+			test.String(t, dump(doc), tt.expected)
+		})
+	}
+}
+
+func TestParseString(t *testing.T) {
+	doc, err := Parse(bytes.NewBufferString("<!doctype html><title>x</title><p class=a>b<br>c<svg><g/></svg>"))
+	test.Error(t, err)
+	test.String(t, doc.String(), `<!doctype html><html><head><title>x</title></head><body><p class="a">b<br>c<svg><g/></svg></p></body></html>`)
+
+	doc, err = Parse(bytes.NewBufferString("<style>a>b</style><p title='&quot;a&amp;'>&lt;b&gt;&nbsp;<svg viewbox=x></svg>"))
+	test.Error(t, err)
+	test.String(t, doc.String(), `<html><head><style>a>b</style></head><body><p title="&quot;a&amp;">&lt;b&gt;&nbsp;<svg viewBox="x"/></p></body></html>`)
+}
+
+func TestNode(t *testing.T) {
+	doc, err := Parse(bytes.NewBufferString("<p id=x>a</p>"))
+	test.Error(t, err)
+	body := doc.FirstChild.LastChild
+	p := body.FirstChild
+	test.T(t, p.Hash, P)
+	test.T(t, p.Type, ElementNode)
+	val, ok := p.Attr("id")
+	test.That(t, ok)
+	test.String(t, string(val), "x")
+	_, ok = p.Attr("class")
+	test.That(t, !ok)
+
+	div := &Node{Type: ElementNode, Hash: Div, Data: []byte("div")}
+	body.InsertBefore(div, p)
+	test.T(t, body.FirstChild, div)
+	test.T(t, div.NextSibling, p)
+	body.RemoveChild(p)
+	div.AppendChild(p)
+	test.String(t, body.String(), "<body><div><p id=\"x\">a</p></div></body>")
+	test.T(t, p.Parent, div)
+}
+
+func ExampleParse() {
+	doc, err := Parse(bytes.NewBufferString("<ul><li>one<li>two</ul>"))
+	if err != nil {
+		panic(err)
+	}
+	var walk func(*Node)
+	walk = func(n *Node) {
+		if n.Type == ElementNode && n.Hash == Li {
+			fmt.Println(n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	// Output:
+	// <li>one</li>
+	// <li>two</li>
+}
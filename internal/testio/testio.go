@@ -0,0 +1,41 @@
+// Package testio contains readers and helpers that are shared by the tests of the stream lexers and parsers.
+package testio
+
+import (
+	"io"
+	"runtime"
+)
+
+// RepeatReader is an io.Reader that reads a byte slice a number of times, without keeping the repeated input in memory.
+type RepeatReader struct {
+	b   []byte
+	n   int
+	pos int
+}
+
+// NewRepeatReader returns a new RepeatReader that reads b n times.
+func NewRepeatReader(b []byte, n int) *RepeatReader {
+	return &RepeatReader{b: b, n: n}
+}
+
+// Read implements io.Reader.
+func (r *RepeatReader) Read(p []byte) (int, error) {
+	if r.n == 0 || len(r.b) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b[r.pos:])
+	if r.pos += n; r.pos == len(r.b) {
+		r.pos = 0
+		r.n--
+	}
+	return n, nil
+}
+
+// TotalAlloc returns the number of bytes that are allocated on the heap while running f.
+func TotalAlloc(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
@@ -0,0 +1,1060 @@
+package js
+
+import (
+	"strings"
+)
+
+// Loc is the byte range of a node in the input stream. Start is inclusive and End is exclusive.
+type Loc struct {
+	Start, End int
+}
+
+// Span returns the byte range of the node.
+func (loc Loc) Span() Loc {
+	return loc
+}
+
+// INode is an interface for AST nodes.
+type INode interface {
+	Span() Loc
+	String() string
+}
+
+// IStmt is a dummy interface for statements.
+type IStmt interface {
+	INode
+	stmtNode()
+}
+
+// IExpr is a dummy interface for expressions.
+type IExpr interface {
+	INode
+	exprNode()
+}
+
+// IBinding is a dummy interface for binding patterns, ie. *Ident, *BindingArray, or *BindingObject.
+type IBinding interface {
+	INode
+	bindingNode()
+}
+
+////////////////////////////////////////////////////////////////
+
+// Program is the root node of a parsed script or module.
+type Program struct {
+	Loc
+	List []IStmt
+}
+
+func (n Program) String() string {
+	return joinNodes(n.List, " ")
+}
+
+func joinNodes(list interface{}, sep string) string {
+	var ss []string
+	switch l := list.(type) {
+	case []IStmt:
+		for _, item := range l {
+			ss = append(ss, item.String())
+		}
+	case []IExpr:
+		for _, item := range l {
+			ss = append(ss, item.String())
+		}
+	}
+	return strings.Join(ss, sep)
+}
+
+////////////////////////////////////////////////////////////////
+
+// BlockStmt is a list of statements enclosed in braces.
+type BlockStmt struct {
+	Loc
+	List []IStmt
+}
+
+func (n BlockStmt) String() string {
+	s := "Stmt({"
+	for _, item := range n.List {
+		s += " " + item.String()
+	}
+	return s + " })"
+}
+
+// EmptyStmt is a lone semicolon.
+type EmptyStmt struct {
+	Loc
+}
+
+func (n EmptyStmt) String() string {
+	return "Stmt(;)"
+}
+
+// ExprStmt is an expression statement.
+type ExprStmt struct {
+	Loc
+	Value IExpr
+}
+
+func (n ExprStmt) String() string {
+	return "Stmt(" + n.Value.String() + ")"
+}
+
+// VarDecl is a variable declaration using var, let, or const.
+type VarDecl struct {
+	Loc
+	TokenType Hash // Var, Let, or Const
+	List      []BindingElement
+}
+
+func (n VarDecl) String() string {
+	s := "Decl(" + n.TokenType.String()
+	for i, item := range n.List {
+		if i != 0 {
+			s += ","
+		}
+		s += " " + item.String()
+	}
+	return s + ")"
+}
+
+// IfStmt is an if statement with an optional else branch.
+type IfStmt struct {
+	Loc
+	Cond IExpr
+	Body IStmt
+	Else IStmt // can be nil
+}
+
+func (n IfStmt) String() string {
+	s := "Stmt(if " + n.Cond.String() + " " + n.Body.String()
+	if n.Else != nil {
+		s += " else " + n.Else.String()
+	}
+	return s + ")"
+}
+
+// DoWhileStmt is a do-while loop.
+type DoWhileStmt struct {
+	Loc
+	Cond IExpr
+	Body IStmt
+}
+
+func (n DoWhileStmt) String() string {
+	return "Stmt(do " + n.Body.String() + " while " + n.Cond.String() + ")"
+}
+
+// WhileStmt is a while loop.
+type WhileStmt struct {
+	Loc
+	Cond IExpr
+	Body IStmt
+}
+
+func (n WhileStmt) String() string {
+	return "Stmt(while " + n.Cond.String() + " " + n.Body.String() + ")"
+}
+
+// ForStmt is a for loop with initializer, condition, and post-expression, any of which can be nil.
+type ForStmt struct {
+	Loc
+	Init INode // *VarDecl or IExpr
+	Cond IExpr
+	Post IExpr
+	Body IStmt
+}
+
+func (n ForStmt) String() string {
+	s := "Stmt(for"
+	if n.Init != nil {
+		s += " " + n.Init.String()
+	}
+	s += " ;"
+	if n.Cond != nil {
+		s += " " + n.Cond.String()
+	}
+	s += " ;"
+	if n.Post != nil {
+		s += " " + n.Post.String()
+	}
+	return s + " " + n.Body.String() + ")"
+}
+
+// ForInStmt is a for-in loop.
+type ForInStmt struct {
+	Loc
+	Init  INode // *VarDecl or IExpr
+	Value IExpr
+	Body  IStmt
+}
+
+func (n ForInStmt) String() string {
+	return "Stmt(for " + n.Init.String() + " in " + n.Value.String() + " " + n.Body.String() + ")"
+}
+
+// ForOfStmt is a for-of or for-await-of loop.
+type ForOfStmt struct {
+	Loc
+	Await bool
+	Init  INode // *VarDecl or IExpr
+	Value IExpr
+	Body  IStmt
+}
+
+func (n ForOfStmt) String() string {
+	s := "Stmt(for"
+	if n.Await {
+		s += " await"
+	}
+	return s + " " + n.Init.String() + " of " + n.Value.String() + " " + n.Body.String() + ")"
+}
+
+// CaseClause is a case or default clause in a switch statement.
+type CaseClause struct {
+	Loc
+	Cond IExpr // nil for default
+	List []IStmt
+}
+
+func (n CaseClause) String() string {
+	s := "Clause("
+	if n.Cond != nil {
+		s += "case " + n.Cond.String()
+	} else {
+		s += "default"
+	}
+	for _, item := range n.List {
+		s += " " + item.String()
+	}
+	return s + ")"
+}
+
+// SwitchStmt is a switch statement.
+type SwitchStmt struct {
+	Loc
+	Init IExpr
+	List []CaseClause
+}
+
+func (n SwitchStmt) String() string {
+	s := "Stmt(switch " + n.Init.String()
+	for _, clause := range n.List {
+		s += " " + clause.String()
+	}
+	return s + ")"
+}
+
+// BranchStmt is a continue or break statement.
+type BranchStmt struct {
+	Loc
+	TokenType Hash   // Continue or Break
+	Label     []byte // can be nil
+}
+
+func (n BranchStmt) String() string {
+	s := "Stmt(" + n.TokenType.String()
+	if n.Label != nil {
+		s += " " + string(n.Label)
+	}
+	return s + ")"
+}
+
+// ReturnStmt is a return statement.
+type ReturnStmt struct {
+	Loc
+	Value IExpr // can be nil
+}
+
+func (n ReturnStmt) String() string {
+	s := "Stmt(return"
+	if n.Value != nil {
+		s += " " + n.Value.String()
+	}
+	return s + ")"
+}
+
+// WithStmt is a with statement.
+type WithStmt struct {
+	Loc
+	Cond IExpr
+	Body IStmt
+}
+
+func (n WithStmt) String() string {
+	return "Stmt(with " + n.Cond.String() + " " + n.Body.String() + ")"
+}
+
+// LabelledStmt is a statement preceded by a label.
+type LabelledStmt struct {
+	Loc
+	Label []byte
+	Value IStmt
+}
+
+func (n LabelledStmt) String() string {
+	return "Stmt(" + string(n.Label) + " : " + n.Value.String() + ")"
+}
+
+// ThrowStmt is a throw statement.
+type ThrowStmt struct {
+	Loc
+	Value IExpr
+}
+
+func (n ThrowStmt) String() string {
+	return "Stmt(throw " + n.Value.String() + ")"
+}
+
+// TryStmt is a try statement with a catch and/or finally block.
+type TryStmt struct {
+	Loc
+	Body    *BlockStmt
+	Binding IBinding   // can be nil
+	Catch   *BlockStmt // can be nil
+	Finally *BlockStmt // can be nil
+}
+
+func (n TryStmt) String() string {
+	s := "Stmt(try " + n.Body.String()
+	if n.Catch != nil {
+		s += " catch"
+		if n.Binding != nil {
+			s += " Binding(" + n.Binding.String() + ")"
+		}
+		s += " " + n.Catch.String()
+	}
+	if n.Finally != nil {
+		s += " finally " + n.Finally.String()
+	}
+	return s + ")"
+}
+
+// DebuggerStmt is a debugger statement.
+type DebuggerStmt struct {
+	Loc
+}
+
+func (n DebuggerStmt) String() string {
+	return "Stmt(debugger)"
+}
+
+// Alias is a name and its local binding in import and export lists. Either can be nil.
+type Alias struct {
+	Name    []byte // can be nil
+	Binding []byte // can be nil
+}
+
+func (alias Alias) String() string {
+	s := ""
+	if alias.Name != nil {
+		s += string(alias.Name)
+	}
+	if alias.Name != nil && alias.Binding != nil {
+		s += " as "
+	}
+	if alias.Binding != nil {
+		s += string(alias.Binding)
+	}
+	return s
+}
+
+// ImportStmt is an import declaration.
+type ImportStmt struct {
+	Loc
+	Default []byte  // can be nil
+	List    []Alias // a namespace import has the name "*"
+	Module  []byte  // including quotes
+}
+
+func (n ImportStmt) String() string {
+	s := "Stmt(import"
+	if n.Default != nil {
+		s += " " + string(n.Default)
+		if n.List != nil {
+			s += " ,"
+		}
+	}
+	if len(n.List) == 1 && len(n.List[0].Name) == 1 && n.List[0].Name[0] == '*' {
+		s += " " + n.List[0].String()
+	} else if n.List != nil {
+		s += " {"
+		for i, item := range n.List {
+			if i != 0 {
+				s += " ,"
+			}
+			s += " " + item.String()
+		}
+		s += " }"
+	}
+	if n.Default != nil || n.List != nil {
+		s += " from"
+	}
+	return s + " " + string(n.Module) + ")"
+}
+
+// ExportStmt is an export declaration.
+type ExportStmt struct {
+	Loc
+	List    []Alias // an export of all names has the name "*"
+	Module  []byte  // can be nil, including quotes
+	Default bool
+	Decl    INode // can be nil, *VarDecl, *FuncDecl, *ClassDecl, or IExpr when Default is set
+}
+
+func (n ExportStmt) String() string {
+	s := "Stmt(export"
+	if n.Decl != nil {
+		if n.Default {
+			s += " default"
+		}
+		return s + " " + n.Decl.String() + ")"
+	} else if len(n.List) == 1 && len(n.List[0].Name) == 1 && n.List[0].Name[0] == '*' {
+		s += " " + n.List[0].String()
+	} else {
+		s += " {"
+		for i, item := range n.List {
+			if i != 0 {
+				s += " ,"
+			}
+			s += " " + item.String()
+		}
+		s += " }"
+	}
+	if n.Module != nil {
+		s += " from " + string(n.Module)
+	}
+	return s + ")"
+}
+
+////////////////////////////////////////////////////////////////
+
+// PropertyName is the name of an object property, class element, or binding property. Either Literal or Computed is set.
+type PropertyName struct {
+	Literal  *LiteralExpr // identifier, string, number, or private name
+	Computed IExpr
+}
+
+// IsComputed returns true if the property name is a computed expression.
+func (n PropertyName) IsComputed() bool {
+	return n.Computed != nil
+}
+
+// IsIdent returns true if the property name is the given identifier.
+func (n PropertyName) IsIdent(data []byte) bool {
+	return n.Literal != nil && n.Literal.TokenType == IdentifierToken && string(n.Literal.Data) == string(data)
+}
+
+func (n PropertyName) String() string {
+	if n.Computed != nil {
+		return "[" + n.Computed.String() + "]"
+	}
+	return string(n.Literal.Data)
+}
+
+// BindingElement is a binding with an optional default value.
+type BindingElement struct {
+	Binding IBinding // can be nil for an array elision
+	Default IExpr    // can be nil
+}
+
+func (n BindingElement) String() string {
+	if n.Binding == nil {
+		return ""
+	}
+	s := n.Binding.String()
+	if n.Default != nil {
+		s += " = " + n.Default.String()
+	}
+	return s
+}
+
+// BindingArray is an array destructuring pattern.
+type BindingArray struct {
+	Loc
+	List []BindingElement
+	Rest IBinding // can be nil
+}
+
+func (n BindingArray) String() string {
+	s := "["
+	for i, item := range n.List {
+		if i != 0 {
+			s += ", "
+		}
+		if item.Binding != nil {
+			s += item.String()
+		}
+	}
+	if n.Rest != nil {
+		if len(n.List) != 0 {
+			s += ", "
+		}
+		s += "..." + n.Rest.String()
+	} else if 0 < len(n.List) && n.List[len(n.List)-1].Binding == nil {
+		s += ","
+	}
+	return s + "]"
+}
+
+// BindingObjectItem is a property in an object destructuring pattern.
+type BindingObjectItem struct {
+	Key   *PropertyName // can be nil for shorthand properties
+	Value BindingElement
+}
+
+// BindingObject is an object destructuring pattern.
+type BindingObject struct {
+	Loc
+	List []BindingObjectItem
+	Rest *Ident // can be nil
+}
+
+func (n BindingObject) String() string {
+	s := "{"
+	for i, item := range n.List {
+		if i != 0 {
+			s += ","
+		}
+		s += " "
+		if item.Key != nil {
+			s += item.Key.String() + ": "
+		}
+		s += item.Value.String()
+	}
+	if n.Rest != nil {
+		if len(n.List) != 0 {
+			s += ","
+		}
+		s += " ..." + n.Rest.String()
+	}
+	return s + " }"
+}
+
+// Params is a list of formal parameters.
+type Params struct {
+	Loc
+	List []BindingElement
+	Rest IBinding // can be nil
+}
+
+func (n Params) String() string {
+	s := "Params("
+	for i, item := range n.List {
+		if i != 0 {
+			s += ", "
+		}
+		s += item.String()
+	}
+	if n.Rest != nil {
+		if len(n.List) != 0 {
+			s += ", "
+		}
+		s += "..." + n.Rest.String()
+	}
+	return s + ")"
+}
+
+// FuncDecl is a function declaration or expression, including generators and async functions.
+type FuncDecl struct {
+	Loc
+	Async     bool
+	Generator bool
+	Name      *Ident // can be nil for expressions and default exports
+	Params    Params
+	Body      BlockStmt
+}
+
+func (n FuncDecl) String() string {
+	s := "Decl("
+	if n.Async {
+		s += "async "
+	}
+	s += "function"
+	if n.Generator {
+		s += "*"
+	}
+	if n.Name != nil {
+		s += " " + n.Name.String()
+	}
+	return s + " " + n.Params.String() + " " + n.Body.String() + ")"
+}
+
+// MethodDecl is a method definition in an object literal or class.
+type MethodDecl struct {
+	Loc
+	Static    bool
+	Async     bool
+	Generator bool
+	Get       bool
+	Set       bool
+	Name      PropertyName
+	Params    Params
+	Body      BlockStmt
+}
+
+func (n MethodDecl) String() string {
+	s := "Method("
+	if n.Static {
+		s += "static "
+	}
+	if n.Async {
+		s += "async "
+	}
+	if n.Generator {
+		s += "* "
+	}
+	if n.Get {
+		s += "get "
+	}
+	if n.Set {
+		s += "set "
+	}
+	return s + n.Name.String() + " " + n.Params.String() + " " + n.Body.String() + ")"
+}
+
+// FieldDefinition is a field in a class body.
+type FieldDefinition struct {
+	Loc
+	Static bool
+	Name   PropertyName
+	Init   IExpr // can be nil
+}
+
+func (n FieldDefinition) String() string {
+	s := "Field("
+	if n.Static {
+		s += "static "
+	}
+	s += n.Name.String()
+	if n.Init != nil {
+		s += " = " + n.Init.String()
+	}
+	return s + ")"
+}
+
+// ClassElement is an element of a class body. Exactly one of its fields is set.
+type ClassElement struct {
+	StaticBlock *BlockStmt
+	Method      *MethodDecl
+	Field       *FieldDefinition
+}
+
+func (n ClassElement) String() string {
+	if n.StaticBlock != nil {
+		return "Static(" + n.StaticBlock.String() + ")"
+	} else if n.Method != nil {
+		return n.Method.String()
+	}
+	return n.Field.String()
+}
+
+// ClassDecl is a class declaration or expression.
+type ClassDecl struct {
+	Loc
+	Name    *Ident // can be nil for expressions and default exports
+	Extends IExpr  // can be nil
+	List    []ClassElement
+}
+
+func (n ClassDecl) String() string {
+	s := "Decl(class"
+	if n.Name != nil {
+		s += " " + n.Name.String()
+	}
+	if n.Extends != nil {
+		s += " extends " + n.Extends.String()
+	}
+	for _, item := range n.List {
+		s += " " + item.String()
+	}
+	return s + ")"
+}
+
+////////////////////////////////////////////////////////////////
+
+// Ident is an identifier reference or binding identifier.
+type Ident struct {
+	Loc
+	Data []byte
+}
+
+func (n Ident) String() string {
+	return string(n.Data)
+}
+
+// LiteralExpr is a numeric, string, regular expression, boolean, null, this, or super literal.
+type LiteralExpr struct {
+	Loc
+	TokenType
+	Data []byte
+}
+
+func (n LiteralExpr) String() string {
+	return string(n.Data)
+}
+
+// Element is an array literal element. A nil Value is an elision.
+type Element struct {
+	Value  IExpr
+	Spread bool
+}
+
+func (n Element) String() string {
+	if n.Value == nil {
+		return ""
+	} else if n.Spread {
+		return "..." + n.Value.String()
+	}
+	return n.Value.String()
+}
+
+// ArrayExpr is an array literal.
+type ArrayExpr struct {
+	Loc
+	List []Element
+}
+
+func (n ArrayExpr) String() string {
+	s := "["
+	for i, item := range n.List {
+		if i != 0 {
+			s += ", "
+		}
+		if item.Value != nil {
+			s += item.String()
+		}
+	}
+	if 0 < len(n.List) && n.List[len(n.List)-1].Value == nil {
+		s += ","
+	}
+	return s + "]"
+}
+
+// Property is an object literal property. Name is nil for spread properties.
+type Property struct {
+	Name   *PropertyName
+	Spread bool
+	Value  IExpr
+	Init   IExpr // only for cover initialized names such as {a = 1} which must become a binding pattern
+}
+
+func (n Property) String() string {
+	if n.Spread {
+		return "..." + n.Value.String()
+	} else if _, ok := n.Value.(*MethodDecl); ok {
+		return n.Value.String()
+	}
+	s := ""
+	if v, ok := n.Value.(*Ident); !ok || n.Name.IsComputed() || string(v.Data) != string(n.Name.Literal.Data) {
+		s += n.Name.String() + ": "
+	}
+	s += n.Value.String()
+	if n.Init != nil {
+		s += " = " + n.Init.String()
+	}
+	return s
+}
+
+// ObjectExpr is an object literal.
+type ObjectExpr struct {
+	Loc
+	List []Property
+}
+
+func (n ObjectExpr) String() string {
+	s := "{"
+	for i, item := range n.List {
+		if i != 0 {
+			s += ","
+		}
+		s += " " + item.String()
+	}
+	return s + " }"
+}
+
+// TemplatePart is a string part of a template literal followed by a substitution expression.
+type TemplatePart struct {
+	Value []byte // including ` or } and ${
+	Expr  IExpr
+}
+
+// TemplateExpr is a template literal, optionally tagged.
+type TemplateExpr struct {
+	Loc
+	Tag      IExpr // can be nil
+	List     []TemplatePart
+	Tail     []byte // including } or ` and `
+	Optional bool
+}
+
+func (n TemplateExpr) String() string {
+	s := ""
+	if n.Tag != nil {
+		s += n.Tag.String()
+		if n.Optional {
+			s += "?."
+		}
+	}
+	for _, item := range n.List {
+		s += string(item.Value) + item.Expr.String()
+	}
+	return s + string(n.Tail)
+}
+
+// GroupExpr is a parenthesized expression.
+type GroupExpr struct {
+	Loc
+	X IExpr
+}
+
+func (n GroupExpr) String() string {
+	return "(" + n.X.String() + ")"
+}
+
+// IndexExpr is a computed member access.
+type IndexExpr struct {
+	Loc
+	X        IExpr
+	Y        IExpr
+	Optional bool
+}
+
+func (n IndexExpr) String() string {
+	if n.Optional {
+		return "(" + n.X.String() + "?.[" + n.Y.String() + "])"
+	}
+	return "(" + n.X.String() + "[" + n.Y.String() + "])"
+}
+
+// DotExpr is a member access by name.
+type DotExpr struct {
+	Loc
+	X        IExpr
+	Y        []byte
+	Optional bool
+}
+
+func (n DotExpr) String() string {
+	if n.Optional {
+		return "(" + n.X.String() + "?." + string(n.Y) + ")"
+	}
+	return "(" + n.X.String() + "." + string(n.Y) + ")"
+}
+
+// NewTargetExpr is the new.target meta property.
+type NewTargetExpr struct {
+	Loc
+}
+
+func (n NewTargetExpr) String() string {
+	return "(new.target)"
+}
+
+// ImportMetaExpr is the import.meta meta property.
+type ImportMetaExpr struct {
+	Loc
+}
+
+func (n ImportMetaExpr) String() string {
+	return "(import.meta)"
+}
+
+// Arg is an argument in a call, optionally spread.
+type Arg struct {
+	Value IExpr
+	Rest  bool
+}
+
+func (n Arg) String() string {
+	if n.Rest {
+		return "..." + n.Value.String()
+	}
+	return n.Value.String()
+}
+
+// Args is a list of arguments.
+type Args struct {
+	Loc
+	List []Arg
+}
+
+func (n Args) String() string {
+	s := "("
+	for i, item := range n.List {
+		if i != 0 {
+			s += ", "
+		}
+		s += item.String()
+	}
+	return s + ")"
+}
+
+// NewExpr is a new expression with optional arguments.
+type NewExpr struct {
+	Loc
+	X    IExpr
+	Args *Args // can be nil
+}
+
+func (n NewExpr) String() string {
+	if n.Args != nil {
+		return "(new " + n.X.String() + n.Args.String() + ")"
+	}
+	return "(new " + n.X.String() + ")"
+}
+
+// CallExpr is a function call, including super and import calls.
+type CallExpr struct {
+	Loc
+	X        IExpr
+	Args     Args
+	Optional bool
+}
+
+func (n CallExpr) String() string {
+	if n.Optional {
+		return "(" + n.X.String() + "?." + n.Args.String() + ")"
+	}
+	return "(" + n.X.String() + n.Args.String() + ")"
+}
+
+// UnaryExpr is a prefix or postfix unary expression, including await, delete, typeof, void, ++, and --.
+type UnaryExpr struct {
+	Loc
+	Op      string
+	X       IExpr
+	Postfix bool
+}
+
+func (n UnaryExpr) String() string {
+	if n.Postfix {
+		return "(" + n.X.String() + n.Op + ")"
+	} else if 'a' <= n.Op[0] && n.Op[0] <= 'z' {
+		return "(" + n.Op + " " + n.X.String() + ")"
+	}
+	return "(" + n.Op + n.X.String() + ")"
+}
+
+// BinaryExpr is a binary expression, including assignments.
+type BinaryExpr struct {
+	Loc
+	Op   string
+	X, Y IExpr
+}
+
+func (n BinaryExpr) String() string {
+	return "(" + n.X.String() + " " + n.Op + " " + n.Y.String() + ")"
+}
+
+// CondExpr is a conditional (ternary) expression.
+type CondExpr struct {
+	Loc
+	Cond, X, Y IExpr
+}
+
+func (n CondExpr) String() string {
+	return "(" + n.Cond.String() + " ? " + n.X.String() + " : " + n.Y.String() + ")"
+}
+
+// YieldExpr is a yield expression inside a generator.
+type YieldExpr struct {
+	Loc
+	Generator bool
+	X         IExpr // can be nil
+}
+
+func (n YieldExpr) String() string {
+	s := "(yield"
+	if n.Generator {
+		s += "*"
+	}
+	if n.X != nil {
+		s += " " + n.X.String()
+	}
+	return s + ")"
+}
+
+// CommaExpr is a list of expressions separated by commas.
+type CommaExpr struct {
+	Loc
+	List []IExpr
+}
+
+func (n CommaExpr) String() string {
+	return "(" + joinNodes(n.List, ", ") + ")"
+}
+
+// ArrowFunc is an arrow function. Either Body or Expr is set for a block or concise body respectively.
+type ArrowFunc struct {
+	Loc
+	Async  bool
+	Params Params
+	Body   *BlockStmt
+	Expr   IExpr
+}
+
+func (n ArrowFunc) String() string {
+	s := "("
+	if n.Async {
+		s += "async "
+	}
+	s += n.Params.String() + " => "
+	if n.Body != nil {
+		s += n.Body.String()
+	} else {
+		s += n.Expr.String()
+	}
+	return s + ")"
+}
+
+////////////////////////////////////////////////////////////////
+
+func (n *BlockStmt) stmtNode()    {}
+func (n *EmptyStmt) stmtNode()    {}
+func (n *ExprStmt) stmtNode()     {}
+func (n *VarDecl) stmtNode()      {}
+func (n *IfStmt) stmtNode()       {}
+func (n *DoWhileStmt) stmtNode()  {}
+func (n *WhileStmt) stmtNode()    {}
+func (n *ForStmt) stmtNode()      {}
+func (n *ForInStmt) stmtNode()    {}
+func (n *ForOfStmt) stmtNode()    {}
+func (n *SwitchStmt) stmtNode()   {}
+func (n *BranchStmt) stmtNode()   {}
+func (n *ReturnStmt) stmtNode()   {}
+func (n *WithStmt) stmtNode()     {}
+func (n *LabelledStmt) stmtNode() {}
+func (n *ThrowStmt) stmtNode()    {}
+func (n *TryStmt) stmtNode()      {}
+func (n *DebuggerStmt) stmtNode() {}
+func (n *ImportStmt) stmtNode()   {}
+func (n *ExportStmt) stmtNode()   {}
+func (n *FuncDecl) stmtNode()     {}
+func (n *ClassDecl) stmtNode()    {}
+
+func (n *Ident) exprNode()          {}
+func (n *LiteralExpr) exprNode()    {}
+func (n *ArrayExpr) exprNode()      {}
+func (n *ObjectExpr) exprNode()     {}
+func (n *TemplateExpr) exprNode()   {}
+func (n *GroupExpr) exprNode()      {}
+func (n *IndexExpr) exprNode()      {}
+func (n *DotExpr) exprNode()        {}
+func (n *NewTargetExpr) exprNode()  {}
+func (n *ImportMetaExpr) exprNode() {}
+func (n *NewExpr) exprNode()        {}
+func (n *CallExpr) exprNode()       {}
+func (n *UnaryExpr) exprNode()      {}
+func (n *BinaryExpr) exprNode()     {}
+func (n *CondExpr) exprNode()       {}
+func (n *YieldExpr) exprNode()      {}
+func (n *CommaExpr) exprNode()      {}
+func (n *ArrowFunc) exprNode()      {}
+func (n *FuncDecl) exprNode()       {}
+func (n *MethodDecl) exprNode()     {}
+func (n *ClassDecl) exprNode()      {}
+
+func (n *Ident) bindingNode()         {}
+func (n *BindingArray) bindingNode()  {}
+func (n *BindingObject) bindingNode() {}
@@ -10,51 +10,73 @@ type Hash uint32
 
 // Unique hash definitions to be used instead of strings
 const (
-	Break      Hash = 0x5    // break
-	Case       Hash = 0x3404 // case
-	Catch      Hash = 0xba05 // catch
-	Class      Hash = 0x505  // class
-	Const      Hash = 0x2c05 // const
-	Continue   Hash = 0x3e08 // continue
-	Debugger   Hash = 0x8408 // debugger
-	Default    Hash = 0xab07 // default
-	Delete     Hash = 0xcd06 // delete
-	Do         Hash = 0x4c02 // do
-	Else       Hash = 0x3704 // else
-	Enum       Hash = 0x3a04 // enum
-	Export     Hash = 0x1806 // export
-	Extends    Hash = 0x4507 // extends
-	False      Hash = 0x5a05 // false
-	Finally    Hash = 0x7a07 // finally
-	For        Hash = 0xc403 // for
-	Function   Hash = 0x4e08 // function
-	If         Hash = 0x5902 // if
-	Implements Hash = 0x5f0a // implements
-	Import     Hash = 0x6906 // import
-	In         Hash = 0x4202 // in
-	Instanceof Hash = 0x710a // instanceof
-	Interface  Hash = 0x8c09 // interface
-	Let        Hash = 0xcf03 // let
-	New        Hash = 0x1203 // new
-	Null       Hash = 0x5504 // null
-	Package    Hash = 0x9507 // package
-	Private    Hash = 0x9c07 // private
-	Protected  Hash = 0xa309 // protected
-	Public     Hash = 0xb506 // public
-	Return     Hash = 0xd06  // return
-	Static     Hash = 0x2f06 // static
-	Super      Hash = 0x905  // super
-	Switch     Hash = 0x2606 // switch
-	This       Hash = 0x2304 // this
-	Throw      Hash = 0x1d05 // throw
-	True       Hash = 0xb104 // true
-	Try        Hash = 0x6e03 // try
-	Typeof     Hash = 0xbf06 // typeof
-	Var        Hash = 0xc703 // var
-	Void       Hash = 0xca04 // void
-	While      Hash = 0x1405 // while
-	With       Hash = 0x2104 // with
-	Yield      Hash = 0x8005 // yield
+	Abstract   Hash = 0xab08  // abstract
+	As         Hash = 0x1002  // as
+	Asserts    Hash = 0x1007  // asserts
+	Async      Hash = 0x9805  // async
+	Await      Hash = 0xda05  // await
+	Break      Hash = 0x8905  // break
+	Case       Hash = 0xa404  // case
+	Catch      Hash = 0xdf05  // catch
+	Class      Hash = 0xe05   // class
+	Const      Hash = 0x9c05  // const
+	Continue   Hash = 0xe408  // continue
+	Debugger   Hash = 0xd208  // debugger
+	Declare    Hash = 0xc207  // declare
+	Default    Hash = 0x1f07  // default
+	Delete     Hash = 0x306   // delete
+	Do         Hash = 0xca02  // do
+	Else       Hash = 0x6004  // else
+	Enum       Hash = 0x6304  // enum
+	Export     Hash = 0x7506  // export
+	Extends    Hash = 0x7d07  // extends
+	False      Hash = 0xb705  // false
+	Finally    Hash = 0x9107  // finally
+	For        Hash = 0xec03  // for
+	From       Hash = 0x2f04  // from
+	Function   Hash = 0x3908  // function
+	Get        Hash = 0x6b03  // get
+	If         Hash = 0x3802  // if
+	Implements Hash = 0xef0a  // implements
+	Import     Hash = 0xf906  // import
+	In         Hash = 0x2602  // in
+	Infer      Hash = 0xff05  // infer
+	Instanceof Hash = 0x260a  // instanceof
+	Interface  Hash = 0x10409 // interface
+	Is         Hash = 0x4602  // is
+	Keyof      Hash = 0x8d05  // keyof
+	Let        Hash = 0x503   // let
+	Meta       Hash = 0x6604  // meta
+	Module     Hash = 0x3206  // module
+	Namespace  Hash = 0x5809  // namespace
+	New        Hash = 0x4003  // new
+	Null       Hash = 0x10d04 // null
+	Of         Hash = 0x2e02  // of
+	Override   Hash = 0xbc08  // override
+	Package    Hash = 0x11107 // package
+	Private    Hash = 0x11807 // private
+	Protected  Hash = 0x1709  // protected
+	Public     Hash = 0x906   // public
+	Readonly   Hash = 0xc708  // readonly
+	Return     Hash = 0x5306  // return
+	Satisfies  Hash = 0x4709  // satisfies
+	Set        Hash = 0xa603  // set
+	Static     Hash = 0x9f06  // static
+	Super      Hash = 0x4f05  // super
+	Switch     Hash = 0x8306  // switch
+	Target     Hash = 0x6806  // target
+	This       Hash = 0x4404  // this
+	Throw      Hash = 0x6d05  // throw
+	True       Hash = 0x7a04  // true
+	Try        Hash = 0xa803  // try
+	Type       Hash = 0xb204  // type
+	Typeof     Hash = 0xb206  // typeof
+	Unique     Hash = 0x11f06 // unique
+	Var        Hash = 0x12503 // var
+	Void       Hash = 0x4     // void
+	While      Hash = 0x7105  // while
+	With       Hash = 0x4204  // with
+	Yield      Hash = 0xce05  // yield
 )
 
 // String returns the hash' name.
@@ -102,55 +124,78 @@ NEXT:
 
 const _Hash_hash0 = 0x9acb0442
 const _Hash_maxLen = 10
-const _Hash_text = "breakclassupereturnewhilexporthrowithiswitchconstaticaselsen" +
-	"umcontinuextendsdofunctionullifalseimplementsimportryinstanc" +
-	"eofinallyieldebuggerinterfacepackageprivateprotectedefaultru" +
-	"epublicatchtypeoforvarvoidelete"
+const _Hash_text = "voideletepubliclassertsprotectedefaultinstanceofromoduleifun" +
+	"ctionewithisatisfiesupereturnamespacelsenumetargethrowhilexp" +
+	"ortruextendswitchbreakeyofinallyasynconstaticasetryabstracty" +
+	"peofalseoverrideclareadonlyieldebuggerawaitcatchcontinuefori" +
+	"mplementsimportinferinterfacenullpackageprivateuniquevar"
 
-var _Hash_table = [1 << 6]Hash{
-	0x0:  0x2f06, // static
-	0x1:  0x9c07, // private
-	0x3:  0xb104, // true
-	0x6:  0x5a05, // false
-	0x7:  0x4c02, // do
-	0x9:  0x2c05, // const
-	0xa:  0x2606, // switch
-	0xb:  0x6e03, // try
-	0xc:  0x1203, // new
-	0xd:  0x4202, // in
-	0xf:  0x8005, // yield
-	0x10: 0x5f0a, // implements
-	0x11: 0xc403, // for
-	0x12: 0x505,  // class
-	0x13: 0x3a04, // enum
-	0x16: 0xc703, // var
-	0x17: 0x5902, // if
-	0x19: 0xcf03, // let
-	0x1a: 0x9507, // package
-	0x1b: 0xca04, // void
-	0x1c: 0xcd06, // delete
-	0x1f: 0x5504, // null
-	0x20: 0x1806, // export
-	0x21: 0xd06,  // return
-	0x23: 0x4507, // extends
-	0x25: 0x2304, // this
-	0x26: 0x905,  // super
-	0x27: 0x1405, // while
-	0x29: 0x5,    // break
-	0x2b: 0x3e08, // continue
-	0x2e: 0x3404, // case
-	0x2f: 0xab07, // default
-	0x31: 0x8408, // debugger
-	0x32: 0x1d05, // throw
-	0x33: 0xbf06, // typeof
-	0x34: 0x2104, // with
-	0x35: 0xba05, // catch
-	0x36: 0x4e08, // function
-	0x37: 0x710a, // instanceof
-	0x38: 0xa309, // protected
-	0x39: 0x8c09, // interface
-	0x3b: 0xb506, // public
-	0x3c: 0x3704, // else
-	0x3d: 0x7a07, // finally
-	0x3f: 0x6906, // import
+var _Hash_table = [1 << 7]Hash{
+	0x0:  0x9f06,  // static
+	0x1:  0x1007,  // asserts
+	0x3:  0x7a04,  // true
+	0x7:  0xca02,  // do
+	0xa:  0xff05,  // infer
+	0xb:  0xa803,  // try
+	0xd:  0x2602,  // in
+	0xf:  0x6604,  // meta
+	0x10: 0x260a,  // instanceof
+	0x12: 0xe05,   // class
+	0x14: 0xda05,  // await
+	0x15: 0x4003,  // new
+	0x17: 0x6004,  // else
+	0x19: 0x9107,  // finally
+	0x1b: 0x2e02,  // of
+	0x1c: 0xab08,  // abstract
+	0x20: 0x7506,  // export
+	0x23: 0x10d04, // null
+	0x25: 0x4404,  // this
+	0x26: 0x4f05,  // super
+	0x27: 0x7d07,  // extends
+	0x29: 0xb705,  // false
+	0x2b: 0xe408,  // continue
+	0x2c: 0xc708,  // readonly
+	0x2d: 0x11f06, // unique
+	0x2e: 0x6b03,  // get
+	0x2f: 0x4709,  // satisfies
+	0x32: 0x6d05,  // throw
+	0x34: 0xc207,  // declare
+	0x35: 0xdf05,  // catch
+	0x36: 0x9805,  // async
+	0x37: 0x3206,  // module
+	0x39: 0x10409, // interface
+	0x3b: 0x906,   // public
+	0x3c: 0x8d05,  // keyof
+	0x3f: 0xf906,  // import
+	0x41: 0x11807, // private
+	0x45: 0xa603,  // set
+	0x46: 0x2f04,  // from
+	0x48: 0xa404,  // case
+	0x49: 0x9c05,  // const
+	0x4a: 0x8306,  // switch
+	0x4b: 0x503,   // let
+	0x4c: 0x4,     // void
+	0x4e: 0xb204,  // type
+	0x4f: 0xce05,  // yield
+	0x50: 0xef0a,  // implements
+	0x51: 0xec03,  // for
+	0x53: 0x6304,  // enum
+	0x56: 0x12503, // var
+	0x59: 0x3908,  // function
+	0x5a: 0x11107, // package
+	0x5e: 0x1002,  // as
+	0x61: 0x5306,  // return
+	0x63: 0x6806,  // target
+	0x66: 0x4602,  // is
+	0x67: 0x7105,  // while
+	0x69: 0x8905,  // break
+	0x6e: 0x4204,  // with
+	0x6f: 0x1f07,  // default
+	0x71: 0xd208,  // debugger
+	0x73: 0xb206,  // typeof
+	0x75: 0x3802,  // if
+	0x78: 0x1709,  // protected
+	0x79: 0x5809,  // namespace
+	0x7a: 0xbc08,  // override
+	0x7d: 0x306,   // delete
 }
@@ -1,4 +1,4 @@
-// Package js is an ECMAScript5.1 lexer following the specifications at http://www.ecma-international.org/ecma-262/5.1/.
+// Package js is an ECMAScript lexer and parser following the specifications at https://tc39.es/ecma262/, including the syntax up to ES2022 such as optional chaining, BigInt literals, and private class members.
 package js
 
 import (
@@ -27,22 +27,26 @@ const (
 	MultiLineCommentToken // token for comments with line terminators (not just any /*block*/)
 	IdentifierToken
 	PunctuatorToken /* { } ( ) [ ] . ; , < > <= >= == != === !==  + - * % ++ -- << >>
-	   >>> & | ^ ! ~ && || ? : = += -= *= %= <<= >>= >>>= &= |= ^= / /= >= */
-	NumericToken
+	   >>> & | ^ ! ~ && || ? : = += -= *= %= <<= >>= >>>= &= |= ^= / /= >= ... => ** **= ?. ?? ??= &&= ||= */
+	NumericToken // also: BigInt literals with an n suffix and numeric separators
 	StringToken
 	RegexpToken
 	TemplateToken
+	PrivateIdentifierToken // #name
+	JSXTextToken           // text between JSX tags, only in JSXMode
 )
 
-// TokenState determines a state in which next token should be read
+// TokenState determines a state in which next token should be read. In ExprState and StmtState a / starts a regular expression (the InputElementRegExp goal of the specification), otherwise it is a division (the InputElementDiv goal).
 type TokenState uint32
 
 // TokenState values
 const (
-	ExprState TokenState = iota
-	StmtParensState
-	SubscriptState
-	PropNameState
+	ExprState       TokenState = iota // start of an expression, where { starts an object literal
+	StmtParensState                   // after if, while, for, with, switch, or catch
+	SubscriptState                    // after an operand, where { starts a block such as a function body
+	PropNameState                     // after . or ?., or at the start of an object literal, where keywords are property names
+	StmtState                         // start of a statement, where { starts a block
+	RestrictedState                   // after return, throw, break, continue, or yield, where a line terminator ends the statement
 )
 
 // ParsingContext determines the context in which following token should be parsed.
@@ -54,8 +58,25 @@ const (
 	GlobalContext ParsingContext = iota
 	StmtParensContext
 	ExprParensContext
-	BracesContext
+	BracesContext // within an object literal or other expression braces
 	TemplateContext
+	JSXTagContext        // within an opening JSX tag
+	JSXClosingTagContext // within a closing or self-closing JSX tag
+	JSXChildrenContext   // between the opening and closing JSX tags of an element
+	TypeArgumentsContext // within TypeScript type arguments or parameters
+	BlockContext         // within a block statement, function body, or class body
+	BracketsContext      // within an array literal or computed member
+	ConditionalContext   // between the ? and : of a conditional expression
+	FunctionExprContext  // from the function or class keyword of a function or class expression until the end of its body
+)
+
+// Mode determines the syntax extensions recognized by the lexer.
+type Mode uint32
+
+// Mode values
+const (
+	JSXMode        Mode = 1 << iota // JSX elements, attributes, and text
+	TypeScriptMode                  // TypeScript type arguments, non-null assertions, and decorators
 )
 
 // String returns the string representation of a TokenType.
@@ -85,6 +106,10 @@ func (tt TokenType) String() string {
 		return "Regexp"
 	case TemplateToken:
 		return "Template"
+	case PrivateIdentifierToken:
+		return "PrivateIdentifier"
+	case JSXTextToken:
+		return "JSXText"
 	}
 	return "Invalid(" + strconv.Itoa(int(tt)) + ")"
 }
@@ -93,10 +118,15 @@ func (tt TokenType) String() string {
 
 // Lexer is the state for the lexer.
 type Lexer struct {
-	r         *buffer.Lexer
-	stack     []ParsingContext
-	state     TokenState
-	emptyLine bool
+	r             *buffer.Lexer
+	mode          Mode
+	stream        bool // reading from a stream, see nextStream
+	stack         []ParsingContext
+	saved         []ParsingContext // copy of stack, see nextStream
+	state         TokenState
+	emptyLine     bool
+	asi           bool // see ASI
+	exportDefault bool // the next function or class keyword follows export default and starts a declaration
 }
 
 // NewLexer returns a new Lexer for a given io.Reader.
@@ -104,11 +134,35 @@ func NewLexer(r io.Reader) *Lexer {
 	return &Lexer{
 		r:         buffer.NewLexer(r),
 		stack:     make([]ParsingContext, 0, 16),
-		state:     ExprState,
+		state:     StmtState,
+		emptyLine: true,
+	}
+}
+
+// NewStreamLexer returns a new Lexer that reads the script from r in chunks, so that memory usage does not grow with the size of the script.
+// The data of a token is only valid until the next call to Next.
+func NewStreamLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		r:         buffer.NewLexerStream(r),
+		stream:    true,
+		stack:     make([]ParsingContext, 0, 16),
+		state:     StmtState,
 		emptyLine: true,
 	}
 }
 
+// SetMode enables the syntax extensions of mode, which can be combined as in JSXMode | TypeScriptMode for TSX. It must be called before the first call to Next.
+func (l *Lexer) SetMode(mode Mode) {
+	l.mode = mode
+}
+
+func (l *Lexer) context() ParsingContext {
+	if last := len(l.stack) - 1; last >= 0 {
+		return l.stack[last]
+	}
+	return GlobalContext
+}
+
 func (l *Lexer) enterContext(context ParsingContext) {
 	l.stack = append(l.stack, context)
 }
@@ -121,6 +175,13 @@ func (l *Lexer) leaveContext() ParsingContext {
 	return ctx
 }
 
+// leaveConditionals leaves unterminated conditional contexts, which are left by the optional marks of TypeScript such as in (a?) => a.
+func (l *Lexer) leaveConditionals() {
+	for l.context() == ConditionalContext {
+		l.leaveContext()
+	}
+}
+
 // Err returns the error encountered during lexing, this is often io.EOF but also other errors can be returned.
 func (l *Lexer) Err() error {
 	return l.r.Err()
@@ -136,8 +197,27 @@ func (l *Lexer) Offset() int {
 	return l.r.Offset()
 }
 
+// ASI returns true if a semicolon is automatically inserted at the last token returned by Next, which is a LineTerminatorToken or a MultiLineCommentToken spanning multiple lines, see https://tc39.es/ecma262/#sec-automatic-semicolon-insertion.
+// This is the case after the restricted productions return, throw, break, continue, and yield, before the postfix ++ and -- operators and the => of an arrow function, and where the next line does not continue the statement.
+// Line terminators for which ASI returns false can be removed without changing the meaning of the program.
+func (l *Lexer) ASI() bool {
+	return l.asi
+}
+
 // Next returns the next Token. It returns ErrorToken when an error was encountered. Using Err() one can retrieve the error message.
 func (l *Lexer) Next() (TokenType, []byte) {
+	l.asi = false
+	if l.stream {
+		return l.nextStream()
+	} else if l.mode&JSXMode != 0 {
+		if ctx := l.context(); ctx == JSXChildrenContext {
+			return l.nextJSXChild()
+		} else if ctx == JSXTagContext || ctx == JSXClosingTagContext {
+			if tt, data := l.nextJSXTag(); tt != UnknownToken {
+				return tt, data
+			}
+		}
+	}
 	c := l.r.Peek(0)
 	switch c {
 	case '(':
@@ -151,8 +231,9 @@ func (l *Lexer) Next() (TokenType, []byte) {
 		l.emptyLine = false
 		return PunctuatorToken, l.r.Shift()
 	case ')':
+		l.leaveConditionals()
 		if l.leaveContext() == StmtParensContext {
-			l.state = ExprState
+			l.state = StmtState
 		} else {
 			l.state = SubscriptState
 		}
@@ -160,45 +241,142 @@ func (l *Lexer) Next() (TokenType, []byte) {
 		l.emptyLine = false
 		return PunctuatorToken, l.r.Shift()
 	case '{':
-		l.enterContext(BracesContext)
-		l.state = ExprState
+		if l.state == ExprState || l.state == RestrictedState && !l.emptyLine {
+			l.enterContext(BracesContext)
+			l.state = PropNameState
+		} else {
+			// blocks follow statements, or operands such as the parameters of a function or the name of a class
+			l.enterContext(BlockContext)
+			l.state = StmtState
+		}
 		l.r.Move(1)
 		l.emptyLine = false
 		return PunctuatorToken, l.r.Shift()
 	case '}':
-		if l.leaveContext() == TemplateContext && l.consumeTemplateToken() {
+		l.leaveConditionals()
+		ctx := l.leaveContext()
+		if ctx == TemplateContext && l.consumeTemplateToken() {
 			l.emptyLine = false
 			return TemplateToken, l.r.Shift()
+		} else if ctx == BracesContext {
+			l.state = SubscriptState
+		} else if ctx == BlockContext && l.context() == FunctionExprContext {
+			// end of the body of a function or class expression, which is an operand
+			l.leaveContext()
+			l.state = SubscriptState
 		} else {
-			// will work incorrectly for objects or functions divided by something,
-			// but that's an extremely rare case
-			l.state = ExprState
-			l.r.Move(1)
-			l.emptyLine = false
-			return PunctuatorToken, l.r.Shift()
+			l.state = StmtState
 		}
+		l.r.Move(1)
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
+	case '[':
+		l.enterContext(BracketsContext)
+		l.state = ExprState
+		l.r.Move(1)
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
 	case ']':
+		l.leaveConditionals()
+		l.leaveContext()
 		l.state = SubscriptState
 		l.r.Move(1)
 		l.emptyLine = false
 		return PunctuatorToken, l.r.Shift()
-	case '[', ';', ',', '~', '?', ':':
+	case '?':
+		if l.r.Peek(1) == '.' && (l.r.Peek(2) < '0' || '9' < l.r.Peek(2)) {
+			// optional chaining, but not a conditional followed by a number such as a?.5:b
+			l.state = PropNameState
+			l.r.Move(2)
+			l.emptyLine = false
+			return PunctuatorToken, l.r.Shift()
+		} else if l.r.Peek(1) == '?' {
+			l.r.Move(2)
+			if l.r.Peek(0) == '=' {
+				l.r.Move(1)
+			}
+		} else {
+			l.enterContext(ConditionalContext)
+			l.r.Move(1)
+		}
+		l.state = ExprState
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
+	case ':':
+		if ctx := l.context(); ctx == ConditionalContext {
+			l.leaveContext()
+			l.state = ExprState
+		} else if ctx == GlobalContext || ctx == BlockContext {
+			// after a label, case, or default
+			l.state = StmtState
+		} else {
+			l.state = ExprState
+		}
+		l.r.Move(1)
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
+	case ';':
+		l.leaveConditionals()
+		l.state = StmtState
+		l.r.Move(1)
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
+	case '#':
+		l.r.Move(1)
+		if l.consumeIdentifierToken() {
+			l.state = SubscriptState
+			l.emptyLine = false
+			return PrivateIdentifierToken, l.r.Shift()
+		}
+		l.r.Move(-1)
+	case '@':
+		if l.mode&TypeScriptMode != 0 {
+			// decorator
+			l.state = ExprState
+			l.r.Move(1)
+			l.emptyLine = false
+			return PunctuatorToken, l.r.Shift()
+		}
+	case ',', '~':
 		l.state = ExprState
 		l.r.Move(1)
 		l.emptyLine = false
 		return PunctuatorToken, l.r.Shift()
 	case '<', '>', '=', '!', '+', '-', '*', '%', '&', '|', '^':
+		if c == '<' && l.mode&JSXMode != 0 && l.isExprStart() && isJSXTagStart(l.r.Peek(1)) && (l.mode&TypeScriptMode == 0 || !l.isTSXTypeParameters()) {
+			l.enterContext(JSXTagContext)
+			l.r.Move(1)
+			l.emptyLine = false
+			return PunctuatorToken, l.r.Shift()
+		} else if l.mode&TypeScriptMode != 0 {
+			if tt, data := l.nextTypeScript(c); tt != UnknownToken {
+				return tt, data
+			}
+		}
 		if l.consumeHTMLLikeCommentToken() {
 			return SingleLineCommentToken, l.r.Shift()
 		} else if l.consumePunctuatorToken() {
-			l.state = ExprState
+			if data := l.r.Lexeme(); (c == '+' || c == '-') && len(data) == 2 && data[1] == c {
+				// postfix operators follow an operand on the same line, otherwise they are prefix operators
+				if l.state != SubscriptState || l.emptyLine {
+					l.state = ExprState
+				}
+			} else if c == '=' && len(data) == 2 && data[1] == '>' {
+				// the body of an arrow function is either a block or an expression
+				l.state = StmtState
+			} else {
+				l.state = ExprState
+			}
 			l.emptyLine = false
 			return PunctuatorToken, l.r.Shift()
 		}
 	case '/':
 		if tt := l.consumeCommentToken(); tt != UnknownToken {
+			if tt == MultiLineCommentToken {
+				l.asi = l.isASI()
+			}
 			return tt, l.r.Shift()
-		} else if l.state == ExprState && l.consumeRegexpToken() {
+		} else if l.isExprStart() && l.consumeRegexpToken() {
 			l.state = SubscriptState
 			l.emptyLine = false
 			return RegexpToken, l.r.Shift()
@@ -213,6 +391,12 @@ func (l *Lexer) Next() (TokenType, []byte) {
 			l.emptyLine = false
 			return NumericToken, l.r.Shift()
 		} else if c == '.' {
+			if l.r.Peek(1) == '.' && l.r.Peek(2) == '.' {
+				l.state = ExprState
+				l.r.Move(3)
+				l.emptyLine = false
+				return PunctuatorToken, l.r.Shift()
+			}
 			l.state = PropNameState
 			l.r.Move(1)
 			l.emptyLine = false
@@ -233,6 +417,7 @@ func (l *Lexer) Next() (TokenType, []byte) {
 		l.r.Move(1)
 		for l.consumeLineTerminator() {
 		}
+		l.asi = l.isASI()
 		l.emptyLine = true
 		return LineTerminatorToken, l.r.Shift()
 	case '`':
@@ -244,14 +429,35 @@ func (l *Lexer) Next() (TokenType, []byte) {
 		if l.consumeIdentifierToken() {
 			if l.state != PropNameState {
 				switch hash := ToHash(l.r.Lexeme()); hash {
-				case 0, This, False, True, Null:
+				case Async:
+					// an async function expression continues an expression like a function expression does
+					if (l.state != ExprState && l.state != RestrictedState) || l.exportDefault || !l.followedBy(false, Function) {
+						l.state = SubscriptState
+					}
+				case Export:
+					// followed by a declaration, or by default which is followed by an expression
+					l.exportDefault = l.followedBy(true, Default, Function) || l.followedBy(true, Default, Class) || l.followedBy(true, Default, Async, Function)
+					l.state = StmtState
+				case 0, This, Super, False, True, Null, As, From, Get, Meta, Of, Set, Static, Target,
+					Abstract, Asserts, Declare, Infer, Is, Keyof, Module, Namespace, Override, Readonly, Satisfies, Type, Unique:
+					// contextual keywords are identifiers as far as the lexer is concerned
 					l.state = SubscriptState
-				case If, While, For, With:
+				case If, While, For, With, Switch, Catch:
 					l.state = StmtParensState
+				case Else, Do, Try, Finally:
+					l.state = StmtState
+				case Return, Throw, Break, Continue, Yield:
+					l.state = RestrictedState
+				case Function, Class:
+					if l.exportDefault {
+						l.exportDefault = false
+					} else if l.state == ExprState || l.state == RestrictedState {
+						l.enterContext(FunctionExprContext)
+					}
+					// followed by a name, parameters, or a body, which is a block
+					l.state = SubscriptState
 				default:
-					// This will include keywords that can't be followed by a regexp, but only
-					// by a specified char (like `switch` or `try`), but we don't check for syntax
-					// errors as we don't attempt to parse a full JS grammar when streaming
+					// keywords such as return, typeof, or case that are followed by an expression
 					l.state = ExprState
 				}
 			} else {
@@ -267,6 +473,7 @@ func (l *Lexer) Next() (TokenType, []byte) {
 			} else if l.consumeLineTerminator() {
 				for l.consumeLineTerminator() {
 				}
+				l.asi = l.isASI()
 				l.emptyLine = true
 				return LineTerminatorToken, l.r.Shift()
 			}
@@ -281,10 +488,334 @@ func (l *Lexer) Next() (TokenType, []byte) {
 	return UnknownToken, l.r.Shift()
 }
 
+// nextStream calls Next when reading from a stream. Next changes the state and the context stack while lexing, so these are saved and restored when Next runs out of buffered data, before reading in more data and lexing the token again.
+func (l *Lexer) nextStream() (tt TokenType, data []byte) {
+	l.r.Free(l.r.ShiftLen())
+	offset := l.r.Offset()
+	state, emptyLine, exportDefault := l.state, l.emptyLine, l.exportDefault
+	l.saved = append(l.saved[:0], l.stack...)
+	defer func() {
+		if err := recover(); err != nil {
+			if !l.r.Retry(err, offset) {
+				panic(err)
+			}
+			l.state, l.emptyLine, l.exportDefault = state, emptyLine, exportDefault
+			l.stack = append(l.stack[:0], l.saved...)
+			tt, data = l.nextStream()
+		}
+	}()
+
+	l.stream = false
+	tt, data = l.Next()
+	l.stream = true
+	return
+}
+
+// followedBy returns true if the identifier that was just consumed is followed by the given keywords, which are separated by whitespace and, if newlines is set, by line terminators and comments. It does not consume anything.
+func (l *Lexer) followedBy(newlines bool, keywords ...Hash) bool {
+	mark := l.r.Pos()
+	defer l.r.Rewind(mark)
+	for _, keyword := range keywords {
+		for l.consumeWhitespaceByte() || l.consumeWhitespaceRune() || newlines && (l.consumeLineTerminator() || l.consumeCommentToken() != UnknownToken) {
+		}
+		start := l.r.Pos()
+		if !l.consumeIdentifierToken() || ToHash(l.r.Lexeme()[start:]) != keyword {
+			return false
+		}
+	}
+	return true
+}
+
+// isExprStart returns true if the next token starts an expression, which is where a / starts a regular expression.
+func (l *Lexer) isExprStart() bool {
+	return l.state == ExprState || l.state == StmtState || l.state == RestrictedState
+}
+
+// isASI returns true if a semicolon is automatically inserted at the line terminator that was just consumed. It looks ahead at the next token to see whether it may continue the statement, without consuming it.
+func (l *Lexer) isASI() bool {
+	if l.state != SubscriptState && l.state != RestrictedState {
+		// the line ends in an operator or keyword, or at the start of a statement
+		return false
+	} else if ctx := l.context(); ctx != GlobalContext && ctx != BlockContext {
+		// statements don't end within parentheses, brackets, object literals, or templates
+		return false
+	}
+
+	mark := l.r.Pos()
+	defer l.r.Rewind(mark)
+	for l.consumeWhitespaceByte() || l.consumeWhitespaceRune() || l.consumeLineTerminator() || l.consumeCommentToken() != UnknownToken {
+	}
+	c := l.r.Peek(0)
+	if c == ';' || c == '}' || c == 0 && l.r.Err() != nil {
+		// the statement ends regardless of the line terminator
+		return false
+	} else if l.state == RestrictedState {
+		return true
+	}
+	switch c {
+	case '+', '-':
+		// postfix operators must be on the same line as their operand
+		return l.r.Peek(1) == c
+	case '!':
+		return l.r.Peek(1) != '='
+	case '.':
+		return '0' <= l.r.Peek(1) && l.r.Peek(1) <= '9'
+	case '=':
+		// assignments continue the statement, and => must be on the same line as the arrow parameters
+		return false
+	case '(', '[', '`', ',', ')', ']', ':', '?', '<', '>', '*', '/', '%', '&', '|', '^':
+		return false
+	}
+	if l.consumeIdentifierToken() {
+		hash := ToHash(l.r.Lexeme()[mark:])
+		return hash != In && hash != Instanceof
+	}
+	return true
+}
+
+// regExp rereads the last token of length n, which was a '/' or '/=' punctuator, as a regular expression. It is used by the parser where the lexer expected a division.
+func (l *Lexer) regExp(n int) (tt TokenType, data []byte) {
+	l.r.Rewind(-n)
+	l.r.Skip()
+	if l.stream {
+		offset := l.r.Offset()
+		defer func() {
+			if err := recover(); err != nil {
+				if !l.r.Retry(err, offset) {
+					panic(err)
+				}
+				l.r.Move(n) // Retry rewinds to the start of the token
+				l.r.Skip()
+				tt, data = l.regExp(n)
+			}
+		}()
+	}
+	if l.consumeRegexpToken() {
+		l.state = SubscriptState
+		return RegexpToken, l.r.Shift()
+	}
+	l.r.Move(n)
+	return PunctuatorToken, l.r.Shift()
+}
+
+// div rereads the last token of length n, which was a regular expression, as a '/' or '/=' punctuator. It is used by the parser where the lexer expected a regular expression.
+func (l *Lexer) div(n int) (TokenType, []byte) {
+	l.r.Rewind(-n)
+	l.r.Skip()
+	l.state = SubscriptState
+	return l.Next()
+}
+
 ////////////////////////////////////////////////////////////////
 
 /*
-The following functions follow the specifications at http://www.ecma-international.org/ecma-262/5.1/
+The following functions follow the specification at https://facebook.github.io/jsx/
+*/
+
+// nextJSXTag lexes the JSX-specific tokens within a tag. For other tokens, such as whitespace, comments, and the braces of attribute expressions, it returns UnknownToken without consuming anything, so that they are lexed as in JavaScript.
+func (l *Lexer) nextJSXTag() (TokenType, []byte) {
+	c := l.r.Peek(0)
+	switch c {
+	case '>':
+		if l.leaveContext() == JSXTagContext {
+			// the opening tag was closed, what follows are its children
+			l.enterContext(JSXChildrenContext)
+		} else if l.context() != JSXChildrenContext {
+			// the element was closed and is an operand
+			l.state = SubscriptState
+		}
+	case '/':
+		if c := l.r.Peek(1); c == '/' || c == '*' {
+			return UnknownToken, nil
+		}
+		l.stack[len(l.stack)-1] = JSXClosingTagContext
+	case '{':
+		// attribute expression or spread attribute
+		l.enterContext(BracesContext)
+		l.state = ExprState
+	case '=', '.', ':':
+	case '"', '\'':
+		if l.consumeJSXStringToken() {
+			l.emptyLine = false
+			return StringToken, l.r.Shift()
+		}
+		return UnknownToken, nil
+	default:
+		if l.consumeIdentifierToken() {
+			// JSX names may contain dashes
+			for l.r.Peek(0) == '-' {
+				l.r.Move(1)
+				l.consumeIdentifierToken()
+			}
+			l.emptyLine = false
+			return IdentifierToken, l.r.Shift()
+		}
+		return UnknownToken, nil
+	}
+	l.r.Move(1)
+	l.emptyLine = false
+	return PunctuatorToken, l.r.Shift()
+}
+
+// nextJSXChild lexes the text, child tags, and closing tag between the tags of a JSX element.
+func (l *Lexer) nextJSXChild() (TokenType, []byte) {
+	c := l.r.Peek(0)
+	if c == '<' {
+		l.r.Move(1)
+		if l.r.Peek(0) == '/' {
+			l.stack[len(l.stack)-1] = JSXClosingTagContext
+		} else {
+			l.enterContext(JSXTagContext)
+		}
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
+	} else if c == '{' {
+		l.enterContext(BracesContext)
+		l.state = ExprState
+		l.r.Move(1)
+		l.emptyLine = false
+		return PunctuatorToken, l.r.Shift()
+	}
+	for {
+		if c := l.r.Peek(0); c == '<' || c == '{' || c == 0 && l.r.Err() != nil {
+			break
+		}
+		l.r.Move(1)
+	}
+	if l.r.Pos() == 0 {
+		return ErrorToken, nil
+	}
+	return JSXTextToken, l.r.Shift()
+}
+
+func (l *Lexer) consumeJSXStringToken() bool {
+	// assume to be on ' or "
+	mark := l.r.Pos()
+	delim := l.r.Peek(0)
+	l.r.Move(1)
+	for {
+		c := l.r.Peek(0)
+		if c == delim {
+			l.r.Move(1)
+			return true
+		} else if c == 0 && l.r.Err() != nil {
+			l.r.Rewind(mark)
+			return false
+		}
+		l.r.Move(1)
+	}
+}
+
+// isJSXTagStart returns true if c, following a <, starts a JSX element name or closes the opening tag of a fragment.
+func isJSXTagStart(c byte) bool {
+	return c == '>' || identifierTable[c] && (c < '0' || '9' < c) || 0xC0 <= c
+}
+
+////////////////////////////////////////////////////////////////
+
+/*
+The following functions follow the TypeScript syntax at https://github.com/microsoft/TypeScript/blob/main/doc/spec-ARCHIVED.md
+*/
+
+// maxTypeArgumentsLen is the maximum number of bytes that are scanned ahead to determine whether a < starts a list of type arguments.
+const maxTypeArgumentsLen = 1024
+
+// nextTypeScript lexes the punctuators that differ in TypeScript, namely the angle brackets of type arguments and the non-null assertion operator. It returns UnknownToken without consuming anything for other punctuators.
+func (l *Lexer) nextTypeScript(c byte) (TokenType, []byte) {
+	if c == '<' && (l.context() == TypeArgumentsContext || l.isTypeArguments()) {
+		l.enterContext(TypeArgumentsContext)
+		l.state = ExprState
+	} else if c == '>' && l.context() == TypeArgumentsContext {
+		// a single > so that nested type arguments such as A<B<C>> are closed one at a time
+		l.leaveContext()
+		l.state = SubscriptState
+	} else if c == '!' && l.r.Peek(1) != '=' && l.state == SubscriptState && !l.emptyLine {
+		// non-null assertion operator as in a!.b, which is followed by an operator instead of an operand
+	} else {
+		return UnknownToken, nil
+	}
+	l.r.Move(1)
+	l.emptyLine = false
+	return PunctuatorToken, l.r.Shift()
+}
+
+// isTypeArguments returns true if the < at the current position starts a list of type arguments or parameters. Like the TypeScript compiler, it requires a matching > that only encloses tokens allowed in types, and which is not followed by the start of an expression, so that a < b > c remains a comparison.
+func (l *Lexer) isTypeArguments() bool {
+	depth := 0
+	for i := 0; i < maxTypeArgumentsLen; i++ {
+		c := l.r.Peek(i)
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			if depth--; depth == 0 {
+				return canFollowTypeArguments(l.r, i+1)
+			}
+		case '=':
+			if l.r.Peek(i+1) != '>' {
+				return false
+			}
+			i++ // function type
+		case '&', '|':
+			if l.r.Peek(i+1) == c {
+				return false // logical operators
+			}
+		case '"', '\'':
+			// string literal type
+			for i++; l.r.Peek(i) != c; i++ {
+				if d := l.r.Peek(i); d == '\\' {
+					i++
+				} else if d == '\n' || d == '\r' || d == 0 {
+					return false
+				}
+			}
+		case '.', ',', '[', ']', '(', ')', '{', '}', ':', ';', '?', '-', ' ', '\t', '\n', '\r':
+		default:
+			if !identifierTable[c] && c < 0x80 {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// isTSXTypeParameters returns true if the < at the current position starts the type parameters of a generic arrow function in TSX, which are written as <T,> or <T extends U> to distinguish them from JSX elements.
+func (l *Lexer) isTSXTypeParameters() bool {
+	i := 1
+	for identifierTable[l.r.Peek(i)] {
+		i++
+	}
+	if l.r.Peek(i) == ',' {
+		return true
+	}
+	for l.r.Peek(i) == ' ' || l.r.Peek(i) == '\t' {
+		i++
+	}
+	for _, c := range []byte("extends") {
+		if l.r.Peek(i) != c {
+			return false
+		}
+		i++
+	}
+	return l.r.Peek(i) == ' ' || l.r.Peek(i) == '\t'
+}
+
+// canFollowTypeArguments returns true if the byte at position i, ignoring whitespace, may follow type arguments in an expression, such as the parenthesis of a call.
+func canFollowTypeArguments(r *buffer.Lexer, i int) bool {
+	for r.Peek(i) == ' ' || r.Peek(i) == '\t' {
+		i++
+	}
+	switch r.Peek(i) {
+	case '(', '`', ')', ']', '[', '{', '}', ',', ';', '.', '?', ':', '=', '|', '&', '\n', '\r', 0:
+		return true
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////
+
+/*
+The following functions follow the specifications at https://tc39.es/ecma262/#sec-ecmascript-language-lexical-grammar
 */
 
 func (l *Lexer) consumeWhitespaceByte() bool {
@@ -360,6 +891,37 @@ func (l *Lexer) consumeOctalDigit() bool {
 	return false
 }
 
+func (l *Lexer) consumeBaseDigit(base int) bool {
+	switch base {
+	case 2:
+		return l.consumeBinaryDigit()
+	case 8:
+		return l.consumeOctalDigit()
+	case 16:
+		return l.consumeHexDigit()
+	}
+	return l.consumeDigit()
+}
+
+// consumeDigits consumes one or more digits of the given base, where digits may be separated by single underscores (numeric separators).
+func (l *Lexer) consumeDigits(base int) bool {
+	if !l.consumeBaseDigit(base) {
+		return false
+	}
+	for {
+		if l.consumeBaseDigit(base) {
+			continue
+		} else if l.r.Peek(0) == '_' {
+			l.r.Move(1)
+			if l.consumeBaseDigit(base) {
+				continue
+			}
+			l.r.Move(-1)
+		}
+		return true
+	}
+}
+
 func (l *Lexer) consumeUnicodeEscape() bool {
 	if l.r.Peek(0) != '\\' || l.r.Peek(1) != 'u' {
 		return false
@@ -460,8 +1022,14 @@ func (l *Lexer) consumePunctuatorToken() bool {
 			if (c == '!' || c == '=') && l.r.Peek(0) == '=' {
 				l.r.Move(1)
 			}
-		} else if (c == '+' || c == '-' || c == '&' || c == '|') && l.r.Peek(0) == c {
+		} else if (c == '+' || c == '-') && l.r.Peek(0) == c {
 			l.r.Move(1)
+		} else if (c == '&' || c == '|' || c == '*') && l.r.Peek(0) == c {
+			// logical assignment &&= ||= and exponentiation ** **=
+			l.r.Move(1)
+			if l.r.Peek(0) == '=' {
+				l.r.Move(1)
+			}
 		} else if c == '=' && l.r.Peek(0) == '>' {
 			l.r.Move(1)
 		}
@@ -516,48 +1084,41 @@ func (l *Lexer) consumeNumericToken() bool {
 	c := l.r.Peek(0)
 	if c == '0' {
 		l.r.Move(1)
-		if l.r.Peek(0) == 'x' || l.r.Peek(0) == 'X' {
-			l.r.Move(1)
-			if l.consumeHexDigit() {
-				for l.consumeHexDigit() {
-				}
-			} else {
-				l.r.Move(-1) // return just the zero
-			}
-			return true
-		} else if l.r.Peek(0) == 'b' || l.r.Peek(0) == 'B' {
+		base := 0
+		if c := l.r.Peek(0); c == 'x' || c == 'X' {
+			base = 16
+		} else if c == 'b' || c == 'B' {
+			base = 2
+		} else if c == 'o' || c == 'O' {
+			base = 8
+		}
+		if base != 0 {
 			l.r.Move(1)
-			if l.consumeBinaryDigit() {
-				for l.consumeBinaryDigit() {
-				}
-			} else {
+			if !l.consumeDigits(base) {
 				l.r.Move(-1) // return just the zero
+			} else if l.r.Peek(0) == 'n' {
+				l.r.Move(1) // BigInt
 			}
 			return true
-		} else if l.r.Peek(0) == 'o' || l.r.Peek(0) == 'O' {
-			l.r.Move(1)
-			if l.consumeOctalDigit() {
-				for l.consumeOctalDigit() {
-				}
-			} else {
-				l.r.Move(-1) // return just the zero
-			}
+		} else if l.r.Peek(0) == 'n' {
+			l.r.Move(1) // BigInt
 			return true
 		}
 	} else if c != '.' {
-		for l.consumeDigit() {
+		l.consumeDigits(10)
+		if l.r.Peek(0) == 'n' {
+			l.r.Move(1) // BigInt
+			return true
 		}
 	}
 	if l.r.Peek(0) == '.' {
 		l.r.Move(1)
-		if l.consumeDigit() {
-			for l.consumeDigit() {
+		if !l.consumeDigits(10) {
+			if c != '.' {
+				// . could belong to the next token
+				l.r.Move(-1)
+				return true
 			}
-		} else if c != '.' {
-			// . could belong to the next token
-			l.r.Move(-1)
-			return true
-		} else {
 			l.r.Rewind(mark)
 			return false
 		}
@@ -570,13 +1131,11 @@ func (l *Lexer) consumeNumericToken() bool {
 		if c == '+' || c == '-' {
 			l.r.Move(1)
 		}
-		if !l.consumeDigit() {
+		if !l.consumeDigits(10) {
 			// e could belong to the next token
 			l.r.Rewind(mark)
 			return true
 		}
-		for l.consumeDigit() {
-		}
 	}
 	return true
 }
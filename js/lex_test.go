@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
+	"github.com/tdewolff/parse/v2/internal/testio"
 	"github.com/tdewolff/test"
 )
 
@@ -30,6 +33,7 @@ func TestTokens(t *testing.T) {
 		{"= += -= *= %= <<=", TTs{PunctuatorToken, PunctuatorToken, PunctuatorToken, PunctuatorToken, PunctuatorToken, PunctuatorToken}},
 		{">>= >>>= &= |= ^= =>", TTs{PunctuatorToken, PunctuatorToken, PunctuatorToken, PunctuatorToken, PunctuatorToken, PunctuatorToken}},
 		{"a = /.*/g;", TTs{IdentifierToken, PunctuatorToken, RegexpToken, PunctuatorToken}},
+		{"f(...a) .. .5", TTs{IdentifierToken, PunctuatorToken, PunctuatorToken, IdentifierToken, PunctuatorToken, PunctuatorToken, PunctuatorToken, NumericToken}},
 
 		{"/*co\nm\u2028m/*ent*/ //co//mment\u2029//comment", TTs{MultiLineCommentToken, SingleLineCommentToken, LineTerminatorToken, SingleLineCommentToken}},
 		{"<!-", TTs{PunctuatorToken, PunctuatorToken, PunctuatorToken}},
@@ -112,6 +116,24 @@ func TestTokens(t *testing.T) {
 
 		// go fuzz
 		{"`", TTs{UnknownToken}},
+
+		// ES2015+
+		{"a?.b a?.[0] a?.(b)", TTs{IdentifierToken, PunctuatorToken, IdentifierToken, IdentifierToken, PunctuatorToken, PunctuatorToken, NumericToken, PunctuatorToken, IdentifierToken, PunctuatorToken, PunctuatorToken, IdentifierToken, PunctuatorToken}},
+		{"a?.5:b", TTs{IdentifierToken, PunctuatorToken, NumericToken, PunctuatorToken, IdentifierToken}},
+		{"a?.if/1/g", TTs{IdentifierToken, PunctuatorToken, IdentifierToken, PunctuatorToken, NumericToken, PunctuatorToken, IdentifierToken}},
+		{"a ?? /b/g", TTs{IdentifierToken, PunctuatorToken, RegexpToken}},
+		{"a ??= b &&= c ||= d", TTs{IdentifierToken, PunctuatorToken, IdentifierToken, PunctuatorToken, IdentifierToken, PunctuatorToken, IdentifierToken}},
+		{"a ** b **= c", TTs{IdentifierToken, PunctuatorToken, IdentifierToken, PunctuatorToken, IdentifierToken}},
+		{"1n 0n 0x1Fn 0b1n 0o7n", TTs{NumericToken, NumericToken, NumericToken, NumericToken, NumericToken}},
+		{"1.5n 1e3n", TTs{NumericToken, IdentifierToken, NumericToken, IdentifierToken}},
+		{"1_000 0x_1 0.000_001 1e1_0", TTs{NumericToken, NumericToken, IdentifierToken, NumericToken, NumericToken}},
+		{"1__0 1_", TTs{NumericToken, IdentifierToken, NumericToken, IdentifierToken}},
+		{"#x #\\u0061 #é", TTs{PrivateIdentifierToken, PrivateIdentifierToken, PrivateIdentifierToken}},
+		{"this.#x/1/g", TTs{IdentifierToken, PunctuatorToken, PrivateIdentifierToken, PunctuatorToken, NumericToken, PunctuatorToken, IdentifierToken}},
+		{"# #1", TTs{UnknownToken, UnknownToken, NumericToken}},
+		{"(a) => /b/", TTs{PunctuatorToken, IdentifierToken, PunctuatorToken, PunctuatorToken, RegexpToken}},
+		{"[...a]", TTs{PunctuatorToken, PunctuatorToken, IdentifierToken, PunctuatorToken}},
+		{"a = /b/dgimsuy", TTs{IdentifierToken, PunctuatorToken, RegexpToken}},
 	}
 
 	for _, tt := range tokenTests {
@@ -151,6 +173,371 @@ func TestTokens(t *testing.T) {
 	}
 }
 
+func TestTokenData(t *testing.T) {
+	var tokenTests = []struct {
+		js       string
+		expected string
+	}{
+		{"a?.b?.[c]??d", "a ?. b ?. [ c ] ?? d"},
+		{"a??=b&&=c||=d", "a ??= b &&= c ||= d"},
+		{"a?.5:b", "a ? .5 : b"},
+		{"a**b**=c***d", "a ** b **= c ** * d"},
+		{"a&&b||c", "a && b || c"},
+		{"1n+0x1Fn+0b1n+0o7n+0n+1.5n", "1n + 0x1Fn + 0b1n + 0o7n + 0n + 1.5 n"},
+		{"1_000_000+0xFF_FF+0b1_0+0o7_7+.5_5e1_0", "1_000_000 + 0xFF_FF + 0b1_0 + 0o7_7 + .5_5e1_0"},
+		{"1__0+1_+1_e1+0_1+1._5", "1 __0 + 1 _ + 1 _e1 + 0 _1 + 1 . _5"},
+		{"1_000n", "1_000n"},
+		{"class A{#x;m(){this.#x;#x in this}}", "class A { #x ; m ( ) { this . #x ; #x in this } }"},
+		{"a=>a...b", "a => a ... b"},
+		{"a = /b/dgimsuy.flags", "a = /b/dgimsuy . flags"},
+	}
+	for _, tt := range tokenTests {
+		t.Run(tt.js, func(t *testing.T) {
+			l := NewLexer(bytes.NewBufferString(tt.js))
+			tokens := []string{}
+			for {
+				token, data := l.Next()
+				if token == ErrorToken {
+					break
+				} else if token != WhitespaceToken {
+					tokens = append(tokens, string(data))
+				}
+			}
+			test.String(t, strings.Join(tokens, " "), tt.expected)
+		})
+	}
+}
+
+// modeTokens returns the non-whitespace tokens of js lexed in the given mode, where JSX text is quoted and regular expressions are enclosed in brackets.
+func modeTokens(js string, mode Mode) string {
+	l := NewLexer(bytes.NewBufferString(js))
+	l.SetMode(mode)
+	tokens := []string{}
+	for {
+		tt, data := l.Next()
+		switch tt {
+		case ErrorToken:
+			return strings.Join(tokens, " ")
+		case WhitespaceToken, LineTerminatorToken:
+			continue
+		case JSXTextToken:
+			tokens = append(tokens, fmt.Sprintf("%q", data))
+		case RegexpToken:
+			tokens = append(tokens, "["+string(data)+"]")
+		default:
+			tokens = append(tokens, string(data))
+		}
+	}
+}
+
+func TestRegExpDiv(t *testing.T) {
+	var regexpDivTests = []struct {
+		js       string
+		expected string
+	}{
+		// statements
+		{"/a/.test(b)", "[/a/] . test ( b )"},
+		{"a\n/b/.test(c)", "a / b / . test ( c )"},
+		{"a = b\n/c/g.exec(d)", "a = b / c / g . exec ( d )"},
+		{"a;\n/b/.test(c)", "a ; [/b/] . test ( c )"},
+		{"a; /b/", "a ; [/b/]"},
+		{"label: /a/", "label : [/a/]"},
+		{"if (a) /b/.exec(c)", "if ( a ) [/b/] . exec ( c )"},
+		{"if (a) {} else /b/.exec(c)", "if ( a ) { } else [/b/] . exec ( c )"},
+		{"while ((a)) /b/", "while ( ( a ) ) [/b/]"},
+		{"for (a in b) /c/", "for ( a in b ) [/c/]"},
+		{"for (const a of /b/g) {}", "for ( const a of / b / g ) { }"},
+		{"do /a/.test(b); while (c)", "do [/a/] . test ( b ) ; while ( c )"},
+		{"do {} while (a) /b/", "do { } while ( a ) [/b/]"},
+		{"switch (a) { case /b/: /c/; default: /d/ }", "switch ( a ) { case [/b/] : [/c/] ; default : [/d/] }"},
+		{"try {} catch (e) {} finally {} /a/", "try { } catch ( e ) { } finally { } [/a/]"},
+
+		// keywords
+		{"return /a/", "return [/a/]"},
+		{"return a / b", "return a / b"},
+		{"throw /a/", "throw [/a/]"},
+		{"typeof /a/", "typeof [/a/]"},
+		{"typeof a / b", "typeof a / b"},
+		{"void /a/", "void [/a/]"},
+		{"delete /a/.b", "delete [/a/] . b"},
+		{"a instanceof /b/.constructor", "a instanceof [/b/] . constructor"},
+		{"a in /b/", "a in [/b/]"},
+		{"new /a/.constructor", "new [/a/] . constructor"},
+		{"await /a/", "await [/a/]"},
+		{"yield /a/", "yield [/a/]"},
+		{"this / a / b", "this / a / b"},
+		{"super.a / b / c", "super . a / b / c"},
+		{"null / a / b", "null / a / b"},
+		{"true / a / b", "true / a / b"},
+		{"a.return / b / c", "a . return / b / c"},
+		{"a?.typeof / b / c", "a ?. typeof / b / c"},
+		{"of / a / b", "of / a / b"},
+
+		// operators
+		{"a / b / c", "a / b / c"},
+		{"a /= b / c", "a /= b / c"},
+		{"a = /=b/", "a = [/=b/]"},
+		{"1 / a / b", "1 / a / b"},
+		{"'a' / b / c", "'a' / b / c"},
+		{"`a` / b / c", "`a` / b / c"},
+		{"`${/a/}` / b / c", "`${ [/a/] }` / b / c"},
+		{"/a/ / b / c", "[/a/] / b / c"},
+		{"a ? /b/ : /c/", "a ? [/b/] : [/c/]"},
+		{"a ? b / c : d / e", "a ? b / c : d / e"},
+		{"a ?? /b/", "a ?? [/b/]"},
+		{"a && /b/ || /c/", "a && [/b/] || [/c/]"},
+		{"!/a/.test(b)", "! [/a/] . test ( b )"},
+		{"~/a/", "~ [/a/]"},
+		{"a, /b/", "a , [/b/]"},
+		{"...a / b / c", "... a / b / c"},
+		{"[.../a/]", "[ ... [/a/] ]"},
+		{"a++ / b / c", "a ++ / b / c"},
+		{"a-- / b / c", "a -- / b / c"},
+		{"a\n++/b/.lastIndex", "a ++ [/b/] . lastIndex"},
+		{"++/a/.lastIndex", "++ [/a/] . lastIndex"},
+		{"a = b++\n/c/", "a = b ++ / c /"},
+		{"a + +/b/.source", "a + + [/b/] . source"},
+		{"#a / b / c", "#a / b / c"},
+
+		// parentheses and brackets
+		{"(a) / b / c", "( a ) / b / c"},
+		{"(/a/)", "( [/a/] )"},
+		{"f(/a/, /b/)", "f ( [/a/] , [/b/] )"},
+		{"a[0] / b / c", "a [ 0 ] / b / c"},
+		{"[/a/] / b / c", "[ [/a/] ] / b / c"},
+		{"a[/b/.source]", "a [ [/b/] . source ]"},
+		{"if (a[b]) /c/", "if ( a [ b ] ) [/c/]"},
+
+		// braces
+		{"{} /a/", "{ } [/a/]"},
+		{"{a} /b/", "{ a } [/b/]"},
+		{"{a: /b/} /c/", "{ a : [/b/] } [/c/]"},
+		{"a = {} / b / c", "a = { } / b / c"},
+		{"a = {b: 1} / c / d", "a = { b : 1 } / c / d"},
+		{"a = {if: 1, b: {}} / c / d", "a = { if : 1 , b : { } } / c / d"},
+		{"a = {b() {}} / c / d", "a = { b ( ) { } } / c / d"},
+		{"a = {b: c ? {} : {}} / d / e", "a = { b : c ? { } : { } } / d / e"},
+		{"f({}) / a / b", "f ( { } ) / a / b"},
+		{"[{}] / a / b", "[ { } ] / a / b"},
+		{"a ? {} / b : c", "a ? { } / b : c"},
+		{"return {} / a / b", "return { } / a / b"},
+		{"return\n{} /a/", "return { } [/a/]"},
+		{"case {}: /a/", "case { } : [/a/]"},
+		{"if (a) {} /b/", "if ( a ) { } [/b/]"},
+		{"{a: {}} /b/", "{ a : { } } [/b/]"},
+		{"label: {} /a/", "label : { } [/a/]"},
+		{"switch (a) { case b: {} /c/ }", "switch ( a ) { case b : { } [/c/] }"},
+		{"`${{}}` / a / b", "`${ { } }` / a / b"},
+		{"`${a}${{}/b/g}`", "`${ a }${ { } / b / g }`"},
+
+		// functions and classes
+		{"function a() {} /b/", "function a ( ) { } [/b/]"},
+		{"function a() { return /b/ } /c/", "function a ( ) { return [/b/] } [/c/]"},
+		{"a = function() {} / b / c", "a = function ( ) { } / b / c"},
+		{"a = function b(c = {}) { if (c) {} } / d / e", "a = function b ( c = { } ) { if ( c ) { } } / d / e"},
+		{"(function() {}) / a / b", "( function ( ) { } ) / a / b"},
+		{"f(function() {} / a / b)", "f ( function ( ) { } / a / b )"},
+		{"async function a() {} /b/", "async function a ( ) { } [/b/]"},
+		{"a = async function() {} / b / c", "a = async function ( ) { } / b / c"},
+		{"return async function() {} / a / b", "return async function ( ) { } / a / b"},
+		{"a = async\nfunction b() {} /c/", "a = async function b ( ) { } [/c/]"},
+		{"export default function() {}\n/a/", "export default function ( ) { } [/a/]"},
+		{"export default async function() {}\n/a/", "export default async function ( ) { } [/a/]"},
+		{"export default class {}\n/a/", "export default class { } [/a/]"},
+		{"export default /* a */\nclass {}\n/b/", "export default /* a */ class { } [/b/]"},
+		{"export function f() {}\n/a/.test(b)", "export function f ( ) { } [/a/] . test ( b )"},
+		{"export class A {}\n/a/", "export class A { } [/a/]"},
+		{"export async function f() {}\n/a/", "export async function f ( ) { } [/a/]"},
+		{"export {a}\n/b/", "export { a } [/b/]"},
+		{"export const a = /b/", "export const a = [/b/]"},
+		{"export default (function() {}) / a / b", "export default ( function ( ) { } ) / a / b"},
+		{"function* a() { yield /b/ }", "function * a ( ) { yield [/b/] }"},
+		{"class A {} /b/", "class A { } [/b/]"},
+		{"class A extends B { c() { return /d/ } } /e/", "class A extends B { c ( ) { return [/d/] } } [/e/]"},
+		{"a = class {} / b / c", "a = class { } / b / c"},
+		{"a = class extends B { static { /c/ } } / d / e", "a = class extends B { static { [/c/] } } / d / e"},
+		{"a = () => {}\n/b/", "a = ( ) => { } [/b/]"},
+		{"a = () => /b/", "a = ( ) => [/b/]"},
+		{"a = b => ({}) / c / d", "a = b => ( { } ) / c / d"},
+		{"a = async () => { await /b/ }", "a = async ( ) => { await [/b/] }"},
+		{"export default {} / a / b", "export default { } / a / b"},
+	}
+	for _, tt := range regexpDivTests {
+		t.Run(tt.js, func(t *testing.T) {
+			test.String(t, modeTokens(tt.js, 0), tt.expected)
+		})
+	}
+}
+
+func TestASI(t *testing.T) {
+	var asiTests = []struct {
+		js       string
+		expected []bool // for each line terminator
+	}{
+		{"a\nb", []bool{true}},
+		{"a = b\nc = d\n", []bool{true, false}},
+		{"a\n(b)", []bool{false}},
+		{"a\n[b]", []bool{false}},
+		{"a\n`b`", []bool{false}},
+		{"a\n.b\n?.c", []bool{false, false}},
+		{"a\n.5", []bool{true}},
+		{"a\n+ b\n- c", []bool{false, false}},
+		{"a\n++b\n--c", []bool{true, true}},
+		{"a++\nb", []bool{true}},
+		{"a\n/b/g", []bool{false}},
+		{"a\n/* c */ /b/g", []bool{false}},
+		{"a\n// c\nb", []bool{true, true}},
+		{"a /* c\n */ b", []bool{true}},
+		{"a /* c\n */ + b", []bool{false}},
+		{"a\nin b\ninstanceof c\nof d", []bool{false, false, true}},
+		{"a\n= b\n== c\n!= d\n!e", []bool{false, false, false, true}},
+		{"a\n? b\n: c", []bool{false, false}},
+		{"a\n&& b\n|| c\n?? d", []bool{false, false, false}},
+		{"a\n'b'\n1\n{}", []bool{true, true, true}},
+		{"a\n;\nb\n}", []bool{false, false, false}},
+		{"a = b +\nc", []bool{false}},
+		{"a = (b\nc)", []bool{false}},
+		{"a = [b\nc]", []bool{false}},
+		{"a = {b\n, c\n}", []bool{false, false}},
+		{"`${a\nb}`", []bool{false}},
+		{"return\na", []bool{true}},
+		{"return a\n+ b", []bool{false}},
+		{"return\n{}", []bool{true}},
+		{"return\n;", []bool{false}},
+		{"throw\na\nbreak\nlabel\ncontinue\nlabel", []bool{true, true, true, true, true}},
+		{"function* a() { yield\nb }", []bool{true}},
+		{"a.return\n+ b", []bool{false}},
+		{"a\n=> b", []bool{false}},
+		{"(a)\n=> b", []bool{false}},
+		{"(a) =>\nb", []bool{false}},
+		{"async\nfunction a() {}", []bool{true}},
+		{"if (a)\nb\nelse\nc", []bool{false, true, false}},
+		{"for (;;)\na", []bool{false}},
+		{"for (a\nof b) {}", []bool{false}},
+		{"do\na\nwhile (b)", []bool{false, true}},
+		{"{\na\n}\nb", []bool{false, false, false}},
+		{"a = function() {\nb\n}\n(c)", []bool{false, false, false}},
+		{"a = {}\nb", []bool{true}},
+		{"let\na = 1", []bool{false}},
+		{"class A {\na = 1\nb() {}\n}", []bool{false, true, false}},
+		{"switch (a) {\ncase b:\nc\n}", []bool{false, false, false}},
+		{"a\u2028b", []bool{true}},
+	}
+	for _, tt := range asiTests {
+		t.Run(tt.js, func(t *testing.T) {
+			asi := []bool{}
+			l := NewLexer(bytes.NewBufferString(tt.js))
+			for {
+				tt, _ := l.Next()
+				if tt == ErrorToken {
+					break
+				} else if tt == LineTerminatorToken || tt == MultiLineCommentToken {
+					asi = append(asi, l.ASI())
+				} else {
+					test.That(t, !l.ASI(), "ASI must be false for", tt)
+				}
+			}
+			test.T(t, asi, tt.expected)
+		})
+	}
+}
+
+func TestJSX(t *testing.T) {
+	var jsxTests = []struct {
+		js       string
+		expected string
+	}{
+		{"x = <div className=\"a\" {...p} on-click={() => f(<b/>)}>Hi {name}, it's <i>me</i>!</div> / 2", `x = < div className = "a" { ... p } on-click = { ( ) => f ( < b / > ) } > "Hi " { name } ", it's " < i > "me" < / i > "!" < / div > / 2`},
+		{"<><a.b c:d='e\nf'/></>", "< > < a . b c : d = 'e\nf' / > < / >"},
+		{"return <a>{/re/.test(x) ? <b /> : null}</a>", "return < a > { [/re/] . test ( x ) ? < b / > : null } < / a >"},
+		{"<a // c\n b /* d */>", "< a // c b /* d */ >"},
+		{"<a>\n  text\n</a>", `< a > "\n  text\n" < / a >`},
+		{"<a b=\"\\\" />", `< a b = "\" / >`},
+		{"a < b > c", "a < b > c"},
+		{"<1", "< 1"},
+		{"<a>text", `< a > "text"`},
+		{"<a b='c", "< a b = ' c"},
+		{"<a b={/c/} {...{d: 1}} />", "< a b = { [/c/] } { ... { d : 1 } } / >"},
+	}
+	for _, tt := range jsxTests {
+		t.Run(tt.js, func(t *testing.T) {
+			test.String(t, modeTokens(tt.js, JSXMode), tt.expected)
+		})
+	}
+
+	l := NewLexer(bytes.NewBufferString("<a>b</a>"))
+	test.T(t, l.context(), GlobalContext)
+	l.SetMode(JSXMode)
+	l.Next()
+	test.T(t, l.context(), JSXTagContext)
+	l.Next()
+	l.Next()
+	test.T(t, l.context(), JSXChildrenContext)
+	tt, _ := l.Next()
+	test.T(t, tt, JSXTextToken)
+	l.Next()
+	test.T(t, l.context(), JSXClosingTagContext)
+	l.Next()
+	l.Next()
+	l.Next()
+	test.T(t, l.context(), GlobalContext)
+}
+
+func TestTypeScript(t *testing.T) {
+	var typeScriptTests = []struct {
+		js       string
+		expected string
+	}{
+		{"let x: Array<Array<number>>= new Map<string, RegExp>();", "let x : Array < Array < number > > = new Map < string , RegExp > ( ) ;"},
+		{"f<T>(x) / 2", "f < T > ( x ) / 2"},
+		{"a < b > c; a < b && c > (d); a >> b; a >= b", "a < b > c ; a < b && c > ( d ) ; a >> b ; a >= b"},
+		{"x = <any>/re/", "x = < any > [/re/]"},
+		{"a! / 2; a!.b; a!!; a !== b", "a ! / 2 ; a ! . b ; a ! ! ; a !== b"},
+		{"!a\n!/re/", "! a ! [/re/]"},
+		{"@Component({a: 1}) class A<T extends {a: 'x>'}> implements I<T> { m(): Promise<T[]> {} }", "@ Component ( { a : 1 } ) class A < T extends { a : 'x>' } > implements I < T > { m ( ) : Promise < T [ ] > { } }"},
+		{"type F = <T>(a: T) => T", "type F = < T > ( a : T ) => T"},
+		{"let f: Foo<(a: number) => void | 'a\\'b'>;", "let f : Foo < ( a : number ) => void | 'a\\'b' > ;"},
+		{"type / 2 / 3; satisfies / 2 / 3", "type / 2 / 3 ; satisfies / 2 / 3"},
+		{"a < b || c > (d)", "a < b || c > ( d )"},
+		{"a < 'b\n' > (c)", "a < ' b ' > ( c )"},
+		{"a < b", "a < b"},
+		{"class A<T> {} /b/; a = class B<T> {} / c / d", "class A < T > { } [/b/] ; a = class B < T > { } / c / d"},
+		{"let f = (a?, b?: number) => {}\n/c/", "let f = ( a ? , b ? : number ) => { } [/c/]"},
+	}
+	for _, tt := range typeScriptTests {
+		t.Run(tt.js, func(t *testing.T) {
+			test.String(t, modeTokens(tt.js, TypeScriptMode), tt.expected)
+		})
+	}
+
+	test.String(t, modeTokens("@dec a! /b/", 0), "@ dec a ! [/b/]", "TypeScript syntax must be opt-in")
+	test.String(t, modeTokens("<a>{x!}</a> as Element; f<string>('x')", JSXMode|TypeScriptMode), "< a > { x ! } < / a > as Element ; f < string > ( 'x' )")
+	test.String(t, modeTokens("<T,>(x: T) => <T>x</T>", JSXMode|TypeScriptMode), `< T , > ( x : T ) => < T > "x" < / T >`)
+	test.String(t, modeTokens("<T extends U>(x: T) => <T extends='u'/>", JSXMode|TypeScriptMode), "< T extends U > ( x : T ) => < T extends = 'u' / >")
+}
+
+func TestModeStream(t *testing.T) {
+	js := "const App = <T,>(p: Props<T>) => <ul className=\"list\">{p.items!.map((x: T) => <li key={x.id}>{x.name} &amp; more</li>)}</ul>;\n"
+	expected := modeTokens(js, JSXMode|TypeScriptMode)
+	l := NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString(js)))
+	l.SetMode(JSXMode | TypeScriptMode)
+	tokens := []string{}
+	for {
+		tt, data := l.Next()
+		if tt == ErrorToken {
+			break
+		} else if tt == JSXTextToken {
+			tokens = append(tokens, fmt.Sprintf("%q", data))
+		} else if tt == RegexpToken {
+			tokens = append(tokens, "["+string(data)+"]")
+		} else if tt != WhitespaceToken && tt != LineTerminatorToken {
+			tokens = append(tokens, string(data))
+		}
+	}
+	test.T(t, l.Err(), io.EOF)
+	test.String(t, strings.Join(tokens, " "), expected)
+}
+
 func TestOffset(t *testing.T) {
 	l := NewLexer(bytes.NewBufferString(`var i=5;`))
 	test.T(t, l.Offset(), 0)
@@ -168,6 +555,40 @@ func TestOffset(t *testing.T) {
 	test.T(t, l.Offset(), 8) // ;
 }
 
+func TestStreamLexer(t *testing.T) {
+	js := "var a = /re[/]g.test(b) ? 1e3 : 0x1F; // c\nlet s = `t${x}u${ {y} }` + 'q' /* c */\nif (a) { b /= 2 }\nclass C { #p = 1 }\nreturn\n/* c\n */ a\n++b"
+	l := NewLexer(bytes.NewBufferString(js))
+	z := NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString(js)))
+	for {
+		tt, data := l.Next()
+		ttStream, dataStream := z.Next()
+		test.T(t, ttStream, tt, "token types must match")
+		test.String(t, string(dataStream), string(data), "token data must match")
+		test.T(t, z.Offset(), l.Offset(), "offsets must match")
+		test.T(t, z.ASI(), l.ASI(), "ASI must match")
+		if tt == ErrorToken {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+}
+
+func TestStreamLexerMemory(t *testing.T) {
+	item := []byte("var a = b / 2 + /re/g.test('x'); // comment\n")
+	r := testio.NewRepeatReader(item, 500000)
+
+	l := NewStreamLexer(r)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for tt, _ := l.Next(); tt != ErrorToken; tt, _ = l.Next() {
+			n++
+		}
+	})
+	test.T(t, l.Err(), io.EOF)
+	test.T(t, n, 24*500000)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*500000, "bytes of input")
+}
+
 ////////////////////////////////////////////////////////////////
 
 func ExampleNewLexer() {
@@ -0,0 +1,1691 @@
+package js
+
+import (
+	"io"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+type token struct {
+	tt         TokenType
+	data       []byte
+	h          Hash // keyword hash of identifiers
+	start, end int
+	lt         bool // preceded by a line terminator
+}
+
+// Parser is the state for the parser.
+type Parser struct {
+	l      *Lexer
+	stream bool // reading from a stream, token data is copied since the lexer frees it
+	err    error
+
+	token
+	ahead   []token
+	prevEnd int
+
+	level     int  // statement nesting level
+	noIn      bool // in operator is not allowed, ie. in the initializer of a for statement
+	inFunc    bool
+	async     bool
+	generator bool
+}
+
+// NewParser returns a new Parser for a given io.Reader.
+func NewParser(r io.Reader) *Parser {
+	return &Parser{
+		l: NewLexer(r),
+	}
+}
+
+// NewStreamParser returns a new Parser that reads the script from r in chunks. The data of the tokens that end up in the AST is copied, so that apart from the AST itself memory usage does not grow with the size of the script.
+func NewStreamParser(r io.Reader) *Parser {
+	return &Parser{
+		l:      NewStreamLexer(r),
+		stream: true,
+	}
+}
+
+// Parse parses the entire input stream as a script or module and returns its AST. The returned error is a *parse.Error for syntax errors.
+func Parse(r io.Reader) (*Program, error) {
+	return NewParser(r).Parse()
+}
+
+// Parse parses the entire input stream and returns its AST. The returned error is a *parse.Error for syntax errors.
+func (p *Parser) Parse() (*Program, error) {
+	p.next()
+	prog := &Program{}
+	for p.tt != ErrorToken {
+		prog.List = append(prog.List, p.parseStmt())
+	}
+	if p.err == nil && p.l.Err() != io.EOF {
+		p.err = p.l.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	prog.Loc = Loc{0, p.end}
+	return prog, nil
+}
+
+// Restore restores the NULL byte at the end of the buffer.
+func (p *Parser) Restore() {
+	p.l.Restore()
+}
+
+////////////////////////////////////////////////////////////////
+
+func (p *Parser) lex() token {
+	lt := false
+	for {
+		tt, data := p.l.Next()
+		if tt == WhitespaceToken || tt == SingleLineCommentToken {
+			continue
+		} else if tt == LineTerminatorToken || tt == MultiLineCommentToken {
+			lt = true
+			continue
+		}
+		end := p.l.Offset()
+		if p.stream {
+			data = parse.Copy(data)
+		}
+		t := token{tt: tt, data: data, start: end - len(data), end: end, lt: lt}
+		if tt == IdentifierToken {
+			t.h = ToHash(data)
+		}
+		return t
+	}
+}
+
+func (p *Parser) next() {
+	p.prevEnd = p.end
+	if p.err != nil {
+		p.tt, p.data, p.h = ErrorToken, nil, 0
+		return
+	} else if 0 < len(p.ahead) {
+		p.token = p.ahead[0]
+		p.ahead = p.ahead[1:]
+		return
+	}
+	p.token = p.lex()
+}
+
+func (p *Parser) peek() token {
+	if len(p.ahead) == 0 {
+		p.ahead = append(p.ahead, p.lex())
+	}
+	return p.ahead[0]
+}
+
+// relexRegExp turns the current '/' or '/=' punctuator into a regular expression.
+func (p *Parser) relexRegExp() bool {
+	if len(p.ahead) != 0 {
+		return false
+	}
+	tt, data := p.l.regExp(len(p.data))
+	if p.stream {
+		data = parse.Copy(data)
+	}
+	p.end = p.l.Offset()
+	p.tt, p.data = tt, data
+	return tt == RegexpToken
+}
+
+// relexDiv turns the current regular expression into a '/' or '/=' punctuator.
+func (p *Parser) relexDiv() {
+	if len(p.ahead) != 0 {
+		return
+	}
+	p.tt, p.data = p.l.div(len(p.data))
+	if p.stream {
+		p.data = parse.Copy(p.data)
+	}
+	p.end = p.l.Offset()
+}
+
+func (p *Parser) failMessage(offset int, msg string, a ...interface{}) {
+	if p.err == nil {
+		if p.tt == ErrorToken && p.l.Err() != io.EOF {
+			p.err = p.l.Err()
+		} else {
+			p.err = parse.NewErrorLexerOffset(p.l.r, offset, "JS parse error: "+msg, a...)
+		}
+	}
+	p.tt, p.data, p.h = ErrorToken, nil, 0
+}
+
+func (p *Parser) fail(in string, expected ...string) {
+	got := "'" + string(p.data) + "'"
+	if p.tt == ErrorToken {
+		got = "EOF"
+	}
+	if len(expected) == 0 {
+		p.failMessage(p.start, "unexpected %s in %s", got, in)
+		return
+	}
+	s := expected[0]
+	for i := 1; i < len(expected); i++ {
+		if i == len(expected)-1 {
+			s += " or "
+		} else {
+			s += ", "
+		}
+		s += expected[i]
+	}
+	p.failMessage(p.start, "expected %s instead of %s in %s", s, got, in)
+}
+
+func (p *Parser) is(c byte) bool {
+	return p.tt == PunctuatorToken && len(p.data) == 1 && p.data[0] == c
+}
+
+func (p *Parser) isOp(op string) bool {
+	return p.tt == PunctuatorToken && string(p.data) == op
+}
+
+func (p *Parser) isKeyword(h Hash) bool {
+	return p.tt == IdentifierToken && p.h == h
+}
+
+func (p *Parser) consume(c byte, in string) bool {
+	if !p.is(c) {
+		p.fail(in, "'"+string(c)+"'")
+		return false
+	}
+	p.next()
+	return true
+}
+
+func (p *Parser) consumeSemicolon(in string) {
+	if p.is(';') {
+		p.next()
+	} else if !p.is('}') && !p.lt && (p.tt != ErrorToken || p.err != nil) {
+		p.fail(in, "';'")
+	}
+}
+
+func isReservedWord(h Hash) bool {
+	switch h {
+	case Break, Case, Catch, Class, Const, Continue, Debugger, Default, Delete, Do, Else, Enum, Export, Extends, False, Finally, For, Function, If, Import, In, Instanceof, New, Null, Return, Super, Switch, This, Throw, True, Try, Typeof, Var, Void, While, With:
+		return true
+	}
+	return false
+}
+
+// isIdentifier returns true if the current token is an identifier that can be used as a binding or reference.
+func (p *Parser) isIdentifier() bool {
+	return p.tt == IdentifierToken && !isReservedWord(p.h) && !(p.h == Yield && p.generator) && !(p.h == Await && p.async)
+}
+
+func (t token) isPunct(c byte) bool {
+	return t.tt == PunctuatorToken && len(t.data) == 1 && t.data[0] == c
+}
+
+////////////////////////////////////////////////////////////////
+
+func (p *Parser) parseStmt() IStmt {
+	p.level++
+	stmt := p.parseStmtInner()
+	p.level--
+	return stmt
+}
+
+func (p *Parser) parseStmtInner() IStmt {
+	start := p.start
+	if p.is('{') {
+		return p.parseBlock("block statement")
+	} else if p.is(';') {
+		p.next()
+		return &EmptyStmt{Loc{start, p.prevEnd}}
+	} else if p.tt == IdentifierToken {
+		switch p.h {
+		case Var, Const:
+			h := p.h
+			p.next()
+			decl := p.parseVarDecl(start, h)
+			p.consumeSemicolon("variable declaration")
+			decl.End = p.prevEnd
+			return decl
+		case Let:
+			if t := p.peek(); t.tt == IdentifierToken && t.h != In && t.h != Instanceof || t.isPunct('[') || t.isPunct('{') {
+				p.next()
+				decl := p.parseVarDecl(start, Let)
+				p.consumeSemicolon("let declaration")
+				decl.End = p.prevEnd
+				return decl
+			}
+		case If:
+			p.next()
+			if !p.consume('(', "if statement") {
+				return nil
+			}
+			cond := p.parseExpr()
+			if !p.consume(')', "if statement") {
+				return nil
+			}
+			body := p.parseStmt()
+			var elseStmt IStmt
+			if p.isKeyword(Else) {
+				p.next()
+				elseStmt = p.parseStmt()
+			}
+			return &IfStmt{Loc{start, p.prevEnd}, cond, body, elseStmt}
+		case Do:
+			p.next()
+			body := p.parseStmt()
+			if !p.isKeyword(While) {
+				p.fail("do-while statement", "'while'")
+				return nil
+			}
+			p.next()
+			if !p.consume('(', "do-while statement") {
+				return nil
+			}
+			cond := p.parseExpr()
+			if !p.consume(')', "do-while statement") {
+				return nil
+			}
+			if p.is(';') {
+				p.next()
+			}
+			return &DoWhileStmt{Loc{start, p.prevEnd}, cond, body}
+		case While:
+			p.next()
+			if !p.consume('(', "while statement") {
+				return nil
+			}
+			cond := p.parseExpr()
+			if !p.consume(')', "while statement") {
+				return nil
+			}
+			body := p.parseStmt()
+			return &WhileStmt{Loc{start, p.prevEnd}, cond, body}
+		case For:
+			return p.parseForStmt()
+		case Continue, Break:
+			h := p.h
+			p.next()
+			var label []byte
+			if !p.lt && p.isIdentifier() {
+				label = p.data
+				p.next()
+			}
+			p.consumeSemicolon(h.String() + " statement")
+			return &BranchStmt{Loc{start, p.prevEnd}, h, label}
+		case Return:
+			if !p.inFunc {
+				p.fail("statement")
+				return nil
+			}
+			p.next()
+			var value IExpr
+			if !p.lt && !p.is(';') && !p.is('}') && p.tt != ErrorToken {
+				value = p.parseExpr()
+			}
+			p.consumeSemicolon("return statement")
+			return &ReturnStmt{Loc{start, p.prevEnd}, value}
+		case With:
+			p.next()
+			if !p.consume('(', "with statement") {
+				return nil
+			}
+			cond := p.parseExpr()
+			if !p.consume(')', "with statement") {
+				return nil
+			}
+			body := p.parseStmt()
+			return &WithStmt{Loc{start, p.prevEnd}, cond, body}
+		case Switch:
+			return p.parseSwitchStmt()
+		case Throw:
+			p.next()
+			if p.lt {
+				p.fail("throw statement", "expression on the same line")
+				return nil
+			}
+			value := p.parseExpr()
+			p.consumeSemicolon("throw statement")
+			return &ThrowStmt{Loc{start, p.prevEnd}, value}
+		case Try:
+			return p.parseTryStmt()
+		case Debugger:
+			p.next()
+			p.consumeSemicolon("debugger statement")
+			return &DebuggerStmt{Loc{start, p.prevEnd}}
+		case Function:
+			return p.parseFuncDecl(start, false, false)
+		case Async:
+			if t := p.peek(); t.tt == IdentifierToken && t.h == Function && !t.lt {
+				p.next()
+				return p.parseFuncDecl(start, true, false)
+			}
+		case Class:
+			return p.parseClassDecl(false)
+		case Import:
+			if t := p.peek(); !t.isPunct('(') && !t.isPunct('.') {
+				if p.level != 1 {
+					p.fail("statement")
+					return nil
+				}
+				return p.parseImportStmt()
+			}
+		case Export:
+			if p.level != 1 {
+				p.fail("statement")
+				return nil
+			}
+			return p.parseExportStmt()
+		}
+	}
+
+	x := p.parseExpr()
+	if v, ok := x.(*Ident); ok && p.is(':') {
+		p.next()
+		value := p.parseStmt()
+		return &LabelledStmt{Loc{start, p.prevEnd}, v.Data, value}
+	}
+	p.consumeSemicolon("expression statement")
+	return &ExprStmt{Loc{start, p.prevEnd}, x}
+}
+
+func (p *Parser) parseStmtList(in string) []IStmt {
+	list := []IStmt{}
+	for !p.is('}') {
+		if p.tt == ErrorToken {
+			p.fail(in, "'}'")
+			return list
+		}
+		list = append(list, p.parseStmt())
+	}
+	return list
+}
+
+func (p *Parser) parseBlock(in string) *BlockStmt {
+	start := p.start
+	if !p.consume('{', in) {
+		return &BlockStmt{}
+	}
+	noIn := p.noIn
+	p.noIn = false
+	list := p.parseStmtList(in)
+	p.noIn = noIn
+	p.next()
+	return &BlockStmt{Loc{start, p.prevEnd}, list}
+}
+
+func (p *Parser) parseVarDecl(start int, h Hash) *VarDecl {
+	// keyword has been consumed
+	decl := &VarDecl{TokenType: h}
+	for {
+		elem := BindingElement{Binding: p.parseBinding()}
+		if p.is('=') {
+			p.next()
+			elem.Default = p.parseAssign()
+		}
+		decl.List = append(decl.List, elem)
+		if !p.is(',') {
+			break
+		}
+		p.next()
+	}
+	decl.Loc = Loc{start, p.prevEnd}
+	return decl
+}
+
+func (p *Parser) parseForStmt() IStmt {
+	start := p.start
+	p.next()
+	await := false
+	if p.isKeyword(Await) && (p.async || !p.inFunc) {
+		await = true
+		p.next()
+	}
+	if !p.consume('(', "for statement") {
+		return nil
+	}
+
+	var init INode
+	p.noIn = true
+	if p.is(';') {
+		// no initializer
+	} else if t := p.peek(); p.isKeyword(Var) || p.isKeyword(Const) || p.isKeyword(Let) && (t.tt == IdentifierToken && t.h != In && t.h != Of || t.isPunct('[') || t.isPunct('{')) {
+		declStart, h := p.start, p.h
+		p.next()
+		init = p.parseVarDecl(declStart, h)
+	} else {
+		init = p.parseExpr()
+	}
+	p.noIn = false
+
+	if p.isKeyword(Of) || p.isKeyword(In) {
+		of := p.h == Of
+		if decl, ok := init.(*VarDecl); ok {
+			if len(decl.List) != 1 || decl.List[0].Default != nil && (of || decl.TokenType != Var) {
+				p.failMessage(decl.Start, "invalid declaration in for-%s statement", p.h.String())
+				return nil
+			}
+		} else if !isAssignable(init.(IExpr), true) {
+			p.failMessage(init.Span().Start, "invalid left-hand side in for-%s statement", p.h.String())
+			return nil
+		}
+		p.next()
+		var value IExpr
+		if of {
+			value = p.parseAssign()
+		} else {
+			value = p.parseExpr()
+		}
+		if !p.consume(')', "for statement") {
+			return nil
+		}
+		body := p.parseStmt()
+		if of {
+			return &ForOfStmt{Loc{start, p.prevEnd}, await, init, value, body}
+		}
+		return &ForInStmt{Loc{start, p.prevEnd}, init, value, body}
+	} else if await {
+		p.fail("for statement", "'of'")
+		return nil
+	}
+
+	var cond, post IExpr
+	if !p.consume(';', "for statement") {
+		return nil
+	}
+	if !p.is(';') {
+		cond = p.parseExpr()
+	}
+	if !p.consume(';', "for statement") {
+		return nil
+	}
+	if !p.is(')') {
+		post = p.parseExpr()
+	}
+	if !p.consume(')', "for statement") {
+		return nil
+	}
+	body := p.parseStmt()
+	return &ForStmt{Loc{start, p.prevEnd}, init, cond, post, body}
+}
+
+func (p *Parser) parseSwitchStmt() IStmt {
+	start := p.start
+	p.next()
+	if !p.consume('(', "switch statement") {
+		return nil
+	}
+	init := p.parseExpr()
+	if !p.consume(')', "switch statement") || !p.consume('{', "switch statement") {
+		return nil
+	}
+
+	clauses := []CaseClause{}
+	for !p.is('}') {
+		clauseStart := p.start
+		var cond IExpr
+		if p.isKeyword(Case) {
+			p.next()
+			cond = p.parseExpr()
+		} else if p.isKeyword(Default) {
+			p.next()
+		} else {
+			p.fail("switch statement", "'case'", "'default'", "'}'")
+			return nil
+		}
+		if !p.consume(':', "switch statement") {
+			return nil
+		}
+		list := []IStmt{}
+		for !p.is('}') && !p.isKeyword(Case) && !p.isKeyword(Default) && p.tt != ErrorToken {
+			list = append(list, p.parseStmt())
+		}
+		clauses = append(clauses, CaseClause{Loc{clauseStart, p.prevEnd}, cond, list})
+	}
+	p.next()
+	return &SwitchStmt{Loc{start, p.prevEnd}, init, clauses}
+}
+
+func (p *Parser) parseTryStmt() IStmt {
+	start := p.start
+	p.next()
+	stmt := &TryStmt{}
+	stmt.Body = p.parseBlock("try statement")
+	if p.isKeyword(Catch) {
+		p.next()
+		if p.is('(') {
+			p.next()
+			stmt.Binding = p.parseBinding()
+			if !p.consume(')', "catch clause") {
+				return nil
+			}
+		}
+		stmt.Catch = p.parseBlock("catch clause")
+	}
+	if p.isKeyword(Finally) {
+		p.next()
+		stmt.Finally = p.parseBlock("finally clause")
+	}
+	if stmt.Catch == nil && stmt.Finally == nil {
+		p.fail("try statement", "'catch'", "'finally'")
+		return nil
+	}
+	stmt.Loc = Loc{start, p.prevEnd}
+	return stmt
+}
+
+func (p *Parser) parseModuleSpecifier(in string) []byte {
+	if p.tt != StringToken {
+		p.fail(in, "module specifier")
+		return nil
+	}
+	module := p.data
+	p.next()
+	return module
+}
+
+func (p *Parser) parseAliasList(in string, isImport bool) []Alias {
+	// on {
+	p.next()
+	list := []Alias{}
+	for !p.is('}') {
+		if p.tt != IdentifierToken && p.tt != StringToken {
+			p.fail(in, "name")
+			return nil
+		}
+		alias := Alias{Name: p.data}
+		isString := p.tt == StringToken
+		p.next()
+		if p.isKeyword(As) {
+			p.next()
+			if isImport && !p.isIdentifier() || !isImport && p.tt != IdentifierToken && p.tt != StringToken {
+				p.fail(in, "name")
+				return nil
+			}
+			alias.Binding = p.data
+			p.next()
+		} else if isImport && isString {
+			p.fail(in, "'as'")
+			return nil
+		}
+		list = append(list, alias)
+		if !p.is(',') {
+			break
+		}
+		p.next()
+	}
+	if !p.consume('}', in) {
+		return nil
+	}
+	return list
+}
+
+func (p *Parser) parseImportStmt() IStmt {
+	start := p.start
+	p.next()
+	stmt := &ImportStmt{}
+	if p.tt == StringToken {
+		stmt.Module = p.data
+		p.next()
+		p.consumeSemicolon("import statement")
+		stmt.Loc = Loc{start, p.prevEnd}
+		return stmt
+	}
+
+	if p.isIdentifier() {
+		stmt.Default = p.data
+		p.next()
+		if p.is(',') {
+			p.next()
+		}
+	}
+	if p.is('*') {
+		p.next()
+		if !p.isKeyword(As) {
+			p.fail("import statement", "'as'")
+			return nil
+		}
+		p.next()
+		if !p.isIdentifier() {
+			p.fail("import statement", "name")
+			return nil
+		}
+		stmt.List = []Alias{{Name: []byte("*"), Binding: p.data}}
+		p.next()
+	} else if p.is('{') {
+		stmt.List = p.parseAliasList("import statement", true)
+	} else if stmt.Default == nil {
+		p.fail("import statement")
+		return nil
+	}
+
+	if !p.isKeyword(From) {
+		p.fail("import statement", "'from'")
+		return nil
+	}
+	p.next()
+	stmt.Module = p.parseModuleSpecifier("import statement")
+	p.consumeSemicolon("import statement")
+	stmt.Loc = Loc{start, p.prevEnd}
+	return stmt
+}
+
+func (p *Parser) parseExportStmt() IStmt {
+	start := p.start
+	p.next()
+	stmt := &ExportStmt{}
+	if p.is('*') {
+		p.next()
+		alias := Alias{Name: []byte("*")}
+		if p.isKeyword(As) {
+			p.next()
+			if p.tt != IdentifierToken && p.tt != StringToken {
+				p.fail("export statement", "name")
+				return nil
+			}
+			alias.Binding = p.data
+			p.next()
+		}
+		stmt.List = []Alias{alias}
+		if !p.isKeyword(From) {
+			p.fail("export statement", "'from'")
+			return nil
+		}
+		p.next()
+		stmt.Module = p.parseModuleSpecifier("export statement")
+		p.consumeSemicolon("export statement")
+	} else if p.is('{') {
+		stmt.List = p.parseAliasList("export statement", false)
+		if p.isKeyword(From) {
+			p.next()
+			stmt.Module = p.parseModuleSpecifier("export statement")
+		}
+		p.consumeSemicolon("export statement")
+	} else if p.isKeyword(Var) || p.isKeyword(Let) || p.isKeyword(Const) {
+		declStart, h := p.start, p.h
+		p.next()
+		decl := p.parseVarDecl(declStart, h)
+		p.consumeSemicolon("export statement")
+		decl.End = p.prevEnd
+		stmt.Decl = decl
+	} else if p.isKeyword(Function) {
+		stmt.Decl = p.parseFuncDecl(p.start, false, false)
+	} else if p.isKeyword(Async) {
+		declStart := p.start
+		p.next()
+		if !p.isKeyword(Function) || p.lt {
+			p.fail("export statement", "'function'")
+			return nil
+		}
+		stmt.Decl = p.parseFuncDecl(declStart, true, false)
+	} else if p.isKeyword(Class) {
+		stmt.Decl = p.parseClassDecl(false)
+	} else if p.isKeyword(Default) {
+		p.next()
+		stmt.Default = true
+		if p.isKeyword(Function) {
+			stmt.Decl = p.parseFuncDecl(p.start, false, true)
+		} else if t := p.peek(); p.isKeyword(Async) && t.tt == IdentifierToken && t.h == Function && !t.lt {
+			declStart := p.start
+			p.next()
+			stmt.Decl = p.parseFuncDecl(declStart, true, true)
+		} else if p.isKeyword(Class) {
+			stmt.Decl = p.parseClassDecl(true)
+		} else {
+			stmt.Decl = p.parseAssign()
+			p.consumeSemicolon("export statement")
+		}
+	} else {
+		p.fail("export statement")
+		return nil
+	}
+	stmt.Loc = Loc{start, p.prevEnd}
+	return stmt
+}
+
+////////////////////////////////////////////////////////////////
+
+func (p *Parser) parseFuncDecl(start int, async, expr bool) *FuncDecl {
+	// on function
+	p.next()
+	f := &FuncDecl{Async: async}
+	if p.is('*') {
+		f.Generator = true
+		p.next()
+	}
+	if p.tt == IdentifierToken && !p.is('(') {
+		if !p.isIdentifier() {
+			p.fail("function declaration", "name")
+			return f
+		}
+		f.Name = &Ident{Loc{p.start, p.end}, p.data}
+		p.next()
+	} else if !expr {
+		p.fail("function declaration", "name")
+		return f
+	}
+	f.Params, f.Body = p.parseFuncParamsBody(async, f.Generator)
+	f.Loc = Loc{start, p.prevEnd}
+	return f
+}
+
+func (p *Parser) parseFuncParamsBody(async, generator bool) (Params, BlockStmt) {
+	parentInFunc, parentAsync, parentGenerator, parentNoIn := p.inFunc, p.async, p.generator, p.noIn
+	p.inFunc, p.async, p.generator, p.noIn = true, async, generator, false
+	params := p.parseParams()
+	body := p.parseBlock("function body")
+	p.inFunc, p.async, p.generator, p.noIn = parentInFunc, parentAsync, parentGenerator, parentNoIn
+	return params, *body
+}
+
+func (p *Parser) parseParams() Params {
+	start := p.start
+	if !p.consume('(', "function parameters") {
+		return Params{}
+	}
+	params := Params{}
+	for !p.is(')') {
+		if p.isOp("...") {
+			p.next()
+			params.Rest = p.parseBinding()
+			break
+		}
+		elem := BindingElement{Binding: p.parseBinding()}
+		if p.is('=') {
+			p.next()
+			elem.Default = p.parseAssign()
+		}
+		params.List = append(params.List, elem)
+		if !p.is(',') {
+			break
+		}
+		p.next()
+	}
+	if !p.consume(')', "function parameters") {
+		return params
+	}
+	params.Loc = Loc{start, p.prevEnd}
+	return params
+}
+
+func (p *Parser) parseBinding() IBinding {
+	start := p.start
+	if p.isIdentifier() {
+		v := &Ident{Loc{p.start, p.end}, p.data}
+		p.next()
+		return v
+	} else if p.is('[') {
+		p.next()
+		b := &BindingArray{}
+		for !p.is(']') {
+			if p.is(',') {
+				b.List = append(b.List, BindingElement{})
+				p.next()
+				continue
+			} else if p.isOp("...") {
+				p.next()
+				b.Rest = p.parseBinding()
+				break
+			}
+			elem := BindingElement{Binding: p.parseBinding()}
+			if p.is('=') {
+				p.next()
+				elem.Default = p.parseAssign()
+			}
+			b.List = append(b.List, elem)
+			if !p.is(',') {
+				break
+			}
+			p.next()
+		}
+		if !p.consume(']', "array binding pattern") {
+			return b
+		}
+		b.Loc = Loc{start, p.prevEnd}
+		return b
+	} else if p.is('{') {
+		p.next()
+		b := &BindingObject{}
+		for !p.is('}') {
+			if p.isOp("...") {
+				p.next()
+				if !p.isIdentifier() {
+					p.fail("object binding pattern", "identifier")
+					return b
+				}
+				b.Rest = &Ident{Loc{p.start, p.end}, p.data}
+				p.next()
+				break
+			}
+			item := BindingObjectItem{}
+			if p.isIdentifier() {
+				if t := p.peek(); !t.isPunct(':') {
+					item.Value.Binding = &Ident{Loc{p.start, p.end}, p.data}
+					p.next()
+				}
+			}
+			if item.Value.Binding == nil {
+				if p.tt == PrivateIdentifierToken {
+					p.fail("object binding pattern")
+					return b
+				}
+				name := p.parsePropertyName("object binding pattern")
+				if !p.consume(':', "object binding pattern") {
+					return b
+				}
+				item.Key = &name
+				item.Value.Binding = p.parseBinding()
+			}
+			if p.is('=') {
+				p.next()
+				item.Value.Default = p.parseAssign()
+			}
+			b.List = append(b.List, item)
+			if !p.is(',') {
+				break
+			}
+			p.next()
+		}
+		if !p.consume('}', "object binding pattern") {
+			return b
+		}
+		b.Loc = Loc{start, p.prevEnd}
+		return b
+	}
+	p.fail("binding pattern")
+	return nil
+}
+
+// parsePropertyName parses a property name, which includes private names that are only allowed in class bodies.
+func (p *Parser) parsePropertyName(in string) PropertyName {
+	if p.tt == IdentifierToken || p.tt == StringToken || p.tt == NumericToken || p.tt == PrivateIdentifierToken {
+		lit := &LiteralExpr{Loc{p.start, p.end}, p.tt, p.data}
+		p.next()
+		return PropertyName{Literal: lit}
+	} else if p.is('[') {
+		p.next()
+		noIn := p.noIn
+		p.noIn = false
+		x := p.parseAssign()
+		p.noIn = noIn
+		p.consume(']', in)
+		return PropertyName{Computed: x}
+	}
+	p.fail(in, "property name")
+	return PropertyName{Literal: &LiteralExpr{}}
+}
+
+func (p *Parser) parseClassDecl(expr bool) *ClassDecl {
+	// on class
+	start := p.start
+	p.next()
+	c := &ClassDecl{}
+	if p.tt == IdentifierToken && !p.isKeyword(Extends) {
+		if !p.isIdentifier() {
+			p.fail("class declaration", "name")
+			return c
+		}
+		c.Name = &Ident{Loc{p.start, p.end}, p.data}
+		p.next()
+	} else if !expr {
+		p.fail("class declaration", "name")
+		return c
+	}
+	if p.isKeyword(Extends) {
+		p.next()
+		c.Extends = p.parseLHS()
+	}
+	if !p.consume('{', "class declaration") {
+		return c
+	}
+	for !p.is('}') {
+		if p.tt == ErrorToken {
+			p.fail("class declaration", "'}'")
+			return c
+		} else if p.is(';') {
+			p.next()
+			continue
+		}
+		c.List = append(c.List, p.parseClassElement())
+	}
+	p.next()
+	c.Loc = Loc{start, p.prevEnd}
+	return c
+}
+
+func (p *Parser) parseClassElement() ClassElement {
+	start := p.start
+	static := false
+	if p.isKeyword(Static) {
+		if t := p.peek(); t.isPunct('{') {
+			p.next()
+			parentInFunc, parentAsync, parentGenerator := p.inFunc, p.async, p.generator
+			p.inFunc, p.async, p.generator = true, false, false
+			block := p.parseBlock("class static block")
+			p.inFunc, p.async, p.generator = parentInFunc, parentAsync, parentGenerator
+			return ClassElement{StaticBlock: block}
+		} else if !t.isPunct('(') && !t.isPunct('=') && !t.isPunct(';') && !t.isPunct('}') {
+			static = true
+			p.next()
+		}
+	}
+
+	async, generator, get, set, name := p.parseMethodPrefix("class element")
+	if p.is('(') {
+		method := p.parseMethod(start, async, generator, get, set, name)
+		method.Static = static
+		return ClassElement{Method: method}
+	} else if async || generator || get || set {
+		p.fail("class element", "'('")
+		return ClassElement{Field: &FieldDefinition{}}
+	}
+
+	field := &FieldDefinition{Static: static, Name: name}
+	if p.is('=') {
+		p.next()
+		parentInFunc, parentAsync, parentGenerator := p.inFunc, p.async, p.generator
+		p.inFunc, p.async, p.generator = true, false, false
+		field.Init = p.parseAssign()
+		p.inFunc, p.async, p.generator = parentInFunc, parentAsync, parentGenerator
+	}
+	p.consumeSemicolon("class element")
+	field.Loc = Loc{start, p.prevEnd}
+	return ClassElement{Field: field}
+}
+
+// parseMethodPrefix parses the modifiers and name of a method or property in an object literal or class body.
+func (p *Parser) parseMethodPrefix(in string) (async, generator, get, set bool, name PropertyName) {
+	if p.is('*') {
+		generator = true
+		p.next()
+		return async, generator, get, set, p.parsePropertyName(in)
+	} else if p.isKeyword(Async) || p.isKeyword(Get) || p.isKeyword(Set) {
+		h := p.h
+		if t := p.peek(); !t.isPunct('(') && !t.isPunct(':') && !t.isPunct(',') && !t.isPunct('}') && !t.isPunct('=') && !t.isPunct(';') && (h != Async || !t.lt) {
+			p.next()
+			if h == Async {
+				async = true
+				if p.is('*') {
+					generator = true
+					p.next()
+				}
+			} else if h == Get {
+				get = true
+			} else {
+				set = true
+			}
+		}
+	}
+	return async, generator, get, set, p.parsePropertyName(in)
+}
+
+func (p *Parser) parseMethod(start int, async, generator, get, set bool, name PropertyName) *MethodDecl {
+	method := &MethodDecl{Async: async, Generator: generator, Get: get, Set: set, Name: name}
+	method.Params, method.Body = p.parseFuncParamsBody(async, generator)
+	method.Loc = Loc{start, p.prevEnd}
+	return method
+}
+
+////////////////////////////////////////////////////////////////
+
+func (p *Parser) parseExpr() IExpr {
+	start := p.start
+	x := p.parseAssign()
+	if !p.is(',') {
+		return x
+	}
+	list := []IExpr{x}
+	for p.is(',') {
+		p.next()
+		list = append(list, p.parseAssign())
+	}
+	return &CommaExpr{Loc{start, p.prevEnd}, list}
+}
+
+func isAssignOp(op []byte) bool {
+	switch string(op) {
+	case "=", "+=", "-=", "*=", "/=", "%=", "**=", "<<=", ">>=", ">>>=", "&=", "|=", "^=", "&&=", "||=", "??=":
+		return true
+	}
+	return false
+}
+
+// isAssignable returns true if the expression can be the target of an assignment. Array and object literals are allowed only if pattern is set.
+func isAssignable(x IExpr, pattern bool) bool {
+	switch n := x.(type) {
+	case *Ident:
+		return true
+	case *DotExpr:
+		return !n.Optional
+	case *IndexExpr:
+		return !n.Optional
+	case *GroupExpr:
+		return isAssignable(n.X, false)
+	case *ArrayExpr, *ObjectExpr:
+		return pattern
+	}
+	return false
+}
+
+func (p *Parser) parseAssign() IExpr {
+	start := p.start
+	if p.isKeyword(Yield) && p.generator {
+		return p.parseYield()
+	}
+	x := p.parseCond()
+	if p.tt == PunctuatorToken && isAssignOp(p.data) {
+		if _, ok := x.(*ArrowFunc); ok {
+			return x
+		}
+		op := string(p.data)
+		if !isAssignable(x, op == "=") {
+			p.failMessage(start, "invalid left-hand side in assignment")
+			return x
+		}
+		p.next()
+		y := p.parseAssign()
+		return &BinaryExpr{Loc{start, p.prevEnd}, op, x, y}
+	}
+	return x
+}
+
+func (p *Parser) parseYield() IExpr {
+	start := p.start
+	p.next()
+	yield := &YieldExpr{}
+	if !p.lt && p.is('*') {
+		yield.Generator = true
+		p.next()
+		yield.X = p.parseAssign()
+	} else if !p.lt && p.tt != ErrorToken && !p.is(')') && !p.is(']') && !p.is('}') && !p.is(',') && !p.is(';') && !p.is(':') {
+		yield.X = p.parseAssign()
+	}
+	yield.Loc = Loc{start, p.prevEnd}
+	return yield
+}
+
+func (p *Parser) parseCond() IExpr {
+	start := p.start
+	x := p.parseBinary(0)
+	if _, ok := x.(*ArrowFunc); ok || !p.is('?') {
+		return x
+	}
+	p.next()
+	noIn := p.noIn
+	p.noIn = false
+	y := p.parseAssign()
+	p.noIn = noIn
+	if !p.consume(':', "conditional expression") {
+		return x
+	}
+	z := p.parseAssign()
+	return &CondExpr{Loc{start, p.prevEnd}, x, y, z}
+}
+
+// binaryPrec returns the precedence of the current token as a binary operator, or zero if it is not a binary operator.
+func (p *Parser) binaryPrec() int {
+	if p.tt == IdentifierToken {
+		if p.h == Instanceof || p.h == In && !p.noIn {
+			return 8
+		}
+		return 0
+	} else if p.tt != PunctuatorToken {
+		return 0
+	}
+	switch string(p.data) {
+	case "??":
+		return 1
+	case "||":
+		return 2
+	case "&&":
+		return 3
+	case "|":
+		return 4
+	case "^":
+		return 5
+	case "&":
+		return 6
+	case "==", "!=", "===", "!==":
+		return 7
+	case "<", ">", "<=", ">=":
+		return 8
+	case "<<", ">>", ">>>":
+		return 9
+	case "+", "-":
+		return 10
+	case "*", "/", "%":
+		return 11
+	case "**":
+		return 12
+	}
+	return 0
+}
+
+func (p *Parser) parseBinary(minPrec int) IExpr {
+	start := p.start
+	x := p.parseUnary()
+	if _, ok := x.(*ArrowFunc); ok {
+		return x
+	}
+	for {
+		prec := p.binaryPrec()
+		if prec <= minPrec {
+			return x
+		}
+		op := string(p.data)
+		var y IExpr
+		if op == "**" {
+			if unary, ok := x.(*UnaryExpr); ok && !unary.Postfix {
+				p.failMessage(start, "unary operator before exponentiation must be parenthesized")
+				return x
+			}
+			p.next()
+			y = p.parseBinary(prec - 1) // right-associative
+		} else {
+			p.next()
+			y = p.parseBinary(prec)
+		}
+		x = &BinaryExpr{Loc{start, p.prevEnd}, op, x, y}
+	}
+}
+
+func (p *Parser) parseUnary() IExpr {
+	start := p.start
+	isUnary := false
+	if p.tt == PunctuatorToken {
+		switch string(p.data) {
+		case "!", "~", "+", "-", "++", "--":
+			isUnary = true
+		}
+	} else if p.tt == IdentifierToken {
+		isUnary = p.h == Delete || p.h == Void || p.h == Typeof || p.h == Await && (p.async || !p.inFunc)
+	}
+	if isUnary {
+		op := string(p.data)
+		p.next()
+		x := p.parseUnary()
+		if (op == "++" || op == "--") && !isAssignable(x, false) {
+			p.failMessage(start, "invalid operand for %s", op)
+			return x
+		}
+		return &UnaryExpr{Loc{start, p.prevEnd}, op, x, false}
+	}
+
+	x := p.parseLHS()
+	if _, ok := x.(*ArrowFunc); !ok && !p.lt && (p.isOp("++") || p.isOp("--")) {
+		op := string(p.data)
+		if !isAssignable(x, false) {
+			p.failMessage(start, "invalid operand for %s", op)
+			return x
+		}
+		p.next()
+		if p.tt == RegexpToken {
+			p.relexDiv()
+		}
+		return &UnaryExpr{Loc{start, p.prevEnd}, op, x, true}
+	}
+	return x
+}
+
+func (p *Parser) parseLHS() IExpr {
+	start := p.start
+	var x IExpr
+	if p.isKeyword(New) {
+		x = p.parseNew()
+	} else {
+		x = p.parsePrimary()
+	}
+	return p.parseSuffix(start, x, true)
+}
+
+func (p *Parser) parseNew() IExpr {
+	start := p.start
+	p.next()
+	if p.is('.') {
+		p.next()
+		if !p.isKeyword(Target) {
+			p.fail("new expression", "'target'")
+			return nil
+		}
+		p.next()
+		return &NewTargetExpr{Loc{start, p.prevEnd}}
+	}
+
+	var x IExpr
+	if p.isKeyword(New) {
+		x = p.parseNew()
+	} else {
+		x = p.parsePrimary()
+	}
+	x = p.parseSuffix(p.start, x, false)
+	if p.is('(') {
+		args := p.parseArgs()
+		return &NewExpr{Loc{start, p.prevEnd}, x, &args}
+	}
+	return &NewExpr{Loc{start, p.prevEnd}, x, nil}
+}
+
+func (p *Parser) parseSuffix(start int, x IExpr, allowCall bool) IExpr {
+	if _, ok := x.(*ArrowFunc); ok {
+		return x
+	}
+	if x != nil {
+		start = x.Span().Start
+	}
+	for {
+		if p.tt == RegexpToken {
+			p.relexDiv()
+		}
+		if p.is('.') {
+			p.next()
+			if p.tt != IdentifierToken && p.tt != PrivateIdentifierToken {
+				p.fail("member expression", "name")
+				return x
+			}
+			name := p.data
+			p.next()
+			x = &DotExpr{Loc{start, p.prevEnd}, x, name, false}
+		} else if p.isOp("?.") {
+			if !allowCall {
+				p.fail("new expression")
+				return x
+			}
+			p.next()
+			if p.is('(') {
+				args := p.parseArgs()
+				x = &CallExpr{Loc{start, p.prevEnd}, x, args, true}
+			} else if p.is('[') {
+				index := p.parseIndex()
+				x = &IndexExpr{Loc{start, p.prevEnd}, x, index, true}
+			} else if p.tt == IdentifierToken || p.tt == PrivateIdentifierToken {
+				name := p.data
+				p.next()
+				x = &DotExpr{Loc{start, p.prevEnd}, x, name, true}
+			} else {
+				p.fail("optional chain", "name", "'('", "'['")
+				return x
+			}
+		} else if p.is('[') {
+			index := p.parseIndex()
+			x = &IndexExpr{Loc{start, p.prevEnd}, x, index, false}
+		} else if p.is('(') && allowCall {
+			args := p.parseArgs()
+			x = &CallExpr{Loc{start, p.prevEnd}, x, args, false}
+		} else if p.tt == TemplateToken && p.data[0] == '`' {
+			x = p.parseTemplate(x)
+		} else {
+			return x
+		}
+	}
+}
+
+func (p *Parser) parseIndex() IExpr {
+	// on [
+	p.next()
+	noIn := p.noIn
+	p.noIn = false
+	index := p.parseExpr()
+	p.noIn = noIn
+	p.consume(']', "index expression")
+	return index
+}
+
+func (p *Parser) parseArgs() Args {
+	// on (
+	start := p.start
+	p.next()
+	noIn := p.noIn
+	p.noIn = false
+	args := Args{List: []Arg{}}
+	for !p.is(')') {
+		rest := false
+		if p.isOp("...") {
+			rest = true
+			p.next()
+		}
+		args.List = append(args.List, Arg{p.parseAssign(), rest})
+		if !p.is(',') {
+			break
+		}
+		p.next()
+	}
+	p.noIn = noIn
+	p.consume(')', "arguments")
+	args.Loc = Loc{start, p.prevEnd}
+	return args
+}
+
+func (p *Parser) parseTemplate(tag IExpr) IExpr {
+	start := p.start
+	if tag != nil {
+		start = tag.Span().Start
+	}
+	noIn := p.noIn
+	p.noIn = false
+	t := &TemplateExpr{Tag: tag}
+	for p.tt == TemplateToken {
+		data := p.data
+		n := len(data)
+		p.next()
+		if 2 < n && data[n-2] == '$' && data[n-1] == '{' {
+			x := p.parseExpr()
+			t.List = append(t.List, TemplatePart{data, x})
+			if p.tt != TemplateToken || p.data[0] != '}' {
+				p.fail("template literal", "'}'")
+				return t
+			}
+		} else {
+			t.Tail = data
+			break
+		}
+	}
+	p.noIn = noIn
+	t.Loc = Loc{start, p.prevEnd}
+	return t
+}
+
+func (p *Parser) parsePrimary() IExpr {
+	start := p.start
+	switch p.tt {
+	case IdentifierToken:
+		switch p.h {
+		case This, Null, True, False, Super:
+			x := &LiteralExpr{Loc{p.start, p.end}, p.tt, p.data}
+			p.next()
+			return x
+		case Function:
+			return p.parseFuncDecl(start, false, true)
+		case Class:
+			return p.parseClassDecl(true)
+		case Import:
+			x := &LiteralExpr{Loc{p.start, p.end}, p.tt, p.data}
+			p.next()
+			if p.is('.') {
+				p.next()
+				if !p.isKeyword(Meta) {
+					p.fail("import meta property", "'meta'")
+					return x
+				}
+				p.next()
+				return &ImportMetaExpr{Loc{start, p.prevEnd}}
+			} else if !p.is('(') {
+				p.fail("import call", "'('")
+			}
+			return x
+		case Async:
+			t := p.peek()
+			if t.tt == IdentifierToken && t.h == Function && !t.lt {
+				p.next()
+				return p.parseFuncDecl(start, true, true)
+			} else if t.tt == IdentifierToken && !t.lt && !isReservedWord(t.h) {
+				p.next()
+				param := &Ident{Loc{p.start, p.end}, p.data}
+				p.next()
+				if !p.isOp("=>") || p.lt {
+					p.fail("async arrow function", "'=>'")
+					return param
+				}
+				return p.parseArrowFunc(start, true, Params{Loc: param.Loc}, []IExpr{param}, nil)
+			} else if t.isPunct('(') && !t.lt {
+				async := &Ident{Loc{p.start, p.end}, p.data}
+				p.next()
+				args := p.parseArgs()
+				if p.isOp("=>") && !p.lt {
+					list := []IExpr{}
+					var rest IBinding
+					for i, arg := range args.List {
+						if arg.Rest {
+							if i != len(args.List)-1 {
+								p.failMessage(arg.Value.Span().Start, "rest parameter must be last")
+								return async
+							}
+							rest = p.exprToBinding(arg.Value)
+						} else {
+							list = append(list, arg.Value)
+						}
+					}
+					return p.parseArrowFunc(start, true, Params{Loc: args.Loc}, list, rest)
+				}
+				return &CallExpr{Loc{start, p.prevEnd}, async, args, false}
+			}
+		}
+		if !p.isIdentifier() {
+			p.fail("expression")
+			return nil
+		}
+		x := &Ident{Loc{p.start, p.end}, p.data}
+		p.next()
+		if p.isOp("=>") && !p.lt {
+			return p.parseArrowFunc(start, false, Params{Loc: x.Loc}, []IExpr{x}, nil)
+		}
+		return x
+	case NumericToken, StringToken, RegexpToken:
+		x := &LiteralExpr{Loc{p.start, p.end}, p.tt, p.data}
+		p.next()
+		return x
+	case PrivateIdentifierToken:
+		// private names are only expressions on the left of in, such as #x in obj
+		if t := p.peek(); t.tt == IdentifierToken && t.h == In && !p.noIn {
+			x := &LiteralExpr{Loc{p.start, p.end}, p.tt, p.data}
+			p.next()
+			return x
+		}
+	case TemplateToken:
+		if p.data[0] == '`' {
+			return p.parseTemplate(nil)
+		}
+	case PunctuatorToken:
+		switch string(p.data) {
+		case "(":
+			return p.parseParen()
+		case "[":
+			return p.parseArray()
+		case "{":
+			return p.parseObject()
+		case "/", "/=":
+			if p.relexRegExp() {
+				x := &LiteralExpr{Loc{p.start, p.end}, p.tt, p.data}
+				p.next()
+				return x
+			}
+		}
+	}
+	p.fail("expression")
+	return nil
+}
+
+func (p *Parser) parseParen() IExpr {
+	// on (
+	start := p.start
+	p.next()
+	noIn := p.noIn
+	p.noIn = false
+	list := []IExpr{}
+	var rest IBinding
+	trailingComma := false
+	for !p.is(')') {
+		if p.isOp("...") {
+			p.next()
+			rest = p.parseBinding()
+			break
+		}
+		list = append(list, p.parseAssign())
+		if !p.is(',') {
+			break
+		}
+		p.next()
+		trailingComma = p.is(')')
+	}
+	p.noIn = noIn
+	if !p.consume(')', "parenthesized expression") {
+		return nil
+	}
+	if p.isOp("=>") && !p.lt {
+		return p.parseArrowFunc(start, false, Params{Loc: Loc{start, p.prevEnd}}, list, rest)
+	} else if rest != nil || len(list) == 0 || trailingComma {
+		p.fail("arrow function", "'=>'")
+		return nil
+	}
+	if len(list) == 1 {
+		return &GroupExpr{Loc{start, p.prevEnd}, list[0]}
+	}
+	return &GroupExpr{Loc{start, p.prevEnd}, &CommaExpr{Loc{list[0].Span().Start, list[len(list)-1].Span().End}, list}}
+}
+
+func (p *Parser) parseArrowFunc(start int, async bool, params Params, list []IExpr, rest IBinding) IExpr {
+	// on =>
+	for _, item := range list {
+		params.List = append(params.List, p.exprToBindingElement(item))
+	}
+	params.Rest = rest
+	p.next()
+
+	arrow := &ArrowFunc{Async: async, Params: params}
+	parentInFunc, parentAsync, parentGenerator := p.inFunc, p.async, p.generator
+	p.inFunc, p.async, p.generator = true, async, false
+	if p.is('{') {
+		noIn := p.noIn
+		p.noIn = false
+		arrow.Body = p.parseBlock("arrow function body")
+		p.noIn = noIn
+	} else {
+		arrow.Expr = p.parseAssign()
+	}
+	p.inFunc, p.async, p.generator = parentInFunc, parentAsync, parentGenerator
+	arrow.Loc = Loc{start, p.prevEnd}
+	return arrow
+}
+
+func (p *Parser) parseArray() IExpr {
+	// on [
+	start := p.start
+	p.next()
+	noIn := p.noIn
+	p.noIn = false
+	array := &ArrayExpr{List: []Element{}}
+	for !p.is(']') {
+		if p.is(',') {
+			array.List = append(array.List, Element{})
+			p.next()
+			continue
+		}
+		spread := false
+		if p.isOp("...") {
+			spread = true
+			p.next()
+		}
+		array.List = append(array.List, Element{p.parseAssign(), spread})
+		if !p.is(',') {
+			break
+		}
+		p.next()
+	}
+	p.noIn = noIn
+	p.consume(']', "array literal")
+	array.Loc = Loc{start, p.prevEnd}
+	return array
+}
+
+func (p *Parser) parseObject() IExpr {
+	// on {
+	start := p.start
+	p.next()
+	noIn := p.noIn
+	p.noIn = false
+	object := &ObjectExpr{List: []Property{}}
+	for !p.is('}') {
+		if p.isOp("...") {
+			p.next()
+			object.List = append(object.List, Property{Spread: true, Value: p.parseAssign()})
+		} else {
+			propStart := p.start
+			async, generator, get, set, name := p.parseMethodPrefix("object literal")
+			if name.Literal != nil && name.Literal.TokenType == PrivateIdentifierToken {
+				p.failMessage(name.Literal.Start, "unexpected '%s' in object literal", name.Literal.Data)
+				return object
+			} else if p.is('(') {
+				method := p.parseMethod(propStart, async, generator, get, set, name)
+				object.List = append(object.List, Property{Name: &name, Value: method})
+			} else if async || generator || get || set {
+				p.fail("object literal", "'('")
+				return object
+			} else if p.is(':') {
+				p.next()
+				object.List = append(object.List, Property{Name: &name, Value: p.parseAssign()})
+			} else if name.Literal != nil && name.Literal.TokenType == IdentifierToken && !isReservedWord(ToHash(name.Literal.Data)) {
+				prop := Property{Name: &name, Value: &Ident{name.Literal.Loc, name.Literal.Data}}
+				if p.is('=') {
+					p.next()
+					prop.Init = p.parseAssign()
+				}
+				object.List = append(object.List, prop)
+			} else {
+				p.fail("object literal", "':'")
+				return object
+			}
+		}
+		if !p.is(',') {
+			break
+		}
+		p.next()
+	}
+	p.noIn = noIn
+	p.consume('}', "object literal")
+	object.Loc = Loc{start, p.prevEnd}
+	return object
+}
+
+////////////////////////////////////////////////////////////////
+
+func (p *Parser) exprToBindingElement(x IExpr) BindingElement {
+	if assign, ok := x.(*BinaryExpr); ok && assign.Op == "=" {
+		return BindingElement{p.exprToBinding(assign.X), assign.Y}
+	}
+	return BindingElement{Binding: p.exprToBinding(x)}
+}
+
+// exprToBinding converts an expression that was parsed as an arrow function parameter to a binding pattern.
+func (p *Parser) exprToBinding(x IExpr) IBinding {
+	switch n := x.(type) {
+	case *Ident:
+		return n
+	case *ArrayExpr:
+		b := &BindingArray{Loc: n.Loc}
+		for i, item := range n.List {
+			if item.Spread {
+				if i != len(n.List)-1 {
+					break
+				}
+				b.Rest = p.exprToBinding(item.Value)
+			} else if item.Value == nil {
+				b.List = append(b.List, BindingElement{})
+			} else {
+				b.List = append(b.List, p.exprToBindingElement(item.Value))
+			}
+		}
+		return b
+	case *ObjectExpr:
+		b := &BindingObject{Loc: n.Loc}
+		for i, item := range n.List {
+			if item.Spread {
+				v, ok := item.Value.(*Ident)
+				if !ok || i != len(n.List)-1 {
+					p.failMessage(item.Value.Span().Start, "invalid rest element in binding pattern")
+					return b
+				}
+				b.Rest = v
+			} else if _, ok := item.Value.(*MethodDecl); ok {
+				p.failMessage(item.Value.Span().Start, "invalid method in binding pattern")
+				return b
+			} else if v, ok := item.Value.(*Ident); ok && !item.Name.IsComputed() && v.Loc == item.Name.Literal.Loc {
+				b.List = append(b.List, BindingObjectItem{nil, BindingElement{v, item.Init}})
+			} else {
+				b.List = append(b.List, BindingObjectItem{item.Name, p.exprToBindingElement(item.Value)})
+			}
+		}
+		return b
+	}
+	if x != nil {
+		p.failMessage(x.Span().Start, "invalid binding pattern")
+	}
+	return nil
+}
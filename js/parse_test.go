@@ -0,0 +1,237 @@
+package js
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func TestParse(t *testing.T) {
+	var parseTests = []struct {
+		js       string
+		expected string
+	}{
+		{"", ""},
+		{"/* comment */", ""},
+		{"{}", "Stmt({ })"},
+		{";", "Stmt(;)"},
+		{"var a = 1, b", "Decl(var a = 1, b)"},
+		{"let [a, , ...b] = c", "Decl(let [a, , ...b] = c)"},
+		{"let [a, ,] = c", "Decl(let [a, ,] = c)"},
+		{"const {x, y: z = 2, ...r} = o", "Decl(const { x, y: z = 2, ...r } = o)"},
+		{"let\nx", "Decl(let x)"},
+		{"let = 5", "Stmt((let = 5))"},
+		{"if (a) b; else c", "Stmt(if a Stmt(b) else Stmt(c))"},
+		{"for (var i = 0; i < 10; i++) {}", "Stmt(for Decl(var i = 0) ; (i < 10) ; (i++) Stmt({ }))"},
+		{"for (;;) ;", "Stmt(for ; ; Stmt(;))"},
+		{"for (let x of y) ;", "Stmt(for Decl(let x) of y Stmt(;))"},
+		{"for (x in y) z", "Stmt(for x in y Stmt(z))"},
+		{"for ((a in b);;) ;", "Stmt(for ((a in b)) ; ; Stmt(;))"},
+		{"for (a.b of c) ;", "Stmt(for (a.b) of c Stmt(;))"},
+		{"async function f() { for await (const x of y) ; }", "Decl(async function f Params() Stmt({ Stmt(for await Decl(const x) of y Stmt(;)) }))"},
+		{"while (a) b--", "Stmt(while a Stmt((b--)))"},
+		{"do a; while (b) c", "Stmt(do Stmt(a) while b) Stmt(c)"},
+		{"switch (a) { case 1: b; break; default: c }", "Stmt(switch a Clause(case 1 Stmt(b) Stmt(break)) Clause(default Stmt(c)))"},
+		{"try { a } catch (e) { b } finally { c }", "Stmt(try Stmt({ Stmt(a) }) catch Binding(e) Stmt({ Stmt(b) }) finally Stmt({ Stmt(c) }))"},
+		{"try {} catch {}", "Stmt(try Stmt({ }) catch Stmt({ }))"},
+		{"lbl: for (;;) continue lbl", "Stmt(lbl : Stmt(for ; ; Stmt(continue lbl)))"},
+		{"with (a) b", "Stmt(with a Stmt(b))"},
+		{"throw a", "Stmt(throw a)"},
+		{"debugger", "Stmt(debugger)"},
+		{"function f(a, b = 1, ...c) { return a }", "Decl(function f Params(a, b = 1, ...c) Stmt({ Stmt(return a) }))"},
+		{"function f() { return\na }", "Decl(function f Params() Stmt({ Stmt(return) Stmt(a) }))"},
+		{"function f({a}, [b]) {}", "Decl(function f Params({ a }, [b]) Stmt({ }))"},
+		{"async function* g() { yield* x; await y }", "Decl(async function* g Params() Stmt({ Stmt((yield* x)) Stmt((await y)) }))"},
+		{"function* g() { yield\n1 }", "Decl(function* g Params() Stmt({ Stmt((yield)) Stmt(1) }))"},
+		{"function f() { yield }", "Decl(function f Params() Stmt({ Stmt(yield) }))"},
+		{"class A extends B { static x = 1; constructor() { super() } get a() {} static { init() } }", "Decl(class A extends B Field(static x = 1) Method(constructor Params() Stmt({ Stmt((super())) })) Method(get a Params() Stmt({ })) Static(Stmt({ Stmt((init())) })))"},
+		{"class A { static() {} get\nb() {} async *c() {} x\ny }", "Decl(class A Method(static Params() Stmt({ })) Method(get b Params() Stmt({ })) Method(async * c Params() Stmt({ })) Field(x) Field(y))"},
+		{"import a, {b as c, d} from 'm'", "Stmt(import a , { b as c , d } from 'm')"},
+		{"import * as ns from 'n'", "Stmt(import * as ns from 'n')"},
+		{"import 'o'", "Stmt(import 'o')"},
+		{"export default function () {}", "Stmt(export default Decl(function Params() Stmt({ })))"},
+		{"export default class {}", "Stmt(export default Decl(class))"},
+		{"export default a + b", "Stmt(export default (a + b))"},
+		{"export {a as b, c}", "Stmt(export { a as b , c })"},
+		{"export * from 'm'", "Stmt(export * from 'm')"},
+		{"export * as ns from 'm'", "Stmt(export * as ns from 'm')"},
+		{"export const x = 1", "Stmt(export Decl(const x = 1))"},
+		{"export async function f() {}", "Stmt(export Decl(async function f Params() Stmt({ })))"},
+
+		// expressions
+		{"x = a ? b : c", "Stmt((x = (a ? b : c)))"},
+		{"a = b + c * d - e", "Stmt((a = ((b + (c * d)) - e)))"},
+		{"a = b = c", "Stmt((a = (b = c)))"},
+		{"a += b, c", "Stmt(((a += b), c))"},
+		{"typeof a === 'b' && !c || delete d.e", "Stmt(((((typeof a) === 'b') && (!c)) || (delete (d.e))))"},
+		{"a in b instanceof c", "Stmt(((a in b) instanceof c))"},
+		{"a << b >>> c | d & e ^ f", "Stmt((((a << b) >>> c) | ((d & e) ^ f)))"},
+		{"a = (b, c) => b + c", "Stmt((a = (Params(b, c) => (b + c))))"},
+		{"a = () => {}", "Stmt((a = (Params() => Stmt({ }))))"},
+		{"a = ([b], {c}, d = 1, ...e) => {}", "Stmt((a = (Params([b], { c }, d = 1, ...e) => Stmt({ }))))"},
+		{"a = async x => x", "Stmt((a = (async Params(x) => x)))"},
+		{"a = async (x, ...y) => {}", "Stmt((a = (async Params(x, ...y) => Stmt({ }))))"},
+		{"async(a, b)", "Stmt((async(a, b)))"},
+		{"a => {}\n(1)", "Stmt((Params(a) => Stmt({ }))) Stmt((1))"},
+		{"f(...a, b)", "Stmt((f(...a, b)))"},
+		{"a.b[c](d)", "Stmt((((a.b)[c])(d)))"},
+		{"a.if.class", "Stmt(((a.if).class))"},
+		{"new A.B(c)", "Stmt((new (A.B)(c)))"},
+		{"new new X", "Stmt((new (new X)))"},
+		{"new X().y", "Stmt(((new X()).y))"},
+		{"function f() { new.target }", "Decl(function f Params() Stmt({ Stmt((new.target)) }))"},
+		{"import.meta.url", "Stmt(((import.meta).url))"},
+		{"import('x')", "Stmt((import('x')))"},
+		{"x = `a${b}c${d}e`", "Stmt((x = `a${b}c${d}e`))"},
+		{"tag`x`", "Stmt(tag`x`)"},
+		{"x = [a, , ...b, ]", "Stmt((x = [a, , ...b]))"},
+		{"x = [,]", "Stmt((x = [,]))"},
+		{"a = {b, c: 1, [d]: 2, 'e': 3, 4: 5, ...h}", "Stmt((a = { b, c: 1, [d]: 2, 'e': 3, 4: 5, ...h }))"},
+		{"a = {e() {}, get f() {}, set f(v) {}, async *g() {}, get: 1, async}", "Stmt((a = { Method(e Params() Stmt({ })), Method(get f Params() Stmt({ })), Method(set f Params(v) Stmt({ })), Method(async * g Params() Stmt({ })), get: 1, async }))"},
+		{"[a, b] = [b, a]", "Stmt(([a, b] = [b, a]))"},
+		{"({a = 1} = b)", "Stmt((({ a = 1 } = b)))"},
+		{"x = function* () { yield a, b }", "Stmt((x = Decl(function* Params() Stmt({ Stmt(((yield a), b)) }))))"},
+		{"x = class extends Y {}", "Stmt((x = Decl(class extends Y)))"},
+		{"a\n++b", "Stmt(a) Stmt((++b))"},
+		{"a\nb", "Stmt(a) Stmt(b)"},
+		{"await x", "Stmt((await x))"},
+		{"function f() { await(x) }", "Decl(function f Params() Stmt({ Stmt((await(x))) }))"},
+
+		// ES2020+
+		{"a?.b?.[c]?.(d)", "Stmt((((a?.b)?.[c])?.(d)))"},
+		{"a ?? b || c", "Stmt((a ?? (b || c)))"},
+		{"a ||= b &&= c ??= d", "Stmt((a ||= (b &&= (c ??= d))))"},
+		{"a ** b ** c", "Stmt((a ** (b ** c)))"},
+		{"x = 1_000n + 0x1Fn", "Stmt((x = (1_000n + 0x1Fn)))"},
+		{"class A { #x = 1; static #y; #m() {} get #z() {} static async *#g() {} has(o) { return #x in o && o?.#x } }", "Decl(class A Field(#x = 1) Field(static #y) Method(#m Params() Stmt({ })) Method(get #z Params() Stmt({ })) Method(static async * #g Params() Stmt({ })) Method(has Params(o) Stmt({ Stmt(return ((#x in o) && (o?.#x))) })))"},
+
+		// regular expressions and divisions
+		{"a = /ab+c/g.test(s) / 2", "Stmt((a = (((/ab+c/g.test)(s)) / 2)))"},
+		{"x = y / z / w", "Stmt((x = ((y / z) / w)))"},
+		{"x = a++ / 2 / 3", "Stmt((x = (((a++) / 2) / 3)))"},
+		{"x = {} / 1 / 2", "Stmt((x = (({ } / 1) / 2)))"},
+		{"x = function() {} / 1 / 2", "Stmt((x = ((Decl(function Params() Stmt({ })) / 1) / 2)))"},
+		{"if (a) /b/.exec(c)", "Stmt(if a Stmt(((/b/.exec)(c))))"},
+		{"{} /b/g", "Stmt({ }) Stmt(/b/g)"},
+		{"x = a\n/b/g", "Stmt((x = ((a / b) / g)))"},
+	}
+	for _, tt := range parseTests {
+		t.Run(tt.js, func(t *testing.T) {
+			prog, err := Parse(bytes.NewBufferString(tt.js))
+			test.Error(t, err)
+			test.String(t, prog.String(), tt.expected)
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var parseErrorTests = []struct {
+		js  string
+		err string
+		col int
+	}{
+		{"return", "unexpected 'return' in statement", 1},
+		{"a b", "expected ';' instead of 'b' in expression statement", 3},
+		{"if a", "expected '(' instead of 'a' in if statement", 4},
+		{"var 1", "unexpected '1' in binding pattern", 5},
+		{"1 = 2", "invalid left-hand side in assignment", 1},
+		{"a++ = 2", "invalid left-hand side in assignment", 1},
+		{"++a()", "invalid operand for ++", 1},
+		{"for (var a = 1, b of c) ;", "invalid declaration in for-of statement", 6},
+		{"for (a() in b) ;", "invalid left-hand side in for-in statement", 6},
+		{"(a, b)", "", 0},
+		{"(a, ...b)", "expected '=>' instead of EOF in arrow function", 10},
+		{"(a, b) => {", "expected '}' instead of EOF in arrow function body", 12},
+		{"(a + b) => c", "invalid binding pattern", 2},
+		{"({a() {}}) => c", "invalid method in binding pattern", 3},
+		{"throw\na", "expected expression on the same line instead of 'a' in throw statement", 1},
+		{"function () {}", "expected name instead of '(' in function declaration", 10},
+		{"try {}", "expected 'catch' or 'finally' instead of EOF in try statement", 7},
+		{"switch (a) { b }", "expected 'case', 'default' or '}' instead of 'b' in switch statement", 14},
+		{"{ import a from 'b' }", "unexpected 'import' in statement", 3},
+		{"a = {get b: 1}", "expected '(' instead of ':' in object literal", 11},
+		{"x = `a${b`", "expected '}' instead of '`' in template literal", 10},
+		{"a ? b", "expected ':' instead of EOF in conditional expression", 6},
+		{"'abc", "unexpected ''' in expression", 1},
+		{"a = {#x: 1}", "unexpected '#x' in object literal", 6},
+		{"a = {get #x() {}}", "unexpected '#x' in object literal", 10},
+		{"let {#x} = a", "unexpected '#x' in object binding pattern", 6},
+		{"a.#x = #y", "unexpected '#y' in expression", 8},
+	}
+	for _, tt := range parseErrorTests {
+		t.Run(tt.js, func(t *testing.T) {
+			_, err := Parse(bytes.NewBufferString(tt.js))
+			if tt.col == 0 {
+				test.Error(t, err)
+			} else if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "JS parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestParseLoc(t *testing.T) {
+	prog, err := Parse(bytes.NewBufferString("var a = 1;\n  foo(bar) "))
+	test.Error(t, err)
+	test.T(t, prog.Loc, Loc{0, 22})
+	test.T(t, prog.List[0].Span(), Loc{0, 10})
+	test.T(t, prog.List[1].Span(), Loc{13, 21})
+	call := prog.List[1].(*ExprStmt).Value.(*CallExpr)
+	test.T(t, call.X.Span(), Loc{13, 16})
+	test.T(t, call.Args.Span(), Loc{16, 21})
+}
+
+func TestStreamParser(t *testing.T) {
+	js := "var a = /re[/]g.test(b) ? 1e3 : 0x1F; // c\nlet s = `t${x}u${ {y} }` + 'q' /* c */\nif (a) { b /= 2 }\nclass C { #p = 1 }\nx = y\n/z/g.exec(w)\nfunction f() { return\n/* c\n */ a\n}"
+	prog, err := Parse(bytes.NewBufferString(js))
+	test.Error(t, err)
+
+	p := NewStreamParser(iotest.OneByteReader(bytes.NewBufferString(js)))
+	progStream, err := p.Parse()
+	test.Error(t, err)
+	test.String(t, progStream.String(), prog.String())
+	test.T(t, progStream.Loc, prog.Loc)
+
+	// the parser relexes a regular expression that is longer than the buffered data
+	re := "/" + strings.Repeat("a ", 5000) + "/g"
+	progStream, err = NewStreamParser(test.NewPlainReader(bytes.NewBufferString(strings.Repeat("a;", 5000) + "for (a of " + re + ") ;"))).Parse()
+	test.Error(t, err)
+	test.String(t, progStream.List[len(progStream.List)-1].String(), "Stmt(for a of "+re+" Stmt(;))")
+
+	js = "a = 1;\nb = {c: /d/, e}}"
+	_, err = Parse(bytes.NewBufferString(js))
+	_, errStream := NewStreamParser(iotest.OneByteReader(bytes.NewBufferString(js))).Parse()
+	if perr, ok := errStream.(*parse.Error); ok {
+		test.String(t, perr.Message, err.(*parse.Error).Message)
+		line, col, _ := perr.Position()
+		test.T(t, line, 2)
+		test.T(t, col, 16)
+	} else {
+		test.Fail(t, "bad error:", errStream)
+	}
+}
+
+func ExampleParse() {
+	prog, err := Parse(bytes.NewBufferString("if (a) { b = 5 * c }"))
+	if err != nil {
+		panic(err)
+	}
+	for _, stmt := range prog.List {
+		if ifStmt, ok := stmt.(*IfStmt); ok {
+			fmt.Println(ifStmt.Cond)
+			fmt.Println(ifStmt.Body)
+		}
+	}
+	// Output:
+	// a
+	// Stmt({ Stmt((b = (5 * c))) })
+}
@@ -0,0 +1,703 @@
+package json
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/strconv"
+)
+
+// Unmarshaler is implemented by types that decode their own JSON representation. It is the same interface as in encoding/json.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+// Unmarshal decodes the JSON value in b into the value pointed to by v. See Decoder.Decode for the conversion rules.
+func Unmarshal(b []byte, v interface{}) error {
+	d := newDecoder(bytes.NewBuffer(b), JSONMode)
+	defer d.p.Restore()
+	if err := d.Decode(v); err != nil {
+		if err == io.EOF {
+			return parse.NewError(bytes.NewBuffer(b), 0, "JSON decode error: unexpected end of input")
+		}
+		return err
+	}
+	if gt, _ := d.p.Next(); gt != ErrorGrammar {
+		return d.p.NewError(d.p.Offset(), "JSON decode error: unexpected data after top-level value")
+	} else if d.p.Err() != io.EOF {
+		return d.p.Err()
+	}
+	return nil
+}
+
+// Decoder decodes JSON values from an io.Reader into Go values using Parser.
+type Decoder struct {
+	p                     *Parser
+	disallowUnknownFields bool
+
+	err   error    // first type error
+	field []string // path of struct fields being decoded
+}
+
+// NewDecoder returns a new Decoder for a given io.Reader, which reads a stream of JSON values separated by optional whitespace, such as NDJSON or concatenated JSON.
+// The input is read in chunks using NewStreamParser, so that each value is decoded as soon as it has been read.
+func NewDecoder(r io.Reader) *Decoder {
+	return newDecoder(r, MultiDocumentMode)
+}
+
+func newDecoder(r io.Reader, mode Mode) *Decoder {
+	p := NewStreamParser(r)
+	p.SetMode(mode)
+	return &Decoder{
+		p: p,
+	}
+}
+
+// DisallowUnknownFields causes Decode to return an error when an object has a key that does not match any exported struct field.
+func (d *Decoder) DisallowUnknownFields() {
+	d.disallowUnknownFields = true
+}
+
+// Decode decodes the next JSON value into the value pointed to by v, following the conversion rules of encoding/json.
+// Objects are decoded into structs, using the field name or the name in the `json:"name"` struct tag with a case-insensitive fallback, or into maps with string, integer, or encoding.TextUnmarshaler keys.
+// Arrays are decoded into slices and arrays, strings into strings or into []byte from base64, numbers into integer and floating-point types, and literals into booleans. Null sets pointers, maps, slices, and interfaces to nil and leaves other values unchanged.
+// Into an empty interface it decodes map[string]interface{}, []interface{}, float64, string, bool, or nil. Types implementing Unmarshaler or encoding.TextUnmarshaler decode themselves.
+// Syntax errors are returned immediately. When a JSON value does not fit the Go type, it is skipped and decoding continues, after which the first such error is returned. Errors are of type *parse.Error.
+// It returns io.EOF when there is no more input.
+func (d *Decoder) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("json: Decode requires a non-nil pointer, got %T", v)
+	}
+	d.err = nil
+	d.field = d.field[:0]
+
+	gt, data := d.p.Next()
+	if gt == ErrorGrammar && d.p.Err() == io.EOF {
+		return io.EOF
+	}
+	if err := d.value(rv, gt, data); err != nil {
+		return err
+	}
+	if d.p.mode&MultiDocumentMode != 0 {
+		d.p.Next() // EndDocumentGrammar
+	}
+	return d.err
+}
+
+// start returns the offset of the value just returned by Next.
+func (d *Decoder) start(data []byte) int {
+	return d.p.Offset() - len(data)
+}
+
+// syntaxError returns the parser's error, or an unexpected end of input error.
+func (d *Decoder) syntaxError() error {
+	if d.p.Err() != io.EOF {
+		return d.p.Err()
+	}
+	return d.p.NewError(d.p.Offset(), "JSON decode error: unexpected end of input")
+}
+
+func describe(gt GrammarType, data []byte) string {
+	switch gt {
+	case StringGrammar:
+		return "string"
+	case NumberGrammar:
+		return "number " + string(data)
+	case LiteralGrammar:
+		if data[0] == 'n' {
+			return "null"
+		}
+		return "bool"
+	case StartObjectGrammar:
+		return "object"
+	case StartArrayGrammar:
+		return "array"
+	}
+	return gt.String()
+}
+
+// typeError records a type mismatch for the value at offset if it is the first.
+func (d *Decoder) typeError(offset int, what string, t reflect.Type) {
+	if d.err != nil {
+		return
+	}
+	if 0 < len(d.field) {
+		d.err = d.p.NewError(offset, "JSON decode error: cannot unmarshal %s into Go struct field %s of type %s", what, strings.Join(d.field, "."), t)
+	} else {
+		d.err = d.p.NewError(offset, "JSON decode error: cannot unmarshal %s into Go value of type %s", what, t)
+	}
+}
+
+// skip skips over the value that starts with the given grammar.
+func (d *Decoder) skip(gt GrammarType) error {
+	if gt == ErrorGrammar {
+		return d.syntaxError()
+	} else if gt != StartObjectGrammar && gt != StartArrayGrammar {
+		return nil
+	}
+	for level := 1; 0 < level; {
+		switch gt, _ = d.p.Next(); gt {
+		case ErrorGrammar:
+			return d.syntaxError()
+		case StartObjectGrammar, StartArrayGrammar:
+			level++
+		case EndObjectGrammar, EndArrayGrammar:
+			level--
+		}
+	}
+	return nil
+}
+
+// raw skips over the value and returns its bytes from the input. They are only valid until the next call to raw.
+func (d *Decoder) raw(gt GrammarType, data []byte) ([]byte, error) {
+	d.p.raw = append(d.p.raw[:0], data...)
+	d.p.record = true
+	err := d.skip(gt)
+	d.p.record = false
+	if err != nil {
+		return nil, err
+	}
+	return d.p.raw, nil
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// indirect allocates and walks through pointers until it reaches a non-pointer value, or a value implementing Unmarshaler or encoding.TextUnmarshaler. For null it stops at the first pointer, since null sets it to nil.
+func indirect(v reflect.Value, null bool) (Unmarshaler, encoding.TextUnmarshaler, reflect.Value) {
+	for {
+		if v.Kind() == reflect.Interface && !v.IsNil() {
+			if e := v.Elem(); e.Kind() == reflect.Ptr && !e.IsNil() && (!null || e.Elem().Kind() == reflect.Ptr) {
+				v = e
+				continue
+			}
+		}
+		if v.Kind() != reflect.Ptr {
+			if v.CanAddr() {
+				if u, ok := v.Addr().Interface().(Unmarshaler); ok {
+					return u, nil, reflect.Value{}
+				} else if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+					return nil, u, reflect.Value{}
+				}
+			}
+			return nil, nil, v
+		} else if null && v.CanSet() {
+			return nil, nil, v
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		if v.Type().NumMethod() != 0 {
+			if u, ok := v.Interface().(Unmarshaler); ok {
+				return u, nil, reflect.Value{}
+			} else if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+				return nil, u, reflect.Value{}
+			}
+		}
+		v = v.Elem()
+	}
+}
+
+func (d *Decoder) value(v reflect.Value, gt GrammarType, data []byte) error {
+	if gt == ErrorGrammar {
+		return d.syntaxError()
+	}
+	offset := d.start(data)
+	null := gt == LiteralGrammar && data[0] == 'n'
+	u, tu, v := indirect(v, null)
+	if u != nil {
+		var perr *parse.Error
+		if gt == StartObjectGrammar || gt == StartArrayGrammar {
+			perr = d.p.NewError(offset, "") // the start of the value may no longer be buffered after skipping it
+		}
+		raw, err := d.raw(gt, data)
+		if err != nil {
+			return err
+		} else if err := u.UnmarshalJSON(raw); err != nil && d.err == nil {
+			if perr == nil {
+				perr = d.p.NewError(offset, "")
+			}
+			perr.Message = fmt.Sprintf("JSON decode error: %v", err)
+			d.err = perr
+		}
+		return nil
+	} else if tu != nil {
+		if gt != StringGrammar {
+			if !null {
+				d.typeError(offset, describe(gt, data), reflect.TypeOf(tu).Elem())
+			}
+			return d.skip(gt)
+		} else if err := tu.UnmarshalText(unescapeLenient(data)); err != nil && d.err == nil {
+			d.err = d.p.NewError(offset, "JSON decode error: %v", err)
+		}
+		return nil
+	}
+
+	switch gt {
+	case StartObjectGrammar:
+		return d.object(v, offset)
+	case StartArrayGrammar:
+		return d.array(v, offset)
+	case EndObjectGrammar, EndArrayGrammar:
+		return d.p.NewError(offset, "JSON decode error: unexpected %s", describe(gt, data))
+	}
+	d.literal(v, gt, data, offset)
+	return nil
+}
+
+func (d *Decoder) literal(v reflect.Value, gt GrammarType, data []byte, offset int) {
+	switch gt {
+	case LiteralGrammar:
+		switch data[0] {
+		case 'n':
+			switch v.Kind() {
+			case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+				v.Set(reflect.Zero(v.Type()))
+			}
+		case 't', 'f':
+			b := data[0] == 't'
+			if v.Kind() == reflect.Bool {
+				v.SetBool(b)
+			} else if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+				v.Set(reflect.ValueOf(b))
+			} else {
+				d.typeError(offset, "bool", v.Type())
+			}
+		}
+	case StringGrammar:
+		s := unescapeLenient(data)
+		switch v.Kind() {
+		case reflect.String:
+			v.SetString(string(s))
+		case reflect.Slice:
+			if v.Type().Elem().Kind() != reflect.Uint8 {
+				d.typeError(offset, "string", v.Type())
+				return
+			}
+			b := make([]byte, base64.StdEncoding.DecodedLen(len(s)))
+			n, err := base64.StdEncoding.Decode(b, s)
+			if err != nil {
+				if d.err == nil {
+					d.err = d.p.NewError(offset, "JSON decode error: %v", err)
+				}
+				return
+			}
+			v.SetBytes(b[:n])
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				d.typeError(offset, "string", v.Type())
+				return
+			}
+			v.Set(reflect.ValueOf(string(s)))
+		default:
+			d.typeError(offset, "string", v.Type())
+		}
+	case NumberGrammar:
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, n := strconv.ParseInt(data)
+			if n != len(data) || v.OverflowInt(i) {
+				d.numberError(data, v.Type(), offset)
+				return
+			}
+			v.SetInt(i)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			i, n := parseUint(data)
+			if n != len(data) || v.OverflowUint(i) {
+				d.numberError(data, v.Type(), offset)
+				return
+			}
+			v.SetUint(i)
+		case reflect.Float32, reflect.Float64:
+			f, n := strconv.ParseFloat(data)
+			if n != len(data) || v.OverflowFloat(f) {
+				d.numberError(data, v.Type(), offset)
+				return
+			}
+			v.SetFloat(f)
+		case reflect.Interface:
+			if v.NumMethod() != 0 {
+				d.typeError(offset, describe(gt, data), v.Type())
+				return
+			}
+			f, _ := strconv.ParseFloat(data)
+			v.Set(reflect.ValueOf(f))
+		default:
+			d.typeError(offset, describe(gt, data), v.Type())
+		}
+	}
+}
+
+func (d *Decoder) numberError(data []byte, t reflect.Type, offset int) {
+	if d.err == nil && bytes.IndexAny(data, ".eE") == -1 && (data[0] != '-' || t.Kind() < reflect.Uint) {
+		d.err = d.p.NewError(offset, "JSON decode error: number %s overflows Go value of type %s", data, t)
+		return
+	}
+	d.typeError(offset, describe(NumberGrammar, data), t)
+}
+
+// parseUint parses an unsigned integer, it returns zero bytes read on overflow.
+func parseUint(b []byte) (uint64, int) {
+	n := uint64(0)
+	i := 0
+	for ; i < len(b) && '0' <= b[i] && b[i] <= '9'; i++ {
+		c := uint64(b[i] - '0')
+		if (1<<64-1-c)/10 < n {
+			return 0, 0
+		}
+		n = n*10 + c
+	}
+	return n, i
+}
+
+func (d *Decoder) array(v reflect.Value, offset int) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			list := []interface{}{}
+			for {
+				gt, data := d.p.Next()
+				if gt == EndArrayGrammar {
+					break
+				}
+				var elem interface{}
+				if err := d.value(reflect.ValueOf(&elem).Elem(), gt, data); err != nil {
+					return err
+				}
+				list = append(list, elem)
+			}
+			v.Set(reflect.ValueOf(list))
+			return nil
+		}
+		fallthrough
+	default:
+		d.typeError(offset, "array", v.Type())
+		return d.skip(StartArrayGrammar)
+	case reflect.Slice, reflect.Array:
+	}
+
+	i := 0
+	for ; ; i++ {
+		gt, data := d.p.Next()
+		if gt == EndArrayGrammar {
+			break
+		}
+		if v.Kind() == reflect.Slice {
+			if v.Cap() <= i {
+				v.Set(reflect.AppendSlice(v.Slice(0, v.Cap()), reflect.MakeSlice(v.Type(), 1+v.Cap()/2+1, 1+v.Cap()/2+1)))
+			}
+			if v.Len() <= i {
+				v.SetLen(i + 1)
+			}
+		}
+		if i < v.Len() {
+			elem := v.Index(i)
+			elem.Set(reflect.Zero(elem.Type()))
+			if err := d.value(elem, gt, data); err != nil {
+				return err
+			}
+		} else if err := d.skip(gt); err != nil {
+			return err
+		}
+	}
+	if v.Kind() == reflect.Array {
+		for ; i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+	} else if i == 0 && v.IsNil() {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	} else {
+		v.SetLen(i)
+	}
+	return nil
+}
+
+func (d *Decoder) object(v reflect.Value, offset int) error {
+	var fields []field
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			d.typeError(offset, "object", v.Type())
+			return d.skip(StartObjectGrammar)
+		}
+		m := map[string]interface{}{}
+		for {
+			gt, key := d.p.Next()
+			if gt == EndObjectGrammar {
+				break
+			} else if gt == ErrorGrammar {
+				return d.syntaxError()
+			}
+			k := string(unescapeLenient(key))
+			var elem interface{}
+			gt, data := d.p.Next()
+			if err := d.value(reflect.ValueOf(&elem).Elem(), gt, data); err != nil {
+				return err
+			}
+			m[k] = elem
+		}
+		v.Set(reflect.ValueOf(m))
+		return nil
+	case reflect.Map:
+		switch kt := v.Type().Key(); kt.Kind() {
+		case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		default:
+			if !reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+				d.typeError(offset, "object", v.Type())
+				return d.skip(StartObjectGrammar)
+			}
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	case reflect.Struct:
+		fields = cachedFields(v.Type())
+	default:
+		d.typeError(offset, "object", v.Type())
+		return d.skip(StartObjectGrammar)
+	}
+
+	for {
+		gt, key := d.p.Next()
+		if gt == EndObjectGrammar {
+			return nil
+		} else if gt == ErrorGrammar {
+			return d.syntaxError()
+		}
+		// the key is only valid until the next call to Next
+		keyOffset := d.p.Start()
+		k := unescapeLenient(key)
+
+		if v.Kind() == reflect.Map {
+			kv, ok := d.mapKey(v.Type().Key(), k, keyOffset)
+			elem := reflect.New(v.Type().Elem()).Elem()
+			gt, data := d.p.Next()
+			if err := d.value(elem, gt, data); err != nil {
+				return err
+			}
+			if ok {
+				v.SetMapIndex(kv, elem)
+			}
+			continue
+		}
+
+		f := lookupField(fields, k)
+		if f == nil {
+			if d.disallowUnknownFields && d.err == nil {
+				d.err = d.p.NewError(keyOffset, "JSON decode error: unknown field %s", key)
+			}
+			gt, _ := d.p.Next()
+			if err := d.skip(gt); err != nil {
+				return err
+			}
+			continue
+		}
+		elem, ok := fieldByIndex(v, f.index)
+		if !ok {
+			if d.err == nil {
+				d.err = d.p.NewError(keyOffset, "JSON decode error: cannot set embedded pointer to unexported struct %v", elem.Type().Elem())
+			}
+			gt, _ := d.p.Next()
+			if err := d.skip(gt); err != nil {
+				return err
+			}
+			continue
+		}
+		gt, data := d.p.Next()
+		d.field = append(d.field, v.Type().Name()+"."+f.name)
+		if 1 < len(d.field) {
+			d.field[len(d.field)-1] = f.name
+		}
+		var err error
+		if f.quoted {
+			err = d.quoted(elem, gt, data)
+		} else {
+			err = d.value(elem, gt, data)
+		}
+		d.field = d.field[:len(d.field)-1]
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// quoted decodes a boolean, number, or string that is encoded inside a JSON string, for fields with the ,string tag option.
+func (d *Decoder) quoted(v reflect.Value, gt GrammarType, data []byte) error {
+	if gt == ErrorGrammar || gt == LiteralGrammar && data[0] == 'n' {
+		return d.value(v, gt, data)
+	}
+	offset := d.start(data)
+	if gt != StringGrammar {
+		if d.err == nil {
+			d.err = d.p.NewError(offset, "JSON decode error: invalid use of ,string struct tag, trying to unmarshal unquoted value into %v", v.Type())
+		}
+		return d.skip(gt)
+	}
+
+	p := NewParser(bytes.NewReader(unescapeLenient(data)))
+	p.SetMode(JSONMode)
+	innerGt, inner := p.Next()
+	inner = parse.Copy(inner)
+	if innerGt != LiteralGrammar && innerGt != NumberGrammar && innerGt != StringGrammar {
+		innerGt = ErrorGrammar
+	} else if gt, _ := p.Next(); gt != ErrorGrammar || p.Err() != io.EOF {
+		innerGt = ErrorGrammar // trailing data
+	}
+	if innerGt == ErrorGrammar {
+		if d.err == nil {
+			d.err = d.p.NewError(offset, "JSON decode error: invalid use of ,string struct tag, trying to unmarshal %s into %v", data, v.Type())
+		}
+		return nil
+	}
+	d.literal(v, innerGt, inner, offset)
+	return nil
+}
+
+// fieldByIndex returns the field of a struct at the index path, allocating nil pointers to embedded structs. If such a pointer cannot be set because it is unexported, it returns the pointer and false.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return v, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v, true
+}
+
+func (d *Decoder) mapKey(kt reflect.Type, k []byte, offset int) (reflect.Value, bool) {
+	if reflect.PtrTo(kt).Implements(textUnmarshalerType) {
+		kv := reflect.New(kt)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText(k); err != nil {
+			if d.err == nil {
+				d.err = d.p.NewError(offset, "JSON decode error: %v", err)
+			}
+			return reflect.Value{}, false
+		}
+		return kv.Elem(), true
+	}
+	switch kt.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(string(k)).Convert(kt), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, n := strconv.ParseInt(k)
+		kv := reflect.New(kt).Elem()
+		if n == len(k) && 0 < n && !kv.OverflowInt(i) {
+			kv.SetInt(i)
+			return kv, true
+		}
+	default:
+		i, n := parseUint(k)
+		kv := reflect.New(kt).Elem()
+		if n == len(k) && 0 < n && !kv.OverflowUint(i) {
+			kv.SetUint(i)
+			return kv, true
+		}
+	}
+	d.typeError(offset, "number "+string(k), kt)
+	return reflect.Value{}, false
+}
+
+////////////////////////////////////////////////////////////////
+
+type field struct {
+	name   string
+	index  []int
+	quoted bool // the ,string tag option
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+func cachedFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t, nil, map[reflect.Type]bool{}))
+	return fields.([]field)
+}
+
+// typeFields returns the decodable fields of a struct type, including those of embedded structs. Fields at a shallower depth take precedence.
+func typeFields(t reflect.Type, index []int, visited map[reflect.Type]bool) []field {
+	if visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	fields := []field{}
+	var embedded []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma != -1 {
+			name, opts = tag[:comma], tag[comma:]
+		}
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		idx := append(append([]int{}, index...), i)
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			embedded = append(embedded, typeFields(ft, idx, visited)...)
+			continue
+		} else if sf.PkgPath != "" {
+			continue // unexported
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		quoted := false
+		if strings.Contains(opts+",", ",string,") {
+			switch sf.Type.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.String:
+				quoted = true
+			}
+		}
+		fields = append(fields, field{name, idx, quoted})
+	}
+	for _, f := range embedded {
+		if lookupExact(fields, f.name) == nil {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func lookupExact(fields []field, name string) *field {
+	for i := range fields {
+		if fields[i].name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+// lookupField finds the field with the given name, preferring an exact match over a case-insensitive match.
+func lookupField(fields []field, name []byte) *field {
+	if f := lookupExact(fields, string(name)); f != nil {
+		return f
+	}
+	for i := range fields {
+		if bytes.EqualFold([]byte(fields[i].name), name) {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////
@@ -0,0 +1,303 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+type Inner struct {
+	X int
+	Y string `json:"y"`
+}
+
+type Embedded struct {
+	E   string
+	Dup int
+}
+
+type Outer struct {
+	Embedded
+	*Inner `json:"-"`
+
+	Str     string `json:"str"`
+	Int     int    `json:"int,omitempty"`
+	Uint8   uint8
+	Float   float64
+	Bool    bool
+	Ptr     *int
+	Bytes   []byte
+	Slice   []Inner
+	Array   [2]int
+	Map     map[string]int
+	IntMap  map[int]string
+	Any     interface{}
+	Nested  Inner
+	Time    time.Time
+	Dup     int
+	Skip    string `json:"-"`
+	private string
+}
+
+type inner struct {
+	X int
+}
+
+type unexportedEmbedded struct {
+	*inner
+	Y int
+}
+
+type quoted struct {
+	N   int     `json:"n,string"`
+	F   float64 `json:",string"`
+	B   bool    `json:"b,omitempty,string"`
+	S   string  `json:"s,string"`
+	Arr []int   `json:"arr,string"` // ignored for other kinds
+}
+
+type upper string
+
+func (u *upper) UnmarshalJSON(b []byte) error {
+	*u = upper(strings.ToUpper(string(b)))
+	return nil
+}
+
+func TestUnmarshal(t *testing.T) {
+	var out Outer
+	err := Unmarshal([]byte(`{
+		"str": "a\"bé😀",
+		"int": -5,
+		"Uint8": 255,
+		"float": 1.5e2,
+		"bool": true,
+		"ptr": 3,
+		"bytes": "aGVsbG8=",
+		"slice": [{"X": 1, "y": "a"}, {"x": 2}],
+		"array": [1, 2, 3],
+		"map": {"a": 1, "b": 2},
+		"intMap": {"-1": "x"},
+		"any": {"a": [1, "b", null, false]},
+		"nested": {"X": 7, "unknown": [{}]},
+		"time": "2020-01-02T03:04:05Z",
+		"E": "embedded",
+		"Dup": 9,
+		"Skip": "x",
+		"private": "x"
+	}`), &out)
+	test.Error(t, err)
+	test.String(t, out.Str, "a\"bé😀")
+	test.T(t, out.Int, -5)
+	test.T(t, out.Uint8, uint8(255))
+	test.T(t, out.Float, 150.0)
+	test.T(t, out.Bool, true)
+	test.T(t, *out.Ptr, 3)
+	test.String(t, string(out.Bytes), "hello")
+	test.T(t, out.Slice, []Inner{{1, "a"}, {2, ""}})
+	test.T(t, out.Array, [2]int{1, 2})
+	test.T(t, out.Map, map[string]int{"a": 1, "b": 2})
+	test.T(t, out.IntMap, map[int]string{-1: "x"})
+	test.T(t, out.Any, map[string]interface{}{"a": []interface{}{1.0, "b", nil, false}})
+	test.T(t, out.Nested, Inner{7, ""})
+	test.That(t, out.Time.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)))
+	test.String(t, out.E, "embedded")
+	test.T(t, out.Dup, 9)
+	test.T(t, out.Embedded.Dup, 0)
+	test.String(t, out.Skip, "")
+	test.String(t, out.private, "")
+}
+
+func TestUnmarshalValues(t *testing.T) {
+	var i interface{}
+	test.Error(t, Unmarshal([]byte(` "x" `), &i))
+	test.T(t, i, "x")
+
+	var f float32
+	test.Error(t, Unmarshal([]byte(`-0.25`), &f))
+	test.T(t, f, float32(-0.25))
+
+	var u uint64
+	test.Error(t, Unmarshal([]byte(`18446744073709551615`), &u))
+	test.T(t, u, uint64(math.MaxUint64))
+
+	p := new(int)
+	test.Error(t, Unmarshal([]byte(`null`), &p))
+	test.T(t, p, (*int)(nil))
+
+	n := 5
+	test.Error(t, Unmarshal([]byte(`null`), &n))
+	test.T(t, n, 5)
+
+	s := []int{1, 2, 3}
+	test.Error(t, Unmarshal([]byte(`[4]`), &s))
+	test.T(t, s, []int{4})
+	test.Error(t, Unmarshal([]byte(`[]`), &s))
+	test.T(t, s, []int{})
+
+	var up upper
+	test.Error(t, Unmarshal([]byte(`"abc"`), &up))
+	test.String(t, string(up), `"ABC"`)
+
+	var ups []upper
+	test.Error(t, Unmarshal([]byte(`[1, {"a": [2]}]`), &ups))
+	test.T(t, ups, []upper{"1", `{"A": [2]}`})
+
+	var str string
+	test.Error(t, Unmarshal([]byte(`"\ud800x\q"`), &str))
+	test.String(t, str, "�x�")
+}
+
+func TestUnmarshalQuoted(t *testing.T) {
+	var q quoted
+	test.Error(t, Unmarshal([]byte(`{"n": "-5", "F": "1.5e2", "b": "true", "s": "\"a\\u0062\"", "arr": [1]}`), &q))
+	test.T(t, q, quoted{-5, 150.0, true, "ab", []int{1}})
+
+	q = quoted{N: 5}
+	test.Error(t, Unmarshal([]byte(`{"n": null, "s": "null"}`), &q))
+	test.T(t, q.N, 5)
+}
+
+func TestUnmarshalError(t *testing.T) {
+	var errorTests = []struct {
+		json string
+		v    interface{}
+		err  string
+		col  int
+	}{
+		{`"a"`, new(int), "cannot unmarshal string into Go value of type int", 1},
+		{`[1, true]`, new([]int), "cannot unmarshal bool into Go value of type int", 5},
+		{`{"int": 1.5}`, new(Outer), "cannot unmarshal number 1.5 into Go struct field Outer.int of type int", 9},
+		{`{"nested": {"y": 5}}`, new(Outer), "cannot unmarshal number 5 into Go struct field Outer.Nested.y of type string", 18},
+		{`300`, new(uint8), "number 300 overflows Go value of type uint8", 1},
+		{`-1`, new(uint), "cannot unmarshal number -1 into Go value of type uint", 1},
+		{`{}`, new([]int), "cannot unmarshal object into Go value of type []int", 1},
+		{`[]`, new(map[string]int), "cannot unmarshal array into Go value of type map[string]int", 1},
+		{`{"x": 1}`, new(map[int]int), "cannot unmarshal number x into Go value of type int", 2},
+		{`"!"`, new([]byte), "illegal base64 data at input byte 0", 1},
+		{`1 2`, new(int), "expected comma character or an array or object ending", 3},
+		{`[1,`, new([]int), "unexpected end of input", 4},
+		{``, new(int), "unexpected end of input", 1},
+		{"{\n  \"a\": tru }", new(interface{}), "unexpected character 't'", 8},
+		{`{"X": 1}`, new(unexportedEmbedded), "cannot set embedded pointer to unexported struct json.inner", 2},
+		{`{"n": 5}`, new(quoted), "invalid use of ,string struct tag, trying to unmarshal unquoted value into int", 7},
+		{`{"n": "5 6"}`, new(quoted), "invalid use of ,string struct tag, trying to unmarshal \"5 6\" into int", 7},
+		{`{"n": "[5]"}`, new(quoted), "invalid use of ,string struct tag, trying to unmarshal \"[5]\" into int", 7},
+		{`{"s": "abc"}`, new(quoted), "invalid use of ,string struct tag, trying to unmarshal \"abc\" into string", 7},
+		{`{"n": "true"}`, new(quoted), "cannot unmarshal bool into Go struct field quoted.n of type int", 7},
+		{`{"n": "300000000000000000000"}`, new(quoted), "number 300000000000000000000 overflows Go value of type int", 7},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.json, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.json), tt.v)
+			if perr, ok := err.(*parse.Error); ok {
+				test.That(t, strings.HasSuffix(perr.Message, tt.err), perr.Message)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+
+	// decoding continues after a type error
+	var out Outer
+	err := Unmarshal([]byte(`{"str": 1, "int": 2}`), &out)
+	test.That(t, err != nil)
+	test.T(t, out.Int, 2)
+
+	// embedded pointer to an unexported struct
+	var embedded unexportedEmbedded
+	err = Unmarshal([]byte(`{"X": 1, "Y": 2}`), &embedded)
+	test.That(t, err != nil)
+	test.T(t, embedded.Y, 2)
+
+	// line numbers
+	err = Unmarshal([]byte("{\n\"int\":\n\"x\"}"), &out)
+	line, col, _ := err.(*parse.Error).Position()
+	test.T(t, line, 3)
+	test.T(t, col, 1)
+
+	test.That(t, Unmarshal([]byte(`1`), 5) != nil)
+	test.That(t, Unmarshal([]byte(`1`), (*int)(nil)) != nil)
+}
+
+func TestDecoder(t *testing.T) {
+	d := NewDecoder(bytes.NewBufferString(`{"X": 1, "Z": 2}`))
+	d.DisallowUnknownFields()
+	var inner Inner
+	err := d.Decode(&inner)
+	test.That(t, err != nil)
+	test.String(t, err.(*parse.Error).Message, "JSON decode error: unknown field \"Z\"")
+	test.T(t, inner.X, 1)
+	test.T(t, d.Decode(&inner), io.EOF)
+
+	// stream of values
+	d = NewDecoder(bytes.NewBufferString("{\"X\": 1}\n{\"X\": 2} 3 [4]\n"))
+	for _, x := range []int{1, 2} {
+		test.Error(t, d.Decode(&inner))
+		test.T(t, inner.X, x)
+	}
+	var n int
+	test.Error(t, d.Decode(&n))
+	test.T(t, n, 3)
+	var ns []int
+	test.Error(t, d.Decode(&ns))
+	test.T(t, ns, []int{4})
+	test.T(t, d.Decode(&n), io.EOF)
+
+	// a type error doesn't stop the stream
+	d = NewDecoder(bytes.NewBufferString(`"a" 5`))
+	test.That(t, d.Decode(&n) != nil)
+	test.Error(t, d.Decode(&n))
+	test.T(t, n, 5)
+
+	// values are decoded while reading, and raw values and error positions are kept across buffer boundaries
+	d = NewDecoder(iotest.OneByteReader(bytes.NewBufferString("[{\"a\" :\n [1, 2]}, \"b\"]\n{\"X\": 1,\n\n \"Z\": 2}")))
+	d.DisallowUnknownFields()
+	var ups []upper
+	test.Error(t, d.Decode(&ups))
+	test.T(t, ups, []upper{"{\"A\" :\n [1, 2]}", `"B"`})
+	err = d.Decode(&inner)
+	test.That(t, err != nil)
+	line, col, _ := err.(*parse.Error).Position()
+	test.T(t, line, 5)
+	test.T(t, col, 2)
+
+	// each value is returned before the next is written
+	r, w := io.Pipe()
+	decoded := make(chan bool)
+	go func() {
+		for _, s := range []string{"{\"X\": 1}\n", "{\"X\": 2}"} {
+			w.Write([]byte(s))
+			<-decoded
+		}
+		w.Close()
+	}()
+	d = NewDecoder(r)
+	for _, x := range []int{1, 2} {
+		test.Error(t, d.Decode(&inner))
+		test.T(t, inner.X, x)
+		decoded <- true
+	}
+	test.T(t, d.Decode(&inner), io.EOF)
+}
+
+func ExampleUnmarshal() {
+	var v struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	if err := Unmarshal([]byte(`{"name": "parse", "tags": ["css", "html"]}`), &v); err != nil {
+		panic(err)
+	}
+	fmt.Println(v.Name, v.Tags)
+	// Output: parse [css html]
+}
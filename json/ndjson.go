@@ -0,0 +1,46 @@
+package json
+
+import (
+	"bufio"
+	"io"
+)
+
+// SplitNDJSON reads newline-delimited JSON (NDJSON or JSON Lines) from r and calls f for each record with its offset in the input and its data, which is only valid during the call. Records are not parsed. Whitespace around records is trimmed and empty lines are skipped. It stops when f returns false, and returns the error of r other than io.EOF.
+// The byte range of a record in the input is from offset to offset+len(record), which allows records to be parsed in parallel, for example by reading them again using io.ReaderAt.
+func SplitNDJSON(r io.Reader, f func(offset int, record []byte) bool) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	var long []byte // lines longer than the buffer
+	offset := 0
+	for {
+		line, err := br.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			long = append(long, line...)
+			continue
+		} else if long != nil {
+			line = append(long, line...)
+			long = nil
+		}
+
+		start, end := 0, len(line)
+		for start < end && isWhitespace(line[start]) {
+			start++
+		}
+		for start < end && isWhitespace(line[end-1]) {
+			end--
+		}
+		if start < end && !f(offset+start, line[start:end]) {
+			return nil
+		}
+		offset += len(line)
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,72 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tdewolff/test"
+)
+
+func TestSplitNDJSON(t *testing.T) {
+	var splitTests = []struct {
+		ndjson   string
+		expected []string
+	}{
+		{"", []string{}},
+		{"\n \n", []string{}},
+		{`{"a": 1}`, []string{`0 {"a": 1}`}},
+		{"{\"a\": 1}\n{\"b\": 2}\n", []string{`0 {"a": 1}`, `9 {"b": 2}`}},
+		{"1\r\n  2 \r\n\r\n\t[3]\t", []string{"0 1", "5 2", "12 [3]"}},
+		{"\"a b\"\n\"c\\nd\"", []string{`0 "a b"`, `6 "c\nd"`}},
+	}
+	for _, tt := range splitTests {
+		t.Run(tt.ndjson, func(t *testing.T) {
+			records := []string{}
+			err := SplitNDJSON(iotest.OneByteReader(bytes.NewBufferString(tt.ndjson)), func(offset int, record []byte) bool {
+				test.String(t, tt.ndjson[offset:offset+len(record)], string(record), "byte range must match record")
+				records = append(records, fmt.Sprintf("%d %s", offset, record))
+				return true
+			})
+			test.Error(t, err)
+			test.T(t, records, tt.expected)
+		})
+	}
+
+	// long lines
+	long := `"` + strings.Repeat("x", 200000) + `"`
+	records := []string{}
+	err := SplitNDJSON(bytes.NewBufferString(long+"\n"+long+"\n1"), func(offset int, record []byte) bool {
+		records = append(records, string(record))
+		return true
+	})
+	test.Error(t, err)
+	test.T(t, records, []string{long, long, "1"})
+
+	// stop
+	n := 0
+	err = SplitNDJSON(bytes.NewBufferString("1\n2\n3\n"), func(offset int, record []byte) bool {
+		n++
+		return n < 2
+	})
+	test.Error(t, err)
+	test.T(t, n, 2)
+
+	err = SplitNDJSON(test.NewErrorReader(0), func(int, []byte) bool { return true })
+	test.T(t, err, test.ErrPlain)
+}
+
+////////////////////////////////////////////////////////////////
+
+func ExampleSplitNDJSON() {
+	ndjson := "{\"id\": 1}\n{\"id\": 2}\n"
+	SplitNDJSON(strings.NewReader(ndjson), func(offset int, record []byte) bool {
+		fmt.Println(offset, string(record))
+		return true
+	})
+	// Output:
+	// 0 {"id": 1}
+	// 10 {"id": 2}
+}
@@ -1,9 +1,10 @@
-// Package json is a JSON parser following the specifications at http://json.org/.
+// Package json is a JSON parser following the specifications at http://json.org/, with optional support for comments and JSON5 following https://spec.json5.org/.
 package json
 
 import (
 	"io"
 	"strconv"
+	"unicode"
 
 	"github.com/tdewolff/parse/v2"
 	"github.com/tdewolff/parse/v2/buffer"
@@ -23,6 +24,8 @@ const (
 	EndObjectGrammar   // }
 	StartArrayGrammar  // [
 	EndArrayGrammar    // ]
+	CommentGrammar     // only in JSONCMode and JSON5Mode
+	EndDocumentGrammar // after each top-level value, only in MultiDocumentMode
 )
 
 // String returns the string representation of a GrammarType.
@@ -46,12 +49,58 @@ func (gt GrammarType) String() string {
 		return "StartArray"
 	case EndArrayGrammar:
 		return "EndArray"
+	case CommentGrammar:
+		return "Comment"
+	case EndDocumentGrammar:
+		return "EndDocument"
 	}
 	return "Invalid(" + strconv.Itoa(int(gt)) + ")"
 }
 
 ////////////////////////////////////////////////////////////////
 
+// Mode determines the syntax accepted by the parser.
+type Mode uint32
+
+// Mode values, which can be combined.
+const (
+	JSONCMode         Mode = 1 << iota // JSON with // and /* */ comments
+	JSON5Mode                          // JSON5, see https://spec.json5.org/, which includes comments
+	MultiDocumentMode                  // a sequence of top-level values, such as NDJSON or concatenated JSON
+)
+
+// JSONMode is strict JSON (ECMA-404) with a single top-level value, which is the default.
+const JSONMode Mode = 0
+
+// String returns the string representation of a Mode, where combined modes are separated by a pipe.
+func (mode Mode) String() string {
+	if mode == JSONMode {
+		return "JSON"
+	} else if mode&^(JSONCMode|JSON5Mode|MultiDocumentMode) != 0 {
+		return "Invalid(" + strconv.Itoa(int(mode)) + ")"
+	}
+
+	s := ""
+	for _, m := range []Mode{JSONCMode, JSON5Mode, MultiDocumentMode} {
+		if mode&m == 0 {
+			continue
+		} else if s != "" {
+			s += "|"
+		}
+		switch m {
+		case JSONCMode:
+			s += "JSONC"
+		case JSON5Mode:
+			s += "JSON5"
+		case MultiDocumentMode:
+			s += "MultiDocument"
+		}
+	}
+	return s
+}
+
+////////////////////////////////////////////////////////////////
+
 // State determines the current state the parser is in.
 type State uint32
 
@@ -82,11 +131,18 @@ func (state State) String() string {
 
 // Parser is the state for the lexer.
 type Parser struct {
-	r     *buffer.Lexer
-	state []State
-	err   error
+	r      *buffer.Lexer
+	stream bool // reading from a stream, see nextStream
+	state  []State
+	saved  []State // copy of state, see nextStream
+	err    error
+	mode   Mode
+	start  int    // offset of the grammar last returned by Next
+	record bool   // append the input to raw, see Decoder.raw and query
+	raw    []byte // input since record was set, including whitespace
 
 	needComma bool
+	needColon bool // a comment follows the object key, see Next
 }
 
 // NewParser returns a new Parser for a given io.Reader.
@@ -97,6 +153,21 @@ func NewParser(r io.Reader) *Parser {
 	}
 }
 
+// NewStreamParser returns a new Parser that reads the JSON text from r in chunks, which together with MultiDocumentMode parses streams of any length, such as NDJSON logs.
+// The data of a grammar is only valid until the next call to Next.
+func NewStreamParser(r io.Reader) *Parser {
+	return &Parser{
+		r:      buffer.NewLexerStream(r),
+		stream: true,
+		state:  []State{ValueState},
+	}
+}
+
+// SetMode sets the syntax accepted by the parser, such as JSON5Mode | MultiDocumentMode. It must be called before the first call to Next.
+func (p *Parser) SetMode(mode Mode) {
+	p.mode = mode
+}
+
 // Err returns the error encountered during tokenization, this is often io.EOF but also other errors can be returned.
 func (p *Parser) Err() error {
 	if p.err != nil {
@@ -115,6 +186,16 @@ func (p *Parser) Offset() int {
 	return p.r.Offset()
 }
 
+// Start returns the offset of the grammar last returned by Next. Unlike Offset, it is the start of an object key and not the position after its colon.
+func (p *Parser) Start() int {
+	return p.start
+}
+
+// NewError returns an error at offset with its line and column. When reading from a stream, offset must not come before the grammar last returned by Next, since earlier data may no longer be buffered.
+func (p *Parser) NewError(offset int, message string, a ...interface{}) *parse.Error {
+	return parse.NewErrorLexerOffset(p.r, offset, message, a...)
+}
+
 // State returns the state the parser is currently in (ie. which token is expected).
 func (p *Parser) State() State {
 	return p.state[len(p.state)-1]
@@ -122,9 +203,26 @@ func (p *Parser) State() State {
 
 // Next returns the next Grammar. It returns ErrorGrammar when an error was encountered. Using Err() one can retrieve the error message.
 func (p *Parser) Next() (GrammarType, []byte) {
+	if p.needComma && len(p.state) == 1 && p.mode&MultiDocumentMode != 0 {
+		p.needComma = false
+		return EndDocumentGrammar, nil
+	} else if p.stream {
+		return p.nextStream()
+	}
 	p.moveWhitespace()
 	c := p.r.Peek(0)
 	state := p.state[len(p.state)-1]
+	if p.needColon {
+		if c == ':' {
+			p.r.Move(1)
+			p.moveWhitespace()
+			p.needColon = false
+			c = p.r.Peek(0)
+		} else if c != '/' {
+			p.err = parse.NewErrorLexer(p.r, "JSON parse error: expected colon character after object key")
+			return ErrorGrammar, nil
+		}
+	}
 	if c == ',' {
 		if state != ArrayState && state != ObjectKeyState {
 			p.err = parse.NewErrorLexer(p.r, "JSON parse error: unexpected comma character")
@@ -135,9 +233,22 @@ func (p *Parser) Next() (GrammarType, []byte) {
 		p.needComma = false
 		c = p.r.Peek(0)
 	}
+	if p.record {
+		p.raw = append(p.raw, p.r.Lexeme()...) // whitespace, comma, and colon
+	}
 	p.r.Skip()
+	p.start = p.r.Offset()
 
-	if p.needComma && c != '}' && c != ']' && c != 0 {
+	if c == '/' && p.mode&(JSONCMode|JSON5Mode) != 0 && (p.r.Peek(1) == '/' || p.r.Peek(1) == '*') {
+		if !p.consumeCommentToken() {
+			p.err = parse.NewErrorLexerOffset(p.r, p.r.Offset()-p.r.Pos(), "JSON parse error: unterminated comment")
+			return ErrorGrammar, nil
+		}
+		return CommentGrammar, p.r.Shift()
+	} else if p.needColon {
+		p.err = parse.NewErrorLexer(p.r, "JSON parse error: expected colon character after object key")
+		return ErrorGrammar, nil
+	} else if p.needComma && c != '}' && c != ']' && c != 0 {
 		p.err = parse.NewErrorLexer(p.r, "JSON parse error: expected comma character or an array or object ending")
 		return ErrorGrammar, nil
 	} else if c == '{' {
@@ -173,29 +284,44 @@ func (p *Parser) Next() (GrammarType, []byte) {
 		p.r.Move(1)
 		return EndArrayGrammar, p.r.Shift()
 	} else if state == ObjectKeyState {
-		if c != '"' || !p.consumeStringToken() {
+		if p.mode&JSON5Mode != 0 {
+			if (c != '"' && c != '\'' || !p.consumeStringToken()) && !p.consumeIdentifierToken() {
+				p.err = parse.NewErrorLexer(p.r, "JSON parse error: expected object key to be a string or identifier")
+				return ErrorGrammar, nil
+			}
+		} else if c != '"' || !p.consumeStringToken() {
 			p.err = parse.NewErrorLexer(p.r, "JSON parse error: expected object key to be a quoted string")
 			return ErrorGrammar, nil
 		}
 		n := p.r.Pos()
 		p.moveWhitespace()
-		if c := p.r.Peek(0); c != ':' {
+		p.state[len(p.state)-1] = ObjectValueState
+		if c := p.r.Peek(0); c == '/' && p.mode&(JSONCMode|JSON5Mode) != 0 && (p.r.Peek(1) == '/' || p.r.Peek(1) == '*') {
+			p.needColon = true // colon follows after the comments
+			return StringGrammar, p.r.Shift()[:n]
+		} else if c != ':' {
 			p.err = parse.NewErrorLexer(p.r, "JSON parse error: expected colon character after object key")
 			return ErrorGrammar, nil
 		}
 		p.r.Move(1)
-		p.state[len(p.state)-1] = ObjectValueState
 		return StringGrammar, p.r.Shift()[:n]
 	} else {
 		p.needComma = true
 		if state == ObjectValueState {
 			p.state[len(p.state)-1] = ObjectKeyState
 		}
-		if c == '"' && p.consumeStringToken() {
+		if p.mode&JSON5Mode != 0 {
+			if (c == '"' || c == '\'') && p.consumeStringToken() {
+				return StringGrammar, p.r.Shift()
+			} else if p.consumeJSON5NumberToken() {
+				return NumberGrammar, p.r.Shift()
+			}
+		} else if c == '"' && p.consumeStringToken() {
 			return StringGrammar, p.r.Shift()
 		} else if p.consumeNumberToken() {
 			return NumberGrammar, p.r.Shift()
-		} else if p.consumeLiteralToken() {
+		}
+		if p.consumeLiteralToken() {
 			return LiteralGrammar, p.r.Shift()
 		}
 		c := p.r.Peek(0) // pick up movement from consumeStringToken to detect NULL or EOF
@@ -210,6 +336,34 @@ func (p *Parser) Next() (GrammarType, []byte) {
 	return ErrorGrammar, nil
 }
 
+// nextStream calls Next when reading from a stream. When Next runs out of buffered data, the state stack and whether a comma or colon is expected are restored, and the grammar is parsed again after reading in more data.
+func (p *Parser) nextStream() (gt GrammarType, data []byte) {
+	p.r.Free(p.r.ShiftLen())
+	offset := p.r.Offset()
+	needComma, needColon := p.needComma, p.needColon
+	p.saved = append(p.saved[:0], p.state...)
+	n := len(p.raw)
+	defer func() {
+		if err := recover(); err != nil {
+			if !p.r.Retry(err, offset) {
+				panic(err)
+			}
+			p.needComma, p.needColon = needComma, needColon
+			p.raw = p.raw[:n]
+			p.state = append(p.state[:0], p.saved...)
+			gt, data = p.nextStream()
+		}
+	}()
+
+	p.stream = false
+	gt, data = p.Next()
+	p.stream = true
+	if p.record {
+		p.raw = append(p.raw, data[:cap(data)]...) // the data of an object key excludes the colon of its lexeme
+	}
+	return
+}
+
 ////////////////////////////////////////////////////////////////
 
 /*
@@ -219,12 +373,50 @@ The following functions follow the specifications at http://json.org/
 func (p *Parser) moveWhitespace() {
 	for {
 		if c := p.r.Peek(0); c != ' ' && c != '\n' && c != '\r' && c != '\t' {
+			if p.mode&JSON5Mode == 0 {
+				break
+			} else if c == '\v' || c == '\f' {
+				p.r.Move(1)
+				continue
+			} else if c < 0xC0 {
+				break
+			} else if r, n := p.r.PeekRune(0); r == '\u00A0' || r == '\uFEFF' || r == '\u2028' || r == '\u2029' || unicode.Is(unicode.Zs, r) {
+				p.r.Move(n)
+				continue
+			}
 			break
 		}
 		p.r.Move(1)
 	}
 }
 
+func (p *Parser) consumeCommentToken() bool {
+	// assume to be on / followed by / or *
+	if p.r.Peek(1) == '/' {
+		p.r.Move(2)
+		for {
+			c := p.r.Peek(0)
+			if c == '\n' || c == '\r' || c == 0 && p.r.Err() != nil {
+				return true
+			} else if c == 0xE2 && p.r.Peek(1) == 0x80 && (p.r.Peek(2) == 0xA8 || p.r.Peek(2) == 0xA9) {
+				return true // U+2028 or U+2029
+			}
+			p.r.Move(1)
+		}
+	}
+	p.r.Move(2)
+	for {
+		c := p.r.Peek(0)
+		if c == '*' && p.r.Peek(1) == '/' {
+			p.r.Move(2)
+			return true
+		} else if c == 0 && p.r.Err() != nil {
+			return false
+		}
+		p.r.Move(1)
+	}
+}
+
 func (p *Parser) consumeLiteralToken() bool {
 	c := p.r.Peek(0)
 	if c == 't' && p.r.Peek(1) == 'r' && p.r.Peek(2) == 'u' && p.r.Peek(3) == 'e' {
@@ -294,11 +486,12 @@ func (p *Parser) consumeNumberToken() bool {
 }
 
 func (p *Parser) consumeStringToken() bool {
-	// assume to be on "
+	// assume to be on " or '
+	quote := p.r.Peek(0)
 	p.r.Move(1)
 	for {
 		c := p.r.Peek(0)
-		if c == '"' {
+		if c == quote {
 			escaped := false
 			for i := p.r.Pos() - 1; i >= 0; i-- {
 				if p.r.Lexeme()[i] == '\\' {
@@ -318,3 +511,111 @@ func (p *Parser) consumeStringToken() bool {
 	}
 	return true
 }
+
+////////////////////////////////////////////////////////////////
+
+/*
+The following functions follow the specifications at https://spec.json5.org/
+*/
+
+var identifierStart = []*unicode.RangeTable{unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl}
+var identifierContinue = []*unicode.RangeTable{unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc}
+
+func (p *Parser) consumeIdentifierToken() bool {
+	mark := p.r.Pos()
+	for first := true; ; first = false {
+		c := p.r.Peek(0)
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '$' || c == '_' || !first && '0' <= c && c <= '9' {
+			p.r.Move(1)
+		} else if c == '\\' && p.r.Peek(1) == 'u' && isHex(p.r.Peek(2)) && isHex(p.r.Peek(3)) && isHex(p.r.Peek(4)) && isHex(p.r.Peek(5)) {
+			p.r.Move(6)
+		} else if c >= 0xC0 {
+			r, n := p.r.PeekRune(0)
+			if !unicode.IsOneOf(identifierStart, r) && (first || r != '\u200C' && r != '\u200D' && !unicode.IsOneOf(identifierContinue, r)) {
+				break
+			}
+			p.r.Move(n)
+		} else {
+			break
+		}
+	}
+	return mark < p.r.Pos()
+}
+
+func (p *Parser) consumeJSON5NumberToken() bool {
+	mark := p.r.Pos()
+	if c := p.r.Peek(0); c == '-' || c == '+' {
+		p.r.Move(1)
+	}
+	if p.consumeWord("Infinity") || p.consumeWord("NaN") {
+		return true
+	}
+
+	c := p.r.Peek(0)
+	if c == '0' && (p.r.Peek(1) == 'x' || p.r.Peek(1) == 'X') && isHex(p.r.Peek(2)) {
+		p.r.Move(3)
+		for isHex(p.r.Peek(0)) {
+			p.r.Move(1)
+		}
+		return true
+	} else if c == '.' {
+		if c := p.r.Peek(1); c < '0' || c > '9' {
+			p.r.Rewind(mark)
+			return false
+		}
+	} else if c >= '1' && c <= '9' {
+		p.r.Move(1)
+		for {
+			if c := p.r.Peek(0); c < '0' || c > '9' {
+				break
+			}
+			p.r.Move(1)
+		}
+	} else if c == '0' {
+		p.r.Move(1)
+	} else {
+		p.r.Rewind(mark)
+		return false
+	}
+	if c := p.r.Peek(0); c == '.' {
+		p.r.Move(1) // trailing decimal point is allowed
+		for {
+			if c := p.r.Peek(0); c < '0' || c > '9' {
+				break
+			}
+			p.r.Move(1)
+		}
+	}
+	mark = p.r.Pos()
+	if c := p.r.Peek(0); c == 'e' || c == 'E' {
+		p.r.Move(1)
+		if c := p.r.Peek(0); c == '+' || c == '-' {
+			p.r.Move(1)
+		}
+		if c := p.r.Peek(0); c < '0' || c > '9' {
+			p.r.Rewind(mark)
+			return true
+		}
+		for {
+			if c := p.r.Peek(0); c < '0' || c > '9' {
+				break
+			}
+			p.r.Move(1)
+		}
+	}
+	return true
+}
+
+func (p *Parser) consumeWord(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if p.r.Peek(i) != word[i] {
+			return false
+		}
+	}
+	p.r.Move(len(word))
+	return true
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
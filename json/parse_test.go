@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/internal/testio"
 	"github.com/tdewolff/test"
 )
 
@@ -131,6 +133,155 @@ func TestGrammarsError(t *testing.T) {
 	}
 }
 
+func TestModes(t *testing.T) {
+	var modeTests = []struct {
+		mode     Mode
+		json     string
+		expected []string
+	}{
+		{JSONCMode, "// comment\n1", []string{"Comment // comment", "Number 1"}},
+		{JSONCMode, "1 /* a\nb */", []string{"Number 1", "Comment /* a\nb */"}},
+		{JSONCMode, "[1, // one\n2 /**/, /**/]", []string{"StartArray [", "Number 1", "Comment // one", "Number 2", "Comment /**/", "Comment /**/", "EndArray ]"}},
+		{JSONCMode, `{/*a*/"a"/*b*/:/*c*/1/*d*/,"b" : 2}`, []string{"StartObject {", "Comment /*a*/", `String "a"`, "Comment /*b*/", "Comment /*c*/", "Number 1", "Comment /*d*/", `String "b"`, "Number 2", "EndObject }"}},
+		{JSONCMode, `{"a" /*b*/ /*c*/ : 1}`, []string{"StartObject {", `String "a"`, "Comment /*b*/", "Comment /*c*/", "Number 1", "EndObject }"}},
+		{JSONCMode, `"a//b"`, []string{`String "a//b"`}},
+		{JSON5Mode, "// comment\u2028 1", []string{"Comment // comment", "Number 1"}},
+		{JSON5Mode, "{a: 1, $b_2: 2, 'c': 3, \"d\": 4, \\u0065: 5, \u00e9\u0301: 6,}", []string{"StartObject {", "String a", "Number 1", "String $b_2", "Number 2", "String 'c'", "Number 3", `String "d"`, "Number 4", `String \u0065`, "Number 5", "String \u00e9\u0301", "Number 6", "EndObject }"}},
+		{JSON5Mode, `['a', 'b\'"', "c'"]`, []string{"StartArray [", "String 'a'", `String 'b\'"'`, `String "c'"`, "EndArray ]"}},
+		{JSON5Mode, "'a\\\nb'", []string{"String 'a\\\nb'"}},
+		{JSON5Mode, "[0x1F, 0XaB, +1, -.5, 5., 5.e3, 1e-2, Infinity, -Infinity, +NaN, 0]", []string{"StartArray [", "Number 0x1F", "Number 0XaB", "Number +1", "Number -.5", "Number 5.", "Number 5.e3", "Number 1e-2", "Number Infinity", "Number -Infinity", "Number +NaN", "Number 0", "EndArray ]"}},
+		{JSON5Mode, "\v\f\u00a0\ufeff\u2003[\u2029true\u3000]", []string{"StartArray [", "Literal true", "EndArray ]"}},
+	}
+	for _, tt := range modeTests {
+		t.Run(tt.json, func(t *testing.T) {
+			p := NewParser(bytes.NewBufferString(tt.json))
+			p.SetMode(tt.mode)
+			grammars := []string{}
+			for {
+				gt, data := p.Next()
+				if gt == ErrorGrammar {
+					test.T(t, p.Err(), io.EOF)
+					break
+				}
+				grammars = append(grammars, gt.String()+" "+string(data))
+			}
+			test.T(t, grammars, tt.expected)
+		})
+	}
+
+	test.String(t, JSONMode.String(), "JSON")
+	test.String(t, (JSON5Mode | MultiDocumentMode).String(), "JSON5|MultiDocument")
+	test.String(t, (JSONCMode | JSON5Mode | MultiDocumentMode).String(), "JSONC|JSON5|MultiDocument")
+
+	// coverage
+	for i := 0; ; i++ {
+		if Mode(i).String() == fmt.Sprintf("Invalid(%d)", i) {
+			break
+		}
+	}
+}
+
+func TestModesError(t *testing.T) {
+	var modeErrorTests = []struct {
+		mode Mode
+		json string
+		err  string
+		col  int
+	}{
+		{JSONMode, "// comment\n1", "unexpected character '/'", 1},
+		{JSONMode, "{a: 1}", "expected object key to be a quoted string", 2},
+		{JSONMode, "'a'", "unexpected character '''", 1},
+		{JSONMode, "Infinity", "unexpected character 'I'", 1},
+		{JSONCMode, "/* comment", "unterminated comment", 1},
+		{JSONCMode, "/ 1", "unexpected character '/'", 1},
+		{JSONCMode, "1 /**/ 2", "expected comma character or an array or object ending", 8},
+		{JSONCMode, `{"a" /**/ 1}`, "expected colon character after object key", 11},
+		{JSONCMode, `{"a" /**/ /}`, "expected colon character after object key", 11},
+		{JSONCMode, "{a: 1}", "expected object key to be a quoted string", 2},
+		{JSONCMode, "0x1", "expected comma character or an array or object ending", 2},
+		{JSON5Mode, "{1: 1}", "expected object key to be a string or identifier", 2},
+		{JSON5Mode, "{a-b: 1}", "expected colon character after object key", 3},
+		{JSON5Mode, "{a: 1, \u0301: 2}", "expected object key to be a string or identifier", 8},
+		{JSON5Mode, "[.]", "unexpected character '.'", 2},
+		{JSON5Mode, "[0x]", "expected comma character or an array or object ending", 3},
+		{JSON5Mode, "[01]", "expected comma character or an array or object ending", 3},
+		{JSON5Mode, "[Inf]", "unexpected character 'I'", 2},
+	}
+	for _, tt := range modeErrorTests {
+		t.Run(tt.json, func(t *testing.T) {
+			p := NewParser(bytes.NewBufferString(tt.json))
+			p.SetMode(tt.mode)
+			for {
+				grammar, _ := p.Next()
+				if grammar == ErrorGrammar {
+					if perr, ok := p.Err().(*parse.Error); ok {
+						test.String(t, perr.Message, "JSON parse error: "+tt.err)
+						_, col, _ := perr.Position()
+						test.T(t, col, tt.col)
+					} else {
+						test.Fail(t, "not a parse error:", p.Err())
+					}
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMultiDocument(t *testing.T) {
+	var documentTests = []struct {
+		mode     Mode
+		json     string
+		expected []string
+	}{
+		{MultiDocumentMode, "", []string{}},
+		{MultiDocumentMode, " 1 ", []string{"Number 1", "EndDocument "}},
+		{MultiDocumentMode, "{\"a\": 1}\n{\"b\": [2]}\n", []string{"StartObject {", `String "a"`, "Number 1", "EndObject }", "EndDocument ", "StartObject {", `String "b"`, "StartArray [", "Number 2", "EndArray ]", "EndObject }", "EndDocument "}},
+		{MultiDocumentMode, `1 "a"[]{}null`, []string{"Number 1", "EndDocument ", `String "a"`, "EndDocument ", "StartArray [", "EndArray ]", "EndDocument ", "StartObject {", "EndObject }", "EndDocument ", "Literal null", "EndDocument "}},
+		{MultiDocumentMode | JSONCMode, "1 // one\n/* two */ 2", []string{"Number 1", "EndDocument ", "Comment // one", "Comment /* two */", "Number 2", "EndDocument "}},
+		{MultiDocumentMode | JSON5Mode, "{a: 1}\n'b'", []string{"StartObject {", "String a", "Number 1", "EndObject }", "EndDocument ", "String 'b'", "EndDocument "}},
+	}
+	for _, tt := range documentTests {
+		t.Run(tt.json, func(t *testing.T) {
+			p := NewParser(bytes.NewBufferString(tt.json))
+			p.SetMode(tt.mode)
+			grammars := []string{}
+			for {
+				gt, data := p.Next()
+				if gt == ErrorGrammar {
+					test.T(t, p.Err(), io.EOF)
+					break
+				}
+				grammars = append(grammars, gt.String()+" "+string(data))
+			}
+			test.T(t, grammars, tt.expected)
+		})
+	}
+
+	var errorTests = []struct {
+		json string
+		col  int
+	}{
+		{"1, 2", 2},
+		{"[1] ]", 5},
+		{"{\"a\": 1} {\"b\"}", 14},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.json, func(t *testing.T) {
+			p := NewParser(bytes.NewBufferString(tt.json))
+			p.SetMode(MultiDocumentMode)
+			for gt, _ := p.Next(); gt != ErrorGrammar; gt, _ = p.Next() {
+			}
+			if perr, ok := p.Err().(*parse.Error); ok {
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "not a parse error:", p.Err())
+			}
+		})
+	}
+}
+
 func TestStates(t *testing.T) {
 	var stateTests = []struct {
 		json     string
@@ -187,6 +338,85 @@ func TestOffset(t *testing.T) {
 	test.T(t, p.Offset(), 34) // }
 }
 
+func TestStreamParser(t *testing.T) {
+	json := `{"a": [1, -2.5e3, "s\"t", true, null], "b": {"c": {}}, "d": []}`
+	p := NewParser(bytes.NewBufferString(json))
+	z := NewStreamParser(iotest.OneByteReader(bytes.NewBufferString(json)))
+	for {
+		gt, data := p.Next()
+		gtStream, dataStream := z.Next()
+		test.T(t, gtStream, gt, "grammar types must match")
+		test.String(t, string(dataStream), string(data), "grammar data must match")
+		test.T(t, z.Offset(), p.Offset(), "offsets must match")
+		if gt == ErrorGrammar {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+
+	json5 := "// config\n{a: [0x1F, +.5, 'b\\'c'], /* x */ 'd' /* y */: Infinity, e: null,}"
+	p = NewParser(bytes.NewBufferString(json5))
+	p.SetMode(JSON5Mode)
+	z = NewStreamParser(iotest.OneByteReader(bytes.NewBufferString(json5)))
+	z.SetMode(JSON5Mode)
+	for {
+		gt, data := p.Next()
+		gtStream, dataStream := z.Next()
+		test.T(t, gtStream, gt, "grammar types must match")
+		test.String(t, string(dataStream), string(data), "grammar data must match")
+		test.T(t, z.Offset(), p.Offset(), "offsets must match")
+		if gt == ErrorGrammar {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+
+	z = NewStreamParser(iotest.OneByteReader(bytes.NewBufferString("{\n  \"a\": tru }")))
+	for gt, _ := z.Next(); gt != ErrorGrammar; gt, _ = z.Next() {
+	}
+	if perr, ok := z.Err().(*parse.Error); ok {
+		line, col, _ := perr.Position()
+		test.T(t, line, 2)
+		test.T(t, col, 8)
+	} else {
+		test.Fail(t, "bad error:", z.Err())
+	}
+}
+
+func TestStreamParserMemory(t *testing.T) {
+	item := []byte(`{"id": 1, "name": "item", "tags": ["a", "b"], "ok": true},`)
+	r := io.MultiReader(bytes.NewBufferString("["), testio.NewRepeatReader(item, 500000), bytes.NewBufferString("null]"))
+
+	p := NewStreamParser(r)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for gt, _ := p.Next(); gt != ErrorGrammar; gt, _ = p.Next() {
+			n++
+		}
+	})
+	test.T(t, p.Err(), io.EOF)
+	test.T(t, n, 13*500000+3)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*500000, "bytes of input")
+}
+
+func TestStreamParserMultiDocumentMemory(t *testing.T) {
+	record := []byte(`{"time": "2021-01-01T00:00:00Z", "level": "info", "msg": "request", "status": 200}` + "\n")
+
+	p := NewStreamParser(testio.NewRepeatReader(record, 500000))
+	p.SetMode(MultiDocumentMode)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for gt, _ := p.Next(); gt != ErrorGrammar; gt, _ = p.Next() {
+			if gt == EndDocumentGrammar {
+				n++
+			}
+		}
+	})
+	test.T(t, p.Err(), io.EOF)
+	test.T(t, n, 500000)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(record)*500000, "bytes of input")
+}
+
 ////////////////////////////////////////////////////////////////
 
 func ExampleNewParser() {
@@ -207,3 +437,27 @@ func ExampleNewParser() {
 	fmt.Println(out)
 	// Output: {"key":5}
 }
+
+func ExampleParser_SetMode() {
+	p := NewParser(bytes.NewBufferString(`{
+		// comments, unquoted keys, single quotes, and hexadecimal numbers
+		name: 'parse',
+		flags: 0xFF,
+	}`))
+	p.SetMode(JSON5Mode)
+	for {
+		gt, data := p.Next()
+		if gt == ErrorGrammar {
+			break
+		}
+		fmt.Println(gt, string(data))
+	}
+	// Output:
+	// StartObject {
+	// Comment // comments, unquoted keys, single quotes, and hexadecimal numbers
+	// String name
+	// String 'parse'
+	// String flags
+	// Number 0xFF
+	// EndObject }
+}
@@ -0,0 +1,510 @@
+package json
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// Pointer is a JSON Pointer following the specification at https://tools.ietf.org/html/rfc6901, as a list of unescaped reference tokens.
+type Pointer []string
+
+// ParsePointer parses a JSON Pointer, either the empty string for the whole document or a list of reference tokens each prefixed by a slash.
+func ParsePointer(s string) (Pointer, error) {
+	if s == "" {
+		return Pointer{}, nil
+	} else if s[0] != '/' {
+		return nil, parse.NewError(bytes.NewBufferString(s), 0, "JSON Pointer parse error: expected '/'")
+	}
+	ptr := Pointer{}
+	token := []byte{}
+	for i := 1; i <= len(s); i++ {
+		if i == len(s) || s[i] == '/' {
+			ptr = append(ptr, string(token))
+			token = token[:0]
+		} else if s[i] != '~' {
+			token = append(token, s[i])
+		} else if i+1 < len(s) && (s[i+1] == '0' || s[i+1] == '1') {
+			token = append(token, "~/"[s[i+1]-'0'])
+			i++
+		} else {
+			return nil, parse.NewError(bytes.NewBufferString(s), i, "JSON Pointer parse error: bad escape sequence")
+		}
+	}
+	return ptr, nil
+}
+
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// String returns the JSON Pointer with its reference tokens escaped.
+func (ptr Pointer) String() string {
+	sb := strings.Builder{}
+	for _, token := range ptr {
+		sb.WriteByte('/')
+		sb.WriteString(escapePointerToken(token))
+	}
+	return sb.String()
+}
+
+// Find streams through the JSON input and returns a copy of the raw bytes of the value the pointer refers to, or nil if it does not exist. Values not on the pointer's path are skipped without being decoded.
+func (ptr Pointer) Find(r io.Reader) ([]byte, error) {
+	var raw []byte
+	err := query(r, ptr, func(_ Pointer, b []byte) bool {
+		raw = b
+		return false
+	})
+	return raw, err
+}
+
+func (ptr Pointer) start() []int {
+	return []int{0}
+}
+
+func (ptr Pointer) next(state int, key []byte, index int) []int {
+	if state < len(ptr) {
+		if key != nil && string(key) == ptr[state] || key == nil && ptr[state] == strconv.Itoa(index) {
+			return []int{state + 1}
+		}
+	}
+	return nil
+}
+
+func (ptr Pointer) accept(state int) bool {
+	return state == len(ptr)
+}
+
+////////////////////////////////////////////////////////////////
+
+type pathSegment struct {
+	descendant bool // segment is preceded by ..
+	wildcard   bool
+	names      []string
+	indices    []int
+	slices     [][3]int // start, end, step, where an end of -1 is unbounded
+}
+
+func (seg pathSegment) matches(key []byte, index int) bool {
+	if seg.wildcard {
+		return true
+	} else if key != nil {
+		for _, name := range seg.names {
+			if string(key) == name {
+				return true
+			}
+		}
+		return false
+	}
+	for _, i := range seg.indices {
+		if i == index {
+			return true
+		}
+	}
+	for _, slice := range seg.slices {
+		if slice[0] <= index && (slice[1] == -1 || index < slice[1]) && (index-slice[0])%slice[2] == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Path is a compiled JSONPath expression, supporting the root $, child selectors .name, ['name'], [index], [start:end:step] and wildcards, unions of selectors separated by commas, and the descendant operator .. followed by any of the former. Filter and script expressions, and negative indices, are not supported since they need values or array lengths not known while streaming.
+type Path struct {
+	source   string
+	segments []pathSegment
+}
+
+// ParsePath parses a JSONPath expression.
+func ParsePath(s string) (*Path, error) {
+	z := &pathParser{s: s}
+	if !z.consume('$') {
+		return nil, z.fail("expected '$'")
+	}
+	path := &Path{source: s}
+	for z.pos < len(s) {
+		seg := pathSegment{}
+		if z.consume('.') {
+			if z.consume('.') {
+				seg.descendant = true
+				if z.peek() == '[' {
+					z.pos++
+					if err := z.parseBracket(&seg); err != nil {
+						return nil, err
+					}
+					path.segments = append(path.segments, seg)
+					continue
+				}
+			}
+			if z.consume('*') {
+				seg.wildcard = true
+			} else if name := z.parseName(); name != "" {
+				seg.names = []string{name}
+			} else {
+				return nil, z.fail("expected name or '*'")
+			}
+		} else if z.consume('[') {
+			if err := z.parseBracket(&seg); err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, z.fail("unexpected '%c'", s[z.pos])
+		}
+		path.segments = append(path.segments, seg)
+	}
+	return path, nil
+}
+
+// String returns the source of the JSONPath expression.
+func (path *Path) String() string {
+	return path.source
+}
+
+// Find streams through the JSON input and calls f with the location and raw bytes of each value matched by the path, in document order. Returning false from f stops the search. Subtrees that cannot contain a match are skipped without being decoded.
+func (path *Path) Find(r io.Reader, f func(Pointer, []byte) bool) error {
+	return query(r, path, f)
+}
+
+// FindAll returns the raw bytes of all values matched by the path in document order.
+func (path *Path) FindAll(r io.Reader) ([][]byte, error) {
+	raws := [][]byte{}
+	err := query(r, path, func(_ Pointer, b []byte) bool {
+		raws = append(raws, b)
+		return true
+	})
+	return raws, err
+}
+
+func (path *Path) start() []int {
+	return []int{0}
+}
+
+func (path *Path) next(state int, key []byte, index int) []int {
+	if len(path.segments) <= state {
+		return nil
+	}
+	var states []int
+	seg := path.segments[state]
+	if seg.descendant {
+		states = append(states, state)
+	}
+	if seg.matches(key, index) {
+		states = append(states, state+1)
+	}
+	return states
+}
+
+func (path *Path) accept(state int) bool {
+	return state == len(path.segments)
+}
+
+type pathParser struct {
+	s   string
+	pos int
+}
+
+func (z *pathParser) fail(message string, a ...interface{}) error {
+	return parse.NewError(bytes.NewBufferString(z.s), z.pos, "JSONPath parse error: "+message, a...)
+}
+
+func (z *pathParser) peek() byte {
+	if z.pos < len(z.s) {
+		return z.s[z.pos]
+	}
+	return 0
+}
+
+func (z *pathParser) consume(c byte) bool {
+	if z.peek() == c {
+		z.pos++
+		return true
+	}
+	return false
+}
+
+func (z *pathParser) skipWhitespace() {
+	for c := z.peek(); c == ' ' || c == '\t' || c == '\n' || c == '\r'; c = z.peek() {
+		z.pos++
+	}
+}
+
+func (z *pathParser) parseName() string {
+	start := z.pos
+	for c := z.peek(); c != 0 && c != '.' && c != '[' && c != ' ' && c != ']' && c != '*'; c = z.peek() {
+		z.pos++
+	}
+	return z.s[start:z.pos]
+}
+
+// parseInt parses an optionally signed integer, it returns false if there is none.
+func (z *pathParser) parseInt() (int, bool) {
+	start := z.pos
+	z.consume('-')
+	for c := z.peek(); '0' <= c && c <= '9'; c = z.peek() {
+		z.pos++
+	}
+	i, err := strconv.Atoi(z.s[start:z.pos])
+	if err != nil {
+		z.pos = start
+		return 0, false
+	}
+	return i, true
+}
+
+// parseBracket parses the selectors between brackets, the opening bracket has been consumed.
+func (z *pathParser) parseBracket(seg *pathSegment) error {
+	for {
+		z.skipWhitespace()
+		if c := z.peek(); c == '\'' || c == '"' {
+			name, err := z.parseString(c)
+			if err != nil {
+				return err
+			}
+			seg.names = append(seg.names, name)
+		} else if z.consume('*') {
+			seg.wildcard = true
+		} else {
+			start := z.pos
+			i, ok := z.parseInt()
+			z.skipWhitespace()
+			if z.peek() == ':' {
+				slice := [3]int{0, -1, 1}
+				if ok {
+					slice[0] = i
+				}
+				z.pos++
+				z.skipWhitespace()
+				if end, ok := z.parseInt(); ok {
+					slice[1] = end
+					if end < 0 {
+						z.pos = start
+						return z.fail("negative indices are not supported")
+					}
+				}
+				z.skipWhitespace()
+				if z.consume(':') {
+					z.skipWhitespace()
+					if step, ok := z.parseInt(); ok {
+						if step <= 0 {
+							z.pos = start
+							return z.fail("slice step must be positive")
+						}
+						slice[2] = step
+					}
+				}
+				if slice[0] < 0 {
+					z.pos = start
+					return z.fail("negative indices are not supported")
+				}
+				if slice[1] != -1 && slice[1] <= slice[0] {
+					slice[1] = slice[0] // empty slice
+				}
+				seg.slices = append(seg.slices, slice)
+			} else if ok {
+				if i < 0 {
+					z.pos = start
+					return z.fail("negative indices are not supported")
+				}
+				seg.indices = append(seg.indices, i)
+			} else if z.peek() == '?' || z.peek() == '(' {
+				return z.fail("filter and script expressions are not supported")
+			} else if z.pos < len(z.s) {
+				return z.fail("unexpected '%c'", z.s[z.pos])
+			} else {
+				return z.fail("unexpected end of path")
+			}
+		}
+		z.skipWhitespace()
+		if z.consume(']') {
+			return nil
+		} else if !z.consume(',') {
+			if z.pos < len(z.s) {
+				return z.fail("expected ',' or ']' instead of '%c'", z.s[z.pos])
+			}
+			return z.fail("unexpected end of path")
+		}
+	}
+}
+
+func (z *pathParser) parseString(quote byte) (string, error) {
+	start := z.pos
+	z.pos++
+	sb := strings.Builder{}
+	for {
+		c := z.peek()
+		if c == 0 && len(z.s) <= z.pos {
+			z.pos = start
+			return "", z.fail("unterminated string")
+		}
+		z.pos++
+		if c == quote {
+			return sb.String(), nil
+		} else if c == '\\' && z.pos < len(z.s) {
+			c = z.s[z.pos]
+			z.pos++
+		}
+		sb.WriteByte(c)
+	}
+}
+
+////////////////////////////////////////////////////////////////
+
+// matcher is a non-deterministic automaton over the path of a value, implemented by Pointer and *Path.
+type matcher interface {
+	start() []int
+	next(state int, key []byte, index int) []int // key is nil for array elements
+	accept(state int) bool
+}
+
+type match struct {
+	ptr        Pointer
+	start, end int // range in the recorded input of the outermost match
+}
+
+type querier struct {
+	p       *Parser
+	m       matcher
+	f       func(Pointer, []byte) bool
+	path    Pointer
+	pending []match // matched values within the outermost matched value, in document order
+	stop    bool
+}
+
+// query streams through the JSON input and calls f for every value accepted by the matcher. The path is tracked alongside the parser's state stack, and values from which no state is reachable are skipped.
+func query(r io.Reader, m matcher, f func(Pointer, []byte) bool) error {
+	q := &querier{
+		p: NewStreamParser(r),
+		m: m,
+		f: f,
+	}
+	defer q.p.Restore()
+	gt, data := q.p.Next()
+	if gt == ErrorGrammar {
+		if q.p.Err() == io.EOF {
+			return nil
+		}
+		return q.p.Err()
+	}
+	return q.value(m.start(), gt, data)
+}
+
+func (q *querier) skip(gt GrammarType) error {
+	if gt != StartObjectGrammar && gt != StartArrayGrammar {
+		return nil
+	}
+	for level := 1; 0 < level; {
+		switch gt, _ = q.p.Next(); gt {
+		case ErrorGrammar:
+			return q.err()
+		case StartObjectGrammar, StartArrayGrammar:
+			level++
+		case EndObjectGrammar, EndArrayGrammar:
+			level--
+		}
+	}
+	return nil
+}
+
+func (q *querier) err() error {
+	if q.p.Err() == io.EOF {
+		return q.p.NewError(q.p.Offset(), "JSON parse error: unexpected end of input")
+	}
+	return q.p.Err()
+}
+
+func (q *querier) value(states []int, gt GrammarType, data []byte) error {
+	if gt == ErrorGrammar {
+		return q.err()
+	}
+	accepted := -1
+	for _, state := range states {
+		if q.m.accept(state) {
+			if len(q.pending) == 0 {
+				// record the input of the outermost match, since the stream parser frees it while parsing
+				q.p.raw = append(q.p.raw[:0], data...)
+				q.p.record = true
+			}
+			accepted = len(q.pending)
+			q.pending = append(q.pending, match{append(Pointer{}, q.path...), len(q.p.raw) - len(data), -1})
+			break
+		}
+	}
+
+	if gt == StartObjectGrammar || gt == StartArrayGrammar {
+		for index := 0; !q.stop; index++ {
+			gtChild, dataChild := q.p.Next()
+			if gtChild == EndObjectGrammar || gtChild == EndArrayGrammar {
+				break
+			} else if gtChild == ErrorGrammar {
+				return q.err()
+			}
+
+			// the key is only valid until the next call to Next
+			key := []byte(nil)
+			if gt == StartObjectGrammar {
+				key = unescapeLenient(dataChild)
+			}
+			var next []int
+			for _, state := range states {
+				for _, n := range q.m.next(state, key, index) {
+					if !containsInt(next, n) {
+						next = append(next, n)
+					}
+				}
+			}
+			if len(next) != 0 {
+				if key != nil {
+					q.path = append(q.path, string(key))
+				} else {
+					q.path = append(q.path, strconv.Itoa(index))
+				}
+			}
+			if gt == StartObjectGrammar {
+				gtChild, dataChild = q.p.Next()
+			}
+			if len(next) == 0 {
+				if gtChild == ErrorGrammar {
+					return q.err()
+				} else if err := q.skip(gtChild); err != nil {
+					return err
+				}
+				continue
+			}
+			err := q.value(next, gtChild, dataChild)
+			q.path = q.path[:len(q.path)-1]
+			if err != nil {
+				return err
+			}
+		}
+	} else if gt == EndObjectGrammar || gt == EndArrayGrammar {
+		return q.p.NewError(q.p.Start(), "JSON parse error: unexpected %s", gt)
+	}
+
+	if accepted != -1 {
+		q.pending[accepted].end = len(q.p.raw)
+		if accepted == 0 {
+			// the outermost match has ended, report it and the matches it contains
+			q.p.record = false
+			buf := parse.Copy(q.p.raw)
+			for _, m := range q.pending {
+				if !q.stop && !q.f(m.ptr, buf[m.start:m.end]) {
+					q.stop = true
+				}
+			}
+			q.pending = q.pending[:0]
+		}
+	}
+	return nil
+}
+
+func containsInt(list []int, i int) bool {
+	for _, j := range list {
+		if i == j {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,216 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/internal/testio"
+	"github.com/tdewolff/test"
+)
+
+const store = `{
+	"store": {
+		"book": [
+			{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95},
+			{"category": "fiction", "author": "Evelyn Waugh", "title": "Sword of Honour", "price": 12.99},
+			{"category": "fiction", "author": "Herman Melville", "title": "Moby Dick", "isbn": "0-553-21311-3", "price": 8.99}
+		],
+		"bicycle": {"color": "red", "price": 19.95}
+	},
+	"a/b": {"m~n": [true, null]},
+	"": "empty"
+}`
+
+func TestPointer(t *testing.T) {
+	var pointerTests = []struct {
+		ptr      string
+		expected string
+	}{
+		{"/store/bicycle/color", `"red"`},
+		{"/store/book/1/author", `"Evelyn Waugh"`},
+		{"/store/book/0", `{"category": "reference", "author": "Nigel Rees", "title": "Sayings of the Century", "price": 8.95}`},
+		{"/store/bicycle", `{"color": "red", "price": 19.95}`},
+		{"/a~1b/m~0n/1", `null`},
+		{"/", `"empty"`},
+		{"/store/book/3", ``},
+		{"/store/book/01", ``},
+		{"/store/book/-", ``},
+		{"/store/x", ``},
+		{"/store/bicycle/color/x", ``},
+	}
+	for _, tt := range pointerTests {
+		t.Run(tt.ptr, func(t *testing.T) {
+			ptr, err := ParsePointer(tt.ptr)
+			test.Error(t, err)
+			test.String(t, ptr.String(), tt.ptr)
+			raw, err := ptr.Find(bytes.NewBufferString(store))
+			test.Error(t, err)
+			test.String(t, string(raw), tt.expected)
+		})
+	}
+
+	ptr, err := ParsePointer("")
+	test.Error(t, err)
+	raw, err := ptr.Find(bytes.NewBufferString(" [1] "))
+	test.Error(t, err)
+	test.String(t, string(raw), "[1]")
+
+	_, err = ParsePointer("a")
+	test.That(t, err != nil)
+	_, err = ParsePointer("/a~2")
+	test.String(t, err.(*parse.Error).Message, "JSON Pointer parse error: bad escape sequence")
+	_, col, _ := err.(*parse.Error).Position()
+	test.T(t, col, 3)
+
+	_, err = Pointer{"a", "b"}.Find(bytes.NewBufferString(`{"a": {"b": `))
+	test.That(t, err != nil)
+}
+
+func TestPath(t *testing.T) {
+	var pathTests = []struct {
+		path     string
+		expected string
+	}{
+		{"$", ""},
+		{"$.store.bicycle.color", `/store/bicycle/color "red"`},
+		{"$['store']['bicycle'][\"color\"]", `/store/bicycle/color "red"`},
+		{"$.store.book[*].author", `/store/book/0/author "Nigel Rees", /store/book/1/author "Evelyn Waugh", /store/book/2/author "Herman Melville"`},
+		{"$..author", `/store/book/0/author "Nigel Rees", /store/book/1/author "Evelyn Waugh", /store/book/2/author "Herman Melville"`},
+		{"$.store.*", "/store/book, /store/bicycle"},
+		{"$.store..price", `/store/book/0/price 8.95, /store/book/1/price 12.99, /store/book/2/price 8.99, /store/bicycle/price 19.95`},
+		{"$..book[2].title", `/store/book/2/title "Moby Dick"`},
+		{"$..book[0,2].price", `/store/book/0/price 8.95, /store/book/2/price 8.99`},
+		{"$..book[:2].price", `/store/book/0/price 8.95, /store/book/1/price 12.99`},
+		{"$..book[1:].price", `/store/book/1/price 12.99, /store/book/2/price 8.99`},
+		{"$..book[::2].price", `/store/book/0/price 8.95, /store/book/2/price 8.99`},
+		{"$..book[2:1].price", ``},
+		{"$..isbn", `/store/book/2/isbn "0-553-21311-3"`},
+		{"$..['color', 'price']", `/store/book/0/price 8.95, /store/book/1/price 12.99, /store/book/2/price 8.99, /store/bicycle/color "red", /store/bicycle/price 19.95`},
+		{"$['a/b']['m~n'][0]", `/a~1b/m~0n/0 true`},
+		{"$.store.book.author", ``},
+		{"$.store.bicycle[0]", ``},
+	}
+	for _, tt := range pathTests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := ParsePath(tt.path)
+			test.Error(t, err)
+			test.String(t, path.String(), tt.path)
+			results := []string{}
+			err = path.Find(bytes.NewBufferString(store), func(ptr Pointer, raw []byte) bool {
+				if tt.path == "$" || ptr[len(ptr)-1] == "book" || ptr[len(ptr)-1] == "bicycle" {
+					raw = nil // too long
+				}
+				results = append(results, strings.TrimSpace(ptr.String()+" "+string(raw)))
+				return true
+			})
+			test.Error(t, err)
+			if tt.path == "$" {
+				test.T(t, results, []string{""})
+			} else {
+				test.String(t, strings.Join(results, ", "), tt.expected)
+			}
+		})
+	}
+}
+
+func TestPathNested(t *testing.T) {
+	// nested matches are reported in document order, and the search can be stopped
+	path, err := ParsePath("$..a")
+	test.Error(t, err)
+	raws, err := path.FindAll(bytes.NewBufferString(`{"a": {"a": 1, "b": {"a": 2}}, "c": [{"a": 3}]}`))
+	test.Error(t, err)
+	test.T(t, len(raws), 4)
+	test.String(t, string(raws[0]), `{"a": 1, "b": {"a": 2}}`)
+	test.String(t, string(raws[1]), `1`)
+	test.String(t, string(raws[2]), `2`)
+	test.String(t, string(raws[3]), `3`)
+
+	n := 0
+	err = path.Find(bytes.NewBufferString(`{"a": {"a": 1, "b": {"a": 2}}, "c": [{"a": 3}]}`), func(Pointer, []byte) bool {
+		n++
+		return n < 2
+	})
+	test.Error(t, err)
+	test.T(t, n, 2)
+
+	// matches are kept while streaming
+	raws, err = path.FindAll(iotest.OneByteReader(bytes.NewBufferString(`{"a": {"a" :1, "b": {"a": 2}}, "c": [{"a": 3}]}`)))
+	test.Error(t, err)
+	test.T(t, len(raws), 4)
+	test.String(t, string(raws[0]), `{"a" :1, "b": {"a": 2}}`)
+	test.String(t, string(raws[1]), `1`)
+	test.String(t, string(raws[2]), `2`)
+	test.String(t, string(raws[3]), `3`)
+}
+
+func TestPathMemory(t *testing.T) {
+	item := []byte(`{"id": 1, "name": "item", "tags": ["a", "b"], "ok": true},`)
+	r := io.MultiReader(bytes.NewBufferString("["), testio.NewRepeatReader(item, 500000), bytes.NewBufferString("null]"))
+
+	path, err := ParsePath("$[250000].tags")
+	test.Error(t, err)
+	var raws [][]byte
+	alloc := testio.TotalAlloc(func() {
+		raws, err = path.FindAll(r)
+	})
+	test.Error(t, err)
+	test.T(t, len(raws), 1)
+	test.String(t, string(raws[0]), `["a", "b"]`)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*500000, "bytes of input")
+}
+
+func TestPathError(t *testing.T) {
+	var errorTests = []struct {
+		path string
+		err  string
+		col  int
+	}{
+		{"", "expected '$'", 1},
+		{"a", "expected '$'", 1},
+		{"$a", "unexpected 'a'", 2},
+		{"$.", "expected name or '*'", 3},
+		{"$[", "unexpected end of path", 3},
+		{"$[1", "unexpected end of path", 4},
+		{"$[1 2]", "expected ',' or ']' instead of '2'", 5},
+		{"$[-1]", "negative indices are not supported", 3},
+		{"$[:-1]", "negative indices are not supported", 3},
+		{"$[::0]", "slice step must be positive", 3},
+		{"$['a]", "unterminated string", 3},
+		{"$[?(@.a)]", "filter and script expressions are not supported", 3},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := ParsePath(tt.path)
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "JSONPath parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func ExamplePath_Find() {
+	path, err := ParsePath("$.store.book[*].title")
+	if err != nil {
+		panic(err)
+	}
+	err = path.Find(bytes.NewBufferString(store), func(ptr Pointer, raw []byte) bool {
+		fmt.Println(ptr, string(raw))
+		return true
+	})
+	if err != nil {
+		panic(err)
+	}
+	// Output:
+	// /store/book/0/title "Sayings of the Century"
+	// /store/book/1/title "Sword of Honour"
+	// /store/book/2/title "Moby Dick"
+}
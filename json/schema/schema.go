@@ -0,0 +1,411 @@
+// Package schema validates JSON documents against a JSON Schema following the specifications at https://json-schema.org/draft/2020-12/json-schema-core.html and https://json-schema.org/draft/2020-12/json-schema-validation.html, while streaming through json.Parser.
+package schema
+
+import (
+	"io"
+	"math/big"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a compiled JSON Schema, or one of its subschemas.
+type Schema struct {
+	location string // JSON Pointer of the schema within the schema document
+	boolean  *bool  // true and false schemas
+	ref      *Schema
+
+	types    []string
+	enum     []interface{}
+	konst    interface{}
+	hasConst bool
+
+	multipleOf       *big.Rat
+	maximum          *big.Rat
+	exclusiveMaximum *big.Rat
+	minimum          *big.Rat
+	exclusiveMinimum *big.Rat
+	maxLength        int // -1 if absent, as for the other counts
+	minLength        int
+	pattern          *regexp.Regexp
+
+	allOf            []*Schema
+	anyOf            []*Schema
+	oneOf            []*Schema
+	not              *Schema
+	ifSchema         *Schema
+	thenSchema       *Schema
+	elseSchema       *Schema
+	dependentSchemas map[string]*Schema
+
+	prefixItems      []*Schema
+	items            *Schema
+	contains         *Schema
+	maxContains      int
+	minContains      int
+	maxItems         int
+	minItems         int
+	uniqueItems      bool
+	unevaluatedItems *Schema
+
+	properties            map[string]*Schema
+	patternProperties     []patternSchema
+	additionalProperties  *Schema
+	propertyNames         *Schema
+	maxProperties         int
+	minProperties         int
+	required              []string
+	dependentRequired     map[string][]string
+	unevaluatedProperties *Schema
+}
+
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *Schema
+}
+
+type reference struct {
+	s        *Schema
+	uri      string
+	location string // of the $ref keyword
+}
+
+type compiler struct {
+	r         *reader
+	resources map[string]*Schema // by absolute URI, with a fragment that is a JSON Pointer or an anchor
+	refs      []reference
+}
+
+// Compile compiles a JSON Schema of draft 2020-12 with the core, applicator, unevaluated, and validation vocabularies. Annotation keywords such as title and format are ignored.
+// References must resolve within the schema document, using JSON Pointers, anchors defined by $anchor, or the URIs of subschemas with an $id. Dynamic references by $dynamicRef are resolved like $ref.
+// Regular expressions are compiled by the regexp package and must use its RE2 syntax. Errors are of type *parse.Error.
+func Compile(b []byte) (*Schema, error) {
+	c := &compiler{
+		r:         newReader(b),
+		resources: map[string]*Schema{},
+	}
+	c.r.offsets = map[string]int{}
+	c.r.keyOffsets = map[string]int{}
+	defer c.r.p.Restore()
+
+	gt, data := c.r.p.Next()
+	v, err := c.r.decode("", gt, data)
+	if err != nil {
+		return nil, err
+	} else if c.r.p.Next(); c.r.p.Err() != io.EOF {
+		return nil, c.r.p.Err()
+	}
+
+	s, err := c.compile(v, "", "", "")
+	if err != nil {
+		return nil, err
+	}
+	for _, ref := range c.refs {
+		target, ok := c.resources[ref.uri]
+		if !ok {
+			return nil, c.error(ref.location, "unresolved reference '%s'", ref.uri)
+		}
+		ref.s.ref = target
+	}
+	return s, nil
+}
+
+// MustCompile is like Compile but panics if the schema cannot be compiled.
+func MustCompile(b []byte) *Schema {
+	s, err := Compile(b)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// error returns an error for the value at the JSON Pointer location.
+func (c *compiler) error(location string, message string, a ...interface{}) error {
+	return c.r.newError(c.r.offsets[location], "JSON Schema error: "+message, a...)
+}
+
+// compile compiles the schema value v at the JSON Pointer location, where base is the URI of the enclosing schema resource at location root.
+func (c *compiler) compile(v interface{}, location, base, root string) (*Schema, error) {
+	s := &Schema{
+		location:      location,
+		maxLength:     -1,
+		minLength:     -1,
+		maxContains:   -1,
+		minContains:   -1,
+		maxItems:      -1,
+		minItems:      -1,
+		maxProperties: -1,
+		minProperties: -1,
+	}
+	if b, ok := v.(bool); ok {
+		s.boolean = &b
+		c.resources[base+"#"+location[len(root):]] = s
+		return s, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, c.error(location, "schema must be an object or a boolean")
+	}
+
+	if id, ok := m["$id"]; ok {
+		sid, ok := id.(string)
+		uri, err := resolve(base, sid)
+		if !ok || err != nil || !strings.HasSuffix(uri, "#") {
+			return nil, c.error(location+"/$id", "$id must be a URI without a fragment")
+		}
+		base, root = uri[:len(uri)-1], location
+	}
+	c.resources[base+"#"+location[len(root):]] = s
+	for _, keyword := range []string{"$anchor", "$dynamicAnchor"} {
+		if anchor, ok := m[keyword]; ok {
+			sanchor, ok := anchor.(string)
+			if !ok {
+				return nil, c.error(location+"/"+keyword, "%s must be a string", keyword)
+			}
+			c.resources[base+"#"+sanchor] = s
+		}
+	}
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		if ref, ok := m[keyword]; ok {
+			sref, ok := ref.(string)
+			uri, err := resolve(base, sref)
+			if !ok || err != nil {
+				return nil, c.error(location+"/"+keyword, "%s must be a URI reference", keyword)
+			}
+			c.refs = append(c.refs, reference{s, uri, location + "/" + keyword})
+		}
+	}
+
+	// compile keywords in order so that errors are deterministic
+	keywords := make([]string, 0, len(m))
+	for keyword := range m {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+	for _, keyword := range keywords {
+		v := m[keyword]
+		loc := location + "/" + keyword
+		switch keyword {
+		case "type":
+			if s.types, ok = stringList(v, true); !ok {
+				return nil, c.error(loc, "type must be a string or an array of strings")
+			}
+			for _, t := range s.types {
+				switch t {
+				case "null", "boolean", "object", "array", "number", "string", "integer":
+				default:
+					return nil, c.error(loc, "unknown type '%s'", t)
+				}
+			}
+		case "enum":
+			if s.enum, ok = v.([]interface{}); !ok {
+				return nil, c.error(loc, "enum must be an array")
+			}
+		case "const":
+			s.konst, s.hasConst = v, true
+		case "multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum":
+			r, ok := v.(*big.Rat)
+			if !ok {
+				return nil, c.error(loc, "%s must be a number", keyword)
+			}
+			switch keyword {
+			case "multipleOf":
+				if r.Sign() <= 0 {
+					return nil, c.error(loc, "multipleOf must be greater than zero")
+				}
+				s.multipleOf = r
+			case "maximum":
+				s.maximum = r
+			case "exclusiveMaximum":
+				s.exclusiveMaximum = r
+			case "minimum":
+				s.minimum = r
+			case "exclusiveMinimum":
+				s.exclusiveMinimum = r
+			}
+		case "maxLength", "minLength", "maxItems", "minItems", "maxContains", "minContains", "maxProperties", "minProperties":
+			r, ok := v.(*big.Rat)
+			if !ok || !r.IsInt() || r.Sign() < 0 || !r.Num().IsInt64() {
+				return nil, c.error(loc, "%s must be a non-negative integer", keyword)
+			}
+			n := int(r.Num().Int64())
+			switch keyword {
+			case "maxLength":
+				s.maxLength = n
+			case "minLength":
+				s.minLength = n
+			case "maxItems":
+				s.maxItems = n
+			case "minItems":
+				s.minItems = n
+			case "maxContains":
+				s.maxContains = n
+			case "minContains":
+				s.minContains = n
+			case "maxProperties":
+				s.maxProperties = n
+			case "minProperties":
+				s.minProperties = n
+			}
+		case "pattern":
+			pattern, ok := v.(string)
+			if !ok {
+				return nil, c.error(loc, "pattern must be a string")
+			}
+			var err error
+			if s.pattern, err = regexp.Compile(pattern); err != nil {
+				return nil, c.error(loc, "bad regular expression '%s'", pattern)
+			}
+		case "uniqueItems":
+			if s.uniqueItems, ok = v.(bool); !ok {
+				return nil, c.error(loc, "uniqueItems must be a boolean")
+			}
+		case "required":
+			if s.required, ok = stringList(v, false); !ok {
+				return nil, c.error(loc, "required must be an array of strings")
+			}
+		case "dependentRequired":
+			deps, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, c.error(loc, "dependentRequired must be an object")
+			}
+			s.dependentRequired = map[string][]string{}
+			for name, list := range deps {
+				if s.dependentRequired[name], ok = stringList(list, false); !ok {
+					return nil, c.error(loc+"/"+escapePointerToken(name), "dependentRequired must have arrays of strings")
+				}
+			}
+		case "allOf", "anyOf", "oneOf", "prefixItems":
+			list, ok := v.([]interface{})
+			if !ok || len(list) == 0 {
+				return nil, c.error(loc, "%s must be a non-empty array", keyword)
+			}
+			schemas := make([]*Schema, len(list))
+			for i, item := range list {
+				var err error
+				if schemas[i], err = c.compile(item, loc+"/"+strconv.Itoa(i), base, root); err != nil {
+					return nil, err
+				}
+			}
+			switch keyword {
+			case "allOf":
+				s.allOf = schemas
+			case "anyOf":
+				s.anyOf = schemas
+			case "oneOf":
+				s.oneOf = schemas
+			case "prefixItems":
+				s.prefixItems = schemas
+			}
+		case "not", "if", "then", "else", "items", "contains", "additionalProperties", "propertyNames", "unevaluatedItems", "unevaluatedProperties":
+			schema, err := c.compile(v, loc, base, root)
+			if err != nil {
+				return nil, err
+			}
+			switch keyword {
+			case "not":
+				s.not = schema
+			case "if":
+				s.ifSchema = schema
+			case "then":
+				s.thenSchema = schema
+			case "else":
+				s.elseSchema = schema
+			case "items":
+				s.items = schema
+			case "contains":
+				s.contains = schema
+			case "additionalProperties":
+				s.additionalProperties = schema
+			case "propertyNames":
+				s.propertyNames = schema
+			case "unevaluatedItems":
+				s.unevaluatedItems = schema
+			case "unevaluatedProperties":
+				s.unevaluatedProperties = schema
+			}
+		case "$defs", "properties", "patternProperties", "dependentSchemas":
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, c.error(loc, "%s must be an object", keyword)
+			}
+			names := make([]string, 0, len(obj))
+			for name := range obj {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			schemas := make(map[string]*Schema, len(obj))
+			for _, name := range names {
+				var err error
+				nameLoc := loc + "/" + escapePointerToken(name)
+				if schemas[name], err = c.compile(obj[name], nameLoc, base, root); err != nil {
+					return nil, err
+				}
+				if keyword == "patternProperties" {
+					pattern, err := regexp.Compile(name)
+					if err != nil {
+						return nil, c.r.newError(c.r.keyOffsets[nameLoc], "JSON Schema error: bad regular expression '%s'", name)
+					}
+					s.patternProperties = append(s.patternProperties, patternSchema{pattern, schemas[name]})
+				}
+			}
+			switch keyword {
+			case "properties":
+				s.properties = schemas
+			case "dependentSchemas":
+				s.dependentSchemas = schemas
+			}
+		}
+	}
+	if s.ifSchema == nil {
+		s.thenSchema, s.elseSchema = nil, nil
+	}
+	if s.contains == nil {
+		s.maxContains, s.minContains = -1, -1
+	} else if s.minContains == -1 {
+		s.minContains = 1
+	}
+	return s, nil
+}
+
+// resolve returns the absolute URI of a reference relative to base, with an unescaped fragment that is always present.
+func resolve(base, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if base != "" {
+		b, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		u = b.ResolveReference(u)
+	}
+	fragment := u.Fragment
+	u.Fragment = ""
+	return u.String() + "#" + fragment, nil
+}
+
+func escapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// stringList returns the strings of an array of strings, or of a single string if single is set.
+func stringList(v interface{}, single bool) ([]string, bool) {
+	if s, ok := v.(string); ok && single {
+		return []string{s}, true
+	}
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	strs := make([]string, len(list))
+	for i, item := range list {
+		if strs[i], ok = item.(string); !ok {
+			return nil, false
+		}
+	}
+	return strs, true
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func TestCompile(t *testing.T) {
+	var compileTests = []string{
+		`true`,
+		`false`,
+		`{}`,
+		`{"title": "a", "description": "b", "format": "email", "examples": [1], "x-unknown": {"type": 5}}`,
+		`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "http://example.com/a.json"}`,
+		`{"type": ["null", "boolean", "object", "array", "number", "string", "integer"]}`,
+		`{"minimum": 1.5e3, "maxLength": 2.0, "multipleOf": 0.1}`,
+		`{"$defs": {"a": {"$anchor": "b"}}, "$ref": "#b"}`,
+		`{"$id": "http://example.com/", "$defs": {"a": {"$id": "a/b.json"}}, "$ref": "a/b.json#"}`,
+		`{"$id": "http://example.com/", "$defs": {"a": {"$id": "a/b.json", "$anchor": "c"}}, "$ref": "http://example.com/a/b.json#c"}`,
+		`{"$id": "http://example.com/", "$defs": {"a": {"$id": "a/b.json", "$defs": {"c": true}}}, "$ref": "a/b.json#/$defs/c"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}}`,
+	}
+	for _, tt := range compileTests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := Compile([]byte(tt))
+			test.Error(t, err)
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	var errorTests = []struct {
+		schema    string
+		err       string
+		line, col int
+	}{
+		{`1`, "schema must be an object or a boolean", 1, 1},
+		{`{"type": 1}`, "type must be a string or an array of strings", 1, 10},
+		{"{\n  \"type\": [\"string\", \"text\"]\n}", "unknown type 'text'", 2, 11},
+		{`{"enum": {}}`, "enum must be an array", 1, 10},
+		{`{"maximum": "1"}`, "maximum must be a number", 1, 13},
+		{`{"multipleOf": 0}`, "multipleOf must be greater than zero", 1, 16},
+		{`{"minLength": -1}`, "minLength must be a non-negative integer", 1, 15},
+		{`{"maxItems": 1.5}`, "maxItems must be a non-negative integer", 1, 14},
+		{`{"pattern": 1}`, "pattern must be a string", 1, 13},
+		{`{"pattern": "("}`, "bad regular expression '('", 1, 13},
+		{`{"patternProperties": {"(": true}}`, "bad regular expression '('", 1, 24},
+		{`{"uniqueItems": 1}`, "uniqueItems must be a boolean", 1, 17},
+		{`{"required": ["a", 1]}`, "required must be an array of strings", 1, 14},
+		{`{"dependentRequired": []}`, "dependentRequired must be an object", 1, 23},
+		{`{"dependentRequired": {"a": "b"}}`, "dependentRequired must have arrays of strings", 1, 29},
+		{`{"allOf": []}`, "allOf must be a non-empty array", 1, 11},
+		{`{"properties": []}`, "properties must be an object", 1, 16},
+		{`{"properties": {"a": 1}}`, "schema must be an object or a boolean", 1, 22},
+		{`{"items": {"not": null}}`, "schema must be an object or a boolean", 1, 19},
+		{`{"$id": "a.json#b"}`, "$id must be a URI without a fragment", 1, 9},
+		{`{"$anchor": 1}`, "$anchor must be a string", 1, 13},
+		{`{"$ref": "%"}`, "$ref must be a URI reference", 1, 10},
+		{"{\n  \"$ref\": \"#/$defs/a\"\n}", "unresolved reference '#/$defs/a'", 2, 11},
+		{`{"$ref": "other.json"}`, "unresolved reference 'other.json#'", 1, 10},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.schema, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "JSON Schema error: "+tt.err)
+				line, col, _ := perr.Position()
+				test.T(t, line, tt.line, "line")
+				test.T(t, col, tt.col, "column")
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+
+	_, err := Compile([]byte(`{"a": }`))
+	test.That(t, err != nil)
+	_, err = Compile([]byte(`{} 1`))
+	test.That(t, err != nil)
+
+	defer func() {
+		test.That(t, recover() != nil, "MustCompile must panic")
+	}()
+	MustCompile([]byte(`1`))
+}
@@ -0,0 +1,638 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/json"
+)
+
+// Violation is a value of the document that is not valid against a keyword of the schema.
+type Violation struct {
+	Path    json.Pointer // location of the value in the document
+	Keyword string       // JSON Pointer of the keyword in the schema document, such as /properties/a/minimum
+	Err     *parse.Error // message with the line and column of the value
+}
+
+// Error returns the error string of the violation, prefixed by the location of the value.
+func (v Violation) Error() string {
+	return pointerString(v.Path.String()) + ": " + v.Err.Error()
+}
+
+// pointerString returns a JSON Pointer for messages, where the root is represented by a slash.
+func pointerString(ptr string) string {
+	if ptr == "" {
+		return "/"
+	}
+	return ptr
+}
+
+// Validate validates the JSON document from r against the schema. It returns all violations in document order, or an error when the document is not valid JSON.
+// The document is streamed through the parser and validated in a single pass, only values that are needed by const, enum, or uniqueItems are decoded.
+func (s *Schema) Validate(r io.Reader) ([]Violation, error) {
+	v := &validator{
+		p: json.NewStreamParser(r),
+	}
+	defer v.p.Restore()
+
+	root := &evaluation{s: s}
+	gt, data := v.p.Next()
+	if gt == json.ErrorGrammar {
+		return nil, v.syntaxError()
+	} else if _, err := v.value([]*evaluation{root}, gt, data, v.p.Start(), false); err != nil {
+		return nil, err
+	} else if v.p.Next(); v.p.Err() != io.EOF {
+		return nil, v.p.Err()
+	}
+
+	sort.SliceStable(root.errs, func(i, j int) bool {
+		return root.errs[i].offset < root.errs[j].offset
+	})
+	violations := make([]Violation, len(root.errs))
+	for i, viol := range root.errs {
+		violations[i] = Violation{
+			Path:    viol.path,
+			Keyword: viol.keyword,
+			Err:     viol.err,
+		}
+	}
+	return violations, nil
+}
+
+// IsValid returns true if the JSON document in b is valid JSON and valid against the schema.
+func (s *Schema) IsValid(b []byte) bool {
+	violations, err := s.Validate(bytes.NewBuffer(b))
+	return err == nil && len(violations) == 0
+}
+
+////////////////////////////////////////////////////////////////
+
+type violation struct {
+	offset  int
+	path    json.Pointer
+	keyword string
+	err     *parse.Error
+}
+
+// evaluationKind is how an evaluation applies to the evaluation of its parent.
+type evaluationKind int
+
+// evaluationKind values, where the in-place kinds evaluate the same value as their parent and the others evaluate a property, item, or property name.
+const (
+	rootKind evaluationKind = iota
+	refKind
+	allOfKind
+	anyOfKind
+	oneOfKind
+	notKind
+	ifKind
+	thenKind
+	elseKind
+	dependentKind
+	childKind
+	containsKind
+	unevaluatedKind
+	propertyNameKind
+)
+
+// evaluation is the evaluation of a schema against a value. Since the document is streamed, the evaluations of all schemas that apply to a value run at the same time, and the in-place applicators such as anyOf are combined at the end of the value.
+type evaluation struct {
+	s      *Schema
+	kind   evaluationKind
+	parent *evaluation
+	key    string // property name for dependentKind and unevaluatedKind
+	index  int    // item index for containsKind and unevaluatedKind
+	deny   string // message when s is the false schema
+
+	subs        []*evaluation // in-place applicators
+	uneval      []*evaluation // evaluations of unevaluatedProperties or unevaluatedItems for every property or item
+	errs        []violation
+	valid       bool
+	keys        []string
+	count       int // number of properties or items
+	containsAll int // number of items valid against contains
+
+	// annotations of the evaluated properties and items, see https://json-schema.org/draft/2020-12/json-schema-core.html#section-11
+	props   map[string]bool
+	items   int // number of leading items that were evaluated
+	itemSet map[int]bool
+}
+
+type validator struct {
+	p    *json.Parser
+	path json.Pointer
+}
+
+// position is the offset of a value. Since the document is streamed, the line and column of objects and arrays are determined at their start, before they are finalized.
+type position struct {
+	offset int
+	err    *parse.Error
+}
+
+func (v *validator) position(gt json.GrammarType, offset int) position {
+	if gt == json.StartObjectGrammar || gt == json.StartArrayGrammar {
+		return position{offset, v.p.NewError(offset, "")}
+	}
+	return position{offset, nil}
+}
+
+func (v *validator) error(ev *evaluation, pos position, keyword, message string, a ...interface{}) {
+	if keyword != "" {
+		keyword = ev.s.location + "/" + keyword
+	} else {
+		keyword = ev.s.location
+	}
+	var err parse.Error
+	if pos.err != nil {
+		err = *pos.err
+	} else {
+		err = *v.p.NewError(pos.offset, "")
+	}
+	err.Message = "JSON Schema validation error: " + fmt.Sprintf(message, a...)
+	ev.errs = append(ev.errs, violation{pos.offset, append(json.Pointer{}, v.path...), keyword, &err})
+}
+
+// syntaxError returns the parser's error, or an unexpected end of input error.
+func (v *validator) syntaxError() error {
+	if v.p.Err() != io.EOF {
+		return v.p.Err()
+	}
+	return v.p.NewError(v.p.Offset(), "JSON parse error: unexpected end of input")
+}
+
+// expand appends the evaluation and the evaluations of its in-place applicators to all. References that lead back to a schema that is already being evaluated for the same value are ignored, since they would recurse infinitely.
+func (v *validator) expand(ev *evaluation, all []*evaluation, stack []*Schema) []*evaluation {
+	all = append(all, ev)
+	s := ev.s
+	if s.boolean != nil {
+		return all
+	}
+	stack = append(stack, s)
+	add := func(sub *Schema, kind evaluationKind, key string) {
+		for _, t := range stack {
+			if t == sub {
+				return
+			}
+		}
+		subEv := &evaluation{s: sub, kind: kind, parent: ev, key: key}
+		ev.subs = append(ev.subs, subEv)
+		all = v.expand(subEv, all, stack)
+	}
+	if s.ref != nil {
+		add(s.ref, refKind, "")
+	}
+	for _, sub := range s.allOf {
+		add(sub, allOfKind, "")
+	}
+	for _, sub := range s.anyOf {
+		add(sub, anyOfKind, "")
+	}
+	for _, sub := range s.oneOf {
+		add(sub, oneOfKind, "")
+	}
+	if s.not != nil {
+		add(s.not, notKind, "")
+	}
+	if s.ifSchema != nil {
+		add(s.ifSchema, ifKind, "")
+		if s.thenSchema != nil {
+			add(s.thenSchema, thenKind, "")
+		}
+		if s.elseSchema != nil {
+			add(s.elseSchema, elseKind, "")
+		}
+	}
+	if 0 < len(s.dependentSchemas) {
+		names := make([]string, 0, len(s.dependentSchemas))
+		for name := range s.dependentSchemas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			add(s.dependentSchemas[name], dependentKind, name)
+		}
+	}
+	return all
+}
+
+// value validates the value that starts with the given grammar at offset against the evaluations, and returns the decoded value if need is set.
+func (v *validator) value(evs []*evaluation, gt json.GrammarType, data []byte, offset int, need bool) (interface{}, error) {
+	var all []*evaluation
+	for _, ev := range evs {
+		all = v.expand(ev, all, nil)
+	}
+	for _, ev := range all {
+		if ev.s.hasConst || ev.s.enum != nil || gt == json.StartArrayGrammar && ev.s.uniqueItems {
+			need = true
+		}
+	}
+
+	pos := v.position(gt, offset)
+	var val interface{}
+	var err error
+	switch gt {
+	case json.ErrorGrammar:
+		return nil, v.syntaxError()
+	case json.StartObjectGrammar:
+		if val, err = v.object(all, need); err != nil {
+			return nil, err
+		}
+	case json.StartArrayGrammar:
+		if val, err = v.array(all, need); err != nil {
+			return nil, err
+		}
+	case json.EndObjectGrammar, json.EndArrayGrammar:
+		return nil, v.p.NewError(offset, "JSON parse error: unexpected %s", gt)
+	default:
+		val = scalar(gt, data)
+	}
+
+	for i := len(all) - 1; 0 <= i; i-- {
+		v.finalize(all[i], gt, val, pos)
+	}
+	return val, nil
+}
+
+func (v *validator) object(all []*evaluation, need bool) (interface{}, error) {
+	var m map[string]interface{}
+	if need {
+		m = map[string]interface{}{}
+	}
+	for {
+		gt, data := v.p.Next()
+		if gt == json.EndObjectGrammar {
+			return m, nil
+		} else if gt == json.ErrorGrammar {
+			return nil, v.syntaxError()
+		}
+		key := unquote(data)
+		keyOffset := v.p.Start()
+
+		var children, names []*evaluation
+		for _, ev := range all {
+			s := ev.s
+			if s.boolean != nil {
+				continue
+			}
+			ev.keys = append(ev.keys, key)
+			ev.count++
+			evaluated := false
+			if sub, ok := s.properties[key]; ok {
+				children = append(children, ev.child(sub, childKind, ""))
+				evaluated = true
+			}
+			for _, pattern := range s.patternProperties {
+				if pattern.pattern.MatchString(key) {
+					children = append(children, ev.child(pattern.schema, childKind, ""))
+					evaluated = true
+				}
+			}
+			if !evaluated && s.additionalProperties != nil {
+				children = append(children, ev.child(s.additionalProperties, childKind, fmt.Sprintf("additional property '%s' is not allowed", key)))
+				evaluated = true
+			}
+			if evaluated {
+				ev.annotateProperty(key)
+			}
+			if s.unevaluatedProperties != nil {
+				child := ev.child(s.unevaluatedProperties, unevaluatedKind, fmt.Sprintf("unevaluated property '%s' is not allowed", key))
+				child.key = key
+				children = append(children, child)
+			}
+			if s.propertyNames != nil {
+				names = append(names, ev.child(s.propertyNames, propertyNameKind, fmt.Sprintf("property name '%s' is not allowed", key)))
+			}
+		}
+		if 0 < len(names) {
+			if _, err := v.value(names, json.StringGrammar, data, keyOffset, false); err != nil {
+				return nil, err
+			}
+			v.report(names)
+		}
+
+		gt, data = v.p.Next()
+		v.path = append(v.path, key)
+		child, err := v.value(children, gt, data, v.p.Start(), need)
+		v.path = v.path[:len(v.path)-1]
+		if err != nil {
+			return nil, err
+		}
+		v.report(children)
+		if need {
+			m[key] = child
+		}
+	}
+}
+
+func (v *validator) array(all []*evaluation, need bool) (interface{}, error) {
+	var a []interface{}
+	if need {
+		a = []interface{}{}
+	}
+	for index := 0; ; index++ {
+		gt, data := v.p.Next()
+		if gt == json.EndArrayGrammar {
+			return a, nil
+		}
+
+		var children []*evaluation
+		for _, ev := range all {
+			s := ev.s
+			if s.boolean != nil {
+				continue
+			}
+			ev.count++
+			if index < len(s.prefixItems) {
+				children = append(children, ev.child(s.prefixItems[index], childKind, ""))
+				ev.items = index + 1
+			} else if s.items != nil {
+				children = append(children, ev.child(s.items, childKind, fmt.Sprintf("additional item at index %d is not allowed", index)))
+				ev.items = index + 1
+			}
+			if s.contains != nil {
+				child := ev.child(s.contains, containsKind, "")
+				child.index = index
+				children = append(children, child)
+			}
+			if s.unevaluatedItems != nil {
+				child := ev.child(s.unevaluatedItems, unevaluatedKind, fmt.Sprintf("unevaluated item at index %d is not allowed", index))
+				child.index = index
+				children = append(children, child)
+			}
+		}
+
+		v.path = append(v.path, strconv.Itoa(index))
+		item, err := v.value(children, gt, data, v.p.Start(), need)
+		v.path = v.path[:len(v.path)-1]
+		if err != nil {
+			return nil, err
+		}
+		v.report(children)
+		if need {
+			a = append(a, item)
+		}
+	}
+}
+
+func (ev *evaluation) child(s *Schema, kind evaluationKind, deny string) *evaluation {
+	return &evaluation{s: s, kind: kind, parent: ev, deny: deny}
+}
+
+func (ev *evaluation) annotateProperty(key string) {
+	if ev.props == nil {
+		ev.props = map[string]bool{}
+	}
+	ev.props[key] = true
+}
+
+func (ev *evaluation) annotateItem(index int) {
+	if ev.itemSet == nil {
+		ev.itemSet = map[int]bool{}
+	}
+	ev.itemSet[index] = true
+}
+
+// merge adds the annotations of a valid in-place evaluation.
+func (ev *evaluation) merge(sub *evaluation) {
+	for key := range sub.props {
+		ev.annotateProperty(key)
+	}
+	if ev.items < sub.items {
+		ev.items = sub.items
+	}
+	for index := range sub.itemSet {
+		ev.annotateItem(index)
+	}
+}
+
+// report passes the results of the evaluations of a property, item, or property name to their parents.
+func (v *validator) report(children []*evaluation) {
+	for _, child := range children {
+		parent := child.parent
+		switch child.kind {
+		case childKind, propertyNameKind:
+			parent.errs = append(parent.errs, child.errs...)
+		case containsKind:
+			if child.valid {
+				parent.containsAll++
+				parent.annotateItem(child.index)
+			}
+		case unevaluatedKind:
+			parent.uneval = append(parent.uneval, child)
+		}
+	}
+}
+
+// finalize checks the keywords that apply to the entire value, and combines the results of the in-place applicators.
+func (v *validator) finalize(ev *evaluation, gt json.GrammarType, val interface{}, pos position) {
+	s := ev.s
+	if s.boolean != nil {
+		if !*s.boolean {
+			deny := ev.deny
+			if deny == "" {
+				deny = "value is not allowed"
+			}
+			v.error(ev, pos, "", deny)
+		}
+		ev.valid = len(ev.errs) == 0
+		return
+	}
+
+	typ := ""
+	switch gt {
+	case json.StartObjectGrammar:
+		typ = "object"
+	case json.StartArrayGrammar:
+		typ = "array"
+	default:
+		typ = typeName(val)
+	}
+	if 0 < len(s.types) {
+		ok := false
+		for _, t := range s.types {
+			if t == typ || t == "number" && typ == "integer" {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			if typ == "integer" {
+				typ = "number"
+			}
+			v.error(ev, pos, "type", "expected %s but got %s", strings.Join(s.types, " or "), typ)
+		}
+	}
+	if s.hasConst && !equal(s.konst, val) {
+		v.error(ev, pos, "const", "value must be %s", appendValue(nil, s.konst))
+	}
+	if s.enum != nil {
+		ok := false
+		for _, item := range s.enum {
+			if equal(item, val) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			v.error(ev, pos, "enum", "value must be one of %s", appendValue(nil, s.enum))
+		}
+	}
+
+	switch val := val.(type) {
+	case *big.Rat:
+		if s.multipleOf != nil && !new(big.Rat).Quo(val, s.multipleOf).IsInt() {
+			v.error(ev, pos, "multipleOf", "%s is not a multiple of %s", formatNumber(val), formatNumber(s.multipleOf))
+		}
+		if s.maximum != nil && 0 < val.Cmp(s.maximum) {
+			v.error(ev, pos, "maximum", "%s is greater than %s", formatNumber(val), formatNumber(s.maximum))
+		}
+		if s.exclusiveMaximum != nil && 0 <= val.Cmp(s.exclusiveMaximum) {
+			v.error(ev, pos, "exclusiveMaximum", "%s is not less than %s", formatNumber(val), formatNumber(s.exclusiveMaximum))
+		}
+		if s.minimum != nil && val.Cmp(s.minimum) < 0 {
+			v.error(ev, pos, "minimum", "%s is less than %s", formatNumber(val), formatNumber(s.minimum))
+		}
+		if s.exclusiveMinimum != nil && val.Cmp(s.exclusiveMinimum) <= 0 {
+			v.error(ev, pos, "exclusiveMinimum", "%s is not greater than %s", formatNumber(val), formatNumber(s.exclusiveMinimum))
+		}
+	case string:
+		if s.maxLength != -1 || s.minLength != -1 {
+			n := utf8.RuneCountInString(val)
+			if s.maxLength != -1 && s.maxLength < n {
+				v.error(ev, pos, "maxLength", "string is longer than %d characters", s.maxLength)
+			}
+			if s.minLength != -1 && n < s.minLength {
+				v.error(ev, pos, "minLength", "string is shorter than %d characters", s.minLength)
+			}
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			v.error(ev, pos, "pattern", "string does not match pattern '%s'", s.pattern)
+		}
+	}
+
+	if gt == json.StartObjectGrammar {
+		if s.maxProperties != -1 && s.maxProperties < ev.count {
+			v.error(ev, pos, "maxProperties", "object has more than %d properties", s.maxProperties)
+		}
+		if s.minProperties != -1 && ev.count < s.minProperties {
+			v.error(ev, pos, "minProperties", "object has fewer than %d properties", s.minProperties)
+		}
+		for _, name := range s.required {
+			if !containsString(ev.keys, name) {
+				v.error(ev, pos, "required", "missing property '%s'", name)
+			}
+		}
+		for _, key := range ev.keys {
+			for _, name := range s.dependentRequired[key] {
+				if !containsString(ev.keys, name) {
+					v.error(ev, pos, "dependentRequired", "missing property '%s' required by property '%s'", name, key)
+				}
+			}
+		}
+	} else if gt == json.StartArrayGrammar {
+		if s.maxItems != -1 && s.maxItems < ev.count {
+			v.error(ev, pos, "maxItems", "array has more than %d items", s.maxItems)
+		}
+		if s.minItems != -1 && ev.count < s.minItems {
+			v.error(ev, pos, "minItems", "array has fewer than %d items", s.minItems)
+		}
+		if s.uniqueItems {
+			items := val.([]interface{})
+		Unique:
+			for j := 1; j < len(items); j++ {
+				for i := 0; i < j; i++ {
+					if equal(items[i], items[j]) {
+						v.error(ev, pos, "uniqueItems", "items at index %d and %d are equal", i, j)
+						break Unique
+					}
+				}
+			}
+		}
+		if s.contains != nil {
+			if ev.containsAll < s.minContains {
+				if s.minContains == 1 {
+					v.error(ev, pos, "contains", "array has no items matching contains")
+				} else {
+					v.error(ev, pos, "minContains", "array has fewer than %d items matching contains", s.minContains)
+				}
+			}
+			if s.maxContains != -1 && s.maxContains < ev.containsAll {
+				v.error(ev, pos, "maxContains", "array has more than %d items matching contains", s.maxContains)
+			}
+		}
+	}
+
+	// in-place applicators
+	anyOf, oneOf := 0, 0
+	var ifValid *bool
+	for _, sub := range ev.subs {
+		switch sub.kind {
+		case refKind, allOfKind:
+			ev.errs = append(ev.errs, sub.errs...)
+		case anyOfKind:
+			if sub.valid {
+				anyOf++
+			}
+		case oneOfKind:
+			if sub.valid {
+				oneOf++
+			}
+		case notKind:
+			if sub.valid {
+				v.error(ev, pos, "not", "value must not be valid against not")
+			}
+			continue // not does not produce annotations
+		case ifKind:
+			ifValid = &sub.valid
+		case thenKind, elseKind:
+			if ifValid == nil || *ifValid != (sub.kind == thenKind) {
+				continue
+			}
+			ev.errs = append(ev.errs, sub.errs...)
+		case dependentKind:
+			if gt != json.StartObjectGrammar || !containsString(ev.keys, sub.key) {
+				continue
+			}
+			ev.errs = append(ev.errs, sub.errs...)
+		}
+		if sub.valid {
+			ev.merge(sub)
+		}
+	}
+	if s.anyOf != nil && anyOf == 0 {
+		v.error(ev, pos, "anyOf", "value must be valid against at least one schema of anyOf")
+	}
+	if s.oneOf != nil && oneOf != 1 {
+		v.error(ev, pos, "oneOf", "value must be valid against exactly one schema of oneOf, but is valid against %d", oneOf)
+	}
+
+	// unevaluated properties and items, after all annotations are known
+	for _, sub := range ev.uneval {
+		if gt == json.StartObjectGrammar && ev.props[sub.key] || gt == json.StartArrayGrammar && (sub.index < ev.items || ev.itemSet[sub.index]) {
+			continue
+		}
+		ev.errs = append(ev.errs, sub.errs...)
+		if gt == json.StartObjectGrammar {
+			ev.annotateProperty(sub.key)
+		} else {
+			ev.annotateItem(sub.index)
+		}
+	}
+	ev.valid = len(ev.errs) == 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, t := range list {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
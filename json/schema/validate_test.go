@@ -0,0 +1,313 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/internal/testio"
+	"github.com/tdewolff/test"
+)
+
+func validate(t *testing.T, schema, doc string) []string {
+	t.Helper()
+	s, err := Compile([]byte(schema))
+	test.Error(t, err)
+	if err != nil {
+		return nil
+	}
+	violations, err := s.Validate(bytes.NewBufferString(doc))
+	test.Error(t, err)
+	locations := []string{}
+	for _, v := range violations {
+		locations = append(locations, v.Path.String()+" "+v.Keyword)
+	}
+	return locations
+}
+
+func TestValidate(t *testing.T) {
+	var validateTests = []struct {
+		schema   string
+		doc      string
+		expected []string // instance and keyword locations of the violations
+	}{
+		{`true`, `{"a": [1]}`, []string{}},
+		{`false`, `1`, []string{" "}},
+		{`{}`, `null`, []string{}},
+
+		// type, const, and enum
+		{`{"type": "string"}`, `"a"`, []string{}},
+		{`{"type": "string"}`, `1`, []string{" /type"}},
+		{`{"type": ["string", "null"]}`, `null`, []string{}},
+		{`{"type": "integer"}`, `1.0`, []string{}},
+		{`{"type": "integer"}`, `1e2`, []string{}},
+		{`{"type": "integer"}`, `1.5`, []string{" /type"}},
+		{`{"type": "number"}`, `1`, []string{}},
+		{`{"type": "object"}`, `[]`, []string{" /type"}},
+		{`{"type": "array"}`, `[]`, []string{}},
+		{`{"type": "boolean"}`, `false`, []string{}},
+		{`{"const": {"a": [1, "b"]}}`, `{"a": [1.0, "b"]}`, []string{}},
+		{`{"const": {"a": [1, "b"]}}`, `{"a": [1, "c"]}`, []string{" /const"}},
+		{`{"const": null}`, `false`, []string{" /const"}},
+		{`{"enum": [1, "a", [true]]}`, `[true]`, []string{}},
+		{`{"enum": [1, "a", [true]]}`, `"b"`, []string{" /enum"}},
+		{`{"enum": [1, "a", [true]]}`, `10e-1`, []string{}},
+
+		// numbers
+		{`{"multipleOf": 0.01}`, `0.07`, []string{}},
+		{`{"multipleOf": 0.01}`, `0.075`, []string{" /multipleOf"}},
+		{`{"multipleOf": 2}`, `1e3`, []string{}},
+		{`{"maximum": 3, "exclusiveMaximum": 3}`, `3`, []string{" /exclusiveMaximum"}},
+		{`{"maximum": 3}`, `3.0001`, []string{" /maximum"}},
+		{`{"minimum": -1, "exclusiveMinimum": -1}`, `-1`, []string{" /exclusiveMinimum"}},
+		{`{"minimum": -1}`, `-2`, []string{" /minimum"}},
+		{`{"minimum": 5}`, `"1"`, []string{}},
+
+		// strings
+		{`{"maxLength": 2}`, `"ab"`, []string{}},
+		{`{"maxLength": 2}`, `"abc"`, []string{" /maxLength"}},
+		{`{"maxLength": 2}`, `"éé"`, []string{}},
+		{`{"minLength": 2}`, `"é"`, []string{" /minLength"}},
+		{`{"pattern": "^a+$"}`, `"aaa"`, []string{}},
+		{`{"pattern": "b"}`, `"abc"`, []string{}},
+		{`{"pattern": "^a+$"}`, `"ab"`, []string{" /pattern"}},
+		{`{"pattern": "^a+$"}`, `1`, []string{}},
+
+		// arrays
+		{`{"items": {"type": "integer"}}`, `[1, "a", 2, null]`, []string{"/1 /items/type", "/3 /items/type"}},
+		{`{"prefixItems": [{"type": "string"}], "items": false}`, `["a"]`, []string{}},
+		{`{"prefixItems": [{"type": "string"}], "items": false}`, `[1, 2]`, []string{"/0 /prefixItems/0/type", "/1 /items"}},
+		{`{"maxItems": 1, "minItems": 1}`, `[]`, []string{" /minItems"}},
+		{`{"maxItems": 1, "minItems": 1}`, `[1, 2]`, []string{" /maxItems"}},
+		{`{"uniqueItems": true}`, `[1, "1", {"a": 1}, [1]]`, []string{}},
+		{`{"uniqueItems": true}`, `[{"a": 1, "b": 2}, {"b": 2, "a": 1.0}]`, []string{" /uniqueItems"}},
+		{`{"contains": {"const": 2}}`, `[1, 2, 3]`, []string{}},
+		{`{"contains": {"const": 2}}`, `[1, 3]`, []string{" /contains"}},
+		{`{"contains": {"type": "string"}, "minContains": 2, "maxContains": 3}`, `["a", 1, "b"]`, []string{}},
+		{`{"contains": {"type": "string"}, "minContains": 2}`, `["a", 1]`, []string{" /minContains"}},
+		{`{"contains": {"type": "string"}, "maxContains": 1}`, `["a", "b"]`, []string{" /maxContains"}},
+		{`{"contains": {"type": "string"}, "minContains": 0}`, `[]`, []string{}},
+		{`{"maxContains": 0}`, `[1]`, []string{}},
+
+		// objects
+		{`{"properties": {"a": {"type": "string"}, "b": true}}`, `{"a": 1, "b": 1, "c": 1}`, []string{"/a /properties/a/type"}},
+		{`{"properties": {"a/b": {"type": "string"}}}`, `{"a/b": 1}`, []string{"/a~1b /properties/a~1b/type"}},
+		{`{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`, `{"x-a": "1", "x-b": 2, "c": 3, "d": "4"}`, []string{"/x-b /patternProperties/^x-/type", "/d /additionalProperties/type"}},
+		{`{"properties": {"a": true}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, []string{"/b /additionalProperties"}},
+		{`{"required": ["a", "b"]}`, `{"a": null}`, []string{" /required"}},
+		{`{"required": ["a"]}`, `["a"]`, []string{}},
+		{`{"maxProperties": 1}`, `{"a": 1, "b": 2}`, []string{" /maxProperties"}},
+		{`{"minProperties": 1}`, `{}`, []string{" /minProperties"}},
+		{`{"propertyNames": {"maxLength": 2}}`, `{"ab": 1, "abc": 2}`, []string{" /propertyNames/maxLength"}},
+		{`{"dependentRequired": {"a": ["b"]}}`, `{"a": 1}`, []string{" /dependentRequired"}},
+		{`{"dependentRequired": {"a": ["b"]}}`, `{"c": 1}`, []string{}},
+		{`{"dependentSchemas": {"a": {"required": ["b"]}}}`, `{"a": 1}`, []string{" /dependentSchemas/a/required"}},
+		{`{"dependentSchemas": {"a": {"required": ["b"]}}}`, `{"c": 1}`, []string{}},
+		{`{"properties": {"a": {"properties": {"b": {"items": {"minimum": 0}}}}}}`, `{"a": {"b": [0, -1]}}`, []string{"/a/b/1 /properties/a/properties/b/items/minimum"}},
+
+		// applicators
+		{`{"allOf": [{"type": "integer"}, {"minimum": 2}]}`, `1.5`, []string{" /allOf/0/type", " /allOf/1/minimum"}},
+		{`{"anyOf": [{"type": "integer"}, {"minimum": 2}]}`, `1.5`, []string{" /anyOf"}},
+		{`{"anyOf": [{"type": "integer"}, {"minimum": 2}]}`, `2.5`, []string{}},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, `2.5`, []string{}},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, `3`, []string{" /oneOf"}},
+		{`{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, `1.5`, []string{" /oneOf"}},
+		{`{"not": {"type": "string"}}`, `"a"`, []string{" /not"}},
+		{`{"not": {"type": "string"}}`, `1`, []string{}},
+		{`{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"minimum": 2}}`, `"a"`, []string{" /then/minLength"}},
+		{`{"if": {"type": "string"}, "then": {"minLength": 2}, "else": {"minimum": 2}}`, `1`, []string{" /else/minimum"}},
+		{`{"if": {"type": "string"}, "then": {"minLength": 2}}`, `1`, []string{}},
+		{`{"then": {"minLength": 2}}`, `"a"`, []string{}},
+		{`{"items": {"anyOf": [{"type": "string"}, {"type": "array", "items": {"$ref": "#/items"}}]}}`, `["a", ["b", [1]]]`, []string{"/1 /items/anyOf"}},
+
+		// references
+		{`{"$defs": {"a": {"type": "string"}}, "properties": {"b": {"$ref": "#/$defs/a"}}}`, `{"b": 1}`, []string{"/b /$defs/a/type"}},
+		{`{"$defs": {"a": {"$anchor": "x", "type": "string"}}, "$ref": "#x"}`, `1`, []string{" /$defs/a/type"}},
+		{`{"$id": "http://example.com/root.json", "$defs": {"a": {"$id": "a.json", "$defs": {"b": {"type": "string"}}, "$ref": "#/$defs/b"}}, "$ref": "a.json"}`, `1`, []string{" /$defs/a/$defs/b/type"}},
+		{`{"$defs": {"a~b": {"type": "string"}}, "$ref": "#/$defs/a~0b"}`, `1`, []string{" /$defs/a~0b/type"}},
+		{`{"$defs": {"a b": {"type": "string"}}, "$ref": "#/$defs/a%20b"}`, `1`, []string{" /$defs/a b/type"}},
+		{`{"type": "object", "properties": {"next": {"$ref": "#"}}, "required": ["v"]}`, `{"v": 1, "next": {"v": 2, "next": {}}}`, []string{"/next/next /required"}},
+		{`{"$ref": "#"}`, `1`, []string{}},
+		{`{"$dynamicAnchor": "x", "$dynamicRef": "#x", "type": "object"}`, `{}`, []string{}},
+
+		// unevaluated
+		{`{"properties": {"a": true}, "allOf": [{"properties": {"b": true}}], "unevaluatedProperties": false}`, `{"a": 1, "b": 2, "c": 3}`, []string{"/c /unevaluatedProperties"}},
+		{`{"anyOf": [{"properties": {"a": true}, "required": ["a"]}, {"properties": {"b": true}, "required": ["b"]}], "unevaluatedProperties": false}`, `{"a": 1, "b": 2}`, []string{}},
+		{`{"anyOf": [{"properties": {"a": true}, "required": ["a"]}, {"properties": {"b": {"type": "string"}}}], "unevaluatedProperties": false}`, `{"a": 1, "b": 2}`, []string{"/b /unevaluatedProperties"}},
+		{`{"patternProperties": {"^a": true}, "unevaluatedProperties": {"type": "integer"}}`, `{"ab": "x", "b": "y"}`, []string{"/b /unevaluatedProperties/type"}},
+		{`{"$ref": "#/$defs/a", "$defs": {"a": {"properties": {"a": true}}}, "unevaluatedProperties": false}`, `{"a": 1}`, []string{}},
+		{`{"if": {"properties": {"a": {"const": 1}}}, "then": {"properties": {"b": true}}, "unevaluatedProperties": false}`, `{"a": 1, "b": 2}`, []string{}},
+		{`{"if": {"properties": {"a": {"const": 1}}}, "then": {"properties": {"b": true}}, "unevaluatedProperties": false}`, `{"a": 2, "b": 2}`, []string{"/a /unevaluatedProperties", "/b /unevaluatedProperties"}},
+		{`{"prefixItems": [true], "unevaluatedItems": false}`, `[1, 2]`, []string{"/1 /unevaluatedItems"}},
+		{`{"allOf": [{"prefixItems": [true, true]}], "unevaluatedItems": false}`, `[1, 2]`, []string{}},
+		{`{"contains": {"type": "string"}, "unevaluatedItems": {"type": "integer"}}`, `["a", 1, null]`, []string{"/2 /unevaluatedItems/type"}},
+		{`{"items": true, "unevaluatedItems": false}`, `[1, 2]`, []string{}},
+		{`{"not": {"not": {"properties": {"a": true}}}, "unevaluatedProperties": false}`, `{"a": 1}`, []string{"/a /unevaluatedProperties"}},
+	}
+	for _, tt := range validateTests {
+		t.Run(tt.schema+" "+tt.doc, func(t *testing.T) {
+			test.T(t, validate(t, tt.schema, tt.doc), tt.expected)
+		})
+	}
+}
+
+func TestValidateMessages(t *testing.T) {
+	var messageTests = []struct {
+		schema string
+		doc    string
+		err    string
+	}{
+		{`false`, `1`, "value is not allowed"},
+		{`{"type": ["string", "object"]}`, `2`, "expected string or object but got number"},
+		{`{"const": {"b": 1, "a": [true, null]}}`, `1`, `value must be {"a":[true,null],"b":1}`},
+		{`{"enum": ["a", 1.5]}`, `1`, `value must be one of ["a",1.5]`},
+		{`{"multipleOf": 0.5}`, `1.25`, "1.25 is not a multiple of 0.5"},
+		{`{"maximum": 1}`, `2`, "2 is greater than 1"},
+		{`{"exclusiveMaximum": 1}`, `1`, "1 is not less than 1"},
+		{`{"minimum": 1}`, `0`, "0 is less than 1"},
+		{`{"exclusiveMinimum": 1}`, `1`, "1 is not greater than 1"},
+		{`{"maxLength": 1}`, `"ab"`, "string is longer than 1 characters"},
+		{`{"minLength": 3}`, `"ab"`, "string is shorter than 3 characters"},
+		{`{"pattern": "^a"}`, `"b"`, "string does not match pattern '^a'"},
+		{`{"maxItems": 0}`, `[1]`, "array has more than 0 items"},
+		{`{"minItems": 2}`, `[1]`, "array has fewer than 2 items"},
+		{`{"uniqueItems": true}`, `[1, 2, 1]`, "items at index 0 and 2 are equal"},
+		{`{"contains": false}`, `[1]`, "array has no items matching contains"},
+		{`{"contains": true, "minContains": 2}`, `[1]`, "array has fewer than 2 items matching contains"},
+		{`{"contains": true, "maxContains": 0}`, `[1]`, "array has more than 0 items matching contains"},
+		{`{"prefixItems": [true], "items": false}`, `[1, 2]`, "additional item at index 1 is not allowed"},
+		{`{"unevaluatedItems": false}`, `[1]`, "unevaluated item at index 0 is not allowed"},
+		{`{"maxProperties": 0}`, `{"a": 1}`, "object has more than 0 properties"},
+		{`{"minProperties": 1}`, `{}`, "object has fewer than 1 properties"},
+		{`{"required": ["a"]}`, `{}`, "missing property 'a'"},
+		{`{"dependentRequired": {"a": ["b"]}}`, `{"a": 1}`, "missing property 'b' required by property 'a'"},
+		{`{"additionalProperties": false}`, `{"a": 1}`, "additional property 'a' is not allowed"},
+		{`{"unevaluatedProperties": false}`, `{"a": 1}`, "unevaluated property 'a' is not allowed"},
+		{`{"propertyNames": false}`, `{"a": 1}`, "property name 'a' is not allowed"},
+		{`{"anyOf": [false]}`, `1`, "value must be valid against at least one schema of anyOf"},
+		{`{"oneOf": [true, true]}`, `1`, "value must be valid against exactly one schema of oneOf, but is valid against 2"},
+		{`{"not": true}`, `1`, "value must not be valid against not"},
+	}
+	for _, tt := range messageTests {
+		t.Run(tt.schema+" "+tt.doc, func(t *testing.T) {
+			violations, err := MustCompile([]byte(tt.schema)).Validate(bytes.NewBufferString(tt.doc))
+			test.Error(t, err)
+			test.T(t, len(violations), 1)
+			if 0 < len(violations) {
+				test.String(t, violations[0].Err.Message, "JSON Schema validation error: "+tt.err)
+			}
+		})
+	}
+}
+
+func TestValidatePositions(t *testing.T) {
+	s := MustCompile([]byte(`{"properties": {"a": {"type": "string"}, "b": {"required": ["c"]}}, "propertyNames": {"maxLength": 1}}`))
+	doc := "{\n  \"a\": 1,\n  \"b\": {\n  },\n  \"cd\" : null\n}"
+	violations, err := s.Validate(bytes.NewBufferString(doc))
+	test.Error(t, err)
+	test.T(t, len(violations), 3)
+
+	// a stream that returns one byte at a time gives the same positions
+	streamViolations, err := s.Validate(iotest.OneByteReader(strings.NewReader(doc)))
+	test.Error(t, err)
+	test.T(t, len(streamViolations), 3)
+	for i := range streamViolations {
+		line, col, _ := streamViolations[i].Err.Position()
+		line2, col2, _ := violations[i].Err.Position()
+		test.T(t, line, line2, "line")
+		test.T(t, col, col2, "column")
+	}
+
+	var positionTests = []struct {
+		path      string
+		line, col int
+	}{
+		{"/a", 2, 8},
+		{"/b", 3, 8},
+		{"", 5, 3},
+	}
+	for i, tt := range positionTests {
+		t.Run(tt.path, func(t *testing.T) {
+			test.String(t, violations[i].Path.String(), tt.path)
+			line, col, _ := violations[i].Err.Position()
+			test.T(t, line, tt.line, "line")
+			test.T(t, col, tt.col, "column")
+		})
+	}
+	test.String(t, violations[0].Error(), "/a: JSON Schema validation error: expected string but got number on line 2 and column 8\n    2:   \"a\": 1,\n              ^")
+}
+
+func TestValidateStream(t *testing.T) {
+	s := MustCompile([]byte(`{"type": "array", "items": {"type": "integer"}, "minItems": 1000000}`))
+	r := io.MultiReader(bytes.NewBufferString("[\n"), testio.NewRepeatReader([]byte("1,\n"), 100000), bytes.NewBufferString(" \"x\"\n]"))
+	violations, err := s.Validate(r)
+	test.Error(t, err)
+	test.T(t, len(violations), 2)
+
+	// the array is reported at its start, the item at the end of the stream
+	line, col, context := violations[0].Err.Position()
+	test.String(t, violations[0].Err.Message, "JSON Schema validation error: array has fewer than 1000000 items")
+	test.T(t, line, 1, "line")
+	test.T(t, col, 1, "column")
+	test.String(t, context, "    1: [\n       ^")
+	line, col, _ = violations[1].Err.Position()
+	test.String(t, violations[1].Path.String(), "/100000")
+	test.T(t, line, 100002, "line")
+	test.T(t, col, 2, "column")
+}
+
+func TestValidateErrors(t *testing.T) {
+	s := MustCompile([]byte(`{"type": "array"}`))
+	var errorTests = []struct {
+		doc string
+		err string
+	}{
+		{``, "JSON parse error: unexpected end of input"},
+		{`[1, 2`, "JSON parse error: unexpected end of input"},
+		{`[1 2]`, "JSON parse error: expected comma character or an array or object ending"},
+		{`[] []`, "JSON parse error: expected comma character or an array or object ending"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.doc, func(t *testing.T) {
+			_, err := s.Validate(bytes.NewBufferString(tt.doc))
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, tt.err)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+
+	_, err := s.Validate(test.NewErrorReader(0))
+	test.T(t, err, test.ErrPlain)
+
+	test.That(t, s.IsValid([]byte(`[1]`)))
+	test.That(t, !s.IsValid([]byte(`{}`)))
+	test.That(t, !s.IsValid([]byte(`[`)))
+}
+
+func ExampleSchema_Validate() {
+	s := MustCompile([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer", "minimum": 0}
+		},
+		"required": ["name"]
+	}`))
+	violations, err := s.Validate(strings.NewReader(`{"age": -1}`))
+	if err != nil {
+		panic(err)
+	}
+	for _, v := range violations {
+		line, col, _ := v.Err.Position()
+		fmt.Printf("%s %s at %d:%d: %s\n", v.Path, v.Keyword, line, col, v.Err.Message)
+	}
+	// Output:
+	//  /required at 1:1: JSON Schema validation error: missing property 'name'
+	// /age /properties/age/minimum at 1:9: JSON Schema validation error: -1 is less than 0
+}
@@ -0,0 +1,241 @@
+package schema
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/json"
+)
+
+// Values of the schema and of the document are decoded into nil, bool, string, *big.Rat, []interface{}, or map[string]interface{}, so that numbers are compared exactly.
+
+// unquote returns the contents of a string token with its escape sequences replaced.
+func unquote(data []byte) string {
+	if bytes.IndexByte(data, '\\') == -1 {
+		return string(data[1 : len(data)-1])
+	}
+	s := ""
+	json.Unmarshal(data, &s)
+	return s
+}
+
+// parseNumber parses a number token, which is always a valid decimal.
+func parseNumber(data []byte) *big.Rat {
+	r, _ := new(big.Rat).SetString(string(data))
+	return r
+}
+
+// scalar returns the value of a string, number, or literal token.
+func scalar(gt json.GrammarType, data []byte) interface{} {
+	switch gt {
+	case json.StringGrammar:
+		return unquote(data)
+	case json.NumberGrammar:
+		return parseNumber(data)
+	case json.LiteralGrammar:
+		if data[0] == 'n' {
+			return nil
+		}
+		return data[0] == 't'
+	}
+	return nil
+}
+
+// reader parses JSON input that is kept in memory, so that errors can refer to any offset.
+type reader struct {
+	p          *json.Parser
+	b          []byte
+	offsets    map[string]int // offsets of decoded values by JSON Pointer, if not nil
+	keyOffsets map[string]int // offsets of the keys of decoded object members by JSON Pointer, if offsets is not nil
+}
+
+func newReader(b []byte) *reader {
+	return &reader{
+		p: json.NewParser(bytes.NewBuffer(b)),
+		b: b,
+	}
+}
+
+// start returns the offset of the value just returned by Next.
+func (r *reader) start(data []byte) int {
+	return r.p.Offset() - len(data)
+}
+
+func (r *reader) newError(offset int, message string, a ...interface{}) *parse.Error {
+	return parse.NewError(bytes.NewBuffer(r.b), offset, message, a...)
+}
+
+// syntaxError returns the parser's error, or an unexpected end of input error.
+func (r *reader) syntaxError() error {
+	if r.p.Err() != io.EOF {
+		return r.p.Err()
+	}
+	return r.newError(r.p.Offset(), "JSON parse error: unexpected end of input")
+}
+
+// decode decodes the value that starts with the given grammar, at the JSON Pointer ptr which is only used to record offsets.
+func (r *reader) decode(ptr string, gt json.GrammarType, data []byte) (interface{}, error) {
+	if r.offsets != nil {
+		r.offsets[ptr] = r.start(data)
+	}
+	switch gt {
+	case json.ErrorGrammar:
+		return nil, r.syntaxError()
+	case json.StartObjectGrammar:
+		m := map[string]interface{}{}
+		for {
+			prev := r.p.Offset()
+			gt, data = r.p.Next()
+			if gt == json.EndObjectGrammar {
+				return m, nil
+			} else if gt == json.ErrorGrammar {
+				return nil, r.syntaxError()
+			}
+			key := unquote(data)
+			if r.offsets != nil {
+				ptr += "/" + escapePointerToken(key)
+				r.keyOffsets[ptr] = prev + bytes.IndexByte(r.b[prev:], '"') // offset includes the colon
+			}
+			gt, data = r.p.Next()
+			v, err := r.decode(ptr, gt, data)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			if r.offsets != nil {
+				ptr = ptr[:strings.LastIndexByte(ptr, '/')]
+			}
+		}
+	case json.StartArrayGrammar:
+		a := []interface{}{}
+		for {
+			gt, data = r.p.Next()
+			if gt == json.EndArrayGrammar {
+				return a, nil
+			}
+			itemPtr := ptr
+			if r.offsets != nil {
+				itemPtr += "/" + strconv.Itoa(len(a))
+			}
+			v, err := r.decode(itemPtr, gt, data)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+		}
+	}
+	return scalar(gt, data), nil
+}
+
+// equal returns true if two values are equal as JSON values, where numbers are equal if their mathematical values are.
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case bool:
+		b, ok := b.(bool)
+		return ok && a == b
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case *big.Rat:
+		b, ok := b.(*big.Rat)
+		return ok && a.Cmp(b) == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, va := range a {
+			if vb, ok := b[k]; !ok || !equal(va, vb) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// appendValue appends the JSON representation of a value, with object keys sorted.
+func appendValue(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case string:
+		return strconv.AppendQuote(b, v)
+	case *big.Rat:
+		return append(b, formatNumber(v)...)
+	case []interface{}:
+		b = append(b, '[')
+		for i, item := range v {
+			if i != 0 {
+				b = append(b, ',')
+			}
+			b = appendValue(b, item)
+		}
+		return append(b, ']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = append(b, '{')
+		for i, k := range keys {
+			if i != 0 {
+				b = append(b, ',')
+			}
+			b = strconv.AppendQuote(b, k)
+			b = append(b, ':')
+			b = appendValue(b, v[k])
+		}
+		return append(b, '}')
+	}
+	return b
+}
+
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := r.Float64()
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// typeName returns the JSON Schema type of a value, where numbers with a zero fractional part are integers.
+func typeName(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case *big.Rat:
+		if v.IsInt() {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
@@ -0,0 +1,315 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Errors returned when converting the data of string and number grammars.
+var (
+	ErrSyntax = errors.New("json: invalid syntax")
+	ErrRange  = errors.New("json: value out of range")
+)
+
+var float64pow10 = []float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9,
+	1e10, 1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19,
+	1e20, 1e21, 1e22,
+}
+
+// Unescape appends the contents of the string token b to dst with its escape sequences replaced, and returns the extended buffer. It does not allocate if dst has enough capacity, which is at most len(b)-2 bytes.
+// It returns ErrSyntax if b is not a double-quoted JSON string, contains control characters or invalid escape sequences, surrogate escapes that are not paired, or invalid UTF-8.
+func Unescape(dst, b []byte) ([]byte, error) {
+	if len(b) < 2 || b[0] != '"' || b[len(b)-1] != '"' {
+		return dst, ErrSyntax
+	}
+	return unescapeString(dst, b[1:len(b)-1], false)
+}
+
+// unescapeLenient returns the contents of the string token b with its escape sequences replaced, like Unescape, but it replaces invalid escape sequences and unpaired surrogates by U+FFFD and keeps control characters and invalid UTF-8. It returns a subslice of b when there is nothing to replace.
+func unescapeLenient(b []byte) []byte {
+	if 2 <= len(b) && b[0] == '"' {
+		b = b[1 : len(b)-1]
+	}
+	if bytes.IndexByte(b, '\\') == -1 {
+		return b
+	}
+	dst, _ := unescapeString(make([]byte, 0, len(b)), b, true)
+	return dst
+}
+
+func unescapeString(dst, b []byte, lenient bool) ([]byte, error) {
+	start := 0 // start of unescaped run
+	for i := 0; i < len(b); {
+		c := b[i]
+		if !lenient && (c < 0x20 || c == '"') {
+			return dst, ErrSyntax
+		} else if utf8.RuneSelf <= c {
+			r, n := utf8.DecodeRune(b[i:])
+			if !lenient && r == utf8.RuneError && n == 1 {
+				return dst, ErrSyntax
+			}
+			i += n
+			continue
+		} else if c != '\\' {
+			i++
+			continue
+		}
+
+		dst = append(dst, b[start:i]...)
+		if i+1 == len(b) {
+			if lenient {
+				return append(dst, c), nil
+			}
+			return dst, ErrSyntax
+		}
+		switch b[i+1] {
+		case '"', '\\', '/':
+			dst = append(dst, b[i+1])
+		case 'b':
+			dst = append(dst, '\b')
+		case 'f':
+			dst = append(dst, '\f')
+		case 'n':
+			dst = append(dst, '\n')
+		case 'r':
+			dst = append(dst, '\r')
+		case 't':
+			dst = append(dst, '\t')
+		case 'u':
+			r, n := unescapeRune(b[i+2:])
+			if n == 0 {
+				if !lenient {
+					return dst, ErrSyntax
+				}
+				r = utf8.RuneError
+			}
+			i += n
+			if utf16.IsSurrogate(r) {
+				r2, n2 := rune(0), 0
+				if i+3 < len(b) && b[i+2] == '\\' && b[i+3] == 'u' {
+					r2, n2 = unescapeRune(b[i+4:])
+				}
+				if dec := utf16.DecodeRune(r, r2); n2 != 0 && dec != utf8.RuneError {
+					r = dec
+					i += 6
+				} else if !lenient {
+					return dst, ErrSyntax
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			dst = appendRune(dst, r)
+		default:
+			if !lenient {
+				return dst, ErrSyntax
+			}
+			dst = appendRune(dst, utf8.RuneError)
+		}
+		i += 2
+		start = i
+	}
+	return append(dst, b[start:]...), nil
+}
+
+func appendRune(b []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(b, buf[:n]...)
+}
+
+// unescapeRune parses the four hexadecimal digits of a \u escape.
+func unescapeRune(b []byte) (rune, int) {
+	if len(b) < 4 {
+		return 0, 0
+	}
+	r := rune(0)
+	for _, c := range b[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c -= 'a' - 10
+		case 'A' <= c && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, 0
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, 4
+}
+
+// ParseInt converts the number token b to an int64. It returns ErrSyntax if b is not an integer without a fraction or exponent, and ErrRange if it overflows.
+func ParseInt(b []byte) (int64, error) {
+	if !validInteger(b) {
+		return 0, ErrSyntax
+	}
+	neg := b[0] == '-'
+	if neg {
+		b = b[1:]
+	}
+	n, m := parseUint(b)
+	if m != len(b) || !neg && 1<<63-1 < n || neg && 1<<63 < n {
+		return 0, ErrRange
+	} else if neg {
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
+
+// ParseUint converts the number token b to an uint64. It returns ErrSyntax if b is not an integer without a fraction or exponent, and ErrRange if it overflows or is negative.
+func ParseUint(b []byte) (uint64, error) {
+	if !validInteger(b) {
+		return 0, ErrSyntax
+	} else if b[0] == '-' {
+		if len(b) == 2 && b[1] == '0' {
+			return 0, nil
+		}
+		return 0, ErrRange
+	}
+	n, m := parseUint(b)
+	if m != len(b) {
+		return 0, ErrRange
+	}
+	return n, nil
+}
+
+// ParseFloat converts the number token b to the nearest float64. It returns ErrSyntax if b is not a JSON number, and ErrRange if it overflows. Numbers of at most 15 significant digits and a small exponent are converted without allocating.
+func ParseFloat(b []byte) (float64, error) {
+	if !validNumber(b) {
+		return 0, ErrSyntax
+	}
+
+	// the conversion is exact when both the mantissa and the power of ten are exact floats
+	i := 0
+	neg := b[0] == '-'
+	if neg {
+		i++
+	}
+	mant, digits, exp := uint64(0), 0, 0
+	dot := false
+	for ; i < len(b) && b[i] != 'e' && b[i] != 'E'; i++ {
+		if b[i] == '.' {
+			dot = true
+			continue
+		} else if dot {
+			exp--
+		}
+		if c := b[i] - '0'; c != 0 || mant != 0 {
+			if digits++; digits <= 15 {
+				mant = mant*10 + uint64(c)
+			}
+		}
+	}
+	if i < len(b) {
+		i++
+		expNeg := b[i] == '-'
+		if b[i] == '-' || b[i] == '+' {
+			i++
+		}
+		e := 0
+		for ; i < len(b) && e < 1000; i++ {
+			e = e*10 + int(b[i]-'0')
+		}
+		if expNeg {
+			e = -e
+		}
+		exp += e
+	}
+	if 15 < digits || exp < -22 || 22 < exp {
+		f, err := strconv.ParseFloat(string(b), 64)
+		if err != nil {
+			return f, ErrRange // the syntax is valid
+		}
+		return f, nil
+	}
+	f := float64(mant)
+	if neg {
+		f = -f
+	}
+	if exp < 0 {
+		return f / float64pow10[-exp], nil
+	}
+	return f * float64pow10[exp], nil
+}
+
+// ParseBigInt converts the number token b to a big.Int. It returns ErrSyntax if b is not an integer without a fraction or exponent.
+func ParseBigInt(b []byte) (*big.Int, error) {
+	if !validInteger(b) {
+		return nil, ErrSyntax
+	}
+	i, _ := new(big.Int).SetString(string(b), 10)
+	return i, nil
+}
+
+// ParseBigFloat converts the number token b to a big.Float with precision prec, or 64 if prec is zero. It returns ErrSyntax if b is not a JSON number, and ErrRange if its exponent overflows.
+func ParseBigFloat(b []byte, prec uint) (*big.Float, error) {
+	if !validNumber(b) {
+		return nil, ErrSyntax
+	}
+	f, _, err := big.ParseFloat(string(b), 10, prec, big.ToNearestEven)
+	if err != nil {
+		return nil, ErrRange
+	}
+	return f, nil
+}
+
+// validInteger returns true if b is a JSON number without a fraction or exponent.
+func validInteger(b []byte) bool {
+	if 0 < len(b) && b[0] == '-' {
+		b = b[1:]
+	}
+	if len(b) == 0 || b[0] == '0' && 1 < len(b) {
+		return false
+	}
+	for _, c := range b {
+		if c < '0' || '9' < c {
+			return false
+		}
+	}
+	return true
+}
+
+// validNumber returns true if b follows the JSON number syntax.
+func validNumber(b []byte) bool {
+	i := 0
+	if i < len(b) && b[i] == '-' {
+		i++
+	}
+	if i < len(b) && b[i] == '0' {
+		i++
+	} else if i < len(b) && '1' <= b[i] && b[i] <= '9' {
+		for i < len(b) && '0' <= b[i] && b[i] <= '9' {
+			i++
+		}
+	} else {
+		return false
+	}
+	if i < len(b) && b[i] == '.' {
+		i++
+		if i == len(b) || b[i] < '0' || '9' < b[i] {
+			return false
+		}
+		for i < len(b) && '0' <= b[i] && b[i] <= '9' {
+			i++
+		}
+	}
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		i++
+		if i < len(b) && (b[i] == '+' || b[i] == '-') {
+			i++
+		}
+		if i == len(b) || b[i] < '0' || '9' < b[i] {
+			return false
+		}
+		for i < len(b) && '0' <= b[i] && b[i] <= '9' {
+			i++
+		}
+	}
+	return i == len(b)
+}
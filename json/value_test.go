@@ -0,0 +1,259 @@
+package json
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestUnescape(t *testing.T) {
+	var unescapeTests = []struct {
+		s        string
+		expected string
+	}{
+		{`""`, ""},
+		{`"abc"`, "abc"},
+		{`"é€😀"`, "\u00e9\u20ac\U0001F600"},
+		{`"\"\\\/\b\f\n\r\t"`, "\"\\/\b\f\n\r\t"},
+		{`"a\u00e9b"`, "a\u00e9b"},
+		{`"\u20AC\u20ac"`, "\u20ac\u20ac"},
+		{`"\ud83d\ude00"`, "\U0001F600"},
+		{`"x\uD83D\uDE00y\u0000"`, "x\U0001F600y\x00"},
+	}
+	for _, tt := range unescapeTests {
+		t.Run(tt.s, func(t *testing.T) {
+			s, err := Unescape(nil, []byte(tt.s))
+			test.Error(t, err)
+			test.String(t, string(s), tt.expected)
+		})
+	}
+
+	var errorTests = []string{
+		``,
+		`"`,
+		`'a'`,
+		`"a`,
+		`"a"b"`,
+		`"\"`,
+		`"\x41"`,
+		`"\u12"`,
+		`"\u12g4"`,
+		`"\ud83d"`,
+		`"\ud83dx"`,
+		`"\ud83dA"`,
+		`"\ude00"`,
+		`"\ude00\ud83d"`,
+		"\"\n\"",
+		"\"\x1f\"",
+		"\"\xff\"",
+		"\"\xe2\x82\"",
+		"\"\xed\xa0\x80\"", // encoded surrogate
+	}
+	for _, tt := range errorTests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := Unescape(nil, []byte(tt))
+			test.T(t, err, ErrSyntax)
+		})
+	}
+
+	dst := []byte("prefix ")
+	dst, err := Unescape(dst, []byte(`"A"`))
+	test.Error(t, err)
+	test.String(t, string(dst), "prefix A")
+}
+
+func TestUnescapeLenient(t *testing.T) {
+	var unescapeTests = []struct {
+		s        string
+		expected string
+	}{
+		{`"abc"`, "abc"},
+		{`abc`, "abc"},
+		{`"a\u00e9\n"`, "a\u00e9\n"},
+		{`"\ud83d\ude00"`, "\U0001F600"},
+		{`"\x41"`, "\ufffd41"},
+		{`"\u12g4"`, "\ufffd12g4"},
+		{`"\ud83dx"`, "\ufffdx"},
+		{`"\ude00\ud83d"`, "\ufffd\ufffd"},
+		{`"a\"`, "a\\"},
+		{"\"\n\xff\"", "\n\xff"},
+	}
+	for _, tt := range unescapeTests {
+		t.Run(tt.s, func(t *testing.T) {
+			test.String(t, string(unescapeLenient([]byte(tt.s))), tt.expected)
+		})
+	}
+}
+
+func TestUnescapeAllocs(t *testing.T) {
+	b := []byte(`"caf\u00e9 \ud83d\ude00 \"quoted\" and a newline\n"`)
+	buf := make([]byte, 0, len(b))
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = Unescape(buf[:0], b)
+	})
+	test.T(t, allocs, 0.0)
+}
+
+func TestParseInt(t *testing.T) {
+	var intTests = []struct {
+		s        string
+		expected int64
+		err      error
+	}{
+		{"0", 0, nil},
+		{"-0", 0, nil},
+		{"5", 5, nil},
+		{"-123", -123, nil},
+		{"9223372036854775807", math.MaxInt64, nil},
+		{"-9223372036854775808", math.MinInt64, nil},
+		{"9223372036854775808", 0, ErrRange},
+		{"-9223372036854775809", 0, ErrRange},
+		{"99999999999999999999999", 0, ErrRange},
+		{"", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{"+1", 0, ErrSyntax},
+		{"01", 0, ErrSyntax},
+		{"1.0", 0, ErrSyntax},
+		{"1e2", 0, ErrSyntax},
+		{"0x1F", 0, ErrSyntax},
+	}
+	for _, tt := range intTests {
+		t.Run(tt.s, func(t *testing.T) {
+			i, err := ParseInt([]byte(tt.s))
+			test.T(t, err, tt.err)
+			test.T(t, i, tt.expected)
+		})
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	var uintTests = []struct {
+		s        string
+		expected uint64
+		err      error
+	}{
+		{"0", 0, nil},
+		{"-0", 0, nil},
+		{"123", 123, nil},
+		{"18446744073709551615", math.MaxUint64, nil},
+		{"18446744073709551616", 0, ErrRange},
+		{"99999999999999999999999", 0, ErrRange},
+		{"-1", 0, ErrRange},
+		{"", 0, ErrSyntax},
+		{"1.5", 0, ErrSyntax},
+		{"007", 0, ErrSyntax},
+		{"1a", 0, ErrSyntax},
+	}
+	for _, tt := range uintTests {
+		t.Run(tt.s, func(t *testing.T) {
+			i, err := ParseUint([]byte(tt.s))
+			test.T(t, err, tt.err)
+			test.T(t, i, tt.expected)
+		})
+	}
+}
+
+func TestParseFloat(t *testing.T) {
+	var floatTests = []struct {
+		s        string
+		expected float64
+		err      error
+	}{
+		{"0", 0, nil},
+		{"1", 1, nil},
+		{"-2.5", -2.5, nil},
+		{"0.1", 0.1, nil},
+		{"0.000001", 0.000001, nil},
+		{"1e22", 1e22, nil},
+		{"1E+3", 1000, nil},
+		{"12.5e-3", 0.0125, nil},
+		{"123456789012345", 123456789012345, nil},
+		{"1234567890123456789", 1234567890123456789, nil},
+		{"0.1e-30", 1e-31, nil},
+		{"1.7976931348623157e308", math.MaxFloat64, nil},
+		{"5e-324", 5e-324, nil},
+		{"1e-400", 0, nil},
+		{"1e400", math.Inf(1), ErrRange},
+		{"-1e400", math.Inf(-1), ErrRange},
+		{"1e99999999999999999999", math.Inf(1), ErrRange},
+		{"", 0, ErrSyntax},
+		{".5", 0, ErrSyntax},
+		{"5.", 0, ErrSyntax},
+		{"1e", 0, ErrSyntax},
+		{"+1", 0, ErrSyntax},
+		{"Infinity", 0, ErrSyntax},
+		{"NaN", 0, ErrSyntax},
+	}
+	for _, tt := range floatTests {
+		t.Run(tt.s, func(t *testing.T) {
+			f, err := ParseFloat([]byte(tt.s))
+			test.T(t, err, tt.err)
+			test.T(t, f, tt.expected)
+		})
+	}
+
+	f, err := ParseFloat([]byte("-0"))
+	test.Error(t, err)
+	test.That(t, math.Signbit(f), "must be negative zero")
+
+	// the fast path must round like strconv
+	r := rand.New(rand.NewSource(0))
+	for i := 0; i < 10000; i++ {
+		s := strconv.FormatInt(r.Int63n(1e15), 10)
+		if n := r.Intn(len(s) + 1); n < len(s) {
+			s = s[:n] + "." + s[n:]
+			if n == 0 {
+				s = "0" + s
+			}
+		}
+		s += "e" + strconv.Itoa(r.Intn(40)-20)
+		f, err := ParseFloat([]byte(s))
+		test.Error(t, err)
+		expected, _ := strconv.ParseFloat(s, 64)
+		test.T(t, f, expected, s)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ParseFloat([]byte("-123.456e-7"))
+	})
+	test.T(t, allocs, 0.0)
+}
+
+func TestParseBig(t *testing.T) {
+	i, err := ParseBigInt([]byte("-123456789012345678901234567890"))
+	test.Error(t, err)
+	test.String(t, i.String(), "-123456789012345678901234567890")
+	_, err = ParseBigInt([]byte("1e30"))
+	test.T(t, err, ErrSyntax)
+
+	f, err := ParseBigFloat([]byte("1.5e1000"), 0)
+	test.Error(t, err)
+	test.String(t, f.Text('g', 10), "1.5e+1000")
+	test.T(t, f.Prec(), uint(64))
+	f, err = ParseBigFloat([]byte("0.1"), 200)
+	test.Error(t, err)
+	test.T(t, f.Prec(), uint(200))
+	_, err = ParseBigFloat([]byte("1e9999999999"), 0)
+	test.T(t, err, ErrRange)
+	_, err = ParseBigFloat([]byte("0x1"), 0)
+	test.T(t, err, ErrSyntax)
+}
+
+////////////////////////////////////////////////////////////////
+
+func ExampleUnescape() {
+	buf := make([]byte, 0, 64)
+	for _, s := range []string{`"caf\u00e9"`, `"line\nbreak"`, `"\ud83d"`} {
+		var err error
+		buf, err = Unescape(buf[:0], []byte(s))
+		fmt.Printf("%q %v\n", buf, err)
+	}
+	// Output:
+	// "café" <nil>
+	// "line\nbreak" <nil>
+	// "" json: invalid syntax
+}
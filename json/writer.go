@@ -0,0 +1,395 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Format determines the output format of the writer.
+type Format uint32
+
+// Format values.
+const (
+	CompactFormat   Format = iota // without whitespace
+	IndentFormat                  // one value per line, indented by nesting level
+	CanonicalFormat               // JSON Canonicalization Scheme (RFC 8785)
+)
+
+// String returns the string representation of a Format.
+func (format Format) String() string {
+	switch format {
+	case CompactFormat:
+		return "Compact"
+	case IndentFormat:
+		return "Indent"
+	case CanonicalFormat:
+		return "Canonical"
+	}
+	return "Invalid(" + strconv.Itoa(int(format)) + ")"
+}
+
+// ErrIncomplete is returned by Close when no complete top-level value has been written, or when an object or array has not been closed.
+var ErrIncomplete = errors.New("json: incomplete document")
+
+const writerBufferSize = 4096
+
+// Writer writes the grammars returned by Parser as JSON to an io.Writer. It validates the nesting of objects and arrays, inserts commas and colons, and validates the data of literals, numbers, and strings.
+// Output is buffered, and Close must be called after the last grammar to flush the output.
+type Writer struct {
+	w      io.Writer
+	format Format
+	indent string
+	buf    []byte
+	state  []State
+	n      []int // number of values or members written at each level
+	docs   int   // number of documents ended by EndDocumentGrammar
+	err    error
+
+	objects []canonicalObject // open objects in CanonicalFormat, whose members are sorted when closed
+	tmp     []byte
+}
+
+type canonicalObject struct {
+	start   int // position of the left brace in buf
+	members []canonicalMember
+}
+
+type canonicalMember struct {
+	key        []byte // unescaped
+	start, end int    // position in buf
+}
+
+// NewWriter returns a new Writer for a given io.Writer, which writes in CompactFormat.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w:      w,
+		indent: "\t",
+		state:  []State{ValueState},
+		n:      []int{0},
+	}
+}
+
+// SetFormat sets the output format. It must be called before the first call to Write.
+func (w *Writer) SetFormat(format Format) {
+	w.format = format
+}
+
+// SetIndent sets the string used for each level of indentation in IndentFormat, which is a tab by default.
+func (w *Writer) SetIndent(indent string) {
+	w.indent = indent
+}
+
+// State returns the state the writer is currently in (ie. which grammar is expected).
+func (w *Writer) State() State {
+	return w.state[len(w.state)-1]
+}
+
+// Write writes a grammar with its data as returned by Parser. String data must include the quotes and number data must follow the JSON number syntax. The data of objects and arrays is ignored, as are WhitespaceGrammar and CommentGrammar. EndDocumentGrammar writes a newline after a top-level value, after which another top-level value may be written, as in NDJSON.
+// It returns an error if the grammar is not expected in the current state or if the data is invalid, after which the writer cannot be used anymore.
+func (w *Writer) Write(gt GrammarType, data []byte) error {
+	if w.err != nil {
+		return w.err
+	} else if gt == WhitespaceGrammar || gt == CommentGrammar {
+		return nil
+	}
+
+	state := w.state[len(w.state)-1]
+	switch gt {
+	case EndObjectGrammar:
+		if state != ObjectKeyState {
+			return w.fail("json: unexpected %s in %s state", gt, state)
+		}
+		if err := w.end('}'); err != nil {
+			return err
+		}
+		return w.flush()
+	case EndArrayGrammar:
+		if state != ArrayState {
+			return w.fail("json: unexpected %s in %s state", gt, state)
+		}
+		if err := w.end(']'); err != nil {
+			return err
+		}
+		return w.flush()
+	case EndDocumentGrammar:
+		if len(w.state) != 1 || w.n[0] == 0 {
+			return w.fail("json: unexpected %s in %s state", gt, state)
+		}
+		w.buf = append(w.buf, '\n')
+		w.n[0] = 0
+		w.docs++
+		return w.flush()
+	}
+
+	if state == ObjectKeyState {
+		if gt != StringGrammar {
+			return w.fail("json: expected object key but got %s", gt)
+		}
+		key, err := Unescape(w.tmp[:0], data)
+		if err != nil {
+			return w.fail("json: invalid string %q", data)
+		}
+		w.separate()
+		if w.format == CanonicalFormat {
+			obj := &w.objects[len(w.objects)-1]
+			key = append([]byte{}, key...)
+			obj.members = append(obj.members, canonicalMember{key: key, start: len(w.buf)})
+			w.buf = appendCanonicalString(w.buf, key)
+		} else {
+			w.buf = append(w.buf, data...)
+		}
+		w.buf = append(w.buf, ':')
+		if w.format == IndentFormat {
+			w.buf = append(w.buf, ' ')
+		}
+		w.state[len(w.state)-1] = ObjectValueState
+		return nil
+	} else if state == ValueState && w.n[0] != 0 {
+		return w.fail("json: unexpected %s after top-level value", gt)
+	}
+
+	switch gt {
+	case StartObjectGrammar, StartArrayGrammar:
+		w.separate()
+		if gt == StartObjectGrammar {
+			if w.format == CanonicalFormat {
+				w.objects = append(w.objects, canonicalObject{start: len(w.buf)})
+			}
+			w.buf = append(w.buf, '{')
+			w.state = append(w.state, ObjectKeyState)
+		} else {
+			w.buf = append(w.buf, '[')
+			w.state = append(w.state, ArrayState)
+		}
+		w.n = append(w.n, 0)
+		return nil
+	case LiteralGrammar:
+		if s := string(data); s != "true" && s != "false" && s != "null" {
+			return w.fail("json: invalid literal %q", data)
+		}
+		w.separate()
+		w.buf = append(w.buf, data...)
+	case NumberGrammar:
+		if !validNumber(data) {
+			return w.fail("json: invalid number %q", data)
+		}
+		w.separate()
+		if w.format == CanonicalFormat {
+			f, err := strconv.ParseFloat(string(data), 64)
+			if err != nil {
+				return w.fail("json: number %s is out of range", data)
+			}
+			w.buf = appendCanonicalNumber(w.buf, f)
+		} else {
+			w.buf = append(w.buf, data...)
+		}
+	case StringGrammar:
+		var err error
+		if w.tmp, err = Unescape(w.tmp[:0], data); err != nil {
+			return w.fail("json: invalid string %q", data)
+		}
+		w.separate()
+		if w.format == CanonicalFormat {
+			w.buf = appendCanonicalString(w.buf, w.tmp)
+		} else {
+			w.buf = append(w.buf, data...)
+		}
+	default:
+		return w.fail("json: unexpected %s", gt)
+	}
+	w.endValue()
+	return w.flush()
+}
+
+// Close checks that a complete top-level value has been written and flushes the output. It does not close the underlying io.Writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	} else if len(w.state) != 1 || w.n[0] == 0 && w.docs == 0 {
+		w.err = ErrIncomplete
+		return w.err
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		w.err = err
+		return err
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+func (w *Writer) fail(message string, a ...interface{}) error {
+	w.err = fmt.Errorf(message, a...)
+	return w.err
+}
+
+// flush writes the buffer when it is full, except for open objects in CanonicalFormat that still need to be sorted.
+func (w *Writer) flush() error {
+	if writerBufferSize <= len(w.buf) && len(w.objects) == 0 {
+		if _, err := w.w.Write(w.buf); err != nil {
+			w.err = err
+			return err
+		}
+		w.buf = w.buf[:0]
+	}
+	return nil
+}
+
+// separate writes the comma and indentation before a value or object member.
+func (w *Writer) separate() {
+	level := len(w.state) - 1
+	if level == 0 || w.state[level] == ObjectValueState {
+		return
+	}
+	if w.n[level] != 0 && (w.format != CanonicalFormat || w.state[level] != ObjectKeyState) {
+		w.buf = append(w.buf, ',') // members of canonical objects are joined when sorted
+	}
+	if w.format == IndentFormat {
+		w.newline(level)
+	}
+}
+
+func (w *Writer) newline(level int) {
+	w.buf = append(w.buf, '\n')
+	for i := 0; i < level; i++ {
+		w.buf = append(w.buf, w.indent...)
+	}
+}
+
+// endValue updates the state after a value has been written.
+func (w *Writer) endValue() {
+	level := len(w.state) - 1
+	if w.state[level] == ObjectValueState {
+		w.state[level] = ObjectKeyState
+	}
+	w.n[level]++
+}
+
+// end writes the end of an object or array.
+func (w *Writer) end(c byte) error {
+	level := len(w.state) - 1
+	if c == '}' && w.format == CanonicalFormat {
+		if err := w.sortObject(); err != nil {
+			return err
+		}
+	} else {
+		if w.format == IndentFormat && w.n[level] != 0 {
+			w.newline(level - 1)
+		}
+		w.buf = append(w.buf, c)
+	}
+	w.state = w.state[:level]
+	w.n = w.n[:level]
+	w.endValue()
+	return nil
+}
+
+// sortObject replaces the members of the innermost canonical object by its members sorted by key, and closes the object. Duplicate keys are not allowed.
+func (w *Writer) sortObject() error {
+	obj := w.objects[len(w.objects)-1]
+	w.objects = w.objects[:len(w.objects)-1]
+
+	members := obj.members
+	for i := range members {
+		if i+1 < len(members) {
+			members[i].end = members[i+1].start
+		} else {
+			members[i].end = len(w.buf)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return lessUTF16(members[i].key, members[j].key)
+	})
+
+	w.tmp = append(w.tmp[:0], '{')
+	for i, m := range members {
+		if i != 0 {
+			if bytes.Equal(members[i-1].key, m.key) {
+				return w.fail("json: duplicate object key %q", m.key)
+			}
+			w.tmp = append(w.tmp, ',')
+		}
+		w.tmp = append(w.tmp, w.buf[m.start:m.end]...)
+	}
+	w.tmp = append(w.tmp, '}')
+	w.buf = append(w.buf[:obj.start], w.tmp...)
+	return nil
+}
+
+////////////////////////////////////////////////////////////////
+
+// appendCanonicalString appends the string s as serialized by ECMAScript's JSON.stringify.
+func appendCanonicalString(b []byte, s []byte) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for _, c := range s {
+		switch c {
+		case '"', '\\':
+			b = append(b, '\\', c)
+		case '\b':
+			b = append(b, '\\', 'b')
+		case '\f':
+			b = append(b, '\\', 'f')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			if c < 0x20 {
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			} else {
+				b = append(b, c)
+			}
+		}
+	}
+	return append(b, '"')
+}
+
+// appendCanonicalNumber appends the number f as serialized by ECMAScript's Number.prototype.toString.
+func appendCanonicalNumber(b []byte, f float64) []byte {
+	if f == 0 {
+		return append(b, '0') // also for negative zero
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || 1e21 <= abs {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-07 to e-7
+		if n := len(b); 4 <= n && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+// lessUTF16 returns true if a sorts before b when comparing their UTF-16 code units.
+func lessUTF16(a, b []byte) bool {
+	for 0 < len(a) && 0 < len(b) {
+		ra, na := utf8.DecodeRune(a)
+		rb, nb := utf8.DecodeRune(b)
+		if ra != rb {
+			return utf16Units(ra) < utf16Units(rb)
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) < len(b)
+}
+
+// utf16Units returns the UTF-16 code units of r as a single comparable value.
+func utf16Units(r rune) uint32 {
+	if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+		return uint32(r1)<<16 | uint32(r2)
+	}
+	return uint32(r) << 16
+}
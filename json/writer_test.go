@@ -0,0 +1,291 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+// rewrite writes the grammars parsed from s with a Writer in the given format.
+func rewrite(s string, format Format) (string, error) {
+	p := NewParser(bytes.NewBufferString(s))
+	p.SetMode(JSON5Mode)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(format)
+	for {
+		gt, data := p.Next()
+		if gt == ErrorGrammar {
+			break
+		} else if err := w.Write(gt, data); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func TestWriter(t *testing.T) {
+	var writerTests = []struct {
+		json     string
+		expected string
+	}{
+		{"null", "null"},
+		{` "a\u0062" `, `"a\u0062"`},
+		{"-1.5e+3", "-1.5e+3"},
+		{"[]", "[]"},
+		{"{}", "{}"},
+		{"[1, [2, {}], [], 3]", "[1,[2,{}],[],3]"},
+		{`{"b": 1, "a": {"c": [true, false]}, "d": null}`, `{"b":1,"a":{"c":[true,false]},"d":null}`},
+		{"// comment\n{\"a\": /* b */ 1,}", `{"a":1}`},
+	}
+	for _, tt := range writerTests {
+		t.Run(tt.json, func(t *testing.T) {
+			s, err := rewrite(tt.json, CompactFormat)
+			test.Error(t, err)
+			test.String(t, s, tt.expected)
+		})
+	}
+
+	// coverage
+	for i := 0; ; i++ {
+		if Format(i).String() == fmt.Sprintf("Invalid(%d)", i) {
+			break
+		}
+	}
+}
+
+func TestWriterMultiDocument(t *testing.T) {
+	p := NewParser(bytes.NewBufferString("{\"b\": 1, \"a\": [2]}\n\n 3 \"c\"\n"))
+	p.SetMode(MultiDocumentMode)
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(CanonicalFormat)
+	for gt, data := p.Next(); gt != ErrorGrammar; gt, data = p.Next() {
+		test.Error(t, w.Write(gt, data))
+	}
+	test.Error(t, w.Close())
+	test.String(t, buf.String(), "{\"a\":[2],\"b\":1}\n3\n\"c\"\n")
+
+	w = NewWriter(&bytes.Buffer{})
+	test.T(t, w.Write(EndDocumentGrammar, nil).Error(), "json: unexpected EndDocument in Value state")
+	w = NewWriter(&bytes.Buffer{})
+	w.Write(StartArrayGrammar, nil)
+	test.T(t, w.Write(EndDocumentGrammar, nil).Error(), "json: unexpected EndDocument in Array state")
+	w = NewWriter(&bytes.Buffer{})
+	w.Write(NumberGrammar, []byte("1"))
+	w.Write(EndDocumentGrammar, nil)
+	w.Write(StartArrayGrammar, nil)
+	test.T(t, w.Close(), ErrIncomplete)
+}
+
+func TestWriterIndent(t *testing.T) {
+	var indentTests = []struct {
+		json     string
+		expected string
+	}{
+		{"1", "1"},
+		{"[]", "[]"},
+		{"[1]", "[\n\t1\n]"},
+		{`{"a": 1, "b": [2, {}, {"c": []}]}`, "{\n\t\"a\": 1,\n\t\"b\": [\n\t\t2,\n\t\t{},\n\t\t{\n\t\t\t\"c\": []\n\t\t}\n\t]\n}"},
+	}
+	for _, tt := range indentTests {
+		t.Run(tt.json, func(t *testing.T) {
+			s, err := rewrite(tt.json, IndentFormat)
+			test.Error(t, err)
+			test.String(t, s, tt.expected)
+		})
+	}
+
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(IndentFormat)
+	w.SetIndent("  ")
+	w.Write(StartArrayGrammar, nil)
+	w.Write(NumberGrammar, []byte("1"))
+	w.Write(EndArrayGrammar, nil)
+	test.Error(t, w.Close())
+	test.String(t, buf.String(), "[\n  1\n]")
+}
+
+func TestWriterCanonical(t *testing.T) {
+	var canonicalTests = []struct {
+		json     string
+		expected string
+	}{
+		// examples from RFC 8785
+		{`{
+			"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			"literals": [null, true, false]
+		}`, `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"` + "\u20ac" + `$\u000f\nA'B\"\\\\\"/"}`},
+		{`{"\u20ac": "Euro Sign", "\r": "Carriage Return", "\ufb33": "Hebrew Letter Dalet With Dagesh", "1": "One", "\ud83d\ude00": "Emoji: Grinning Face", "\u0080": "Control", "\u00f6": "Latin Small Letter O With Diaeresis"}`,
+			`{"\r":"Carriage Return","1":"One","` + "\u0080" + `":"Control","` + "\u00f6" + `":"Latin Small Letter O With Diaeresis","` + "\u20ac" + `":"Euro Sign","` + "\U0001F600" + `":"Emoji: Grinning Face","` + "\ufb33" + `":"Hebrew Letter Dalet With Dagesh"}`},
+
+		{`{"b": [{"d": 1, "c": 2}], "a": {"f": {}, "e": []}}`, `{"a":{"e":[],"f":{}},"b":[{"c":2,"d":1}]}`},
+		{`{"aa": 1, "a": 2, "": 3}`, `{"":3,"a":2,"aa":1}`},
+		{`[-0, 1.0, 1e2, 0.1e1, "\t\b\f\u001f\u007f"]`, `[0,1,100,1,"\t\b\f\u001f` + "\u007f" + `"]`},
+	}
+	for _, tt := range canonicalTests {
+		t.Run(tt.json, func(t *testing.T) {
+			s, err := rewrite(tt.json, CanonicalFormat)
+			test.Error(t, err)
+			test.String(t, s, tt.expected)
+		})
+	}
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	// examples from RFC 8785 appendix B
+	var numberTests = []struct {
+		bits     uint64
+		expected string
+	}{
+		{0x0000000000000000, "0"},
+		{0x8000000000000000, "0"},
+		{0x0000000000000001, "5e-324"},
+		{0x8000000000000001, "-5e-324"},
+		{0x7fefffffffffffff, "1.7976931348623157e+308"},
+		{0xffefffffffffffff, "-1.7976931348623157e+308"},
+		{0x4340000000000000, "9007199254740992"},
+		{0xc340000000000000, "-9007199254740992"},
+		{0x4430000000000000, "295147905179352830000"},
+		{0x44b52d02c7e14af5, "9.999999999999997e+22"},
+		{0x44b52d02c7e14af6, "1e+23"},
+		{0x44b52d02c7e14af7, "1.0000000000000001e+23"},
+		{0x444b1ae4d6e2ef4e, "999999999999999700000"},
+		{0x444b1ae4d6e2ef4f, "999999999999999900000"},
+		{0x444b1ae4d6e2ef50, "1e+21"},
+		{0x3eb0c6f7a0b5ed8c, "9.999999999999997e-7"},
+		{0x3eb0c6f7a0b5ed8d, "0.000001"},
+		{0x41b3de4355555553, "333333333.3333332"},
+		{0x41b3de4355555554, "333333333.33333325"},
+		{0x41b3de4355555555, "333333333.3333333"},
+		{0x41b3de4355555556, "333333333.3333334"},
+		{0x41b3de4355555557, "333333333.33333343"},
+		{0xbecbf647612f3696, "-0.0000033333333333333333"},
+		{0x43143ff3c1cb0959, "1424953923781206.2"},
+	}
+	for _, tt := range numberTests {
+		t.Run(tt.expected, func(t *testing.T) {
+			test.String(t, string(appendCanonicalNumber(nil, math.Float64frombits(tt.bits))), tt.expected)
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	type G struct {
+		gt   GrammarType
+		data string
+	}
+	var errorTests = []struct {
+		grammars []G
+		err      string
+	}{
+		{[]G{{EndObjectGrammar, "}"}}, "json: unexpected EndObject in Value state"},
+		{[]G{{StartArrayGrammar, "["}, {EndObjectGrammar, "}"}}, "json: unexpected EndObject in Array state"},
+		{[]G{{StartObjectGrammar, "{"}, {EndArrayGrammar, "]"}}, "json: unexpected EndArray in ObjectKey state"},
+		{[]G{{StartObjectGrammar, "{"}, {StringGrammar, `"a"`}, {EndObjectGrammar, "}"}}, "json: unexpected EndObject in ObjectValue state"},
+		{[]G{{StartObjectGrammar, "{"}, {NumberGrammar, "1"}}, "json: expected object key but got Number"},
+		{[]G{{NumberGrammar, "1"}, {NumberGrammar, "2"}}, "json: unexpected Number after top-level value"},
+		{[]G{{ErrorGrammar, ""}}, "json: unexpected Error"},
+		{[]G{{LiteralGrammar, "nul"}}, `json: invalid literal "nul"`},
+		{[]G{{NumberGrammar, "0x1F"}}, `json: invalid number "0x1F"`},
+		{[]G{{NumberGrammar, "01"}}, `json: invalid number "01"`},
+		{[]G{{NumberGrammar, "1."}}, `json: invalid number "1."`},
+		{[]G{{NumberGrammar, "1e"}}, `json: invalid number "1e"`},
+		{[]G{{NumberGrammar, "-"}}, `json: invalid number "-"`},
+		{[]G{{StringGrammar, "'a'"}}, `json: invalid string "'a'"`},
+		{[]G{{StringGrammar, `"a"b"`}}, `json: invalid string "\"a\"b\""`},
+		{[]G{{StringGrammar, `"\"`}}, `json: invalid string "\"\\\""`},
+		{[]G{{StringGrammar, `"\x"`}}, `json: invalid string "\"\\x\""`},
+		{[]G{{StringGrammar, `"\u12"`}}, `json: invalid string "\"\\u12\""`},
+		{[]G{{StringGrammar, "\"\n\""}}, `json: invalid string "\"\n\""`},
+		{[]G{{StringGrammar, "\"\xff\""}}, `json: invalid string "\"\xff\""`},
+		{[]G{{StringGrammar, `"\ud800"`}}, `json: invalid string "\"\\ud800\""`},
+		{[]G{{StartObjectGrammar, "{"}, {StringGrammar, "a"}}, `json: invalid string "a"`},
+		{[]G{{StartArrayGrammar, "["}}, "json: incomplete document"},
+		{[]G{}, "json: incomplete document"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.err, func(t *testing.T) {
+			w := NewWriter(&bytes.Buffer{})
+			var err error
+			for _, g := range tt.grammars {
+				if err = w.Write(g.gt, []byte(g.data)); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = w.Close()
+			}
+			test.T(t, err.Error(), tt.err)
+			test.T(t, w.Write(NumberGrammar, []byte("1")), err, "error must persist")
+		})
+	}
+
+	_, err := rewrite(`{"a": 1, "b": {"c": 2, "c": 3}}`, CompactFormat)
+	test.Error(t, err)
+	_, err = rewrite(`{"a": 1, "b": {"c": 2, "\u0063": 3}}`, CanonicalFormat)
+	test.T(t, err.Error(), `json: duplicate object key "c"`)
+	_, err = rewrite(`1e999`, CanonicalFormat)
+	test.T(t, err.Error(), "json: number 1e999 is out of range")
+}
+
+func TestWriterFlush(t *testing.T) {
+	var n int
+	s := "[" + strings.Repeat(`{"b": 1, "a": "`+strings.Repeat("x", 100)+`"},`, 100) + "null]"
+	for _, format := range []Format{CompactFormat, CanonicalFormat} {
+		buf := &bytes.Buffer{}
+		w := NewWriter(buf)
+		w.SetFormat(format)
+		p := NewParser(bytes.NewBufferString(s))
+		for gt, data := p.Next(); gt != ErrorGrammar; gt, data = p.Next() {
+			test.Error(t, w.Write(gt, data))
+		}
+		test.That(t, writerBufferSize <= buf.Len(), "output must be flushed before Close")
+		n = buf.Len()
+		test.Error(t, w.Close())
+		test.That(t, n < buf.Len())
+	}
+
+	w := NewWriter(test.NewErrorWriter(0))
+	err := w.Write(StartArrayGrammar, nil)
+	for i := 0; err == nil && i < writerBufferSize; i++ {
+		err = w.Write(StringGrammar, []byte(`"a"`))
+	}
+	test.T(t, err, test.ErrPlain)
+
+	w = NewWriter(test.NewErrorWriter(0))
+	test.Error(t, w.Write(LiteralGrammar, []byte("null")))
+	test.T(t, w.Close(), test.ErrPlain)
+}
+
+////////////////////////////////////////////////////////////////
+
+func ExampleWriter() {
+	p := NewParser(bytes.NewBufferString(`{"b": [1, 2.50], "a": "\u00e9"}`))
+	buf := &bytes.Buffer{}
+	w := NewWriter(buf)
+	w.SetFormat(CanonicalFormat)
+	for {
+		gt, data := p.Next()
+		if gt == ErrorGrammar {
+			break
+		}
+		if err := w.Write(gt, data); err != nil {
+			panic(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	fmt.Println(buf.String())
+	// Output: {"a":"é","b":[1,2.5]}
+}
@@ -0,0 +1,172 @@
+package sourcemap
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+type offsetMapping struct {
+	genOffset  int
+	source     int
+	origOffset int
+	name       int
+}
+
+// Generator builds a source map from byte offsets, such as the offsets of the tokens returned by a lexer in the source and the length of a buffer.Writer in the generated file.
+// Offsets are converted into lines and columns when the source map is created.
+type Generator struct {
+	// SourcesContent includes the contents of the sources in the source map.
+	SourcesContent bool
+
+	file     string
+	sources  []string
+	contents []*positioner
+	names    []string
+	nameMap  map[string]int
+	mappings []offsetMapping
+}
+
+// NewGenerator returns a new Generator for the generated file with the given name.
+func NewGenerator(file string) *Generator {
+	return &Generator{
+		file:    file,
+		nameMap: map[string]int{},
+	}
+}
+
+// AddSource adds a source file with the given name and contents, and returns its index to be passed to Add.
+func (g *Generator) AddSource(name string, content []byte) int {
+	g.sources = append(g.sources, name)
+	g.contents = append(g.contents, newPositioner(content))
+	return len(g.sources) - 1
+}
+
+// Add maps the offset in the generated file to the offset in the source with the given index.
+// Mappings are best added at the start of each token that is copied from the source. Use a negative source index to mark the start of generated content that has no source.
+func (g *Generator) Add(genOffset, source, origOffset int) {
+	g.add(genOffset, source, origOffset, -1)
+}
+
+// AddName is like Add, but it also records the original name of an identifier, for example when the identifier was renamed in the generated file.
+func (g *Generator) AddName(genOffset, source, origOffset int, name string) {
+	i, ok := g.nameMap[name]
+	if !ok {
+		i = len(g.names)
+		g.nameMap[name] = i
+		g.names = append(g.names, name)
+	}
+	g.add(genOffset, source, origOffset, i)
+}
+
+func (g *Generator) add(genOffset, source, origOffset, name int) {
+	if source < 0 {
+		source, origOffset, name = -1, 0, -1
+	}
+	if n := len(g.mappings); 0 < n {
+		if last := g.mappings[n-1]; last.genOffset == genOffset {
+			g.mappings = g.mappings[:n-1] // a later mapping overrides an earlier one at the same position
+		} else if last.source == source && last.source == -1 {
+			return // consecutive unmapped segments
+		}
+	}
+	g.mappings = append(g.mappings, offsetMapping{genOffset, source, origOffset, name})
+}
+
+// SourceMap returns the source map for the generated file with contents out, which are used to convert the generated offsets into lines and columns.
+func (g *Generator) SourceMap(out []byte) *SourceMap {
+	sm := &SourceMap{
+		File:    g.file,
+		Sources: append([]string{}, g.sources...),
+		Names:   append([]string{}, g.names...),
+	}
+	if g.SourcesContent {
+		sm.SourcesContent = make([]*string, len(g.sources))
+		for i, p := range g.contents {
+			content := string(p.b)
+			sm.SourcesContent[i] = &content
+		}
+	}
+
+	mappings := append([]offsetMapping{}, g.mappings...)
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return mappings[i].genOffset < mappings[j].genOffset
+	})
+	gen := newPositioner(out)
+	sm.Mappings = make([]Mapping, 0, len(mappings))
+	for _, m := range mappings {
+		genLine, genCol := gen.position(m.genOffset)
+		mapping := Mapping{genLine, genCol, -1, 0, 0, -1}
+		if m.source != -1 {
+			mapping.Source, mapping.Name = m.source, m.name
+			mapping.OrigLine, mapping.OrigCol = g.contents[m.source].position(m.origOffset)
+		}
+		if n := len(sm.Mappings); 0 < n && sm.Mappings[n-1].GenLine == genLine && sm.Mappings[n-1].GenCol == genCol {
+			sm.Mappings[n-1] = mapping
+			continue
+		}
+		sm.Mappings = append(sm.Mappings, mapping)
+	}
+	return sm
+}
+
+// positioner converts offsets into zero-based lines and columns, where columns count UTF-16 code units. It treats \n, \r, \r\n, U+2028, and U+2029 as newlines, like parse.Position.
+// It is fastest for increasing offsets.
+type positioner struct {
+	b     []byte
+	lines []int // offsets of line starts up to the cursor
+
+	offset, col int // cursor
+}
+
+func newPositioner(b []byte) *positioner {
+	return &positioner{
+		b:     b,
+		lines: []int{0},
+	}
+}
+
+func (p *positioner) position(offset int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	} else if len(p.b) < offset {
+		offset = len(p.b)
+	}
+
+	if offset < p.offset {
+		// move the cursor back to the start of the line containing offset
+		line := sort.SearchInts(p.lines, offset+1) - 1
+		p.offset, p.col = p.lines[line], 0
+		p.lines = p.lines[:line+1]
+	}
+	for p.offset < offset {
+		c := p.b[p.offset]
+		n := 1
+		newline := false
+		if c == '\n' {
+			newline = true
+		} else if c == '\r' {
+			if p.offset+1 < len(p.b) && p.b[p.offset+1] == '\n' {
+				n = 2
+			}
+			newline = true
+		} else if 0xC0 <= c {
+			var r rune
+			r, n = utf8.DecodeRune(p.b[p.offset:])
+			if r == '\u2028' || r == '\u2029' {
+				newline = true
+			} else if 0x10000 <= r {
+				p.col++ // surrogate pair
+			}
+		}
+		if offset < p.offset+n {
+			break // offset is inside a multi-byte character or \r\n
+		}
+		p.offset += n
+		p.col++
+		if newline {
+			p.lines = append(p.lines, p.offset)
+			p.col = 0
+		}
+	}
+	return len(p.lines) - 1, p.col
+}
@@ -0,0 +1,101 @@
+package sourcemap
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/parse/v2/buffer"
+	"github.com/tdewolff/parse/v2/css"
+	"github.com/tdewolff/test"
+)
+
+func TestPositioner(t *testing.T) {
+	p := newPositioner([]byte("ab\ncd\r\nef\rg h\U0001F600i€j"))
+	var positionTests = []struct {
+		offset    int
+		line, col int
+	}{
+		{0, 0, 0},
+		{1, 0, 1},
+		{2, 0, 2},
+		{3, 1, 0},
+		{5, 1, 2},
+		{6, 1, 2}, // inside \r\n
+		{7, 2, 0},
+		{10, 3, 0},
+		{11, 3, 1},
+		{12, 3, 1}, // inside U+2028
+		{14, 4, 0},
+		{15, 4, 1},
+		{19, 4, 3}, // after surrogate pair
+		{20, 4, 4},
+		{23, 4, 5},
+		{100, 4, 6},
+		{4, 1, 1},
+		{-1, 0, 0},
+		{8, 2, 1},
+	}
+	for _, tt := range positionTests {
+		t.Run(fmt.Sprint(tt.offset), func(t *testing.T) {
+			line, col := p.position(tt.offset)
+			test.T(t, line, tt.line, "line")
+			test.T(t, col, tt.col, "column")
+		})
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	g := NewGenerator("out.js")
+	a := g.AddSource("a.js", []byte("var alpha = 1;\nvar beta = alpha;"))
+	b := g.AddSource("b.js", []byte("f()"))
+	g.Add(0, a, 0)
+	g.AddName(4, a, 4, "alpha")
+	g.Add(7, a, 10)
+	g.Add(7, a, 12)
+	g.Add(9, -1, 0)
+	g.Add(10, -1, 0)
+	g.AddName(11, a, 19, "beta")
+	g.AddName(12, a, 26, "alpha")
+	g.Add(14, b, 0)
+	g.Add(4, a, 4)
+	sm := g.SourceMap([]byte("var a=1;\nvar b=a;\nf()"))
+	test.String(t, sm.File, "out.js")
+	test.T(t, sm.Sources, []string{"a.js", "b.js"})
+	test.T(t, sm.Names, []string{"alpha", "beta"})
+	test.T(t, len(sm.SourcesContent), 0)
+	test.String(t, mappingsString(sm.Mappings), "0:0->0:0:0 0:4->0:0:4 0:7->0:0:12 1:0 1:2->0:1:4(1) 1:3->0:1:11(0) 1:5->1:0:0")
+
+	g.SourcesContent = true
+	sm = g.SourceMap([]byte("var a=1;\nvar b=a;\nf()"))
+	test.String(t, *sm.SourcesContent[1], "f()")
+}
+
+func ExampleGenerator() {
+	// remove whitespace and comments from CSS
+	input := []byte("a {\n  color: red; /* comment */\n}\n\nb { margin: 0 }")
+	g := NewGenerator("out.css")
+	source := g.AddSource("in.css", input)
+	w := buffer.NewWriter(nil)
+	l := css.NewLexer(bytes.NewBuffer(input))
+	for {
+		tt, data := l.Next()
+		if tt == css.ErrorToken {
+			break
+		} else if tt == css.WhitespaceToken || tt == css.CommentToken {
+			continue
+		}
+		g.Add(w.Len(), source, l.Offset()-len(data))
+		w.Write(data)
+	}
+
+	sm := g.SourceMap(w.Bytes())
+	fmt.Println(string(w.Bytes()))
+	fmt.Println(sm.Lookup(0, 12))
+	b, _ := sm.MarshalJSON()
+	fmt.Println(string(b))
+	// Output:
+	// a{color:red;}b{margin:0}
+	// 0:12->0:2:0 true
+	// {"version":3,"file":"out.css","sources":["in.css"],"names":[],"mappings":"AAAA,CAAE,CACA,KAAK,CAAE,GAAG,CACZ,CAEA,CAAE,CAAE,MAAM,CAAE,CAAE"}
+}
@@ -0,0 +1,361 @@
+// Package sourcemap generates, parses, and composes source maps following the specification at https://sourcemaps.info/spec.html (revision 3).
+package sourcemap
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"sort"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/json"
+)
+
+// Mapping maps a position in the generated file to a position in one of the sources. Lines and columns are zero-based, where columns count UTF-16 code units.
+type Mapping struct {
+	GenLine, GenCol   int
+	Source            int // index into Sources, or -1 if the position is not mapped
+	OrigLine, OrigCol int
+	Name              int // index into Names, or -1 if there is no name
+}
+
+// String returns a string representation of the mapping for debugging purposes.
+func (m Mapping) String() string {
+	if m.Source == -1 {
+		return fmt.Sprintf("%d:%d", m.GenLine, m.GenCol)
+	} else if m.Name == -1 {
+		return fmt.Sprintf("%d:%d->%d:%d:%d", m.GenLine, m.GenCol, m.Source, m.OrigLine, m.OrigCol)
+	}
+	return fmt.Sprintf("%d:%d->%d:%d:%d(%d)", m.GenLine, m.GenCol, m.Source, m.OrigLine, m.OrigCol, m.Name)
+}
+
+// SourceMap is a source map with its mappings decoded. Mappings are sorted by their position in the generated file.
+// SourcesContent is either empty or has the same length as Sources, where nil entries denote unavailable contents.
+type SourceMap struct {
+	File           string
+	SourceRoot     string
+	Sources        []string
+	SourcesContent []*string
+	Names          []string
+	Mappings       []Mapping
+}
+
+type sourceMapJSON struct {
+	Version        int
+	File           string
+	SourceRoot     string
+	Sources        []string
+	SourcesContent []*string
+	Names          []string
+	Mappings       *string
+	Sections       []interface{}
+}
+
+// Parse parses a source map in its JSON representation. Index maps, which contain sections, are not supported.
+func Parse(b []byte) (*SourceMap, error) {
+	raw := sourceMapJSON{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	} else if raw.Version != 3 {
+		return nil, parse.NewError(bytes.NewBuffer(b), 0, "source map parse error: unsupported version %d", raw.Version)
+	} else if raw.Sections != nil {
+		return nil, parse.NewError(bytes.NewBuffer(b), 0, "source map parse error: index maps are not supported")
+	} else if raw.Mappings == nil {
+		return nil, parse.NewError(bytes.NewBuffer(b), 0, "source map parse error: missing mappings")
+	} else if len(raw.SourcesContent) != 0 && len(raw.SourcesContent) != len(raw.Sources) {
+		return nil, parse.NewError(bytes.NewBuffer(b), 0, "source map parse error: sourcesContent and sources differ in length")
+	}
+
+	mappings, err := ParseMappings([]byte(*raw.Mappings), len(raw.Sources), len(raw.Names))
+	if err != nil {
+		return nil, err
+	}
+	return &SourceMap{
+		File:           raw.File,
+		SourceRoot:     raw.SourceRoot,
+		Sources:        raw.Sources,
+		SourcesContent: raw.SourcesContent,
+		Names:          raw.Names,
+		Mappings:       mappings,
+	}, nil
+}
+
+// ParseMappings decodes the mappings field of a source map for the given number of sources and names. Error positions are relative to b.
+func ParseMappings(b []byte, sources, names int) ([]Mapping, error) {
+	mappings := []Mapping{}
+	genLine, genCol, source, origLine, origCol, name := 0, 0, 0, 0, 0, 0
+	var fields [5]int
+	for i := 0; i < len(b); {
+		if b[i] == ';' {
+			genLine++
+			genCol = 0
+			i++
+			continue
+		} else if b[i] == ',' {
+			i++
+			continue
+		}
+
+		start := i
+		k := 0
+		for i < len(b) && b[i] != ',' && b[i] != ';' {
+			if k == len(fields) {
+				return nil, mappingsError(b, i, "too many fields in segment")
+			}
+			n, m := ParseVLQ(b[i:])
+			if m == 0 {
+				return nil, mappingsError(b, i, "invalid VLQ value")
+			}
+			fields[k] = n
+			k++
+			i += m
+		}
+		if k != 1 && k != 4 && k != 5 {
+			return nil, mappingsError(b, start, "segment must have 1, 4, or 5 fields")
+		}
+
+		m := Mapping{Source: -1, Name: -1}
+		genCol += fields[0]
+		if genCol < 0 {
+			return nil, mappingsError(b, start, "negative generated column")
+		}
+		m.GenLine, m.GenCol = genLine, genCol
+		if 4 <= k {
+			source += fields[1]
+			origLine += fields[2]
+			origCol += fields[3]
+			if source < 0 || sources <= source {
+				return nil, mappingsError(b, start, "source index %d out of range", source)
+			} else if origLine < 0 || origCol < 0 {
+				return nil, mappingsError(b, start, "negative original position")
+			}
+			m.Source, m.OrigLine, m.OrigCol = source, origLine, origCol
+		}
+		if k == 5 {
+			name += fields[4]
+			if name < 0 || names <= name {
+				return nil, mappingsError(b, start, "name index %d out of range", name)
+			}
+			m.Name = name
+		}
+		mappings = append(mappings, m)
+	}
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return mappings[i].GenLine < mappings[j].GenLine || mappings[i].GenLine == mappings[j].GenLine && mappings[i].GenCol < mappings[j].GenCol
+	})
+	return mappings, nil
+}
+
+func mappingsError(b []byte, offset int, message string, a ...interface{}) error {
+	return parse.NewError(bytes.NewBuffer(b), offset, "source map parse error: "+message, a...)
+}
+
+// AppendMappings appends the encoded mappings field of a source map to b. The mappings must be sorted by their position in the generated file.
+func AppendMappings(b []byte, mappings []Mapping) []byte {
+	genLine, source, origLine, origCol, name := 0, 0, 0, 0, 0
+	genCol := 0
+	for i, m := range mappings {
+		if genLine < m.GenLine {
+			for genLine < m.GenLine {
+				b = append(b, ';')
+				genLine++
+			}
+			genCol = 0
+		} else if 0 < i {
+			b = append(b, ',')
+		}
+		b = AppendVLQ(b, m.GenCol-genCol)
+		genCol = m.GenCol
+		if m.Source != -1 {
+			b = AppendVLQ(b, m.Source-source)
+			b = AppendVLQ(b, m.OrigLine-origLine)
+			b = AppendVLQ(b, m.OrigCol-origCol)
+			source, origLine, origCol = m.Source, m.OrigLine, m.OrigCol
+			if m.Name != -1 {
+				b = AppendVLQ(b, m.Name-name)
+				name = m.Name
+			}
+		}
+	}
+	return b
+}
+
+// MarshalJSON returns the JSON representation of the source map.
+func (sm *SourceMap) MarshalJSON() ([]byte, error) {
+	b := []byte(`{"version":3`)
+	if sm.File != "" {
+		b = append(b, `,"file":`...)
+		b = appendString(b, sm.File)
+	}
+	if sm.SourceRoot != "" {
+		b = append(b, `,"sourceRoot":`...)
+		b = appendString(b, sm.SourceRoot)
+	}
+	b = append(b, `,"sources":[`...)
+	for i, source := range sm.Sources {
+		if 0 < i {
+			b = append(b, ',')
+		}
+		b = appendString(b, source)
+	}
+	b = append(b, ']')
+	if len(sm.SourcesContent) != 0 {
+		b = append(b, `,"sourcesContent":[`...)
+		for i, content := range sm.SourcesContent {
+			if 0 < i {
+				b = append(b, ',')
+			}
+			if content == nil {
+				b = append(b, "null"...)
+			} else {
+				b = appendString(b, *content)
+			}
+		}
+		b = append(b, ']')
+	}
+	b = append(b, `,"names":[`...)
+	for i, name := range sm.Names {
+		if 0 < i {
+			b = append(b, ',')
+		}
+		b = appendString(b, name)
+	}
+	b = append(b, `],"mappings":"`...)
+	b = AppendMappings(b, sm.Mappings)
+	b = append(b, `"}`...)
+	return b, nil
+}
+
+// appendString appends s as a JSON string to b.
+func appendString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b = append(b, '\\', c)
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			if c < 0x20 {
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			} else {
+				b = append(b, c)
+			}
+		}
+	}
+	return append(b, '"')
+}
+
+// Lookup returns the mapping of the segment that contains the given position in the generated file, that is the last mapping on the same line at or before the column.
+// It returns false if there is no such mapping or if the segment is not mapped to a source.
+func (sm *SourceMap) Lookup(genLine, genCol int) (Mapping, bool) {
+	i := sort.Search(len(sm.Mappings), func(i int) bool {
+		m := sm.Mappings[i]
+		return genLine < m.GenLine || genLine == m.GenLine && genCol < m.GenCol
+	})
+	if i == 0 || sm.Mappings[i-1].GenLine != genLine || sm.Mappings[i-1].Source == -1 {
+		return Mapping{}, false
+	}
+	return sm.Mappings[i-1], true
+}
+
+// Compose returns a source map that maps the generated file of sm to the sources of inner, where inner is the source map of the given source of sm.
+// This combines the source maps of consecutive transformations, such as a compiler followed by a minifier. Names of inner take precedence over names of sm.
+// Mappings to other sources of sm are retained, while mappings to positions that inner does not map become unmapped.
+func (sm *SourceMap) Compose(source string, inner *SourceMap) *SourceMap {
+	composed := &SourceMap{
+		File:       sm.File,
+		SourceRoot: sm.SourceRoot,
+	}
+	sourceIndex := map[string]int{}
+	addSource := func(name string, content *string) int {
+		if i, ok := sourceIndex[name]; ok {
+			return i
+		}
+		sourceIndex[name] = len(composed.Sources)
+		composed.Sources = append(composed.Sources, name)
+		composed.SourcesContent = append(composed.SourcesContent, content)
+		return len(composed.Sources) - 1
+	}
+	nameIndex := map[string]int{}
+	addName := func(name string) int {
+		if i, ok := nameIndex[name]; ok {
+			return i
+		}
+		nameIndex[name] = len(composed.Names)
+		composed.Names = append(composed.Names, name)
+		return len(composed.Names) - 1
+	}
+
+	outerSources := make([]int, len(sm.Sources))
+	for i, name := range sm.Sources {
+		if name == source {
+			outerSources[i] = -1
+		} else {
+			outerSources[i] = addSource(name, sm.content(i))
+		}
+	}
+	innerSources := make([]int, len(inner.Sources))
+	for i, name := range inner.Sources {
+		if inner.SourceRoot != sm.SourceRoot {
+			name = inner.SourceRoot + name
+		}
+		innerSources[i] = addSource(name, inner.content(i))
+	}
+
+	composed.Mappings = make([]Mapping, 0, len(sm.Mappings))
+	for _, m := range sm.Mappings {
+		c := Mapping{GenLine: m.GenLine, GenCol: m.GenCol, Source: -1, Name: -1}
+		if m.Source != -1 && outerSources[m.Source] != -1 {
+			c.Source, c.OrigLine, c.OrigCol = outerSources[m.Source], m.OrigLine, m.OrigCol
+			if m.Name != -1 {
+				c.Name = addName(sm.Names[m.Name])
+			}
+		} else if m.Source != -1 {
+			if n, ok := inner.Lookup(m.OrigLine, m.OrigCol); ok {
+				c.Source, c.OrigLine, c.OrigCol = innerSources[n.Source], n.OrigLine, n.OrigCol
+				if n.Name != -1 {
+					c.Name = addName(inner.Names[n.Name])
+				} else if m.Name != -1 {
+					c.Name = addName(sm.Names[m.Name])
+				}
+			}
+		}
+		composed.Mappings = append(composed.Mappings, c)
+	}
+
+	hasContent := false
+	for _, content := range composed.SourcesContent {
+		hasContent = hasContent || content != nil
+	}
+	if !hasContent {
+		composed.SourcesContent = nil
+	}
+	return composed
+}
+
+func (sm *SourceMap) content(i int) *string {
+	if i < len(sm.SourcesContent) {
+		return sm.SourcesContent[i]
+	}
+	return nil
+}
+
+// Comment returns the comment that links a generated file to its source map at the given URL. It uses the single-line comment syntax of JavaScript unless css is set.
+func Comment(url string, css bool) string {
+	if css {
+		return "/*# sourceMappingURL=" + url + " */"
+	}
+	return "//# sourceMappingURL=" + url
+}
+
+// DataURL returns the source map as a base64 encoded data URL, which can be used to inline the source map in the generated file.
+func (sm *SourceMap) DataURL() string {
+	b, _ := sm.MarshalJSON()
+	return "data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString(b)
+}
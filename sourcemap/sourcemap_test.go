@@ -0,0 +1,182 @@
+package sourcemap
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func mappingsString(mappings []Mapping) string {
+	s := []string{}
+	for _, m := range mappings {
+		s = append(s, m.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func TestMappings(t *testing.T) {
+	var mappingsTests = []struct {
+		mappings string
+		expected string
+	}{
+		{"", ""},
+		{"AAAA", "0:0->0:0:0"},
+		{"AAAA,CAAC", "0:0->0:0:0 0:1->0:0:1"},
+		{"AAAAA,EAAEC", "0:0->0:0:0(0) 0:2->0:0:2(1)"},
+		{"AAAA;;IACA", "0:0->0:0:0 2:4->0:1:0"},
+		{"A,GAAA,C", "0:0 0:3->0:0:0 0:4"},
+		{"KAAK,CCCC;AAAA", "0:5->0:0:5 0:6->1:1:6 1:0->1:1:6"},
+		{";;", ""},
+	}
+	for _, tt := range mappingsTests {
+		t.Run(tt.mappings, func(t *testing.T) {
+			mappings, err := ParseMappings([]byte(tt.mappings), 2, 2)
+			test.Error(t, err)
+			test.String(t, mappingsString(mappings), tt.expected)
+			test.String(t, string(AppendMappings(nil, mappings)), strings.TrimRight(tt.mappings, ";"))
+		})
+	}
+
+	mappings, err := ParseMappings([]byte("EAAA,DAAC"), 1, 0)
+	test.Error(t, err)
+	test.String(t, mappingsString(mappings), "0:1->0:0:1 0:2->0:0:0", "segments must be sorted")
+
+	var errorTests = []struct {
+		mappings string
+		err      string
+		col      int
+	}{
+		{"AA", "segment must have 1, 4, or 5 fields", 1},
+		{"AAAA,AAAAAA", "too many fields in segment", 11},
+		{"AA!A", "invalid VLQ value", 3},
+		{"AAAg", "invalid VLQ value", 4},
+		{"D", "negative generated column", 1},
+		{"AAAA,ACAA", "source index 1 out of range", 6},
+		{"ADAA", "source index -1 out of range", 1},
+		{"AADA", "negative original position", 1},
+		{"AAAAC", "name index 1 out of range", 1},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.mappings, func(t *testing.T) {
+			_, err := ParseMappings([]byte(tt.mappings), 1, 1)
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "source map parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	sm, err := Parse([]byte(`{"version":3,"file":"out.js","sourceRoot":"src/","sources":["a.js","b\"c.js"],"sourcesContent":["var a;",null],"names":["a"],"mappings":"AAAA,IAAIA;ACAJ","x_extension":1}`))
+	test.Error(t, err)
+	test.String(t, sm.File, "out.js")
+	test.String(t, sm.SourceRoot, "src/")
+	test.T(t, sm.Sources, []string{"a.js", "b\"c.js"})
+	test.String(t, *sm.SourcesContent[0], "var a;")
+	test.T(t, sm.SourcesContent[1], (*string)(nil))
+	test.T(t, sm.Names, []string{"a"})
+	test.String(t, mappingsString(sm.Mappings), "0:0->0:0:0 0:4->0:0:4(0) 1:0->1:0:0")
+
+	b, err := sm.MarshalJSON()
+	test.Error(t, err)
+	test.String(t, string(b), `{"version":3,"file":"out.js","sourceRoot":"src/","sources":["a.js","b\"c.js"],"sourcesContent":["var a;",null],"names":["a"],"mappings":"AAAA,IAAIA;ACAJ"}`)
+
+	var errorTests = []struct {
+		sourcemap string
+		err       string
+	}{
+		{`{"version":2,"mappings":""}`, "source map parse error: unsupported version 2"},
+		{`{"version":3,"sources":[]}`, "source map parse error: missing mappings"},
+		{`{"version":3,"sections":[],"mappings":""}`, "source map parse error: index maps are not supported"},
+		{`{"version":3,"sources":["a"],"sourcesContent":["",""],"mappings":""}`, "source map parse error: sourcesContent and sources differ in length"},
+		{`{"version":3,"sources":["a"],"mappings":"AACA,AC"}`, "source map parse error: segment must have 1, 4, or 5 fields"},
+		{`{"version":3,"mappings":1}`, "JSON decode error: cannot unmarshal number 1 into Go struct field sourceMapJSON.Mappings of type string"},
+		{`{"version":3`, "JSON parse error: expected object key to be a quoted string"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.sourcemap, func(t *testing.T) {
+			_, err := Parse([]byte(tt.sourcemap))
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, tt.err)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	sm, err := Parse([]byte(`{"version":3,"sources":["a.js"],"names":[],"mappings":"CAAA,IAAE,E;;AAAA"}`))
+	test.Error(t, err)
+	var lookupTests = []struct {
+		line, col int
+		expected  string
+	}{
+		{0, 0, ""},
+		{0, 1, "0:1->0:0:0"},
+		{0, 4, "0:1->0:0:0"},
+		{0, 5, "0:5->0:0:2"},
+		{0, 6, "0:5->0:0:2"},
+		{0, 7, ""},
+		{1, 0, ""},
+		{2, 10, "2:0->0:0:2"},
+		{3, 0, ""},
+	}
+	for _, tt := range lookupTests {
+		t.Run(fmt.Sprint(tt.line, ":", tt.col), func(t *testing.T) {
+			m, ok := sm.Lookup(tt.line, tt.col)
+			if tt.expected == "" {
+				test.That(t, !ok)
+			} else {
+				test.That(t, ok)
+				test.String(t, m.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompose(t *testing.T) {
+	// a.ts -> a.js (inner) and a.js, b.js -> out.js (outer)
+	inner, err := Parse([]byte(`{"version":3,"file":"a.js","sources":["a.ts"],"sourcesContent":["let x: number = 1"],"names":["x"],"mappings":"AAAA,IAAIA;AAAA"}`))
+	test.Error(t, err)
+	outer := &SourceMap{
+		File:    "out.js",
+		Sources: []string{"b.js", "a.js"},
+		Names:   []string{"y", "z"},
+		Mappings: []Mapping{
+			{0, 0, 0, 0, 0, -1},
+			{0, 2, 1, 0, 0, 0},
+			{0, 4, 1, 0, 4, 1},
+			{0, 6, 1, 1, 2, -1},
+			{0, 7, 1, 2, 0, -1},
+			{0, 8, -1, 0, 0, -1},
+			{0, 9, 0, 0, 5, -1},
+		},
+	}
+
+	sm := outer.Compose("a.js", inner)
+	test.String(t, sm.File, "out.js")
+	test.T(t, sm.Sources, []string{"b.js", "a.ts"})
+	test.T(t, sm.SourcesContent, []*string{nil, inner.SourcesContent[0]})
+	test.T(t, sm.Names, []string{"y", "x"})
+	test.String(t, mappingsString(sm.Mappings), "0:0->0:0:0 0:2->1:0:0(0) 0:4->1:0:4(1) 0:6->1:0:4 0:7 0:8 0:9->0:0:5")
+
+	sm = outer.Compose("c.js", inner)
+	test.T(t, sm.Sources, []string{"b.js", "a.js", "a.ts"})
+	test.T(t, len(sm.SourcesContent), 3)
+	test.String(t, mappingsString(sm.Mappings), mappingsString(outer.Mappings))
+}
+
+func TestDataURL(t *testing.T) {
+	sm := &SourceMap{Sources: []string{"a.js"}, Mappings: []Mapping{{0, 0, 0, 0, 0, -1}}}
+	test.String(t, sm.DataURL(), "data:application/json;charset=utf-8;base64,eyJ2ZXJzaW9uIjozLCJzb3VyY2VzIjpbImEuanMiXSwibmFtZXMiOltdLCJtYXBwaW5ncyI6IkFBQUEifQ==")
+	test.String(t, Comment("out.js.map", false), "//# sourceMappingURL=out.js.map")
+	test.String(t, Comment("out.css.map", true), "/*# sourceMappingURL=out.css.map */")
+}
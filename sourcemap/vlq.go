@@ -0,0 +1,59 @@
+package sourcemap
+
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// base64Values maps base64 characters to their value, or to -1 for other bytes.
+var base64Values = [256]int8{}
+
+func init() {
+	for i := range base64Values {
+		base64Values[i] = -1
+	}
+	for i := 0; i < len(base64Chars); i++ {
+		base64Values[base64Chars[i]] = int8(i)
+	}
+}
+
+// AppendVLQ appends the base64 variable-length quantity of n to b, as used by the mappings of source maps.
+func AppendVLQ(b []byte, n int) []byte {
+	u := uint64(n) << 1
+	if n < 0 {
+		u = uint64(-int64(n))<<1 | 1
+	}
+	for {
+		digit := u & 0x1F
+		u >>= 5
+		if u != 0 {
+			digit |= 0x20 // continuation bit
+		}
+		b = append(b, base64Chars[digit])
+		if u == 0 {
+			return b
+		}
+	}
+}
+
+// ParseVLQ parses a base64 variable-length quantity and returns the integer it represents and the number of bytes read.
+// It returns zero bytes read when b does not start with a complete quantity or when it overflows a signed 32-bit integer, the range allowed by the specification.
+func ParseVLQ(b []byte) (int, int) {
+	u := uint64(0)
+	for i := 0; i < len(b); i++ {
+		digit := base64Values[b[i]]
+		if digit == -1 || 7 < i {
+			return 0, 0
+		}
+		u |= uint64(digit&0x1F) << (5 * uint(i))
+		if digit&0x20 == 0 {
+			if u&1 == 1 {
+				if 1<<31 < u>>1 {
+					return 0, 0
+				}
+				return -int(u >> 1), i + 1
+			} else if 1<<31-1 < u>>1 {
+				return 0, 0
+			}
+			return int(u >> 1), i + 1
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,40 @@
+package sourcemap
+
+import (
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestVLQ(t *testing.T) {
+	var vlqTests = []struct {
+		n   int
+		vlq string
+	}{
+		{0, "A"},
+		{1, "C"},
+		{-1, "D"},
+		{15, "e"},
+		{16, "gB"},
+		{-16, "hB"},
+		{123, "2H"},
+		{-2147483648, "hgggggE"},
+		{2147483647, "+/////D"},
+	}
+	for _, tt := range vlqTests {
+		t.Run(tt.vlq, func(t *testing.T) {
+			test.String(t, string(AppendVLQ(nil, tt.n)), tt.vlq)
+			n, m := ParseVLQ([]byte(tt.vlq + "A"))
+			test.T(t, n, tt.n)
+			test.T(t, m, len(tt.vlq))
+		})
+	}
+
+	var errorTests = []string{"", "g", "!", "gggggggggA", "ggggggQ", "ggggggE", "jgggggE"}
+	for _, vlq := range errorTests {
+		t.Run(vlq, func(t *testing.T) {
+			_, m := ParseVLQ([]byte(vlq))
+			test.T(t, m, 0)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package svg
+
+import (
+	"testing"
+
+	"github.com/tdewolff/test"
+)
+
+func TestHashTable(t *testing.T) {
+	test.T(t, ToHash([]byte("svg")), Svg, "'svg' must resolve to Svg")
+	test.T(t, ToHash([]byte("clipPath")), ClipPath, "'clipPath' must resolve to ClipPath")
+	test.T(t, ToHash([]byte("clip-path")), Clip_Path, "'clip-path' must resolve to Clip_Path")
+	test.T(t, ToHash([]byte("xlink:href")), Xlink_Href, "'xlink:href' must resolve to Xlink_Href")
+	test.T(t, ToHash([]byte("viewBox")), ViewBox, "'viewBox' must resolve to ViewBox")
+	test.T(t, ToHash([]byte("viewbox")), Hash(0), "'viewbox' must resolve to zero")
+	test.T(t, ToHash([]byte("")), Hash(0), "'' must resolve to zero")
+	test.T(t, Transform.String(), "transform")
+	test.T(t, SkewX.String(), "skewX")
+	test.T(t, Hash(0xffffff).String(), "")
+}
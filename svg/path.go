@@ -0,0 +1,132 @@
+package svg
+
+import (
+	"strconv"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/buffer"
+	parseStrconv "github.com/tdewolff/parse/v2/strconv"
+)
+
+// PathCommand is a command of SVG path data with its numeric arguments. Arc flags are returned as 0 or 1.
+type PathCommand struct {
+	Cmd  byte // one of MmZzLlHhVvCcSsQqTtAa
+	Args []float64
+}
+
+// String returns the string representation of a PathCommand.
+func (cmd PathCommand) String() string {
+	b := []byte{cmd.Cmd}
+	for i, arg := range cmd.Args {
+		if 0 < i {
+			b = append(b, ' ')
+		}
+		b = strconv.AppendFloat(b, arg, 'g', -1, 64)
+	}
+	return string(b)
+}
+
+// pathArgs returns the number of arguments for each command, or -1 if the byte is not a command.
+var pathArgs = [256]int8{}
+
+func init() {
+	for i := range pathArgs {
+		pathArgs[i] = -1
+	}
+	for _, cmd := range []struct {
+		c byte
+		n int8
+	}{{'M', 2}, {'Z', 0}, {'L', 2}, {'H', 1}, {'V', 1}, {'C', 6}, {'S', 4}, {'Q', 4}, {'T', 2}, {'A', 7}} {
+		pathArgs[cmd.c] = cmd.n
+		pathArgs[cmd.c+'a'-'A'] = cmd.n
+	}
+}
+
+// ParsePath parses SVG path data, such as the value of the d attribute, following the grammar at https://www.w3.org/TR/SVG2/paths.html#PathDataBNF.
+// Repeated commands are made explicit, where coordinate pairs following a moveto are returned as lineto commands.
+// Upon an error it returns the commands up to the error together with a *parse.Error, since the specification renders paths up to the first error.
+func ParsePath(b []byte) ([]PathCommand, error) {
+	cmds := []PathCommand{}
+	args := make([]float64, 0, len(b)/2)
+	i := skipWhitespace(b, 0)
+	if string(b[i:skipName(b, i)]) == "none" && skipWhitespace(b, i+4) == len(b) {
+		return cmds, nil
+	}
+	cmd := byte(0)
+	for i < len(b) {
+		if pathArgs[b[i]] != -1 {
+			if b[i] != 'M' && b[i] != 'm' && len(cmds) == 0 {
+				return cmds, pathError(b, i, "path must start with a moveto command")
+			}
+			cmd = b[i]
+			i = skipWhitespace(b, i+1)
+		} else if cmd == 0 {
+			return cmds, pathError(b, i, "path must start with a moveto command")
+		} else if cmd == 'Z' || cmd == 'z' {
+			return cmds, pathError(b, i, "unexpected '%c'", b[i])
+		} else if cmd == 'M' {
+			cmd = 'L'
+		} else if cmd == 'm' {
+			cmd = 'l'
+		}
+
+		n := int(pathArgs[cmd])
+		start := len(args)
+		for j := 0; j < n; j++ {
+			if j != 0 {
+				i = skipCommaWhitespace(b, i)
+			}
+			if (cmd == 'A' || cmd == 'a') && (j == 3 || j == 4) {
+				// arc flags may be written without separators
+				if i == len(b) || b[i] != '0' && b[i] != '1' {
+					return cmds, pathError(b, i, "expected arc flag")
+				}
+				args = append(args, float64(b[i]-'0'))
+				i++
+				continue
+			}
+			f, m := parseStrconv.ParseFloat(b[i:])
+			if m == 0 {
+				return cmds, pathError(b, i, "expected number")
+			}
+			args = append(args, f)
+			i += m
+		}
+		cmds = append(cmds, PathCommand{cmd, args[start:len(args):len(args)]})
+
+		i = skipWhitespace(b, i)
+		if i < len(b) && b[i] == ',' {
+			if cmd == 'Z' || cmd == 'z' {
+				return cmds, pathError(b, i, "unexpected ','")
+			}
+			i = skipWhitespace(b, i+1)
+			if i == len(b) || pathArgs[b[i]] != -1 {
+				return cmds, pathError(b, i, "expected number")
+			}
+		}
+	}
+	return cmds, nil
+}
+
+func pathError(b []byte, offset int, message string, a ...interface{}) error {
+	return parse.NewError(buffer.NewReader(b), offset, "SVG path parse error: "+message, a...)
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func skipWhitespace(b []byte, i int) int {
+	for i < len(b) && isWhitespace(b[i]) {
+		i++
+	}
+	return i
+}
+
+func skipCommaWhitespace(b []byte, i int) int {
+	i = skipWhitespace(b, i)
+	if i < len(b) && b[i] == ',' {
+		i = skipWhitespace(b, i+1)
+	}
+	return i
+}
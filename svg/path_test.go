@@ -0,0 +1,95 @@
+package svg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func pathString(cmds []PathCommand) string {
+	s := []string{}
+	for _, cmd := range cmds {
+		s = append(s, cmd.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func TestParsePath(t *testing.T) {
+	var pathTests = []struct {
+		path     string
+		expected string
+	}{
+		{"", ""},
+		{"none", ""},
+		{"M10 20", "M10 20"},
+		{" M 10,20 L 30 40 z ", "M10 20 L30 40 z"},
+		{"M10-20.5.5.5e1", "M10 -20.5 L0.5 5"},
+		{"m1 2 3 4 5 6", "m1 2 l3 4 l5 6"},
+		{"M1 2 3 4Z m5 6", "M1 2 L3 4 Z m5 6"},
+		{"M0 0H10V10h-5v-5", "M0 0 H10 V10 h-5 v-5"},
+		{"M0 0C1 2 3 4 5 6 7 8 9 10 11 12", "M0 0 C1 2 3 4 5 6 C7 8 9 10 11 12"},
+		{"M0 0S1 2 3 4Q5 6 7 8T9 10", "M0 0 S1 2 3 4 Q5 6 7 8 T9 10"},
+		{"M0 0A25 26 -30 0 1 50 -25", "M0 0 A25 26 -30 0 1 50 -25"},
+		{"M0 0a25,26 -30 1,0 50,-25", "M0 0 a25 26 -30 1 0 50 -25"},
+		{"M0 0a25 26 -30 1150-25", "M0 0 a25 26 -30 1 1 50 -25"},
+		{"M0,0,1,1", "M0 0 L1 1"},
+	}
+	for _, tt := range pathTests {
+		t.Run(tt.path, func(t *testing.T) {
+			cmds, err := ParsePath([]byte(tt.path))
+			test.Error(t, err)
+			test.String(t, pathString(cmds), tt.expected)
+		})
+	}
+}
+
+func TestParsePathError(t *testing.T) {
+	var errorTests = []struct {
+		path     string
+		err      string
+		col      int
+		expected string
+	}{
+		{"L10 20", "path must start with a moveto command", 1, ""},
+		{"10 20", "path must start with a moveto command", 1, ""},
+		{"M10", "expected number", 4, ""},
+		{"M10 20 L30", "expected number", 11, "M10 20"},
+		{"M10 20 x", "expected number", 8, "M10 20"},
+		{"M10 20Z 5", "unexpected '5'", 9, "M10 20 Z"},
+		{"M10 20Z,", "unexpected ','", 8, "M10 20 Z"},
+		{"M10 20,", "expected number", 8, "M10 20"},
+		{"M10 20,L1 2", "expected number", 8, "M10 20"},
+		{"M10,,20", "expected number", 5, ""},
+		{"M0 0A25 26 -30 2 1 50 -25", "expected arc flag", 16, "M0 0"},
+		{"M0 0A25 26 -30 0", "expected arc flag", 17, "M0 0"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.path, func(t *testing.T) {
+			cmds, err := ParsePath([]byte(tt.path))
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "SVG path parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+			test.String(t, pathString(cmds), tt.expected)
+		})
+	}
+}
+
+func ExampleParsePath() {
+	cmds, _ := ParsePath([]byte("M10,10 20,20 h5 a5 5 0 01-5 5z"))
+	for _, cmd := range cmds {
+		fmt.Println(string(cmd.Cmd), cmd.Args)
+	}
+	// Output:
+	// M [10 10]
+	// L [20 20]
+	// h [5]
+	// a [5 5 0 0 1 -5 5]
+	// z []
+}
@@ -0,0 +1,150 @@
+package svg
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/buffer"
+	parseStrconv "github.com/tdewolff/parse/v2/strconv"
+)
+
+// TransformCommand is a transform function of an SVG transform list with its numeric arguments. Func is one of Matrix, Translate, Scale, Rotate, SkewX, or SkewY.
+// Optional arguments are filled in with their defaults, so that translate and scale have two arguments, and rotate has three.
+type TransformCommand struct {
+	Func Hash
+	Args []float64
+}
+
+// String returns the string representation of a TransformCommand.
+func (cmd TransformCommand) String() string {
+	b := append([]byte(cmd.Func.String()), '(')
+	for i, arg := range cmd.Args {
+		if 0 < i {
+			b = append(b, ' ')
+		}
+		b = strconv.AppendFloat(b, arg, 'g', -1, 64)
+	}
+	return string(append(b, ')'))
+}
+
+// Matrix returns the affine transformation matrix [a b c d e f] of the transform function, see https://www.w3.org/TR/SVG11/coords.html#TransformMatrixDefined. Angles are in degrees.
+func (cmd TransformCommand) Matrix() [6]float64 {
+	switch cmd.Func {
+	case Matrix:
+		return [6]float64{cmd.Args[0], cmd.Args[1], cmd.Args[2], cmd.Args[3], cmd.Args[4], cmd.Args[5]}
+	case Translate:
+		return [6]float64{1, 0, 0, 1, cmd.Args[0], cmd.Args[1]}
+	case Scale:
+		return [6]float64{cmd.Args[0], 0, 0, cmd.Args[1], 0, 0}
+	case Rotate:
+		sin, cos := math.Sincos(cmd.Args[0] * math.Pi / 180.0)
+		cx, cy := cmd.Args[1], cmd.Args[2]
+		return [6]float64{cos, sin, -sin, cos, cx - cos*cx + sin*cy, cy - sin*cx - cos*cy}
+	case SkewX:
+		return [6]float64{1, 0, math.Tan(cmd.Args[0] * math.Pi / 180.0), 1, 0, 0}
+	case SkewY:
+		return [6]float64{1, math.Tan(cmd.Args[0] * math.Pi / 180.0), 0, 1, 0, 0}
+	}
+	return [6]float64{1, 0, 0, 1, 0, 0}
+}
+
+// TransformMatrix returns the affine transformation matrix [a b c d e f] of a transform list, where the transform functions are applied from right to left.
+func TransformMatrix(cmds []TransformCommand) [6]float64 {
+	m := [6]float64{1, 0, 0, 1, 0, 0}
+	for _, cmd := range cmds {
+		n := cmd.Matrix()
+		m = [6]float64{
+			m[0]*n[0] + m[2]*n[1],
+			m[1]*n[0] + m[3]*n[1],
+			m[0]*n[2] + m[2]*n[3],
+			m[1]*n[2] + m[3]*n[3],
+			m[0]*n[4] + m[2]*n[5] + m[4],
+			m[1]*n[4] + m[3]*n[5] + m[5],
+		}
+	}
+	return m
+}
+
+// ParseTransform parses an SVG transform list, such as the value of the transform attribute, following the grammar at https://www.w3.org/TR/SVG11/coords.html#TransformAttribute.
+// Upon an error it returns the transform functions up to the error together with a *parse.Error.
+func ParseTransform(b []byte) ([]TransformCommand, error) {
+	cmds := []TransformCommand{}
+	i := skipWhitespace(b, 0)
+	if string(b[i:skipName(b, i)]) == "none" && skipWhitespace(b, i+4) == len(b) {
+		return cmds, nil
+	}
+	for i < len(b) {
+		if 0 < len(cmds) {
+			i = skipCommaWhitespace(b, i)
+		}
+		start := i
+		i = skipName(b, i)
+		f := ToHash(b[start:i])
+		min, max := 0, 0
+		switch f {
+		case Matrix:
+			min, max = 6, 6
+		case Translate, Scale:
+			min, max = 1, 2
+		case Rotate:
+			min, max = 1, 3
+		case SkewX, SkewY:
+			min, max = 1, 1
+		default:
+			if start == i {
+				return cmds, transformError(b, start, "expected transform function")
+			}
+			return cmds, transformError(b, start, "unknown transform function '%s'", b[start:i])
+		}
+
+		i = skipWhitespace(b, i)
+		if i == len(b) || b[i] != '(' {
+			return cmds, transformError(b, i, "expected '('")
+		}
+		i = skipWhitespace(b, i+1)
+
+		args := make([]float64, 0, 6)
+		for i < len(b) && b[i] != ')' {
+			if 0 < len(args) {
+				i = skipCommaWhitespace(b, i)
+			}
+			x, n := parseStrconv.ParseFloat(b[i:])
+			if n == 0 {
+				return cmds, transformError(b, i, "expected number")
+			} else if len(args) == max {
+				return cmds, transformError(b, i, "too many arguments for %s", f)
+			}
+			args = append(args, x)
+			i = skipWhitespace(b, i+n)
+		}
+		if i == len(b) {
+			return cmds, transformError(b, i, "expected ')'")
+		} else if len(args) < min || f == Rotate && len(args) == 2 {
+			return cmds, transformError(b, i, "too few arguments for %s", f)
+		}
+		i = skipWhitespace(b, i+1)
+
+		switch {
+		case f == Translate && len(args) == 1:
+			args = append(args, 0)
+		case f == Scale && len(args) == 1:
+			args = append(args, args[0])
+		case f == Rotate && len(args) == 1:
+			args = append(args, 0, 0)
+		}
+		cmds = append(cmds, TransformCommand{f, args})
+	}
+	return cmds, nil
+}
+
+func transformError(b []byte, offset int, message string, a ...interface{}) error {
+	return parse.NewError(buffer.NewReader(b), offset, "SVG transform parse error: "+message, a...)
+}
+
+func skipName(b []byte, i int) int {
+	for i < len(b) && ('a' <= b[i] && b[i] <= 'z' || 'A' <= b[i] && b[i] <= 'Z') {
+		i++
+	}
+	return i
+}
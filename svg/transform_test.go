@@ -0,0 +1,123 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func transformString(cmds []TransformCommand) string {
+	s := []string{}
+	for _, cmd := range cmds {
+		s = append(s, cmd.String())
+	}
+	return strings.Join(s, " ")
+}
+
+func TestParseTransform(t *testing.T) {
+	var transformTests = []struct {
+		transform string
+		expected  string
+	}{
+		{"", ""},
+		{" none ", ""},
+		{"translate(10)", "translate(10 0)"},
+		{"translate(10,20)", "translate(10 20)"},
+		{"scale(2)", "scale(2 2)"},
+		{"scale(2 3)", "scale(2 3)"},
+		{"rotate(45)", "rotate(45 0 0)"},
+		{"rotate(45, 10, 20)", "rotate(45 10 20)"},
+		{"skewX(30) skewY(-30)", "skewX(30) skewY(-30)"},
+		{"matrix(1 0 0 1 -5-5)", "matrix(1 0 0 1 -5 -5)"},
+		{" translate ( 1 , 2 ) ,scale(3)rotate(4) ", "translate(1 2) scale(3 3) rotate(4 0 0)"},
+	}
+	for _, tt := range transformTests {
+		t.Run(tt.transform, func(t *testing.T) {
+			cmds, err := ParseTransform([]byte(tt.transform))
+			test.Error(t, err)
+			test.String(t, transformString(cmds), tt.expected)
+		})
+	}
+}
+
+func TestParseTransformError(t *testing.T) {
+	var errorTests = []struct {
+		transform string
+		err       string
+		col       int
+		expected  string
+	}{
+		{"translat(1)", "unknown transform function 'translat'", 1, ""},
+		{"scale(1),,scale(2)", "expected transform function", 10, "scale(1 1)"},
+		{"(1)", "expected transform function", 1, ""},
+		{"scale 1", "expected '('", 7, ""},
+		{"scale(1", "expected ')'", 8, ""},
+		{"scale(1 2 3)", "too many arguments for scale", 11, ""},
+		{"matrix(1 2 3 4 5)", "too few arguments for matrix", 17, ""},
+		{"rotate(1 2)", "too few arguments for rotate", 11, ""},
+		{"skewX()", "too few arguments for skewX", 7, ""},
+		{"skewX(a)", "expected number", 7, ""},
+		{"translate(1,,2)", "expected number", 13, ""},
+		{"rotate(1) none", "unknown transform function 'none'", 11, "rotate(1 0 0)"},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.transform, func(t *testing.T) {
+			cmds, err := ParseTransform([]byte(tt.transform))
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "SVG transform parse error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+			test.String(t, transformString(cmds), tt.expected)
+		})
+	}
+}
+
+func TestTransformMatrix(t *testing.T) {
+	var matrixTests = []struct {
+		transform string
+		expected  [6]float64
+	}{
+		{"", [6]float64{1, 0, 0, 1, 0, 0}},
+		{"matrix(1 2 3 4 5 6)", [6]float64{1, 2, 3, 4, 5, 6}},
+		{"translate(10 20)", [6]float64{1, 0, 0, 1, 10, 20}},
+		{"scale(2 3)", [6]float64{2, 0, 0, 3, 0, 0}},
+		{"rotate(90)", [6]float64{0, 1, -1, 0, 0, 0}},
+		{"rotate(90 10 0)", [6]float64{0, 1, -1, 0, 10, -10}},
+		{"skewX(45)", [6]float64{1, 0, 1, 1, 0, 0}},
+		{"skewY(45)", [6]float64{1, 1, 0, 1, 0, 0}},
+		{"translate(10 20) scale(2)", [6]float64{2, 0, 0, 2, 10, 20}},
+		{"scale(2) translate(10 20)", [6]float64{2, 0, 0, 2, 20, 40}},
+	}
+	for _, tt := range matrixTests {
+		t.Run(tt.transform, func(t *testing.T) {
+			cmds, err := ParseTransform([]byte(tt.transform))
+			test.Error(t, err)
+			m := TransformMatrix(cmds)
+			for i := range m {
+				if 1e-9 < math.Abs(m[i]-tt.expected[i]) {
+					test.Fail(t, m, "!=", tt.expected)
+					break
+				}
+			}
+		})
+	}
+}
+
+func ExampleParseTransform() {
+	cmds, _ := ParseTransform([]byte("translate(10,20) rotate(90)"))
+	for _, cmd := range cmds {
+		fmt.Println(cmd.Func, cmd.Args)
+	}
+	fmt.Printf("%.4g\n", TransformMatrix(cmds))
+	// Output:
+	// translate [10 20]
+	// rotate [90 0 0]
+	// [6.123e-17 1 -1 6.123e-17 10 20]
+}
@@ -1,4 +1,4 @@
-// Package xml is an XML1.0 lexer following the specifications at http://www.w3.org/TR/xml/.
+// Package xml is an XML1.0 lexer following the specifications at http://www.w3.org/TR/xml/, with namespace resolution following http://www.w3.org/TR/xml-names/.
 package xml
 
 import (
@@ -63,8 +63,9 @@ func (tt TokenType) String() string {
 
 // Lexer is the state for the lexer.
 type Lexer struct {
-	r   *buffer.Lexer
-	err error
+	r      *buffer.Lexer
+	stream bool // reading from a stream, see nextStream
+	err    error
 
 	inTag bool
 
@@ -79,6 +80,15 @@ func NewLexer(r io.Reader) *Lexer {
 	}
 }
 
+// NewStreamLexer returns a new Lexer that reads the XML document from r in chunks, so that large documents are lexed with bounded memory usage.
+// The data returned by Next, Text, and AttrVal is only valid until the next call to Next.
+func NewStreamLexer(r io.Reader) *Lexer {
+	return &Lexer{
+		r:      buffer.NewLexerStream(r),
+		stream: true,
+	}
+}
+
 // Err returns the error encountered during lexing, this is often io.EOF but also other errors can be returned.
 func (l *Lexer) Err() error {
 	if l.err != nil {
@@ -109,6 +119,9 @@ func (l *Lexer) AttrVal() []byte {
 
 // Next returns the next Token. It returns ErrorToken when an error was encountered. Using Err() one can retrieve the error message.
 func (l *Lexer) Next() (TokenType, []byte) {
+	if l.stream {
+		return l.nextStream()
+	}
 	l.text = nil
 	var c byte
 	if l.inTag {
@@ -188,6 +201,27 @@ func (l *Lexer) Next() (TokenType, []byte) {
 	}
 }
 
+// nextStream calls Next when reading from a stream. When Next runs out of buffered data, the lexer is restored to before the token, which resets whether it is inside a tag, and the token is lexed again after reading in more data.
+func (l *Lexer) nextStream() (tt TokenType, data []byte) {
+	l.r.Free(l.r.ShiftLen())
+	offset := l.r.Offset()
+	state := *l
+	defer func() {
+		if err := recover(); err != nil {
+			if !l.r.Retry(err, offset) {
+				panic(err)
+			}
+			*l = state
+			tt, data = l.nextStream()
+		}
+	}()
+
+	l.stream = false
+	tt, data = l.Next()
+	l.stream = true
+	return
+}
+
 ////////////////////////////////////////////////////////////////
 
 // The following functions follow the specifications at http://www.w3.org/html/wg/drafts/html/master/syntax.html
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"io"
 	"testing"
+	"testing/iotest"
 
 	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/internal/testio"
 	"github.com/tdewolff/test"
 )
 
@@ -247,6 +249,52 @@ func TestOffset(t *testing.T) {
 	test.T(t, l.Offset(), 26) // </div>
 }
 
+func TestStreamLexer(t *testing.T) {
+	xml := `<?xml version="1.0"?><!DOCTYPE root><root a="1" b='2'>text &amp; more<![CDATA[x<y]]><!--comment--><empty/><?pi x?></root>`
+	l := NewLexer(bytes.NewBufferString(xml))
+	z := NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString(xml)))
+	for {
+		tt, data := l.Next()
+		ttStream, dataStream := z.Next()
+		test.T(t, ttStream, tt, "token types must match")
+		test.String(t, string(dataStream), string(data), "token data must match")
+		test.String(t, string(z.Text()), string(l.Text()), "texts must match")
+		test.String(t, string(z.AttrVal()), string(l.AttrVal()), "attribute values must match")
+		test.T(t, z.Offset(), l.Offset(), "offsets must match")
+		if tt == ErrorToken {
+			break
+		}
+	}
+	test.T(t, z.Err(), io.EOF)
+
+	z = NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString("<a>\n<b \x00>")))
+	for tt, _ := z.Next(); tt != ErrorToken; tt, _ = z.Next() {
+	}
+	if perr, ok := z.Err().(*parse.Error); ok {
+		line, col, _ := perr.Position()
+		test.T(t, line, 2)
+		test.T(t, col, 4)
+	} else {
+		test.Fail(t, "bad error:", z.Err())
+	}
+}
+
+func TestStreamLexerMemory(t *testing.T) {
+	item := []byte(`<item id="1">text &amp; more<![CDATA[x]]><!--c--></item>`)
+	r := io.MultiReader(bytes.NewBufferString("<root>"), testio.NewRepeatReader(item, 500000), bytes.NewBufferString("</root>"))
+
+	l := NewStreamLexer(r)
+	n := 0
+	alloc := testio.TotalAlloc(func() {
+		for tt, _ := l.Next(); tt != ErrorToken; tt, _ = l.Next() {
+			n++
+		}
+	})
+	test.T(t, l.Err(), io.EOF)
+	test.T(t, n, 7*500000+3)
+	test.That(t, alloc < 1<<20, "allocated", alloc, "bytes for", len(item)*500000, "bytes of input")
+}
+
 ////////////////////////////////////////////////////////////////
 
 func ExampleNewLexer() {
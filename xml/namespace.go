@@ -0,0 +1,377 @@
+package xml
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// Reserved namespace URIs that are bound to the xml and xmlns prefixes, see https://www.w3.org/TR/xml-names/#ns-decl.
+var (
+	XMLNamespace   = []byte("http://www.w3.org/XML/1998/namespace")
+	XMLNSNamespace = []byte("http://www.w3.org/2000/xmlns/")
+)
+
+var (
+	xmlBytes   = []byte("xml")
+	xmlnsBytes = []byte("xmlns")
+)
+
+// Name is a namespace-qualified name of an element or attribute.
+type Name struct {
+	Space []byte // namespace URI, nil when the name is in no namespace
+	Local []byte
+}
+
+// String returns the string representation of a Name in Clark notation, eg. {http://www.w3.org/2000/svg}rect.
+func (n Name) String() string {
+	if n.Space == nil {
+		return string(n.Local)
+	}
+	return "{" + string(n.Space) + "}" + string(n.Local)
+}
+
+type binding struct {
+	prefix []byte // empty for the default namespace
+	uri    []byte // empty when undeclaring the default namespace
+}
+
+type scope struct {
+	bindings int // number of bindings before the element
+	ns       int // length of the namespace buffer before the element
+}
+
+type nsToken struct {
+	tt      TokenType
+	data    []byte
+	text    []byte
+	attrVal []byte
+	name    Name
+	offset  int // offset of the name in the input
+	end     int // offset after the token in the input
+}
+
+// NamespaceLexer is a layer over Lexer that resolves the namespaces of elements and attributes following the specification at https://www.w3.org/TR/xml-names/.
+// It returns the same tokens as Lexer, but the names of start tags, end tags, and attributes are available through Name.
+// Since namespace declarations may follow the name of the element they apply to, all attributes of a start tag are read and copied before the start tag is returned.
+type NamespaceLexer struct {
+	l   *Lexer
+	err error
+
+	bindings []binding
+	scopes   []scope
+	ns       []byte // storage for the prefixes and URIs of the bindings
+
+	buf    []byte // storage for the tokens of the current start tag
+	tokens []nsToken
+	i      int // index of the next buffered token
+	tok    nsToken
+}
+
+// NewNamespaceLexer returns a new NamespaceLexer for a given Lexer, which may read from a stream.
+func NewNamespaceLexer(l *Lexer) *NamespaceLexer {
+	return &NamespaceLexer{
+		l: l,
+	}
+}
+
+// Err returns the error encountered during lexing or namespace resolution, this is often io.EOF but also other errors can be returned.
+func (z *NamespaceLexer) Err() error {
+	if z.err != nil {
+		return z.err
+	}
+	return z.l.Err()
+}
+
+// Offset returns the position in the input stream after the current token.
+func (z *NamespaceLexer) Offset() int {
+	return z.tok.end
+}
+
+// Text returns the textual representation of a token, see Lexer.Text.
+func (z *NamespaceLexer) Text() []byte {
+	return z.tok.text
+}
+
+// AttrVal returns the attribute value when an AttributeToken was returned from Next.
+func (z *NamespaceLexer) AttrVal() []byte {
+	return z.tok.attrVal
+}
+
+// Name returns the resolved name when a StartTagToken, EndTagToken, or AttributeToken was returned from Next.
+// Attributes without a prefix are in no namespace, and namespace declarations are in the XMLNSNamespace namespace with the declared prefix or xmlns as local name.
+// Processing instructions and their attributes are not namespace-aware, their names are returned as is.
+func (z *NamespaceLexer) Name() Name {
+	return z.tok.name
+}
+
+// Depth returns the number of open elements.
+func (z *NamespaceLexer) Depth() int {
+	return len(z.scopes)
+}
+
+// Lookup returns the namespace URI bound to prefix in the current scope, or the default namespace if prefix is empty.
+// It returns false when the prefix is not declared.
+func (z *NamespaceLexer) Lookup(prefix []byte) ([]byte, bool) {
+	for i := len(z.bindings) - 1; 0 <= i; i-- {
+		if bytes.Equal(z.bindings[i].prefix, prefix) {
+			if len(z.bindings[i].uri) == 0 {
+				return nil, len(prefix) == 0
+			}
+			return z.bindings[i].uri, true
+		}
+	}
+	if len(prefix) == 0 {
+		return nil, true
+	} else if bytes.Equal(prefix, xmlBytes) {
+		return XMLNamespace, true
+	} else if bytes.Equal(prefix, xmlnsBytes) {
+		return XMLNSNamespace, true
+	}
+	return nil, false
+}
+
+// Next returns the next Token. It returns ErrorToken when an error was encountered. Using Err() one can retrieve the error message.
+func (z *NamespaceLexer) Next() (TokenType, []byte) {
+	if z.err != nil {
+		return ErrorToken, nil
+	} else if z.i < len(z.tokens) {
+		z.tok = z.tokens[z.i]
+		z.i++
+		return z.tok.tt, z.tok.data
+	}
+
+	start := z.l.Offset()
+	tt, data := z.l.Next()
+	z.tok = nsToken{
+		tt:      tt,
+		data:    data,
+		text:    z.l.Text(),
+		attrVal: z.l.AttrVal(),
+		end:     z.l.Offset(),
+	}
+	switch tt {
+	case StartTagToken:
+		z.tok.offset = start + 1
+		z.readTag()
+		if !z.startTag() {
+			return ErrorToken, nil
+		}
+		z.tok = z.tokens[0]
+		z.i = 1
+	case StartTagPIToken:
+		// processing instructions are not namespace-aware
+		z.readTag()
+		for i := range z.tokens {
+			z.tokens[i].name.Local = z.tokens[i].text
+		}
+		z.tok = z.tokens[0]
+		z.i = 1
+	case EndTagToken:
+		z.tok.offset = start + 2
+		if !z.resolve(&z.tok, true) {
+			return ErrorToken, nil
+		}
+		z.pop()
+	}
+	return z.tok.tt, z.tok.data
+}
+
+// readTag buffers the current start tag token together with its attributes and closing token.
+func (z *NamespaceLexer) readTag() {
+	z.buf = z.buf[:0]
+	z.tokens = append(z.tokens[:0], z.tok)
+	z.tokens[0].data = z.copy(z.tok.data)
+	z.tokens[0].text = z.copy(z.tok.text)
+	for {
+		start := z.l.Offset()
+		tt, data := z.l.Next()
+		tok := z.copyToken(tt, data)
+		if tt == AttributeToken {
+			tok.offset = start + len(data) - len(parse.TrimWhitespace(data))
+		}
+		z.tokens = append(z.tokens, tok)
+		if tt != AttributeToken {
+			break
+		}
+	}
+}
+
+// startTag declares the namespaces of the buffered start tag and resolves its names.
+func (z *NamespaceLexer) startTag() bool {
+	z.scopes = append(z.scopes, scope{len(z.bindings), len(z.ns)})
+	attrs := z.tokens[1 : len(z.tokens)-1]
+	for i := range attrs {
+		if !z.declare(&attrs[i]) {
+			return false
+		}
+	}
+
+	if !z.resolve(&z.tokens[0], true) {
+		return false
+	}
+	for i := range attrs {
+		if attrs[i].name.Local == nil && !z.resolve(&attrs[i], false) {
+			return false
+		}
+		for j := 0; j < i; j++ {
+			if bytes.Equal(attrs[i].name.Space, attrs[j].name.Space) && bytes.Equal(attrs[i].name.Local, attrs[j].name.Local) {
+				z.fail(attrs[i].offset, "duplicate attribute '%s'", attrs[i].text)
+				return false
+			}
+		}
+	}
+	if z.tokens[len(z.tokens)-1].tt == StartTagCloseVoidToken {
+		z.pop()
+	}
+	return true
+}
+
+// declare adds a namespace binding to the current scope if the attribute is a namespace declaration.
+func (z *NamespaceLexer) declare(attr *nsToken) bool {
+	var prefix []byte
+	if bytes.Equal(attr.text, xmlnsBytes) {
+		attr.name = Name{XMLNSNamespace, xmlnsBytes}
+	} else if 6 < len(attr.text) && bytes.Equal(attr.text[:6], []byte("xmlns:")) {
+		prefix = attr.text[6:]
+		attr.name = Name{XMLNSNamespace, prefix}
+	} else {
+		return true
+	}
+
+	start := len(z.ns)
+	z.ns = unescapeAttrVal(z.ns, attr.attrVal)
+	uri := z.ns[start:len(z.ns):len(z.ns)]
+	if bytes.Equal(prefix, xmlnsBytes) {
+		z.fail(attr.offset, "cannot declare the xmlns prefix")
+		return false
+	} else if bytes.Equal(prefix, xmlBytes) {
+		if !bytes.Equal(uri, XMLNamespace) {
+			z.fail(attr.offset, "the xml prefix must be bound to %s", XMLNamespace)
+			return false
+		}
+	} else if bytes.Equal(uri, XMLNamespace) || bytes.Equal(uri, XMLNSNamespace) {
+		z.fail(attr.offset, "cannot bind to %s", uri)
+		return false
+	} else if len(uri) == 0 && len(prefix) != 0 {
+		z.fail(attr.offset, "empty namespace URI for prefix '%s'", prefix)
+		return false
+	}
+	if len(prefix) != 0 {
+		start = len(z.ns)
+		z.ns = append(z.ns, prefix...)
+		prefix = z.ns[start:len(z.ns):len(z.ns)]
+	}
+	z.bindings = append(z.bindings, binding{prefix, uri})
+	return true
+}
+
+// resolve sets the name of a tag or attribute token. Unprefixed attributes are in no namespace.
+func (z *NamespaceLexer) resolve(tok *nsToken, isElement bool) bool {
+	prefix, local := []byte(nil), tok.text
+	if i := bytes.IndexByte(tok.text, ':'); i != -1 {
+		prefix, local = tok.text[:i], tok.text[i+1:]
+		if len(prefix) == 0 || len(local) == 0 || bytes.IndexByte(local, ':') != -1 {
+			z.fail(tok.offset, "invalid qualified name '%s'", tok.text)
+			return false
+		} else if isElement && bytes.Equal(prefix, xmlnsBytes) {
+			z.fail(tok.offset, "elements cannot have the xmlns prefix")
+			return false
+		}
+	} else if !isElement {
+		tok.name = Name{nil, local}
+		return true
+	}
+
+	uri, ok := z.Lookup(prefix)
+	if !ok {
+		z.fail(tok.offset, "undeclared namespace prefix '%s'", prefix)
+		return false
+	}
+	tok.name = Name{uri, local}
+	return true
+}
+
+func (z *NamespaceLexer) pop() {
+	if last := len(z.scopes) - 1; 0 <= last {
+		z.bindings = z.bindings[:z.scopes[last].bindings]
+		z.ns = z.ns[:z.scopes[last].ns]
+		z.scopes = z.scopes[:last]
+	}
+}
+
+func (z *NamespaceLexer) fail(offset int, message string, a ...interface{}) {
+	z.err = parse.NewErrorLexerOffset(z.l.r, offset, "XML namespace error: "+message, a...)
+	z.tok = nsToken{end: z.tok.end}
+	z.tokens = z.tokens[:0]
+}
+
+// copyToken returns a token with copies of the data returned by the Lexer, which otherwise may be overwritten when reading from a stream.
+func (z *NamespaceLexer) copyToken(tt TokenType, data []byte) nsToken {
+	return nsToken{
+		tt:      tt,
+		data:    z.copy(data),
+		text:    z.copy(z.l.Text()),
+		attrVal: z.copy(z.l.AttrVal()),
+		end:     z.l.Offset(),
+	}
+}
+
+func (z *NamespaceLexer) copy(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	start := len(z.buf)
+	z.buf = append(z.buf, b...)
+	return z.buf[start:len(z.buf):len(z.buf)]
+}
+
+// unescapeAttrVal appends the attribute value b without quotes to dst, replacing character and predefined entity references.
+func unescapeAttrVal(dst, b []byte) []byte {
+	if 1 < len(b) && (b[0] == '"' || b[0] == '\'') && b[0] == b[len(b)-1] {
+		b = b[1 : len(b)-1]
+	}
+	for i := 0; i < len(b); i++ {
+		if b[i] != '&' {
+			dst = append(dst, b[i])
+			continue
+		}
+		end := bytes.IndexByte(b[i:], ';')
+		if end == -1 {
+			dst = append(dst, b[i:]...)
+			break
+		}
+		entity := b[i+1 : i+end]
+		switch string(entity) {
+		case "lt":
+			dst = append(dst, '<')
+		case "gt":
+			dst = append(dst, '>')
+		case "amp":
+			dst = append(dst, '&')
+		case "apos":
+			dst = append(dst, '\'')
+		case "quot":
+			dst = append(dst, '"')
+		default:
+			var r uint64
+			var err error = strconv.ErrSyntax
+			if 2 < len(entity) && entity[0] == '#' && (entity[1] == 'x' || entity[1] == 'X') {
+				r, err = strconv.ParseUint(string(entity[2:]), 16, 32)
+			} else if 1 < len(entity) && entity[0] == '#' {
+				r, err = strconv.ParseUint(string(entity[1:]), 10, 32)
+			}
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				dst = append(dst, b[i:i+end+1]...)
+			} else {
+				var rb [utf8.UTFMax]byte
+				n := utf8.EncodeRune(rb[:], rune(r))
+				dst = append(dst, rb[:n]...)
+			}
+		}
+		i += end
+	}
+	return dst
+}
@@ -0,0 +1,161 @@
+package xml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func namespaceNames(z *NamespaceLexer) []string {
+	names := []string{}
+	for {
+		tt, _ := z.Next()
+		switch tt {
+		case ErrorToken:
+			return names
+		case StartTagToken, StartTagPIToken:
+			names = append(names, "<"+z.Name().String())
+		case EndTagToken:
+			names = append(names, "</"+z.Name().String())
+		case AttributeToken:
+			names = append(names, z.Name().String()+"="+string(z.AttrVal()))
+		}
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	var namespaceTests = []struct {
+		xml      string
+		expected string
+	}{
+		{"<a/>", "<a"},
+		{"<a b='c'></a>", "<a b='c' </a"},
+		{"<a xmlns='x'><b c='d'/></a>", "<{x}a {http://www.w3.org/2000/xmlns/}xmlns='x' <{x}b c='d' </{x}a"},
+		{"<p:a xmlns:p='x' p:b='c'/>", "<{x}a {http://www.w3.org/2000/xmlns/}p='x' {x}b='c'"},
+		{"<p:a p:b='c' xmlns:p='x'/>", "<{x}a {x}b='c' {http://www.w3.org/2000/xmlns/}p='x'"},
+		{"<a xmlns='x'><b xmlns=''><c/></b><d/></a>", "<{x}a {http://www.w3.org/2000/xmlns/}xmlns='x' <b {http://www.w3.org/2000/xmlns/}xmlns='' <c </b <{x}d </{x}a"},
+		{"<p:a xmlns:p='x'><p:b xmlns:p='y'/><p:c/></p:a>", "<{x}a {http://www.w3.org/2000/xmlns/}p='x' <{y}b {http://www.w3.org/2000/xmlns/}p='y' <{x}c </{x}a"},
+		{"<a xml:lang='en'/>", "<a {http://www.w3.org/XML/1998/namespace}lang='en'"},
+		{"<a xmlns:p='x&amp;y&#65;&#x42;&unknown;'><p:b/></a>", "<a {http://www.w3.org/2000/xmlns/}p='x&amp;y&#65;&#x42;&unknown;' <{x&yAB&unknown;}b </a"},
+		{"<a xmlns:xml='http://www.w3.org/XML/1998/namespace'/>", "<a {http://www.w3.org/2000/xmlns/}xml='http://www.w3.org/XML/1998/namespace'"},
+		{"<?xml version='1.0'?><a/>", "<xml version='1.0' <a"},
+		{"<p:a xmlns:p='x'>text<!-- c --></p:a >", "<{x}a {http://www.w3.org/2000/xmlns/}p='x' </{x}a"},
+		{"<p:a xmlns:p='x'", "<{x}a {http://www.w3.org/2000/xmlns/}p='x'"},
+	}
+	for _, tt := range namespaceTests {
+		t.Run(tt.xml, func(t *testing.T) {
+			z := NewNamespaceLexer(NewLexer(bytes.NewBufferString(tt.xml)))
+			test.String(t, strings.Join(namespaceNames(z), " "), tt.expected)
+			test.T(t, z.Err(), io.EOF)
+		})
+	}
+}
+
+func TestNamespaceTokens(t *testing.T) {
+	z := NewNamespaceLexer(NewLexer(bytes.NewBufferString("<p:a xmlns:p=\"x\" b>c</p:a>")))
+	tt, data := z.Next()
+	test.T(t, tt, StartTagToken)
+	test.String(t, string(data), "<p:a")
+	test.String(t, string(z.Text()), "p:a")
+	test.T(t, z.Offset(), 4)
+	test.T(t, z.Depth(), 1)
+	uri, ok := z.Lookup([]byte("p"))
+	test.That(t, ok)
+	test.String(t, string(uri), "x")
+	_, ok = z.Lookup([]byte("q"))
+	test.That(t, !ok)
+
+	tt, data = z.Next()
+	test.T(t, tt, AttributeToken)
+	test.String(t, string(data), " xmlns:p=\"x\"")
+	test.String(t, string(z.AttrVal()), "\"x\"")
+	tt, _ = z.Next()
+	test.T(t, tt, AttributeToken)
+	test.T(t, z.Name(), Name{nil, []byte("b")})
+	test.T(t, z.AttrVal(), []byte(nil))
+	tt, _ = z.Next()
+	test.T(t, tt, StartTagCloseToken)
+	test.T(t, z.Offset(), 19)
+	tt, data = z.Next()
+	test.T(t, tt, TextToken)
+	test.String(t, string(data), "c")
+	test.T(t, z.Name(), Name{})
+	tt, _ = z.Next()
+	test.T(t, tt, EndTagToken)
+	test.String(t, z.Name().String(), "{x}a")
+	test.T(t, z.Depth(), 0)
+	_, ok = z.Lookup([]byte("p"))
+	test.That(t, !ok, "prefix must be out of scope")
+}
+
+func TestNamespaceError(t *testing.T) {
+	var errorTests = []struct {
+		xml string
+		err string
+		col int
+	}{
+		{"<p:a/>", "undeclared namespace prefix 'p'", 2},
+		{"<a>\n  <b p:c='d'/></a>", "undeclared namespace prefix 'p'", 6},
+		{"<p:a xmlns:p='x'/><p:b/>", "undeclared namespace prefix 'p'", 20},
+		{"<p:a xmlns:p='x'></p:a></p:b>", "undeclared namespace prefix 'p'", 26},
+		{"<a xmlns:p=''/>", "empty namespace URI for prefix 'p'", 4},
+		{"<a xmlns:xmlns='x'/>", "cannot declare the xmlns prefix", 4},
+		{"<a xmlns:xml='x'/>", "the xml prefix must be bound to http://www.w3.org/XML/1998/namespace", 4},
+		{"<a xmlns:p='http://www.w3.org/2000/xmlns/'/>", "cannot bind to http://www.w3.org/2000/xmlns/", 4},
+		{"<a xmlns='http://www.w3.org/XML/1998/namespace'/>", "cannot bind to http://www.w3.org/XML/1998/namespace", 4},
+		{"<xmlns:a/>", "elements cannot have the xmlns prefix", 2},
+		{"<:a/>", "invalid qualified name ':a'", 2},
+		{"<a: xmlns:a='x'/>", "invalid qualified name 'a:'", 2},
+		{"<a xmlns:p='x' b:c:d=''/>", "invalid qualified name 'b:c:d'", 16},
+		{"<a b='' b=''/>", "duplicate attribute 'b'", 9},
+		{"<a xmlns:p='x' xmlns:q='x' p:b='' q:b=''/>", "duplicate attribute 'q:b'", 35},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.xml, func(t *testing.T) {
+			z := NewNamespaceLexer(NewLexer(bytes.NewBufferString(tt.xml)))
+			namespaceNames(z)
+			if perr, ok := z.Err().(*parse.Error); ok {
+				test.String(t, perr.Message, "XML namespace error: "+tt.err)
+				_, col, _ := perr.Position()
+				test.T(t, col, tt.col)
+			} else {
+				test.Fail(t, "bad error:", z.Err())
+			}
+			tt, _ := z.Next()
+			test.T(t, tt, ErrorToken, "must keep returning errors")
+		})
+	}
+}
+
+func TestNamespaceStream(t *testing.T) {
+	xml := "<?xml version=\"1.0\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\">\n" +
+		strings.Repeat("<g><use xlink:href=\"#a\" x=\"1\"/><text xml:space=\"preserve\">a</text></g>\n", 100) + "</svg>"
+	expected := namespaceNames(NewNamespaceLexer(NewLexer(bytes.NewBufferString(xml))))
+	z := NewNamespaceLexer(NewStreamLexer(iotest.OneByteReader(bytes.NewBufferString(xml))))
+	test.T(t, namespaceNames(z), expected)
+	test.T(t, z.Err(), io.EOF)
+}
+
+func ExampleNamespaceLexer() {
+	z := NewNamespaceLexer(NewLexer(bytes.NewBufferString(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="#a"/></svg>`)))
+	for {
+		tt, _ := z.Next()
+		if tt == ErrorToken {
+			break
+		} else if tt == StartTagToken || tt == AttributeToken {
+			fmt.Println(tt, z.Name())
+		}
+	}
+	// Output:
+	// StartTag {http://www.w3.org/2000/svg}svg
+	// Attribute {http://www.w3.org/2000/xmlns/}xmlns
+	// Attribute {http://www.w3.org/2000/xmlns/}xlink
+	// StartTag {http://www.w3.org/2000/svg}use
+	// Attribute {http://www.w3.org/1999/xlink}href
+}
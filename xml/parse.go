@@ -0,0 +1,587 @@
+package xml
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+)
+
+// NodeType determines the type of node, eg. an element or a text node.
+type NodeType uint32
+
+// NodeType values.
+const (
+	ErrorNode NodeType = iota
+	DocumentNode
+	DoctypeNode
+	ElementNode
+	TextNode
+	CDATANode
+	CommentNode
+	ProcInstNode
+)
+
+// String returns the string representation of a NodeType.
+func (nt NodeType) String() string {
+	switch nt {
+	case ErrorNode:
+		return "Error"
+	case DocumentNode:
+		return "Document"
+	case DoctypeNode:
+		return "Doctype"
+	case ElementNode:
+		return "Element"
+	case TextNode:
+		return "Text"
+	case CDATANode:
+		return "CDATA"
+	case CommentNode:
+		return "Comment"
+	case ProcInstNode:
+		return "ProcInst"
+	}
+	return "Invalid(" + strconv.Itoa(int(nt)) + ")"
+}
+
+// Attr is an attribute of an element or processing instruction. Val is the unquoted value, and is nil for attributes without a value.
+type Attr struct {
+	Key, Val []byte
+}
+
+// Node is a node in the document tree. Data holds the element name, the text, the CDATA contents, the comment, the processing instruction target, or the doctype.
+// The pseudo-attributes of processing instructions such as <?xml version="1.0"?> are stored in Attrs.
+type Node struct {
+	Type  NodeType
+	Data  []byte
+	Attrs []Attr
+
+	Parent, FirstChild, LastChild, PrevSibling, NextSibling *Node
+}
+
+// Attr returns the value of the attribute with the given key and whether it exists.
+func (n *Node) Attr(key string) ([]byte, bool) {
+	for _, attr := range n.Attrs {
+		if string(attr.Key) == key {
+			return attr.Val, true
+		}
+	}
+	return nil, false
+}
+
+// AppendChild adds c as the last child of n. It panics if c already has a parent.
+func (n *Node) AppendChild(c *Node) {
+	n.InsertBefore(c, nil)
+}
+
+// InsertBefore inserts c as a child of n, immediately before ref, or as the last child if ref is nil. It panics if c already has a parent.
+func (n *Node) InsertBefore(c, ref *Node) {
+	if c.Parent != nil || c.PrevSibling != nil || c.NextSibling != nil {
+		panic("xml: InsertBefore called for an attached child Node")
+	}
+	var prev, next *Node
+	if ref != nil {
+		prev, next = ref.PrevSibling, ref
+	} else {
+		prev = n.LastChild
+	}
+	if prev != nil {
+		prev.NextSibling = c
+	} else {
+		n.FirstChild = c
+	}
+	if next != nil {
+		next.PrevSibling = c
+	} else {
+		n.LastChild = c
+	}
+	c.Parent, c.PrevSibling, c.NextSibling = n, prev, next
+}
+
+// RemoveChild removes the child c from n. It panics if c is not a child of n.
+func (n *Node) RemoveChild(c *Node) {
+	if c.Parent != n {
+		panic("xml: RemoveChild called for a non-child Node")
+	}
+	if c.PrevSibling != nil {
+		c.PrevSibling.NextSibling = c.NextSibling
+	} else {
+		n.FirstChild = c.NextSibling
+	}
+	if c.NextSibling != nil {
+		c.NextSibling.PrevSibling = c.PrevSibling
+	} else {
+		n.LastChild = c.PrevSibling
+	}
+	c.Parent, c.PrevSibling, c.NextSibling = nil, nil, nil
+}
+
+// String returns the XML serialization of the node and its descendants. Text and attribute values are written as they are stored.
+func (n *Node) String() string {
+	buf := &bytes.Buffer{}
+	n.render(buf)
+	return buf.String()
+}
+
+func (n *Node) render(buf *bytes.Buffer) {
+	switch n.Type {
+	case DoctypeNode:
+		buf.WriteString("<!DOCTYPE ")
+		buf.Write(n.Data)
+		buf.WriteByte('>')
+		return
+	case TextNode:
+		buf.Write(n.Data)
+		return
+	case CDATANode:
+		buf.WriteString("<![CDATA[")
+		buf.Write(n.Data)
+		buf.WriteString("]]>")
+		return
+	case CommentNode:
+		buf.WriteString("<!--")
+		buf.Write(n.Data)
+		buf.WriteString("-->")
+		return
+	case ProcInstNode, ElementNode:
+		if n.Type == ProcInstNode {
+			buf.WriteString("<?")
+		} else {
+			buf.WriteByte('<')
+		}
+		buf.Write(n.Data)
+		for _, attr := range n.Attrs {
+			buf.WriteByte(' ')
+			buf.Write(attr.Key)
+			if attr.Val != nil {
+				quote := byte('"')
+				if bytes.IndexByte(attr.Val, '"') != -1 {
+					quote = '\''
+				}
+				buf.WriteByte('=')
+				buf.WriteByte(quote)
+				buf.Write(attr.Val)
+				buf.WriteByte(quote)
+			}
+		}
+		if n.Type == ProcInstNode {
+			buf.WriteString("?>")
+			return
+		} else if n.FirstChild == nil {
+			buf.WriteString("/>")
+			return
+		}
+		buf.WriteByte('>')
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		c.render(buf)
+	}
+	if n.Type == ElementNode {
+		buf.WriteString("</")
+		buf.Write(n.Data)
+		buf.WriteByte('>')
+	}
+}
+
+////////////////////////////////////////////////////////////////
+
+type treeBuilder struct {
+	l      *Lexer
+	strict bool
+	err    error
+
+	doc     *Node
+	oe      []*Node // stack of open elements
+	root    bool    // whether the root element has been seen
+	doctype bool    // whether the doctype has been seen
+	dtd     bool    // whether the doctype has an external or internal subset, which may declare entities
+	first   bool    // whether the current token is the first in the document
+}
+
+// Parse parses an XML document and returns the document node. It recovers from errors: end tags close the most recent open element with the same name and are ignored otherwise, unclosed elements are closed at the end of the input, and duplicate attributes are dropped.
+// Character and entity references are not decoded. The error is only non-nil for read errors.
+func Parse(r io.Reader) (*Node, error) {
+	b := &treeBuilder{
+		l:   NewLexer(r),
+		doc: &Node{Type: DocumentNode},
+	}
+	b.parse()
+	if _, ok := b.err.(*parse.Error); ok {
+		b.err = nil
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.doc, nil
+}
+
+// ParseStrict parses an XML document like Parse, but checks that it is well-formed following the XML 1.0 specification.
+// This includes matching start and end tags, a single root element, unique attributes, quoted attribute values, legal names and characters, and well-formed references, comments, and processing instructions.
+// Any violation is returned as a *parse.Error with the line and column. Entity references must be one of the five predefined entities unless the doctype has an external or internal subset, other constraints imposed by the doctype are not checked.
+func ParseStrict(r io.Reader) (*Node, error) {
+	b := &treeBuilder{
+		l:      NewLexer(r),
+		strict: true,
+		doc:    &Node{Type: DocumentNode},
+	}
+	b.parse()
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.doc, nil
+}
+
+func (b *treeBuilder) parse() {
+	b.first = true
+	for b.err == nil {
+		offset := b.l.Offset()
+		tt, data := b.l.Next()
+		switch tt {
+		case ErrorToken:
+			if err := b.l.Err(); err != io.EOF {
+				b.err = err
+			} else if b.strict && 0 < len(b.oe) {
+				b.fail(offset, "unclosed element '%s'", b.oe[len(b.oe)-1].Data)
+			} else if b.strict && !b.root {
+				b.fail(offset, "missing root element")
+			}
+			return
+		case StartTagToken, StartTagPIToken:
+			b.startTag(tt, offset)
+		case EndTagToken:
+			b.endTag(data, offset)
+		case TextToken:
+			if b.first && bytes.HasPrefix(data, []byte("\xEF\xBB\xBF")) {
+				// byte order mark
+				data = data[3:]
+				offset += 3
+				if len(data) == 0 {
+					continue
+				}
+			}
+			if b.strict {
+				if len(b.oe) == 0 {
+					if i := firstNonWhitespace(data); i != -1 {
+						b.fail(offset+i, "text outside the root element")
+						break
+					}
+				} else if !b.checkText(data, offset, false) {
+					break
+				} else if i := bytes.Index(data, []byte("]]>")); i != -1 {
+					b.fail(offset+i, "unexpected ']]>' in text")
+					break
+				}
+			}
+			if 0 < len(b.oe) {
+				b.insert(&Node{Type: TextNode, Data: parse.Copy(data)})
+			}
+		case CDATAToken:
+			if b.strict {
+				if !bytes.HasSuffix(data, []byte("]]>")) {
+					b.fail(offset+len(data), "unexpected end of input in CDATA section")
+					break
+				} else if len(b.oe) == 0 {
+					b.fail(offset, "CDATA section outside the root element")
+					break
+				} else if !b.checkChars(b.l.Text(), offset+9) {
+					break
+				}
+			}
+			if 0 < len(b.oe) {
+				b.insert(&Node{Type: CDATANode, Data: parse.Copy(b.l.Text())})
+			}
+		case CommentToken:
+			text := b.l.Text()
+			if !bytes.HasSuffix(data, []byte("-->")) {
+				text = data[4:]
+				if b.strict {
+					b.fail(offset+len(data), "unexpected end of input in comment")
+					break
+				}
+			} else if b.strict {
+				if i := bytes.Index(text, []byte("--")); i != -1 {
+					b.fail(offset+4+i, "unexpected '--' in comment")
+					break
+				} else if bytes.HasSuffix(text, []byte("-")) {
+					b.fail(offset+4+len(text)-1, "unexpected '-' at the end of comment")
+					break
+				} else if !b.checkChars(text, offset+4) {
+					break
+				}
+			}
+			b.insert(&Node{Type: CommentNode, Data: parse.Copy(text)})
+		case DOCTYPEToken:
+			if b.strict {
+				if !bytes.HasSuffix(data, []byte(">")) {
+					b.fail(offset+len(data), "unexpected end of input in doctype")
+					break
+				} else if b.doctype || b.root {
+					b.fail(offset, "unexpected doctype")
+					break
+				}
+			}
+			b.doctype = true
+			b.dtd = hasSubset(b.l.Text())
+			if len(b.oe) == 0 {
+				b.insert(&Node{Type: DoctypeNode, Data: parse.Copy(parse.TrimWhitespace(b.l.Text()))})
+			}
+		}
+		b.first = false
+	}
+}
+
+func (b *treeBuilder) startTag(tt TokenType, offset int) {
+	isProcInst := tt == StartTagPIToken
+	first := b.first
+	n := &Node{Type: ElementNode, Data: parse.Copy(b.l.Text())}
+	if isProcInst {
+		n.Type = ProcInstNode
+		offset++
+	}
+	if b.strict && !isProcInst && !isName(n.Data) {
+		b.fail(offset+1, "invalid element name '%s'", n.Data)
+		return
+	} else if b.strict && isProcInst {
+		if !isName(n.Data) || bytes.IndexByte(n.Data, ':') != -1 {
+			b.fail(offset+1, "invalid processing instruction target '%s'", n.Data)
+			return
+		} else if parse.EqualFold(n.Data, xmlBytes) && (!first || !bytes.Equal(n.Data, xmlBytes)) {
+			b.fail(offset+1, "unexpected XML declaration")
+			return
+		}
+	}
+
+	isOpen := false
+	for {
+		attrOffset := b.l.Offset()
+		tt, data := b.l.Next()
+		if tt != AttributeToken {
+			if tt == ErrorToken {
+				if err := b.l.Err(); err != io.EOF {
+					b.err = err
+					return
+				} else if b.strict {
+					b.fail(attrOffset, "unexpected end of input in tag")
+					return
+				}
+			} else if b.strict && isProcInst != (tt == StartTagClosePIToken) {
+				if isProcInst {
+					b.fail(attrOffset, "expected '?>' instead of '%s'", data)
+				} else {
+					b.fail(attrOffset, "unexpected '?>'")
+				}
+				return
+			}
+			isOpen = !isProcInst && tt == StartTagCloseToken
+			break
+		}
+
+		key := b.l.Text()
+		attrOffset += len(data) - len(parse.TrimWhitespace(data))
+		if b.strict && !isProcInst {
+			if !isName(key) {
+				b.fail(attrOffset, "invalid attribute name '%s'", key)
+				return
+			} else if b.l.AttrVal() == nil {
+				b.fail(attrOffset+len(key), "expected '=' after attribute name '%s'", key)
+				return
+			}
+			val := b.l.AttrVal()
+			valOffset := attrOffset + len(parse.TrimWhitespace(data)) - len(val)
+			if len(val) < 2 || val[0] != '"' && val[0] != '\'' || val[len(val)-1] != val[0] {
+				b.fail(valOffset, "expected quoted attribute value")
+				return
+			} else if !b.checkText(val[1:len(val)-1], valOffset+1, true) {
+				return
+			}
+		}
+		duplicate := false
+		for _, attr := range n.Attrs {
+			if bytes.Equal(attr.Key, key) {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate && b.strict && !isProcInst {
+			b.fail(attrOffset, "duplicate attribute '%s'", key)
+			return
+		} else if !duplicate {
+			n.Attrs = append(n.Attrs, Attr{parse.Copy(key), unquote(b.l.AttrVal())})
+		}
+	}
+
+	if !isProcInst {
+		if b.strict && b.root && len(b.oe) == 0 {
+			b.fail(offset, "multiple root elements")
+			return
+		}
+		b.root = true
+	}
+	b.insert(n)
+	if isOpen {
+		b.oe = append(b.oe, n)
+	}
+}
+
+func (b *treeBuilder) endTag(data []byte, offset int) {
+	name := b.l.Text()
+	if b.strict {
+		if !bytes.HasSuffix(data, []byte(">")) {
+			b.fail(offset+len(data), "unexpected end of input in tag")
+		} else if len(b.oe) == 0 {
+			b.fail(offset, "unexpected end tag '</%s>'", name)
+		} else if top := b.oe[len(b.oe)-1]; !bytes.Equal(top.Data, name) {
+			b.fail(offset, "expected end tag '</%s>' instead of '</%s>'", top.Data, name)
+		} else {
+			b.oe = b.oe[:len(b.oe)-1]
+		}
+		return
+	}
+	for i := len(b.oe) - 1; 0 <= i; i-- {
+		if bytes.Equal(b.oe[i].Data, name) {
+			b.oe = b.oe[:i]
+			break
+		}
+	}
+}
+
+func (b *treeBuilder) insert(n *Node) {
+	if 0 < len(b.oe) {
+		b.oe[len(b.oe)-1].AppendChild(n)
+	} else {
+		b.doc.AppendChild(n)
+	}
+}
+
+func (b *treeBuilder) fail(offset int, message string, a ...interface{}) {
+	b.err = parse.NewErrorLexerOffset(b.l.r, offset, "XML parse error: "+message, a...)
+}
+
+// checkText checks that text or an attribute value contains only legal characters and well-formed references.
+func (b *treeBuilder) checkText(text []byte, offset int, isAttrVal bool) bool {
+	if !b.checkChars(text, offset) {
+		return false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '<' && isAttrVal {
+			b.fail(offset+i, "unexpected '<' in attribute value")
+			return false
+		} else if text[i] == '&' {
+			n := referenceLen(text[i:])
+			if n == 0 {
+				b.fail(offset+i, "invalid reference")
+				return false
+			} else if name := text[i+1 : i+n-1]; b.strict && !b.dtd && name[0] != '#' && !isPredefinedEntity(name) {
+				b.fail(offset+i, "undeclared entity '%s'", name)
+				return false
+			}
+			i += n - 1
+		}
+	}
+	return true
+}
+
+func (b *treeBuilder) checkChars(text []byte, offset int) bool {
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRune(text[i:])
+		if !isChar(r) || r == utf8.RuneError && n == 1 {
+			b.fail(offset+i, "illegal character %U", r)
+			return false
+		}
+		i += n
+	}
+	return true
+}
+
+// hasSubset returns true if the doctype contents have an external ID or an internal subset following the root element name.
+func hasSubset(doctype []byte) bool {
+	return 1 < len(bytes.Fields(doctype)) || bytes.IndexByte(doctype, '[') != -1
+}
+
+// isPredefinedEntity returns true for the entities that are declared without a doctype, see https://www.w3.org/TR/xml/#sec-predefined-ent.
+func isPredefinedEntity(name []byte) bool {
+	switch string(name) {
+	case "lt", "gt", "amp", "apos", "quot":
+		return true
+	}
+	return false
+}
+
+// referenceLen returns the length of the character or entity reference at the start of b, or zero if it is not well-formed.
+func referenceLen(b []byte) int {
+	end := bytes.IndexByte(b, ';')
+	if end < 2 {
+		return 0
+	}
+	ref := b[1:end]
+	if ref[0] != '#' {
+		if !isName(ref) {
+			return 0
+		}
+		return end + 1
+	}
+
+	var r uint64
+	var err error
+	if 1 < len(ref) && ref[1] == 'x' {
+		r, err = strconv.ParseUint(string(ref[2:]), 16, 32)
+	} else {
+		r, err = strconv.ParseUint(string(ref[1:]), 10, 32)
+	}
+	if err != nil || !isChar(rune(r)) {
+		return 0
+	}
+	return end + 1
+}
+
+// isChar returns true for the characters allowed in XML documents, see https://www.w3.org/TR/xml/#NT-Char.
+func isChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D || 0x20 <= r && r <= 0xD7FF || 0xE000 <= r && r <= 0xFFFD || 0x10000 <= r && r <= 0x10FFFF
+}
+
+// isName returns true if b is a valid XML name, see https://www.w3.org/TR/xml/#NT-Name.
+func isName(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for i := 0; i < len(b); {
+		r, n := utf8.DecodeRune(b[i:])
+		if r == utf8.RuneError && n == 1 || !isNameStartChar(r) && (i == 0 || !isNameChar(r)) {
+			return false
+		}
+		i += n
+	}
+	return true
+}
+
+func isNameStartChar(r rune) bool {
+	return r == ':' || 'A' <= r && r <= 'Z' || r == '_' || 'a' <= r && r <= 'z' || 0xC0 <= r && r <= 0xD6 || 0xD8 <= r && r <= 0xF6 || 0xF8 <= r && r <= 0x2FF || 0x370 <= r && r <= 0x37D || 0x37F <= r && r <= 0x1FFF || 0x200C <= r && r <= 0x200D || 0x2070 <= r && r <= 0x218F || 0x2C00 <= r && r <= 0x2FEF || 0x3001 <= r && r <= 0xD7FF || 0xF900 <= r && r <= 0xFDCF || 0xFDF0 <= r && r <= 0xFFFD || 0x10000 <= r && r <= 0xEFFFF
+}
+
+func isNameChar(r rune) bool {
+	return r == '-' || r == '.' || '0' <= r && r <= '9' || r == 0xB7 || 0x300 <= r && r <= 0x36F || 0x203F <= r && r <= 0x2040
+}
+
+func firstNonWhitespace(b []byte) int {
+	for i, c := range b {
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return i
+		}
+	}
+	return -1
+}
+
+func unquote(b []byte) []byte {
+	if b == nil {
+		return nil
+	} else if 0 < len(b) && (b[0] == '"' || b[0] == '\'') {
+		if 1 < len(b) && b[len(b)-1] == b[0] {
+			return parse.Copy(b[1 : len(b)-1])
+		}
+		return parse.Copy(b[1:])
+	}
+	return parse.Copy(b)
+}
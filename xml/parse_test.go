@@ -0,0 +1,178 @@
+package xml
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/test"
+)
+
+func TestParse(t *testing.T) {
+	var parseTests = []struct {
+		xml      string
+		expected string
+	}{
+		{"<a/>", "<a/>"},
+		{"<a></a>", "<a/>"},
+		{"<a b='c' d=\"e\" f=g h>text</a>", "<a b=\"c\" d=\"e\" f=\"g\" h>text</a>"},
+		{"<a b='\"'/>", "<a b='\"'/>"},
+		{"<a b='1' b='2'/>", "<a b=\"1\"/>"},
+		{"<?xml version=\"1.0\"?>\n<!DOCTYPE a SYSTEM \"a.dtd\">\n<a><!-- c --><![CDATA[<d>]]><?pi x?></a>\n", "<?xml version=\"1.0\"?><!DOCTYPE a SYSTEM \"a.dtd\"><a><!-- c --><![CDATA[<d>]]><?pi x?></a>"},
+		{"<a><b><c></b>d</a>", "<a><b><c/></b>d</a>"},
+		{"<a></b>c</a>", "<a>c</a>"},
+		{"<a><b>", "<a><b/></a>"},
+		{"<a/><b/>", "<a/><b/>"},
+		{"\xEF\xBB\xBF<a/>", "<a/>"},
+		{"<a><!-- c", "<a><!-- c--></a>"},
+		{"<a x=\"\x00\">", ""},
+	}
+	for _, tt := range parseTests {
+		t.Run(tt.xml, func(t *testing.T) {
+			doc, err := Parse(bytes.NewBufferString(tt.xml))
+			test.Error(t, err)
+			test.T(t, doc.Type, DocumentNode)
+			test.String(t, doc.String(), tt.expected)
+		})
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	doc, err := ParseStrict(bytes.NewBufferString("<?xml version=\"1.0\"?><a x=\"1\">b<c/><!--d--></a>"))
+	test.Error(t, err)
+	pi := doc.FirstChild
+	test.T(t, pi.Type, ProcInstNode)
+	test.String(t, string(pi.Data), "xml")
+	version, _ := pi.Attr("version")
+	test.String(t, string(version), "1.0")
+
+	a := pi.NextSibling
+	test.T(t, a.Type, ElementNode)
+	test.T(t, a.Parent, doc)
+	test.T(t, doc.LastChild, a)
+	x, ok := a.Attr("x")
+	test.That(t, ok)
+	test.String(t, string(x), "1")
+	_, ok = a.Attr("y")
+	test.That(t, !ok)
+
+	test.T(t, a.FirstChild.Type, TextNode)
+	test.T(t, a.FirstChild.NextSibling.Type, ElementNode)
+	test.T(t, a.LastChild.Type, CommentNode)
+	test.T(t, a.LastChild.PrevSibling, a.FirstChild.NextSibling)
+
+	c := a.FirstChild.NextSibling
+	a.RemoveChild(c)
+	doc.InsertBefore(c, a)
+	test.String(t, doc.String(), "<?xml version=\"1.0\"?><c/><a x=\"1\">b<!--d--></a>")
+	test.T(t, ElementNode.String(), "Element")
+	test.T(t, NodeType(100).String(), "Invalid(100)")
+}
+
+func TestParseStrict(t *testing.T) {
+	var validTests = []string{
+		"<a/>",
+		"<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE a [<!ENTITY b \"c\">]>\n<!-- comment -->\n<a>&b;&amp;&#65;&#x42;</a>\n<?pi?>\n",
+		"\xEF\xBB\xBF<?xml version=\"1.0\"?><a/>",
+		"<a:b xmlns:a='x' a:c='&lt;' d=\"'\">\n\t<e-f.g_h·/><![CDATA[<&]]>é\U0001F600</a:b >",
+		"<élément/>",
+		"<a b='&quot;&apos;'>&lt;&gt;&amp;</a>",
+		"<!DOCTYPE a SYSTEM \"a.dtd\"><a>&b;</a>",
+		"<!DOCTYPE a[<!ENTITY b \"c\">]><a b='&b;'/>",
+	}
+	for _, xml := range validTests {
+		t.Run(xml, func(t *testing.T) {
+			doc, err := ParseStrict(bytes.NewBufferString(xml))
+			test.Error(t, err)
+			doc2, _ := Parse(bytes.NewBufferString(xml))
+			test.String(t, doc.String(), doc2.String())
+		})
+	}
+
+	var errorTests = []struct {
+		xml  string
+		err  string
+		line int
+		col  int
+	}{
+		{"", "missing root element", 1, 1},
+		{"<!-- c -->", "missing root element", 1, 11},
+		{"<a>", "unclosed element 'a'", 1, 4},
+		{"<a>\n<b></a>", "expected end tag '</b>' instead of '</a>'", 2, 4},
+		{"<a/></a>", "unexpected end tag '</a>'", 1, 5},
+		{"<a/><b/>", "multiple root elements", 1, 5},
+		{"<a/>b", "text outside the root element", 1, 5},
+		{"c<a/>", "text outside the root element", 1, 1},
+		{"<a/><![CDATA[b]]>", "CDATA section outside the root element", 1, 5},
+		{"<a b='1' b='2'/>", "duplicate attribute 'b'", 1, 10},
+		{"<a b/>", "expected '=' after attribute name 'b'", 1, 5},
+		{"<a b=c/>", "expected quoted attribute value", 1, 6},
+		{"<a b='<'/>", "unexpected '<' in attribute value", 1, 7},
+		{"<a b='&'/>", "invalid reference", 1, 7},
+		{"<1a/>", "invalid element name '1a'", 1, 2},
+		{"<a -b=''/>", "invalid attribute name '-b'", 1, 4},
+		{"<!foo>", "invalid element name '!foo'", 1, 2},
+		{"<a>&#0;</a>", "invalid reference", 1, 4},
+		{"<a>&#xZ;</a>", "invalid reference", 1, 4},
+		{"<a>&1;</a>", "invalid reference", 1, 4},
+		{"<a>& b</a>", "invalid reference", 1, 4},
+		{"<a>&bogus;</a>", "undeclared entity 'bogus'", 1, 4},
+		{"<a b='x&c;'/>", "undeclared entity 'c'", 1, 8},
+		{"<!DOCTYPE a><a>&b;</a>", "undeclared entity 'b'", 1, 16},
+		{"<a>]]></a>", "unexpected ']]>' in text", 1, 4},
+		{"<a>\x01</a>", "illegal character U+0001", 1, 4},
+		{"<a>\xFF</a>", "illegal character U+FFFD", 1, 4},
+		{"<a><![CDATA[\x0B]]></a>", "illegal character U+000B", 1, 13},
+		{"<a><![CDATA[b</a>", "unexpected end of input in CDATA section", 1, 18},
+		{"<a><!-- b -- c --></a>", "unexpected '--' in comment", 1, 11},
+		{"<a><!-- b ---></a>", "unexpected '-' at the end of comment", 1, 11},
+		{"<a><!-- b", "unexpected end of input in comment", 1, 10},
+		{"<a/><!DOCTYPE a>", "unexpected doctype", 1, 5},
+		{"<!DOCTYPE a><!DOCTYPE a><a/>", "unexpected doctype", 1, 13},
+		{"<!DOCTYPE a", "unexpected end of input in doctype", 1, 12},
+		{"<a", "unexpected end of input in tag", 1, 3},
+		{"<a></a", "unexpected end of input in tag", 1, 7},
+		{"<a b='1'?>", "unexpected '?>'", 1, 9},
+		{"<?pi />", "expected '?>' instead of '/>'", 1, 5},
+		{"<a/><?xml version='1.0'?>", "unexpected XML declaration", 1, 7},
+		{" <?xml version='1.0'?><a/>", "unexpected XML declaration", 1, 4},
+		{"<?XML?><a/>", "unexpected XML declaration", 1, 3},
+		{"<?a:b?><a/>", "invalid processing instruction target 'a:b'", 1, 3},
+		{"<a>\x00</a>", "unexpected NULL character", 1, 4},
+	}
+	for _, tt := range errorTests {
+		t.Run(tt.xml, func(t *testing.T) {
+			_, err := ParseStrict(bytes.NewBufferString(tt.xml))
+			if perr, ok := err.(*parse.Error); ok {
+				test.String(t, perr.Message, "XML parse error: "+tt.err)
+				line, col, _ := perr.Position()
+				test.T(t, line, tt.line, "line")
+				test.T(t, col, tt.col, "column")
+			} else {
+				test.Fail(t, "bad error:", err)
+			}
+		})
+	}
+
+	_, err := Parse(test.NewErrorReader(0))
+	test.T(t, err, test.ErrPlain)
+}
+
+func ExampleParseStrict() {
+	doc, err := ParseStrict(bytes.NewBufferString(`<list><item id="1">a</item><item id="2">b</item></list>`))
+	if err != nil {
+		panic(err)
+	}
+	for item := doc.FirstChild.FirstChild; item != nil; item = item.NextSibling {
+		id, _ := item.Attr("id")
+		fmt.Println(string(id), string(item.FirstChild.Data))
+	}
+
+	_, err = ParseStrict(bytes.NewBufferString("<list>\n  <item></list>"))
+	fmt.Println(err.(*parse.Error).Message)
+	// Output:
+	// 1 a
+	// 2 b
+	// XML parse error: expected end tag '</item>' instead of '</list>'
+}